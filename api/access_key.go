@@ -5,14 +5,15 @@ import (
 )
 
 type AccessKey struct {
-	ID                uid.ID `json:"id"`
-	Created           Time   `json:"created"`
-	Name              string `json:"name"`
-	IssuedForName     string `json:"issuedForName"`
-	IssuedFor         uid.ID `json:"issuedFor"`
-	ProviderID        uid.ID `json:"providerID"`
-	Expires           Time   `json:"expires,omitempty" note:"key is no longer valid after this time"`
-	ExtensionDeadline Time   `json:"extensionDeadline" note:"key must be renewed after this time"`
+	ID                uid.ID   `json:"id"`
+	Created           Time     `json:"created"`
+	Name              string   `json:"name"`
+	IssuedForName     string   `json:"issuedForName"`
+	IssuedFor         uid.ID   `json:"issuedFor"`
+	ProviderID        uid.ID   `json:"providerID"`
+	Scopes            []string `json:"scopes,omitempty" note:"if set, limits what the key can be used for"`
+	Expires           Time     `json:"expires,omitempty" note:"key is no longer valid after this time"`
+	ExtensionDeadline Time     `json:"extensionDeadline" note:"key must be renewed after this time"`
 }
 
 type ListAccessKeysRequest struct {
@@ -27,15 +28,22 @@ type CreateAccessKeyRequest struct {
 	Name              string   `json:"name" validate:"excludes= "`
 	TTL               Duration `json:"ttl" validate:"required" note:"maximum time valid"`
 	ExtensionDeadline Duration `json:"extensionDeadline,omitempty" validate:"required" note:"How long the key is active for before it needs to be renewed. The access key must be used within this amount of time to renew validity"`
+	Scopes            []string `json:"scopes,omitempty" example:"read-only" note:"limit the key to read-only requests (read-only), creating destination tokens (tokens), creating tokens for one destination (ex: destination:production), or permissions of the infra API (ex: grants:read)"`
+}
+
+type RotateAccessKeyRequest struct {
+	ID          uid.ID   `uri:"id" json:"-" validate:"required"`
+	GracePeriod Duration `json:"gracePeriod,omitempty" note:"how long the previous secret of the key remains valid. If not set, the previous secret is no longer valid"`
 }
 
 type CreateAccessKeyResponse struct {
-	ID                uid.ID `json:"id"`
-	Created           Time   `json:"created"`
-	Name              string `json:"name"`
-	IssuedFor         uid.ID `json:"issuedFor"`
-	ProviderID        uid.ID `json:"providerID"`
-	Expires           Time   `json:"expires" note:"after this deadline the key is no longer valid"`
-	ExtensionDeadline Time   `json:"extensionDeadline" note:"the key must be used by this time to remain valid"`
-	AccessKey         string `json:"accessKey"`
+	ID                uid.ID   `json:"id"`
+	Created           Time     `json:"created"`
+	Name              string   `json:"name"`
+	IssuedFor         uid.ID   `json:"issuedFor"`
+	ProviderID        uid.ID   `json:"providerID"`
+	Scopes            []string `json:"scopes,omitempty"`
+	Expires           Time     `json:"expires" note:"after this deadline the key is no longer valid"`
+	ExtensionDeadline Time     `json:"extensionDeadline" note:"the key must be used by this time to remain valid"`
+	AccessKey         string   `json:"accessKey"`
 }
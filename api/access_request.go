@@ -0,0 +1,52 @@
+package api
+
+import (
+	"github.com/infrahq/infra/uid"
+)
+
+type AccessRequest struct {
+	ID          uid.ID   `json:"id"`
+	Created     Time     `json:"created"`
+	Updated     Time     `json:"updated"`
+	RequestedBy uid.ID   `json:"requestedBy" note:"id of the user that requested access"`
+	Privilege   string   `json:"privilege" example:"cluster-admin" note:"a role or permission"`
+	Resource    string   `json:"resource" example:"production.payments" note:"a resource name in Infra's Universal Resource Notation"`
+	Reason      string   `json:"reason,omitempty" example:"investigating incident 1234"`
+	Duration    Duration `json:"duration,omitempty" note:"how long the grant is valid for once approved, zero means it does not expire"`
+	Status      string   `json:"status" example:"pending" note:"one of pending, approved, or denied"`
+	ReviewedBy  uid.ID   `json:"reviewedBy,omitempty" note:"id of the user that approved or denied the request"`
+	GrantID     uid.ID   `json:"grantID,omitempty" note:"id of the grant created when the request was approved"`
+}
+
+type ListAccessRequestsRequest struct {
+	RequestedBy uid.ID `form:"requestedBy" note:"only show requests made by this user"`
+	Resource    string `form:"resource" example:"production.payments"`
+	Status      string `form:"status" validate:"omitempty,oneof=pending approved denied" example:"pending"`
+	PaginationRequest
+}
+
+type CreateAccessRequestRequest struct {
+	Privilege string   `json:"privilege" validate:"required" example:"cluster-admin" note:"a role or permission"`
+	Resource  string   `json:"resource" validate:"required" example:"production.payments" note:"a resource name in Infra's Universal Resource Notation"`
+	Reason    string   `json:"reason" example:"investigating incident 1234"`
+	Duration  Duration `json:"duration,omitempty" note:"how long the grant is valid for once approved, omit for a grant that does not expire"`
+}
+
+type AccessApprover struct {
+	ID       uid.ID `json:"id"`
+	Created  Time   `json:"created"`
+	User     uid.ID `json:"user,omitempty"`
+	Group    uid.ID `json:"group,omitempty"`
+	Resource string `json:"resource" example:"production" note:"requests for this resource, or any resource within it, may be approved"`
+}
+
+type ListAccessApproversRequest struct {
+	Resource string `form:"resource" example:"production"`
+	PaginationRequest
+}
+
+type CreateAccessApproverRequest struct {
+	User     uid.ID `json:"user" validate:"required_without=Group"`
+	Group    uid.ID `json:"group" validate:"required_without=User"`
+	Resource string `json:"resource" validate:"required" example:"production" note:"requests for this resource, or any resource within it, may be approved"`
+}
@@ -0,0 +1,31 @@
+package api
+
+import (
+	"github.com/infrahq/infra/uid"
+)
+
+type AuditEvent struct {
+	ID          uid.ID `json:"id"`
+	Created     Time   `json:"created"`
+	Actor       uid.ID `json:"actor,omitempty" note:"id of the user that made the request"`
+	ActorName   string `json:"actorName,omitempty"`
+	ActorKind   string `json:"actorKind,omitempty" example:"service-account" note:"user, or service-account when the request was made by automation"`
+	AccessKeyID uid.ID `json:"accessKeyID,omitempty" note:"id of the access key used to authenticate the request"`
+	Method      string `json:"method" example:"DELETE"`
+	Route       string `json:"route" example:"/api/grants/:id"`
+	Path        string `json:"path" example:"/api/grants/4yJ3n3D8E2"`
+	Resource    string `json:"resource,omitempty" example:"grants" note:"the kind of resource targeted by the request"`
+	ResourceID  uid.ID `json:"resourceID,omitempty"`
+	Before      string `json:"before,omitempty" note:"JSON representation of the resource before the request"`
+	After       string `json:"after,omitempty" note:"JSON representation of the resource after the request"`
+	ResultCode  int    `json:"resultCode" example:"204" note:"HTTP status code of the response"`
+}
+
+type ListAuditEventsRequest struct {
+	Actor      uid.ID `form:"actor" note:"only show events for requests made by this user"`
+	Resource   string `form:"resource" example:"grants" note:"only show events that target this kind of resource"`
+	ResourceID uid.ID `form:"resourceID" note:"only show events that target this resource"`
+	After      Time   `form:"after" note:"only show events that occurred after this time"`
+	Before     Time   `form:"before" note:"only show events that occurred before this time"`
+	PaginationRequest
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/infrahq/infra/uid"
+)
+
+type CheckAccessRequest struct {
+	User      uid.ID `form:"user" validate:"required"`
+	Privilege string `form:"privilege" validate:"required" example:"view" note:"a role or permission"`
+	Resource  string `form:"resource" validate:"required" example:"production.web" note:"a resource name in Infra's Universal Resource Notation"`
+}
+
+type CheckAccessResponse struct {
+	Allowed bool               `json:"allowed"`
+	Grants  []CheckAccessGrant `json:"grants" note:"the grants that allow the access"`
+	Missing []string           `json:"missing,omitempty" note:"the permissions that are not granted, when the resource is infra"`
+}
+
+// CheckAccessGrant is a grant that allows access, and the group that the user
+// has the grant through, if any.
+type CheckAccessGrant struct {
+	Grant     Grant  `json:"grant"`
+	GroupName string `json:"groupName,omitempty" note:"the name of the group that was granted access, which the user is a member of"`
+}
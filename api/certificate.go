@@ -0,0 +1,13 @@
+package api
+
+type SignCertificateRequest struct {
+	// CertificateSigningRequest is a PEM encoded certificate signing request
+	// for the key of a client certificate.
+	CertificateSigningRequest PEM `json:"certificateSigningRequest" validate:"required"`
+}
+
+type SignCertificateResponse struct {
+	// Certificate is the PEM encoded client certificate, signed by the server.
+	Certificate PEM  `json:"certificate"`
+	Expires     Time `json:"expires"`
+}
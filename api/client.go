@@ -90,20 +90,9 @@ func request[Req, Res any](client Client, method string, path string, query Quer
 
 	req.URL.RawQuery = url.Values(query).Encode()
 
-	clientName, clientVersion := "client", "unknown"
-	if client.Name != "" {
-		clientName = client.Name
-	}
-
-	if client.Version != "" {
-		clientVersion = client.Version
-	}
-
-	req.Header.Add("Authorization", "Bearer "+client.AccessKey)
+	setHeaders(client, req)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Infra-Version", apiVersion)
-	req.Header.Set("User-Agent", fmt.Sprintf("Infra/%v (%s %v; %v/%v)", apiVersion, clientName, clientVersion, runtime.GOOS, runtime.GOARCH))
 
 	resp, err := client.HTTP.Do(req)
 	if err != nil {
@@ -130,6 +119,10 @@ func request[Req, Res any](client Client, method string, path string, query Quer
 	}
 
 	var resBody Res
+	if resp.StatusCode == http.StatusNoContent {
+		return &resBody, nil
+	}
+
 	if err := json.Unmarshal(body, &resBody); err != nil {
 		return nil, fmt.Errorf("parsing json response: %w. partial text: %q", err, partialText(body, 100))
 	}
@@ -137,10 +130,70 @@ func request[Req, Res any](client Client, method string, path string, query Quer
 	return &resBody, nil
 }
 
+// setHeaders sets the headers that are sent with every request.
+func setHeaders(client Client, req *http.Request) {
+	clientName, clientVersion := "client", "unknown"
+	if client.Name != "" {
+		clientName = client.Name
+	}
+
+	if client.Version != "" {
+		clientVersion = client.Version
+	}
+
+	// clients that authenticate with a client certificate have no access key
+	if client.AccessKey != "" {
+		req.Header.Add("Authorization", "Bearer "+client.AccessKey)
+	}
+	req.Header.Set("Infra-Version", apiVersion)
+	req.Header.Set("User-Agent", fmt.Sprintf("Infra/%v (%s %v; %v/%v)", apiVersion, clientName, clientVersion, runtime.GOOS, runtime.GOARCH))
+}
+
 func get[Res any](client Client, path string, query Query) (*Res, error) {
 	return request[EmptyRequest, Res](client, http.MethodGet, path, query, nil)
 }
 
+// listPageLimit is the number of items requested in each page when list
+// requests every page.
+const listPageLimit = 1000
+
+// list requests a list of Res. When pr does not request a specific page, list
+// follows the cursor of each page until it has every item in the list.
+func list[Res any](client Client, path string, query Query, pr PaginationRequest) (*ListResponse[Res], error) {
+	if pr.Page != 0 || pr.Limit != 0 || pr.Cursor != 0 {
+		if pr.Page != 0 {
+			query["page"] = []string{fmt.Sprint(pr.Page)}
+		}
+		if pr.Limit != 0 {
+			query["limit"] = []string{fmt.Sprint(pr.Limit)}
+		}
+		if pr.Cursor != 0 {
+			query["cursor"] = []string{pr.Cursor.String()}
+		}
+		return get[ListResponse[Res]](client, path, query)
+	}
+
+	result := &ListResponse[Res]{Items: []Res{}}
+	query["limit"] = []string{fmt.Sprint(listPageLimit)}
+	for {
+		page, err := get[ListResponse[Res]](client, path, query)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Items = append(result.Items, page.Items...)
+		result.PaginationInfo.TotalCount = page.PaginationInfo.TotalCount
+
+		if page.PaginationInfo.NextCursor == 0 {
+			break
+		}
+		query["cursor"] = []string{page.PaginationInfo.NextCursor.String()}
+	}
+
+	result.Count = len(result.Items)
+	return result, nil
+}
+
 func post[Req, Res any](client Client, path string, req *Req) (res *Res, err error) {
 	return request[Req, Res](client, http.MethodPost, path, Query{}, req)
 }
@@ -155,7 +208,9 @@ func delete(client Client, path string) error {
 		return err
 	}
 
-	req.Header.Add("Authorization", "Bearer "+client.AccessKey)
+	if client.AccessKey != "" {
+		req.Header.Add("Authorization", "Bearer "+client.AccessKey)
+	}
 
 	resp, err := client.HTTP.Do(req)
 	if err != nil {
@@ -188,7 +243,12 @@ func (c Client) ListUsers(req ListUsersRequest) (*ListResponse[User], error) {
 	ids := slice.Map[uid.ID, string](req.IDs, func(id uid.ID) string {
 		return id.String()
 	})
-	return get[ListResponse[User]](c, "/api/users", Query{"name": {req.Name}, "group": {req.Group.String()}, "ids": ids})
+	return list[User](c, "/api/users", Query{
+		"name":                {req.Name},
+		"group":               {req.Group.String()},
+		"ids":                 ids,
+		"showServiceAccounts": {fmt.Sprint(req.ShowServiceAccounts)},
+	}, req.PaginationRequest)
 }
 
 func (c Client) GetUser(id uid.ID) (*User, error) {
@@ -207,16 +267,52 @@ func (c Client) DeleteUser(id uid.ID) error {
 	return delete(c, fmt.Sprintf("/api/users/%s", id))
 }
 
+func (c Client) EnrollUserMFA(id uid.ID) (*EnrollUserMFAResponse, error) {
+	return post[Resource, EnrollUserMFAResponse](c, fmt.Sprintf("/api/users/%s/mfa", id), &Resource{ID: id})
+}
+
+func (c Client) ConfirmUserMFA(req *ConfirmUserMFARequest) (*ConfirmUserMFAResponse, error) {
+	return put[ConfirmUserMFARequest, ConfirmUserMFAResponse](c, fmt.Sprintf("/api/users/%s/mfa", req.ID), req)
+}
+
+func (c Client) DisableUserMFA(id uid.ID) error {
+	return delete(c, fmt.Sprintf("/api/users/%s/mfa", id))
+}
+
+func (c Client) ListUserSessions(req ListUserSessionsRequest) (*ListResponse[Session], error) {
+	return list[Session](c, fmt.Sprintf("/api/users/%s/sessions", req.ID), Query{}, req.PaginationRequest)
+}
+
+func (c Client) RevokeUserSession(userID, sessionID uid.ID) error {
+	return delete(c, fmt.Sprintf("/api/users/%s/sessions/%s", userID, sessionID))
+}
+
+func (c Client) RevokeUserSessions(req RevokeUserSessionsRequest) error {
+	return delete(c, fmt.Sprintf("/api/users/%s/sessions?excludeCurrent=%t", req.ID, req.ExcludeCurrent))
+}
+
+func (c Client) ListServiceAccounts(req ListServiceAccountsRequest) (*ListResponse[User], error) {
+	return list[User](c, "/api/service-accounts", Query{"name": {req.Name}}, req.PaginationRequest)
+}
+
+func (c Client) CreateServiceAccount(req *CreateServiceAccountRequest) (*User, error) {
+	return post[CreateServiceAccountRequest, User](c, "/api/service-accounts", req)
+}
+
+func (c Client) DeleteServiceAccount(id uid.ID) error {
+	return delete(c, fmt.Sprintf("/api/service-accounts/%s", id))
+}
+
 // Deprecated: use ListGrants
 func (c Client) ListUserGrants(id uid.ID) (*ListResponse[Grant], error) {
 	return get[ListResponse[Grant]](c, fmt.Sprintf("/api/users/%s/grants", id), Query{})
 }
 
 func (c Client) ListGroups(req ListGroupsRequest) (*ListResponse[Group], error) {
-	return get[ListResponse[Group]](c, "/api/groups", Query{
+	return list[Group](c, "/api/groups", Query{
 		"name":   {req.Name},
 		"userID": {req.UserID.String()},
-	})
+	}, req.PaginationRequest)
 }
 
 func (c Client) GetGroup(id uid.ID) (*Group, error) {
@@ -231,13 +327,23 @@ func (c Client) DeleteGroup(id uid.ID) error {
 	return delete(c, fmt.Sprintf("/api/groups/%s", id))
 }
 
+func (c Client) AddUsersToGroup(req *UpdateUsersInGroupRequest) error {
+	_, err := put[UpdateUsersInGroupRequest, EmptyResponse](c, fmt.Sprintf("/api/groups/%s/users", req.GroupID), req)
+	return err
+}
+
+func (c Client) RemoveUsersFromGroup(req *UpdateUsersInGroupRequest) error {
+	_, err := request[UpdateUsersInGroupRequest, EmptyResponse](c, http.MethodDelete, fmt.Sprintf("/api/groups/%s/users", req.GroupID), Query{}, req)
+	return err
+}
+
 // Deprecated: use ListGrants
 func (c Client) ListGroupGrants(id uid.ID) (*ListResponse[Grant], error) {
 	return get[ListResponse[Grant]](c, fmt.Sprintf("/api/groups/%s/grants", id), Query{})
 }
 
 func (c Client) ListProviders(name string) (*ListResponse[Provider], error) {
-	return get[ListResponse[Provider]](c, "/api/providers", Query{"name": {name}})
+	return list[Provider](c, "/api/providers", Query{"name": {name}}, PaginationRequest{})
 }
 
 func (c Client) GetProvider(id uid.ID) (*Provider, error) {
@@ -252,16 +358,87 @@ func (c Client) UpdateProvider(req UpdateProviderRequest) (*Provider, error) {
 	return put[UpdateProviderRequest, Provider](c, fmt.Sprintf("/api/providers/%s", req.ID.String()), &req)
 }
 
+func (c Client) CreateSCIMAccessKey(req *CreateSCIMAccessKeyRequest) (*CreateAccessKeyResponse, error) {
+	return post[CreateSCIMAccessKeyRequest, CreateAccessKeyResponse](c, fmt.Sprintf("/api/providers/%s/scim-access-key", req.ProviderID), req)
+}
+
 func (c Client) DeleteProvider(id uid.ID) error {
 	return delete(c, fmt.Sprintf("/api/providers/%s", id))
 }
 
 func (c Client) ListGrants(req ListGrantsRequest) (*ListResponse[Grant], error) {
-	return get[ListResponse[Grant]](c, "/api/grants", Query{
+	return list[Grant](c, "/api/grants", Query{
 		"user":      {req.User.String()},
 		"group":     {req.Group.String()},
 		"resource":  {req.Resource},
 		"privilege": {req.Privilege},
+	}, req.PaginationRequest)
+}
+
+// WatchGrants watches the grants for req.Resource, and for the resources within
+// it. fn is called with every matching grant when the watch starts, and again
+// each time those grants change. To resume a watch, set req.Revision to the
+// revision of the last response passed to fn.
+//
+// WatchGrants returns nil when the server ends the watch, which it does
+// periodically. It returns an error when the context is cancelled, the request
+// fails, or fn returns an error.
+func (c Client) WatchGrants(ctx context.Context, req WatchGrantsRequest, fn func(WatchGrantsResponse) error) error {
+	path := "/api/grants/watch"
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s%s", c.URL, path), nil)
+	if err != nil {
+		return err
+	}
+
+	httpReq.URL.RawQuery = url.Values(Query{
+		"resource": {req.Resource},
+		"revision": {req.Revision},
+	}).Encode()
+
+	setHeaders(c, httpReq)
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.HTTP.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("GET %q: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("reading response: %w", err)
+		}
+		return checkError(httpReq, resp, body)
+	}
+
+	return readEvents(resp.Body, func(event string, data []byte) error {
+		if event != "grants" {
+			return nil
+		}
+
+		var watchResp WatchGrantsResponse
+		if err := json.Unmarshal(data, &watchResp); err != nil {
+			return fmt.Errorf("parsing json response: %w. partial text: %q", err, partialText(data, 100))
+		}
+
+		return fn(watchResp)
+	})
+}
+
+// ListEffectiveGrants lists the privileges of a user, including the privileges
+// granted to the groups the user is a member of.
+func (c Client) ListEffectiveGrants(req ListEffectiveGrantsRequest) (*ListResponse[EffectiveGrant], error) {
+	return get[ListResponse[EffectiveGrant]](c, "/api/grants/effective", Query{
+		"user": {req.User.String()},
+	})
+}
+
+func (c Client) CheckAccess(req CheckAccessRequest) (*CheckAccessResponse, error) {
+	return get[CheckAccessResponse](c, "/api/authz/check", Query{
+		"user":      {req.User.String()},
+		"privilege": {req.Privilege},
+		"resource":  {req.Resource},
 	})
 }
 
@@ -273,11 +450,49 @@ func (c Client) DeleteGrant(id uid.ID) error {
 	return delete(c, fmt.Sprintf("/api/grants/%s", id))
 }
 
+func (c Client) ListAccessRequests(req ListAccessRequestsRequest) (*ListResponse[AccessRequest], error) {
+	return list[AccessRequest](c, "/api/access-requests", Query{
+		"requestedBy": {req.RequestedBy.String()},
+		"resource":    {req.Resource},
+		"status":      {req.Status},
+	}, req.PaginationRequest)
+}
+
+func (c Client) GetAccessRequest(id uid.ID) (*AccessRequest, error) {
+	return get[AccessRequest](c, fmt.Sprintf("/api/access-requests/%s", id), Query{})
+}
+
+func (c Client) CreateAccessRequest(req *CreateAccessRequestRequest) (*AccessRequest, error) {
+	return post[CreateAccessRequestRequest, AccessRequest](c, "/api/access-requests", req)
+}
+
+func (c Client) ApproveAccessRequest(id uid.ID) (*AccessRequest, error) {
+	return post[EmptyRequest, AccessRequest](c, fmt.Sprintf("/api/access-requests/%s/approve", id), &EmptyRequest{})
+}
+
+func (c Client) DenyAccessRequest(id uid.ID) (*AccessRequest, error) {
+	return post[EmptyRequest, AccessRequest](c, fmt.Sprintf("/api/access-requests/%s/deny", id), &EmptyRequest{})
+}
+
+func (c Client) ListAccessApprovers(req ListAccessApproversRequest) (*ListResponse[AccessApprover], error) {
+	return list[AccessApprover](c, "/api/access-approvers", Query{
+		"resource": {req.Resource},
+	}, req.PaginationRequest)
+}
+
+func (c Client) CreateAccessApprover(req *CreateAccessApproverRequest) (*AccessApprover, error) {
+	return post[CreateAccessApproverRequest, AccessApprover](c, "/api/access-approvers", req)
+}
+
+func (c Client) DeleteAccessApprover(id uid.ID) error {
+	return delete(c, fmt.Sprintf("/api/access-approvers/%s", id))
+}
+
 func (c Client) ListDestinations(req ListDestinationsRequest) (*ListResponse[Destination], error) {
-	return get[ListResponse[Destination]](c, "/api/destinations", Query{
+	return list[Destination](c, "/api/destinations", Query{
 		"name":      {req.Name},
 		"unique_id": {req.UniqueID},
-	})
+	}, req.PaginationRequest)
 }
 
 func (c Client) CreateDestination(req *CreateDestinationRequest) (*Destination, error) {
@@ -293,29 +508,97 @@ func (c Client) DeleteDestination(id uid.ID) error {
 }
 
 func (c Client) ListAccessKeys(req ListAccessKeysRequest) (*ListResponse[AccessKey], error) {
-	return get[ListResponse[AccessKey]](c, "/api/access-keys", Query{
+	return list[AccessKey](c, "/api/access-keys", Query{
 		"user_id":      {req.UserID.String()},
 		"name":         {req.Name},
 		"show_expired": {fmt.Sprint(req.ShowExpired)},
-	})
+	}, req.PaginationRequest)
 }
 
 func (c Client) CreateAccessKey(req *CreateAccessKeyRequest) (*CreateAccessKeyResponse, error) {
 	return post[CreateAccessKeyRequest, CreateAccessKeyResponse](c, "/api/access-keys", req)
 }
 
+func (c Client) RotateAccessKey(req *RotateAccessKeyRequest) (*CreateAccessKeyResponse, error) {
+	return post[RotateAccessKeyRequest, CreateAccessKeyResponse](c, fmt.Sprintf("/api/access-keys/%s/rotate", req.ID), req)
+}
+
 func (c Client) DeleteAccessKey(id uid.ID) error {
 	return delete(c, fmt.Sprintf("/api/access-keys/%s", id))
 }
 
-func (c Client) CreateToken() (*CreateTokenResponse, error) {
-	return post[EmptyRequest, CreateTokenResponse](c, "/api/tokens", &EmptyRequest{})
+func (c Client) ListAuditEvents(req ListAuditEventsRequest) (*ListResponse[AuditEvent], error) {
+	query := Query{
+		"actor":      {req.Actor.String()},
+		"resource":   {req.Resource},
+		"resourceID": {req.ResourceID.String()},
+	}
+	if !req.After.Time().IsZero() {
+		query["after"] = []string{req.After.String()}
+	}
+	if !req.Before.Time().IsZero() {
+		query["before"] = []string{req.Before.String()}
+	}
+	return list[AuditEvent](c, "/api/audit-events", query, req.PaginationRequest)
+}
+
+func (c Client) ListWebhooks(req ListWebhooksRequest) (*ListResponse[Webhook], error) {
+	return list[Webhook](c, "/api/webhooks", Query{}, req.PaginationRequest)
+}
+
+func (c Client) GetWebhook(id uid.ID) (*Webhook, error) {
+	return get[Webhook](c, fmt.Sprintf("/api/webhooks/%s", id), Query{})
+}
+
+func (c Client) CreateWebhook(req *CreateWebhookRequest) (*Webhook, error) {
+	return post[CreateWebhookRequest, Webhook](c, "/api/webhooks", req)
+}
+
+func (c Client) DeleteWebhook(id uid.ID) error {
+	return delete(c, fmt.Sprintf("/api/webhooks/%s", id))
+}
+
+func (c Client) ListWebhookDeliveries(req ListWebhookDeliveriesRequest) (*ListResponse[WebhookDelivery], error) {
+	return list[WebhookDelivery](c, fmt.Sprintf("/api/webhooks/%s/deliveries", req.ID), Query{}, req.PaginationRequest)
+}
+
+func (c Client) ListRoles(req ListRolesRequest) (*ListResponse[Role], error) {
+	return list[Role](c, "/api/roles", Query{"name": {req.Name}}, req.PaginationRequest)
+}
+
+func (c Client) GetRole(id uid.ID) (*Role, error) {
+	return get[Role](c, fmt.Sprintf("/api/roles/%s", id), Query{})
+}
+
+func (c Client) CreateRole(req *CreateRoleRequest) (*Role, error) {
+	return post[CreateRoleRequest, Role](c, "/api/roles", req)
+}
+
+func (c Client) UpdateRole(req UpdateRoleRequest) (*Role, error) {
+	return put[UpdateRoleRequest, Role](c, fmt.Sprintf("/api/roles/%s", req.ID), &req)
+}
+
+func (c Client) DeleteRole(id uid.ID) error {
+	return delete(c, fmt.Sprintf("/api/roles/%s", id))
+}
+
+func (c Client) CreateToken(req *CreateTokenRequest) (*CreateTokenResponse, error) {
+	return post[CreateTokenRequest, CreateTokenResponse](c, "/api/tokens", req)
+}
+
+func (c Client) SignCertificate(req *SignCertificateRequest) (*SignCertificateResponse, error) {
+	return post[SignCertificateRequest, SignCertificateResponse](c, "/api/certificates", req)
 }
 
 func (c Client) Login(req *LoginRequest) (*LoginResponse, error) {
 	return post[LoginRequest, LoginResponse](c, "/api/login", req)
 }
 
+func (c Client) RequestPasswordReset(req *PasswordResetRequest) error {
+	_, err := post[PasswordResetRequest, EmptyResponse](c, "/api/password-reset", req)
+	return err
+}
+
 func (c Client) Logout() error {
 	_, err := post[EmptyRequest, EmptyResponse](c, "/api/logout", &EmptyRequest{})
 	return err
@@ -5,10 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"runtime"
 	"testing"
 
 	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/uid"
 )
 
 func TestErrorStatusCode(t *testing.T) {
@@ -123,3 +126,79 @@ func TestGet(t *testing.T) {
 		assert.DeepEqual(t, req.Header, expectedHeaders)
 	})
 }
+
+func TestList(t *testing.T) {
+	pages := map[string]ListResponse[int]{
+		"": {
+			Items:          []int{1, 2},
+			PaginationInfo: PaginationResponse{TotalCount: 5, NextCursor: Cursor(2)},
+		},
+		Cursor(2).String(): {
+			Items:          []int{3, 4},
+			PaginationInfo: PaginationResponse{TotalCount: 5, NextCursor: Cursor(4)},
+		},
+		Cursor(4).String(): {
+			Items:          []int{5},
+			PaginationInfo: PaginationResponse{TotalCount: 5},
+		},
+	}
+
+	var queries []url.Values
+	handler := func(resp http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.Query())
+		page, ok := pages[r.URL.Query().Get("cursor")]
+		if !ok {
+			resp.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		resp.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(resp).Encode(page)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(handler))
+	t.Cleanup(srv.Close)
+
+	c := Client{URL: srv.URL}
+
+	t.Run("every page", func(t *testing.T) {
+		queries = nil
+		actual, err := list[int](c, "/items", Query{"name": {"a"}}, PaginationRequest{})
+		assert.NilError(t, err)
+
+		expected := &ListResponse[int]{
+			Items:          []int{1, 2, 3, 4, 5},
+			Count:          5,
+			PaginationInfo: PaginationResponse{TotalCount: 5},
+		}
+		assert.DeepEqual(t, actual, expected)
+
+		assert.Equal(t, len(queries), 3)
+		for _, query := range queries {
+			assert.Equal(t, query.Get("name"), "a")
+			assert.Equal(t, query.Get("limit"), "1000")
+		}
+	})
+
+	t.Run("single page", func(t *testing.T) {
+		queries = nil
+		actual, err := list[int](c, "/items", Query{}, PaginationRequest{Limit: 2, Cursor: Cursor(2)})
+		assert.NilError(t, err)
+		assert.DeepEqual(t, actual.Items, []int{3, 4})
+		assert.Equal(t, actual.PaginationInfo.NextCursor, Cursor(4))
+
+		assert.Equal(t, len(queries), 1)
+		assert.Equal(t, queries[0].Get("limit"), "2")
+		assert.Equal(t, queries[0].Get("page"), "")
+	})
+}
+
+func TestCursor_UnmarshalText(t *testing.T) {
+	id := uid.New()
+
+	var actual Cursor
+	err := actual.UnmarshalText([]byte(Cursor(id).String()))
+	assert.NilError(t, err)
+	assert.Equal(t, actual, Cursor(id))
+
+	err = actual.UnmarshalText([]byte("not a cursor"))
+	assert.Error(t, err, "invalid cursor")
+}
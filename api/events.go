@@ -0,0 +1,67 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxEventSize is the largest server-sent event that readEvents accepts.
+const maxEventSize = 16 * 1024 * 1024
+
+// readEvents reads a stream of server-sent events from r, and calls fn with the
+// name and data of each event. Comments and the id and retry fields are ignored.
+// readEvents returns nil when r is closed at the end of an event.
+//
+// See https://html.spec.whatwg.org/multipage/server-sent-events.html
+func readEvents(r io.Reader, fn func(event string, data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	var (
+		event string
+		data  bytes.Buffer
+	)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			if data.Len() > 0 {
+				if event == "" {
+					event = "message"
+				}
+				if err := fn(event, bytes.TrimSuffix(data.Bytes(), []byte("\n"))); err != nil {
+					return err
+				}
+			}
+
+			event = ""
+			data.Reset()
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading events: %w", err)
+	}
+
+	return nil
+}
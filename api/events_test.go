@@ -0,0 +1,53 @@
+package api
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	gocmp "github.com/google/go-cmp/cmp"
+	"gotest.tools/v3/assert"
+)
+
+func TestReadEvents(t *testing.T) {
+	type event struct {
+		name string
+		data string
+	}
+
+	read := func(t *testing.T, stream string) ([]event, error) {
+		t.Helper()
+		var events []event
+		err := readEvents(strings.NewReader(stream), func(name string, data []byte) error {
+			events = append(events, event{name: name, data: string(data)})
+			return nil
+		})
+		return events, err
+	}
+
+	t.Run("events", func(t *testing.T) {
+		events, err := read(t, ": keepalive\n\n"+
+			"id: 1\nevent: grants\ndata: {\"revision\":\"1\"}\n\n"+
+			"data: first\ndata:second\n\n"+
+			"retry: 1000\n\n")
+		assert.NilError(t, err)
+		assert.DeepEqual(t, events, []event{
+			{name: "grants", data: `{"revision":"1"}`},
+			{name: "message", data: "first\nsecond"},
+		}, gocmp.AllowUnexported(event{}))
+	})
+
+	t.Run("incomplete event is ignored", func(t *testing.T) {
+		events, err := read(t, "event: grants\ndata: {\"revision\"")
+		assert.NilError(t, err)
+		assert.Equal(t, len(events), 0)
+	})
+
+	t.Run("error from fn", func(t *testing.T) {
+		expected := errors.New("failed")
+		err := readEvents(strings.NewReader("data: one\n\ndata: two\n\n"), func(string, []byte) error {
+			return expected
+		})
+		assert.Assert(t, errors.Is(err, expected))
+	})
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"strings"
+
 	"github.com/infrahq/infra/uid"
 )
 
@@ -15,6 +17,7 @@ type Grant struct {
 	Group     uid.ID `json:"group,omitempty"`
 	Privilege string `json:"privilege" note:"a role or permission"`
 	Resource  string `json:"resource" note:"a resource name in Infra's Universal Resource Notation"`
+	Expires   Time   `json:"expires,omitempty" note:"the grant is no longer valid after this time"`
 }
 
 type ListGrantsRequest struct {
@@ -25,9 +28,108 @@ type ListGrantsRequest struct {
 	PaginationRequest
 }
 
+type ListEffectiveGrantsRequest struct {
+	User uid.ID `form:"user" validate:"required"`
+}
+
+// EffectiveGrant is a privilege that a user has on a resource, granted either
+// to the user or to a group the user is a member of. All the grants of the same
+// privilege on the same resource are combined into one EffectiveGrant.
+type EffectiveGrant struct {
+	Privilege string  `json:"privilege" note:"a role or permission"`
+	Resource  string  `json:"resource" note:"a resource name in Infra's Universal Resource Notation"`
+	Expires   Time    `json:"expires,omitempty" note:"the privilege is no longer granted after this time"`
+	Grants    []Grant `json:"grants" note:"the grants to the user, or to their groups, that give the privilege"`
+}
+
+type WatchGrantsRequest struct {
+	Resource string `form:"resource" validate:"required" example:"production" note:"grants for this resource, and for any resource within it, are watched"`
+	Revision string `form:"revision" note:"the revision of the last event received, the watch resumes from this revision"`
+}
+
+// WatchGrantsResponse is the data of each event sent by a watch on grants. The
+// event includes every grant matched by the watch, not only the grants that
+// changed.
+type WatchGrantsResponse struct {
+	Revision string  `json:"revision"`
+	Items    []Grant `json:"items"`
+}
+
 type CreateGrantRequest struct {
 	User      uid.ID `json:"user" validate:"required_without=Group"`
 	Group     uid.ID `json:"group" validate:"required_without=User"`
 	Privilege string `json:"privilege" validate:"required" example:"view" note:"a role or permission"`
 	Resource  string `json:"resource" validate:"required" example:"production" note:"a resource name in Infra's Universal Resource Notation"`
+	Expires   Time   `json:"expires,omitempty" note:"optional, the grant is no longer valid after this time"`
+}
+
+// ResourceMatches returns true if a grant on pattern applies to resource.
+//
+// Resources are a destination name followed by the names of any resources
+// within it, separated by dots, for example "production.web". A grant applies
+// to its resource and to every resource within it, so "production" matches
+// "production.web". Each part of the pattern may use * to match any
+// characters within that part, so "staging-*" matches "staging-2.web" and
+// "*.monitoring" matches "production.monitoring".
+func ResourceMatches(pattern, resource string) bool {
+	patternParts := strings.Split(pattern, ".")
+	resourceParts := strings.Split(resource, ".")
+
+	if len(patternParts) > len(resourceParts) {
+		return false
+	}
+
+	for i, part := range patternParts {
+		if !matchResourcePart(part, resourceParts[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ResourceOverlaps returns true if a grant on pattern applies to resource, or
+// to any resource within it. For example, "*.monitoring" overlaps "production"
+// because it applies to "production.monitoring".
+func ResourceOverlaps(pattern, resource string) bool {
+	patternParts := strings.Split(pattern, ".")
+	resourceParts := strings.Split(resource, ".")
+
+	for i := 0; i < len(patternParts) && i < len(resourceParts); i++ {
+		if !matchResourcePart(patternParts[i], resourceParts[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsResourcePattern returns true if the resource uses wildcards.
+func IsResourcePattern(resource string) bool {
+	return strings.Contains(resource, "*")
+}
+
+// matchResourcePart matches one part of a resource against a pattern where *
+// matches any sequence of characters.
+func matchResourcePart(pattern, part string) bool {
+	literals := strings.Split(pattern, "*")
+	if len(literals) == 1 {
+		return pattern == part
+	}
+
+	if !strings.HasPrefix(part, literals[0]) {
+		return false
+	}
+	part = part[len(literals[0]):]
+
+	last := literals[len(literals)-1]
+	for _, literal := range literals[1 : len(literals)-1] {
+		i := strings.Index(part, literal)
+		if i < 0 {
+			return false
+		}
+		part = part[i+len(literal):]
+	}
+
+	return len(part) >= len(last) && strings.HasSuffix(part, last)
 }
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestResourceMatches(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		resource string
+		expected bool
+	}{
+		{pattern: "production", resource: "production", expected: true},
+		{pattern: "production", resource: "production.web", expected: true},
+		{pattern: "production", resource: "production-2", expected: false},
+		{pattern: "production.web", resource: "production", expected: false},
+		{pattern: "production.web", resource: "production.web", expected: true},
+		{pattern: "staging-*", resource: "staging-2", expected: true},
+		{pattern: "staging-*", resource: "staging-2.web", expected: true},
+		{pattern: "staging-*", resource: "staging", expected: false},
+		{pattern: "staging-*", resource: "production", expected: false},
+		{pattern: "*.monitoring", resource: "production.monitoring", expected: true},
+		{pattern: "*.monitoring", resource: "production.monitoring-2", expected: false},
+		{pattern: "*.monitoring", resource: "production", expected: false},
+		{pattern: "*.team-*", resource: "staging.team-a", expected: true},
+		{pattern: "*-east-*.web", resource: "us-east-1.web", expected: true},
+		{pattern: "*-east-*.web", resource: "us-west-1.web", expected: false},
+		{pattern: "a*a", resource: "a", expected: false},
+		{pattern: "a*a", resource: "aa", expected: true},
+		{pattern: "*", resource: "anything.at.all", expected: true},
+	}
+
+	for _, tc := range testCases {
+		actual := ResourceMatches(tc.pattern, tc.resource)
+		assert.Equal(t, actual, tc.expected, "%s matches %s", tc.pattern, tc.resource)
+	}
+}
+
+func TestResourceOverlaps(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		resource string
+		expected bool
+	}{
+		{pattern: "production", resource: "production", expected: true},
+		{pattern: "production.web", resource: "production", expected: true},
+		{pattern: "*.monitoring", resource: "production", expected: true},
+		{pattern: "staging-*.web", resource: "staging-2", expected: true},
+		{pattern: "staging-*", resource: "production", expected: false},
+		{pattern: "production.web", resource: "production.api", expected: false},
+	}
+
+	for _, tc := range testCases {
+		actual := ResourceOverlaps(tc.pattern, tc.resource)
+		assert.Equal(t, actual, tc.expected, "%s overlaps %s", tc.pattern, tc.resource)
+	}
+}
@@ -22,3 +22,8 @@ type ListGroupsRequest struct {
 type CreateGroupRequest struct {
 	Name string `json:"name" validate:"required"`
 }
+
+type UpdateUsersInGroupRequest struct {
+	GroupID uid.ID   `uri:"id" json:"-" validate:"required"`
+	UserIDs []uid.ID `json:"userIDs" validate:"required"`
+}
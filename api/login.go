@@ -12,12 +12,27 @@ type LoginRequestPasswordCredentials struct {
 	Name     string `json:"name" validate:"required_without=Email"`
 	Email    string `json:"email" validate:"required_without=Name"` // #1825: remove, this is for migration
 	Password string `json:"password" validate:"required"`
+	MFACode  string `json:"mfaCode,omitempty" note:"a TOTP or recovery code, required when the user has enabled multi-factor authentication"`
+}
+
+// LoginRequestPasswordReset is the single use token from an invitation or
+// password reset email.
+type LoginRequestPasswordReset struct {
+	Token   string `json:"token" validate:"required"`
+	MFACode string `json:"mfaCode,omitempty" note:"a TOTP or recovery code, required when the user has enabled multi-factor authentication"`
 }
 
 type LoginRequest struct {
-	AccessKey           string                           `json:"accessKey" validate:"excluded_with=OIDC,excluded_with=PasswordCredentials"`
-	PasswordCredentials *LoginRequestPasswordCredentials `json:"passwordCredentials" validate:"excluded_with=OIDC,excluded_with=AccessKey"`
-	OIDC                *LoginRequestOIDC                `json:"oidc" validate:"excluded_with=KeyExchange,excluded_with=PasswordCredentials"`
+	AccessKey           string                           `json:"accessKey" validate:"excluded_with=OIDC,excluded_with=PasswordCredentials,excluded_with=PasswordReset"`
+	PasswordCredentials *LoginRequestPasswordCredentials `json:"passwordCredentials" validate:"excluded_with=OIDC,excluded_with=AccessKey,excluded_with=PasswordReset"`
+	OIDC                *LoginRequestOIDC                `json:"oidc" validate:"excluded_with=KeyExchange,excluded_with=PasswordCredentials,excluded_with=PasswordReset"`
+	PasswordReset       *LoginRequestPasswordReset       `json:"passwordReset,omitempty" validate:"excluded_with=OIDC,excluded_with=AccessKey,excluded_with=PasswordCredentials" note:"exchanges the token for an access key that can only be used to set a new password"`
+}
+
+// PasswordResetRequest sends a password reset email to a user of the infra
+// provider.
+type PasswordResetRequest struct {
+	Email string `json:"email" validate:"required,email"`
 }
 
 type LoginResponse struct {
@@ -25,5 +40,6 @@ type LoginResponse struct {
 	Name                   string `json:"name"`
 	AccessKey              string `json:"accessKey"`
 	PasswordUpdateRequired bool   `json:"passwordUpdateRequired,omitempty"`
+	MFAEnrollmentRequired  bool   `json:"mfaEnrollmentRequired,omitempty" note:"the access key can only be used to enable multi-factor authentication"`
 	Expires                Time   `json:"expires"`
 }
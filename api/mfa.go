@@ -0,0 +1,19 @@
+package api
+
+import (
+	"github.com/infrahq/infra/uid"
+)
+
+type EnrollUserMFAResponse struct {
+	Secret string `json:"secret" note:"the base32 encoded TOTP secret, for authenticator apps that can not read the URI"`
+	URI    string `json:"uri" example:"otpauth://totp/Infra:alice@example.com?issuer=Infra&secret=JBSWY3DPEHPK3PXP" note:"the otpauth URI of the secret, to show as a QR code"`
+}
+
+type ConfirmUserMFARequest struct {
+	ID   uid.ID `uri:"id" json:"-" validate:"required"`
+	Code string `json:"code" validate:"required" note:"a code from the authenticator app"`
+}
+
+type ConfirmUserMFAResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes" note:"codes that can each be used once instead of a TOTP code"`
+}
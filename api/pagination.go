@@ -1,13 +1,57 @@
 package api
 
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/infrahq/infra/uid"
+)
+
 type PaginationRequest struct {
-	Page  int `form:"page" validate:"min=0"`
-	Limit int `form:"limit" validate:"min=0,max=1000"`
+	Page   int    `form:"page" validate:"min=0"`
+	Limit  int    `form:"limit" validate:"min=0,max=1000"`
+	Cursor Cursor `form:"cursor" note:"return the page that follows this cursor, from nextCursor of the previous page"`
 }
 
 type PaginationResponse struct {
 	Page  int `json:"page,omitempty"`
 	Limit int `json:"limit,omitempty"`
 
-	//TODO: add some indication of number of records/pages or if a next page exists
+	TotalCount int    `json:"totalCount" note:"number of records that match the request, across all pages"`
+	NextCursor Cursor `json:"nextCursor,omitempty" note:"pass as the cursor of the next request to get the next page, empty on the last page"`
+}
+
+// Cursor is an opaque position in a list of results. It is used to request
+// the page that follows a previous page.
+type Cursor uid.ID
+
+func (c Cursor) String() string {
+	if c == 0 {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(uid.ID(c).String()))
+}
+
+func (c Cursor) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+func (c *Cursor) UnmarshalText(data []byte) error {
+	if len(data) == 0 {
+		*c = 0
+		return nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(string(data))
+	if err != nil {
+		return fmt.Errorf("invalid cursor")
+	}
+
+	id, err := uid.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("invalid cursor")
+	}
+
+	*c = Cursor(id)
+	return nil
 }
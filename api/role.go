@@ -0,0 +1,28 @@
+package api
+
+import (
+	"github.com/infrahq/infra/uid"
+)
+
+type Role struct {
+	ID          uid.ID   `json:"id"`
+	Created     Time     `json:"created"`
+	Updated     Time     `json:"updated"`
+	Name        string   `json:"name" example:"user-manager"`
+	Permissions []string `json:"permissions" example:"[\"users:read\", \"users:write\"]"`
+}
+
+type ListRolesRequest struct {
+	Name string `form:"name"`
+	PaginationRequest
+}
+
+type CreateRoleRequest struct {
+	Name        string   `json:"name" validate:"required,excludesall=: " example:"user-manager" note:"used as the privilege of grants on the infra resource"`
+	Permissions []string `json:"permissions" validate:"required,min=1,dive,required" example:"[\"users:read\", \"users:write\"]"`
+}
+
+type UpdateRoleRequest struct {
+	ID          uid.ID   `uri:"id" json:"-" validate:"required"`
+	Permissions []string `json:"permissions" validate:"required,min=1,dive,required" example:"[\"users:read\", \"users:write\"]"`
+}
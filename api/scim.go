@@ -0,0 +1,114 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/infrahq/infra/uid"
+)
+
+// SCIM schema URNs, see https://datatracker.ietf.org/doc/html/rfc7643
+const (
+	SCIMSchemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	SCIMSchemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	SCIMSchemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	SCIMSchemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	SCIMSchemaPatchOp               = "urn:ietf:params:scim:api:messages:2.0:PatchOp"
+	SCIMSchemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+)
+
+type CreateSCIMAccessKeyRequest struct {
+	ProviderID uid.ID   `uri:"id" json:"-" validate:"required"`
+	Name       string   `json:"name" validate:"excludes= "`
+	TTL        Duration `json:"ttl" validate:"required" note:"maximum time valid"`
+}
+
+type SCIMMeta struct {
+	ResourceType string `json:"resourceType"`
+	Created      Time   `json:"created"`
+	LastModified Time   `json:"lastModified"`
+	Location     string `json:"location"`
+}
+
+type SCIMUser struct {
+	Schemas    []string        `json:"schemas"`
+	ID         string          `json:"id,omitempty"`
+	ExternalID string          `json:"externalId,omitempty"`
+	UserName   string          `json:"userName" validate:"required"`
+	Active     *bool           `json:"active,omitempty"`
+	Emails     []SCIMEmail     `json:"emails,omitempty"`
+	Groups     []SCIMReference `json:"groups,omitempty"`
+	Meta       *SCIMMeta       `json:"meta,omitempty"`
+}
+
+type SCIMEmail struct {
+	Value   string `json:"value"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+// SCIMReference is a reference to a user or group, for example a member of a
+// group.
+type SCIMReference struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+}
+
+type SCIMGroup struct {
+	Schemas     []string        `json:"schemas"`
+	ID          string          `json:"id,omitempty"`
+	ExternalID  string          `json:"externalId,omitempty"`
+	DisplayName string          `json:"displayName" validate:"required"`
+	Members     []SCIMReference `json:"members"`
+	Meta        *SCIMMeta       `json:"meta,omitempty"`
+}
+
+type SCIMListResponse[T any] struct {
+	Schemas      []string `json:"schemas"`
+	TotalResults int      `json:"totalResults"`
+	StartIndex   int      `json:"startIndex"`
+	ItemsPerPage int      `json:"itemsPerPage"`
+	Resources    []T      `json:"Resources"`
+}
+
+type SCIMPatchRequest struct {
+	Schemas    []string             `json:"schemas"`
+	Operations []SCIMPatchOperation `json:"Operations" validate:"required,min=1"`
+}
+
+type SCIMPatchOperation struct {
+	Op    string          `json:"op" validate:"required"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type SCIMError struct {
+	Schemas  []string `json:"schemas"`
+	Status   string   `json:"status"`
+	SCIMType string   `json:"scimType,omitempty"`
+	Detail   string   `json:"detail,omitempty"`
+}
+
+type SCIMServiceProviderConfig struct {
+	Schemas               []string             `json:"schemas"`
+	Patch                 SCIMSupported        `json:"patch"`
+	Bulk                  SCIMSupported        `json:"bulk"`
+	Filter                SCIMFilterSupported  `json:"filter"`
+	ChangePassword        SCIMSupported        `json:"changePassword"`
+	Sort                  SCIMSupported        `json:"sort"`
+	ETag                  SCIMSupported        `json:"etag"`
+	AuthenticationSchemes []SCIMAuthentication `json:"authenticationSchemes"`
+}
+
+type SCIMSupported struct {
+	Supported bool `json:"supported"`
+}
+
+type SCIMFilterSupported struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+type SCIMAuthentication struct {
+	Type        string `json:"type"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
@@ -0,0 +1,12 @@
+package api
+
+type ListServiceAccountsRequest struct {
+	Name string `form:"name"`
+	PaginationRequest
+}
+
+// CreateServiceAccountRequest creates an identity for automation, which can only
+// authenticate with access keys
+type CreateServiceAccountRequest struct {
+	Name string `json:"name" validate:"required" example:"deploy-bot"`
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"github.com/infrahq/infra/uid"
+)
+
+// Session is an access key that was created when a user logged in.
+type Session struct {
+	ID           uid.ID `json:"id"`
+	Created      Time   `json:"created"`
+	ProviderID   uid.ID `json:"providerID"`
+	ProviderName string `json:"providerName" note:"the identity provider the user logged in with"`
+	ClientName   string `json:"clientName" example:"cli 0.13.4 (darwin/arm64)" note:"the client the user logged in with"`
+	LastUsed     Time   `json:"lastUsed"`
+	Expires      Time   `json:"expires" note:"the session is no longer valid after this time"`
+	Current      bool   `json:"current" note:"the session was used to make this request"`
+}
+
+type ListUserSessionsRequest struct {
+	ID uid.ID `uri:"id" json:"-" validate:"required"`
+	PaginationRequest
+}
+
+type RevokeUserSessionRequest struct {
+	ID        uid.ID `uri:"id" validate:"required"`
+	SessionID uid.ID `uri:"sessionID" validate:"required"`
+}
+
+type RevokeUserSessionsRequest struct {
+	ID             uid.ID `uri:"id" validate:"required"`
+	ExcludeCurrent bool   `form:"excludeCurrent" note:"do not revoke the session used to make this request"`
+}
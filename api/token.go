@@ -1,5 +1,11 @@
 package api
 
+type CreateTokenRequest struct {
+	// Destination is the name of the destination the token is for. The token
+	// is only accepted by that destination.
+	Destination string `json:"destination"`
+}
+
 type CreateTokenResponse struct {
 	Expires Time   `json:"expires"`
 	Token   string `json:"token"`
@@ -57,6 +57,20 @@ func (t *Time) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// UnmarshalText is used to read a Time from a query parameter. An empty value
+// is the zero time.
+func (t *Time) UnmarshalText(data []byte) error {
+	if len(data) == 0 {
+		return nil
+	}
+	tmp, err := time.Parse(time.RFC3339, string(data))
+	if err != nil {
+		return err
+	}
+	*t = Time(tmp.UTC())
+	return nil
+}
+
 func (t Time) String() string {
 	return time.Time(t).Format(time.RFC3339)
 }
@@ -106,6 +120,11 @@ func NewListResponse[T, M any](items []M, pr PaginationResponse, fn func(item M)
 		PaginationInfo: pr,
 	}
 
+	// when the list is not paginated every record is in this response
+	if pr.Limit == 0 {
+		result.PaginationInfo.TotalCount = len(items)
+	}
+
 	for _, item := range items {
 		result.Items = append(result.Items, fn(item))
 	}
@@ -183,3 +183,19 @@ func TestDuration_MarshalJSON_RoundTripProperty(t *testing.T) {
 		})
 	}
 }
+
+func TestTime_UnmarshalText(t *testing.T) {
+	var empty Time
+	err := empty.UnmarshalText([]byte(""))
+	assert.NilError(t, err)
+	assert.Assert(t, empty.Time().IsZero())
+
+	var value Time
+	err = value.UnmarshalText([]byte("2016-01-02T01:24:21-05:00"))
+	assert.NilError(t, err)
+	assert.Equal(t, value.Time(), time.Date(2016, 1, 2, 6, 24, 21, 0, time.UTC))
+
+	var invalid Time
+	err = invalid.UnmarshalText([]byte("yesterday"))
+	assert.ErrorContains(t, err, "cannot parse")
+}
@@ -14,13 +14,18 @@ type User struct {
 	Updated       Time     `json:"updated"`
 	LastSeenAt    Time     `json:"lastSeenAt"`
 	Name          string   `json:"name" validate:"required"`
+	Kind          string   `json:"kind" example:"user" note:"user, or service-account for identities that can only authenticate with access keys"`
 	ProviderNames []string `json:"providerNames,omitempty"`
+
+	FailedLoginAttempts int  `json:"failedLoginAttempts,omitempty" note:"failed password logins since the last successful login"`
+	LockedUntil         Time `json:"lockedUntil,omitempty" note:"password login is not allowed until this time, after too many failed logins"`
 }
 
 type ListUsersRequest struct {
-	Name  string   `form:"name"`
-	Group uid.ID   `form:"group"`
-	IDs   []uid.ID `form:"ids"`
+	Name                string   `form:"name"`
+	Group               uid.ID   `form:"group"`
+	IDs                 []uid.ID `form:"ids"`
+	ShowServiceAccounts bool     `form:"showServiceAccounts" note:"include service accounts, which are not listed by default"`
 	PaginationRequest
 }
 
@@ -33,9 +38,11 @@ type CreateUserResponse struct {
 	ID              uid.ID `json:"id"`
 	Name            string `json:"name" validate:"required"`
 	OneTimePassword string `json:"oneTimePassword,omitempty"`
+	InvitationSent  bool   `json:"invitationSent,omitempty" note:"an invitation to set a password was emailed to the user, instead of returning a one time password"`
 }
 
 type UpdateUserRequest struct {
 	ID       uid.ID `uri:"id" json:"-" validate:"required"`
-	Password string `json:"password" validate:"required,min=8"`
+	Password string `json:"password" validate:"required_without=Unlock,omitempty,min=8"`
+	Unlock   bool   `json:"unlock,omitempty" note:"clears failed password logins, so that a locked user can login again"`
 }
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+
+	"github.com/infrahq/infra/uid"
+)
+
+// Webhook events
+const (
+	WebhookEventGrantCreated = "grant.created"
+	WebhookEventGrantDeleted = "grant.deleted"
+	WebhookEventUserCreated  = "user.created"
+	WebhookEventLogin        = "login.succeeded"
+	WebhookEventLoginFailed  = "login.failed"
+)
+
+type Webhook struct {
+	ID      uid.ID   `json:"id"`
+	Created Time     `json:"created"`
+	Updated Time     `json:"updated"`
+	URL     string   `json:"url" example:"https://example.com/infra-events"`
+	Events  []string `json:"events" example:"[\"grant.created\", \"grant.deleted\"]"`
+}
+
+type ListWebhooksRequest struct {
+	PaginationRequest
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url" example:"https://example.com/infra-events"`
+	Events []string `json:"events" validate:"required,min=1,dive,oneof=grant.created grant.deleted user.created login.succeeded login.failed" example:"[\"grant.created\", \"grant.deleted\"]"`
+	Secret string   `json:"secret" validate:"required,min=16" note:"used to sign each payload, the signature is sent in the Infra-Signature header"`
+}
+
+type WebhookDelivery struct {
+	ID         uid.ID `json:"id"`
+	Created    Time   `json:"created"`
+	WebhookID  uid.ID `json:"webhookID"`
+	EventID    uid.ID `json:"eventID" note:"the id of the payload, which is the same for every attempt to deliver it"`
+	Event      string `json:"event" example:"grant.created"`
+	Attempt    int    `json:"attempt" example:"1"`
+	StatusCode int    `json:"statusCode,omitempty" example:"200" note:"the status code of the response, if one was received"`
+	Error      string `json:"error,omitempty" note:"the reason the attempt failed, empty when it succeeded"`
+}
+
+type ListWebhookDeliveriesRequest struct {
+	ID uid.ID `uri:"id" json:"-" validate:"required"`
+	PaginationRequest
+}
+
+// WebhookPayload is the body of a webhook request. The payload is signed with
+// the secret of the webhook, and the signature is sent in the Infra-Signature
+// header as sha256=<hex encoded HMAC-SHA256 of the body>.
+type WebhookPayload struct {
+	ID      uid.ID          `json:"id"`
+	Event   string          `json:"event"`
+	Created Time            `json:"created"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// WebhookLogin is the data of login.succeeded and login.failed events.
+type WebhookLogin struct {
+	UserID uid.ID `json:"userID,omitempty"`
+	Name   string `json:"name,omitempty"`
+	Method string `json:"method"`
+}
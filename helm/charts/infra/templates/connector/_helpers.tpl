@@ -76,6 +76,8 @@ Pod labels
 
 {{/*
 Pod annotations
+The connector only reads its config at startup, so the checksum restarts it
+when any of the config changes.
 */}}
 {{- define "connector.podAnnotations" -}}
 rollme: {{ include (print .Template.BasePath "/connector/configmap.yaml") . | sha1sum }}
@@ -76,9 +76,12 @@ Pod labels
 
 {{/*
 Pod annotations
+The checksum restarts the server when settings that are only read at startup
+change. Providers, grants, and users are reloaded by the server, so they are
+left out of the checksum.
 */}}
 {{- define "server.podAnnotations" -}}
-rollme: {{ include (print .Template.BasePath "/server/configmap.yaml") . | sha1sum }}
+rollme: {{ list (omit .Values.server.config "providers" "grants" "users" "identities") .Values.server.additionalSecrets | toYaml | sha1sum }}
 {{- if or .Values.server.podAnnotations .Values.global.podAnnotations }}
 {{ merge .Values.server.podAnnotations .Values.global.podAnnotations | toYaml }}
 {{- end }}
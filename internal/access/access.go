@@ -8,7 +8,10 @@ import (
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/uid"
 )
 
@@ -28,16 +31,35 @@ func hasAuthorization(c *gin.Context, requestedResource uid.ID, isResourceOwner
 		return nil, fmt.Errorf("owner lookup: %w", err)
 	}
 
-	if owner {
+	// an access key limited to permissions or destinations is limited to them
+	// for its own identity too
+	if key := AuthenticatedAccessKey(c); owner && (key == nil || !key.IsLimited()) {
 		return getDB(c), nil
 	}
 
 	return RequireInfraRole(c, oneOfRoles...)
 }
 
+// requireScopeForSelf checks the access key of the request can be used for an
+// action that is limited to the calling identity. A key that is limited to
+// permissions or destinations must also have scope.
+func requireScopeForSelf(c *gin.Context, scope string) error {
+	key := AuthenticatedAccessKey(c)
+	if key == nil || !key.IsLimited() || key.Scopes.Includes(scope) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: access key does not have the %q scope", ErrNotAuthorized, scope)
+}
+
 const ResourceInfraAPI = "infra"
 
-// RequireInfraRole checks that the identity in the context can perform an action on a resource based on their granted roles
+// RequireInfraRole checks that the identity in the context can perform an action
+// on the infra API. Each of oneOfRoles is either a role, or a permission. The
+// roles granted to the identity, and to its groups, are resolved to the
+// permissions they include. The identity is authorized when it has one of the
+// permissions, or all the permissions of one of the roles. When the access key
+// of the request has permission scopes, only those permissions are used.
 func RequireInfraRole(c *gin.Context, oneOfRoles ...string) (*gorm.DB, error) {
 	db := getDB(c)
 
@@ -46,37 +68,107 @@ func RequireInfraRole(c *gin.Context, oneOfRoles ...string) (*gorm.DB, error) {
 		return nil, fmt.Errorf("no active identity")
 	}
 
+	permissions, err := infraPermissions(db, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	if key := AuthenticatedAccessKey(c); key != nil {
+		permissions = limitToPermissionScopes(permissions, key.PermissionScopes())
+	}
+
 	for _, role := range oneOfRoles {
-		ok, err := Can(db, identity.PolyID(), role, ResourceInfraAPI)
+		if hasPermissions(permissions, role) {
+			return db, nil
+		}
+	}
+
+	return nil, ErrNotAuthorized
+}
+
+// infraPermissions returns the permissions granted to the identity on the infra
+// API, directly or by its groups, along with the grants of each permission.
+func infraPermissions(db *gorm.DB, identity *models.Identity) (map[string][]models.Grant, error) {
+	subjects, err := identitySubjects(db, identity)
+	if err != nil {
+		return nil, fmt.Errorf("auth user groups: %w", err)
+	}
+
+	grants, err := data.ListGrants(db, data.BySubjects(subjects), data.ByResource(ResourceInfraAPI), data.ByNotExpired())
+	if err != nil {
+		return nil, fmt.Errorf("infra grants: %w", err)
+	}
+
+	permissions := make(map[string][]models.Grant)
+	for _, grant := range grants {
+		granted, err := privilegePermissions(db, grant.Privilege)
 		if err != nil {
 			return nil, err
 		}
 
-		if ok {
-			return db, nil
+		for _, permission := range granted {
+			permissions[permission] = append(permissions[permission], grant)
 		}
 	}
 
-	// check if they belong to a group that is authorized
-	groups, err := data.ListGroups(db, data.ByGroupMember(identity.ID))
-	if err != nil {
-		return nil, fmt.Errorf("auth user groups: %w", err)
+	return permissions, nil
+}
+
+// limitToPermissionScopes removes the permissions that are not one of scopes.
+// All the permissions are kept when there are no scopes.
+func limitToPermissionScopes(permissions map[string][]models.Grant, scopes []string) map[string][]models.Grant {
+	if len(scopes) == 0 {
+		return permissions
 	}
 
-	for _, group := range groups {
-		for _, role := range oneOfRoles {
-			ok, err := Can(db, group.PolyID(), role, ResourceInfraAPI)
-			if err != nil {
-				return nil, err
-			}
+	limited := make(map[string][]models.Grant)
+	for _, scope := range scopes {
+		if grants, ok := permissions[scope]; ok {
+			limited[scope] = grants
+		}
+	}
 
-			if ok {
-				return db, nil
-			}
+	return limited
+}
+
+// privilegePermissions returns the permissions included in the privilege of a
+// grant on the infra API. The privilege is a permission, a built-in role, or
+// the name of a custom role.
+func privilegePermissions(db *gorm.DB, privilege string) ([]string, error) {
+	if models.IsPermission(privilege) {
+		return []string{privilege}, nil
+	}
+
+	if permissions, ok := models.InfraRolePermissions[privilege]; ok {
+		return permissions, nil
+	}
+
+	role, err := data.GetRole(db, data.ByName(privilege))
+	switch {
+	case errors.Is(err, internal.ErrNotFound):
+		return nil, nil
+	case err != nil:
+		return nil, fmt.Errorf("infra role: %w", err)
+	}
+
+	return role.Permissions, nil
+}
+
+// hasPermissions returns true if permissions includes the permission, or all
+// the permissions of the built-in role.
+func hasPermissions(permissions map[string][]models.Grant, roleOrPermission string) bool {
+	required, ok := models.InfraRolePermissions[roleOrPermission]
+	if !ok {
+		return len(permissions[roleOrPermission]) > 0
+	}
+
+	for _, permission := range required {
+		if len(permissions[permission]) == 0 {
+			return false
 		}
 	}
 
-	return nil, ErrNotAuthorized
+	return true
 }
 
 var ErrNotAuthorized = errors.New("not authorized")
@@ -125,12 +217,32 @@ func HandleAuthErr(err error, resource, operation string, roles ...string) error
 	}
 }
 
-// Can checks if an identity has a privilege that means it can perform an action on a resource
+// Can checks if an identity has a privilege that means it can perform an action
+// on a resource. A grant on a resource also applies to the resources within it,
+// and may use wildcards, see api.ResourceMatches.
 func Can(db *gorm.DB, identity uid.PolymorphicID, privilege, resource string) (bool, error) {
-	grants, err := data.ListGrants(db, data.BySubject(identity), data.ByPrivilege(privilege), data.ByResource(resource))
+	grants, err := grantsFor(db, []uid.PolymorphicID{identity}, privilege, resource)
 	if err != nil {
 		return false, fmt.Errorf("has grants: %w", err)
 	}
 
 	return len(grants) > 0, nil
 }
+
+// grantsFor returns the unexpired grants of privilege to any of subjects that
+// apply to resource.
+func grantsFor(db *gorm.DB, subjects []uid.PolymorphicID, privilege, resource string) ([]models.Grant, error) {
+	grants, err := data.ListGrants(db, data.BySubjects(subjects), data.ByPrivilege(privilege), data.ByNotExpired())
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []models.Grant
+	for _, grant := range grants {
+		if api.ResourceMatches(grant.Resource, resource) {
+			matches = append(matches, grant)
+		}
+	}
+
+	return matches, nil
+}
@@ -1,27 +1,33 @@
 package access
 
 import (
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/uid"
 )
 
-func currentAccessKey(c *gin.Context) *models.AccessKey {
-	accessKey, ok := c.MustGet("key").(*models.AccessKey)
-	if !ok {
-		return nil
+// AuthenticatedAccessKey returns the access key that was used to authenticate
+// the request. Returns nil if the request was not authenticated.
+func AuthenticatedAccessKey(c *gin.Context) *models.AccessKey {
+	if raw, ok := c.Get("key"); ok {
+		if key, ok := raw.(*models.AccessKey); ok {
+			return key
+		}
 	}
-
-	return accessKey
+	return nil
 }
 
-func ListAccessKeys(c *gin.Context, identityID uid.ID, name string, showExpired bool, pg models.Pagination) ([]models.AccessKey, error) {
+func ListAccessKeys(c *gin.Context, identityID uid.ID, name string, showExpired bool, pg *models.Pagination) ([]models.AccessKey, error) {
 	roles := []string{models.InfraAdminRole, models.InfraViewRole}
-	db, err := RequireInfraRole(c, roles...)
+	db, err := RequireInfraRole(c, models.PermissionAccessKeysRead)
 	if err != nil {
 		return nil, HandleAuthErr(err, "access keys", "list", roles...)
 	}
@@ -35,8 +41,22 @@ func ListAccessKeys(c *gin.Context, identityID uid.ID, name string, showExpired
 }
 
 func CreateAccessKey(c *gin.Context, accessKey *models.AccessKey) (body string, err error) {
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	db, err := RequireInfraRole(c, models.PermissionAccessKeysWrite)
+	if err != nil {
+		return "", HandleAuthErr(err, "access key", "create", models.InfraAdminRole)
+	}
+
+	if err := validateAccessKeyScopes(accessKey.Scopes, AuthenticatedAccessKey(c)); err != nil {
+		return "", err
+	}
+
+	issuedFor, err := data.GetIdentity(db, data.ByID(accessKey.IssuedFor))
 	if err != nil {
+		return "", err
+	}
+
+	// the new key only acts as the identity for its permission scopes
+	if err := requireIdentityPermissions(c, issuedFor, accessKey.PermissionScopes()); err != nil {
 		return "", HandleAuthErr(err, "access key", "create", models.InfraAdminRole)
 	}
 
@@ -48,8 +68,52 @@ func CreateAccessKey(c *gin.Context, accessKey *models.AccessKey) (body string,
 	return body, err
 }
 
+// validateAccessKeyScopes checks that scopes can be requested for a new access
+// key. When the request was made with a scoped access key, the new key must be
+// limited at least as much as that key.
+func validateAccessKeyScopes(scopes []string, requestKey *models.AccessKey) error {
+	for _, scope := range scopes {
+		destination := strings.TrimPrefix(scope, models.ScopeDestinationPrefix)
+		if destination != scope && destination != "" {
+			continue
+		}
+
+		if scope != models.ScopeReadOnly && scope != models.ScopeTokens && !models.IsPermission(scope) {
+			return fmt.Errorf("%w: unknown access key scope %q", internal.ErrBadRequest, scope)
+		}
+	}
+
+	if requestKey == nil {
+		return nil
+	}
+
+	requested := models.AccessKey{Scopes: scopes}
+	for _, scope := range []string{models.ScopeReadOnly, models.ScopeTokens} {
+		if requestKey.Scopes.Includes(scope) && !requested.Scopes.Includes(scope) {
+			return fmt.Errorf("%w: access keys created with this access key must have the %q scope", internal.ErrBadRequest, scope)
+		}
+	}
+
+	permissions := requestKey.PermissionScopes()
+	if len(permissions) == 0 {
+		return nil
+	}
+
+	if len(requested.PermissionScopes()) == 0 {
+		return fmt.Errorf("%w: access keys created with this access key must be limited to its permissions", internal.ErrBadRequest)
+	}
+
+	for _, scope := range requested.PermissionScopes() {
+		if !requestKey.Scopes.Includes(scope) {
+			return fmt.Errorf("%w: access keys created with this access key can not have the %q scope", internal.ErrBadRequest, scope)
+		}
+	}
+
+	return nil
+}
+
 func DeleteAccessKey(c *gin.Context, id uid.ID) error {
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	db, err := RequireInfraRole(c, models.PermissionAccessKeysWrite)
 	if err != nil {
 		return HandleAuthErr(err, "access key", "delete", models.InfraAdminRole)
 	}
@@ -57,9 +121,63 @@ func DeleteAccessKey(c *gin.Context, id uid.ID) error {
 	return data.DeleteAccessKeys(db, data.ByID(id))
 }
 
+// RotateAccessKey replaces the secret of an access key. The previous secret
+// remains valid for gracePeriod. Access keys can be rotated by the identity
+// they were issued for, so that automation can rotate its own key.
+func RotateAccessKey(c *gin.Context, id uid.ID, gracePeriod time.Duration) (*models.AccessKey, string, error) {
+	db, err := hasAuthorization(c, id, isAccessKeyOwner, models.PermissionAccessKeysWrite)
+	if err != nil {
+		return nil, "", HandleAuthErr(err, "access key", "rotate", models.InfraAdminRole)
+	}
+
+	key, err := data.GetAccessKey(db, data.ByID(id))
+	if err != nil {
+		return nil, "", err
+	}
+
+	if gracePeriod < 0 {
+		return nil, "", fmt.Errorf("%w: grace period must not be negative", internal.ErrBadRequest)
+	}
+
+	// a secret that was replaced by a rotation must not be able to replace
+	// the current secret, otherwise a leaked secret could lock out the owner
+	if requestKey := AuthenticatedAccessKey(c); requestKey != nil && requestKey.UsedPreviousSecret {
+		return nil, "", fmt.Errorf("%w: access keys can not be rotated with a secret that was replaced by a rotation", internal.ErrBadRequest)
+	}
+
+	body, err := data.RotateAccessKey(db, key, gracePeriod)
+	if err != nil {
+		return nil, "", fmt.Errorf("rotate access key: %w", err)
+	}
+
+	return key, body, nil
+}
+
+// isAccessKeyOwner checks if the access key was issued for the authenticated
+// identity.
+func isAccessKeyOwner(c *gin.Context, id uid.ID) (bool, error) {
+	identity := AuthenticatedIdentity(c)
+	if identity == nil {
+		return false, nil
+	}
+
+	key, err := data.GetAccessKey(getDB(c), data.ByID(id))
+	switch {
+	case errors.Is(err, internal.ErrNotFound):
+		return false, nil
+	case err != nil:
+		return false, err
+	}
+
+	return key.IssuedFor == identity.ID, nil
+}
+
 func DeleteRequestAccessKey(c *gin.Context) error {
 	// does not need authorization check, this action is limited to the calling key
-	key := currentAccessKey(c)
+	key := AuthenticatedAccessKey(c)
+	if key == nil {
+		return fmt.Errorf("%w: the request was not authenticated with an access key", internal.ErrBadRequest)
+	}
 
 	db := getDB(c)
 
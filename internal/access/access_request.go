@@ -0,0 +1,300 @@
+package access
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// CreateAccessRequest creates a pending access request for the authenticated
+// identity. Any authenticated identity may request access.
+func CreateAccessRequest(c *gin.Context, request *models.AccessRequest) error {
+	identity := AuthenticatedIdentity(c)
+	if identity == nil {
+		return fmt.Errorf("no active identity")
+	}
+
+	request.RequestedBy = identity.ID
+	request.Status = models.AccessRequestPending
+
+	return data.CreateAccessRequest(getDB(c), request)
+}
+
+func GetAccessRequest(c *gin.Context, id uid.ID) (*models.AccessRequest, error) {
+	db := getDB(c)
+
+	request, err := data.GetAccessRequest(db, data.ByID(id))
+	if err != nil {
+		return nil, err
+	}
+
+	roles := []string{models.InfraAdminRole, models.InfraViewRole}
+	if err := canViewAccessRequest(c, request, models.PermissionAccessRequestsRead); err != nil {
+		return nil, HandleAuthErr(err, "access request", "get", roles...)
+	}
+
+	return request, nil
+}
+
+// ListAccessRequests lists access requests. Identities without an infra role
+// may list their own requests, and the requests they are able to approve.
+func ListAccessRequests(c *gin.Context, requestedBy uid.ID, resource, status string, pg *models.Pagination) ([]models.AccessRequest, error) {
+	selectors := []data.SelectorFunc{
+		data.ByOptionalRequestedBy(requestedBy),
+		data.ByOptionalResource(resource),
+		data.ByOptionalStatus(status),
+		data.ByPagination(pg),
+	}
+
+	roles := []string{models.InfraAdminRole, models.InfraViewRole}
+	db, err := RequireInfraRole(c, models.PermissionAccessRequestsRead)
+	if err == nil {
+		return data.ListAccessRequests(db, selectors...)
+	}
+	err = HandleAuthErr(err, "access requests", "list", roles...)
+
+	if errors.Is(err, ErrNotAuthorized) {
+		db := getDB(c)
+		identity := AuthenticatedIdentity(c)
+		if identity == nil {
+			return nil, err
+		}
+
+		approvers, err := listApproversFor(db, identity)
+		if err != nil {
+			return nil, err
+		}
+
+		resources := make([]string, 0, len(approvers))
+		for _, approver := range approvers {
+			resources = append(resources, approver.Resource)
+		}
+
+		selectors = append(selectors, data.ByRequestedByOrResources(identity.ID, resources))
+		return data.ListAccessRequests(db, selectors...)
+	}
+
+	return nil, err
+}
+
+// ApproveAccessRequest approves a pending access request and creates a grant
+// for the identity that made the request. The grant is recorded as created by
+// the approver.
+func ApproveAccessRequest(c *gin.Context, id uid.ID) (*models.AccessRequest, error) {
+	db, request, err := reviewAccessRequest(c, id, "approve")
+	if err != nil {
+		return nil, err
+	}
+
+	grant := &models.Grant{
+		Subject:   uid.NewIdentityPolymorphicID(request.RequestedBy),
+		Privilege: request.Privilege,
+		Resource:  request.Resource,
+		CreatedBy: request.ReviewedBy,
+	}
+
+	if request.Duration > 0 {
+		expires := time.Now().Add(request.Duration)
+		grant.ExpiresAt = &expires
+	}
+
+	if err := data.CreateGrant(db, grant); err != nil {
+		return nil, fmt.Errorf("create grant: %w", err)
+	}
+
+	if grant.ID == 0 {
+		// the requester already had an equivalent grant
+		existing, err := data.GetGrant(db, data.BySubject(grant.Subject), data.ByPrivilege(grant.Privilege), data.ByResource(grant.Resource), data.ByNotExpired())
+		if err != nil {
+			return nil, fmt.Errorf("get grant: %w", err)
+		}
+		grant = existing
+	}
+
+	request.Status = models.AccessRequestApproved
+	request.GrantID = grant.ID
+
+	if err := data.SaveAccessRequest(db, request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// DenyAccessRequest denies a pending access request.
+func DenyAccessRequest(c *gin.Context, id uid.ID) (*models.AccessRequest, error) {
+	db, request, err := reviewAccessRequest(c, id, "deny")
+	if err != nil {
+		return nil, err
+	}
+
+	request.Status = models.AccessRequestDenied
+
+	if err := data.SaveAccessRequest(db, request); err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// reviewAccessRequest checks that the authenticated identity may review the
+// access request, and that the request is still pending.
+func reviewAccessRequest(c *gin.Context, id uid.ID, operation string) (*gorm.DB, *models.AccessRequest, error) {
+	db := getDB(c)
+
+	identity := AuthenticatedIdentity(c)
+	if identity == nil {
+		return nil, nil, fmt.Errorf("no active identity")
+	}
+
+	request, err := data.GetAccessRequest(db, data.ByID(id))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if _, err := RequireInfraRole(c, models.PermissionAccessRequestsWrite); err != nil {
+		if !errors.Is(err, ErrNotAuthorized) {
+			return nil, nil, err
+		}
+
+		ok, err := canApprove(db, identity, request.Resource)
+		switch {
+		case err != nil:
+			return nil, nil, err
+		case !ok:
+			return nil, nil, HandleAuthErr(ErrNotAuthorized, "access request", operation, models.InfraAdminRole)
+		}
+	}
+
+	if request.RequestedBy == identity.ID {
+		return nil, nil, fmt.Errorf("%w: cannot %s your own access request", internal.ErrBadRequest, operation)
+	}
+
+	if request.Status != models.AccessRequestPending {
+		return nil, nil, fmt.Errorf("%w: access request has already been %s", internal.ErrBadRequest, request.Status)
+	}
+
+	request.ReviewedBy = identity.ID
+	return db, request, nil
+}
+
+func canViewAccessRequest(c *gin.Context, request *models.AccessRequest, permission string) error {
+	_, err := RequireInfraRole(c, permission)
+	if !errors.Is(err, ErrNotAuthorized) {
+		return err
+	}
+
+	identity := AuthenticatedIdentity(c)
+	if identity == nil {
+		return err
+	}
+
+	if request.RequestedBy == identity.ID {
+		return nil
+	}
+
+	ok, approverErr := canApprove(getDB(c), identity, request.Resource)
+	switch {
+	case approverErr != nil:
+		return approverErr
+	case ok:
+		return nil
+	}
+
+	return err
+}
+
+// canApprove returns true if the identity, or any of its groups, is an
+// approver for the resource or any resource that contains it.
+func canApprove(db *gorm.DB, identity *models.Identity, resource string) (bool, error) {
+	subjects, err := identitySubjects(db, identity)
+	if err != nil {
+		return false, err
+	}
+
+	approvers, err := data.ListAccessApprovers(db, data.BySubjects(subjects), data.ByResources(resourceAndParents(resource)))
+	if err != nil {
+		return false, err
+	}
+
+	return len(approvers) > 0, nil
+}
+
+func listApproversFor(db *gorm.DB, identity *models.Identity) ([]models.AccessApprover, error) {
+	subjects, err := identitySubjects(db, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.ListAccessApprovers(db, data.BySubjects(subjects))
+}
+
+// identitySubjects returns the polymorphic IDs of the identity and all the
+// groups it belongs to.
+func identitySubjects(db *gorm.DB, identity *models.Identity) ([]uid.PolymorphicID, error) {
+	groups, err := data.ListGroups(db, data.ByGroupMember(identity.ID))
+	if err != nil {
+		return nil, fmt.Errorf("identity groups: %w", err)
+	}
+
+	subjects := []uid.PolymorphicID{identity.PolyID()}
+	for _, group := range groups {
+		subjects = append(subjects, group.PolyID())
+	}
+
+	return subjects, nil
+}
+
+// resourceAndParents returns the resource along with all the resources that
+// contain it. For example, production.payments returns production.payments
+// and production.
+func resourceAndParents(resource string) []string {
+	resources := []string{resource}
+	for i := strings.LastIndex(resource, "."); i > 0; i = strings.LastIndex(resource, ".") {
+		resource = resource[:i]
+		resources = append(resources, resource)
+	}
+
+	return resources
+}
+
+func ListAccessApprovers(c *gin.Context, resource string, pg *models.Pagination) ([]models.AccessApprover, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole}
+	db, err := RequireInfraRole(c, models.PermissionAccessApproversRead)
+	if err != nil {
+		return nil, HandleAuthErr(err, "access approvers", "list", roles...)
+	}
+
+	return data.ListAccessApprovers(db, data.ByOptionalResource(resource), data.ByPagination(pg))
+}
+
+func CreateAccessApprover(c *gin.Context, approver *models.AccessApprover) error {
+	db, err := RequireInfraRole(c, models.PermissionAccessApproversWrite)
+	if err != nil {
+		return HandleAuthErr(err, "access approver", "create", models.InfraAdminRole)
+	}
+
+	return data.CreateAccessApprover(db, approver)
+}
+
+func DeleteAccessApprover(c *gin.Context, id uid.ID) error {
+	db, err := RequireInfraRole(c, models.PermissionAccessApproversWrite)
+	if err != nil {
+		return HandleAuthErr(err, "access approver", "delete", models.InfraAdminRole)
+	}
+
+	if _, err := data.GetAccessApprover(db, data.ByID(id)); err != nil {
+		return err
+	}
+
+	return data.DeleteAccessApprovers(db, data.ByID(id))
+}
@@ -0,0 +1,13 @@
+package access
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestResourceAndParents(t *testing.T) {
+	assert.DeepEqual(t, resourceAndParents("production"), []string{"production"})
+	assert.DeepEqual(t, resourceAndParents("production.payments"), []string{"production.payments", "production"})
+	assert.DeepEqual(t, resourceAndParents("a.b.c"), []string{"a.b.c", "a.b", "a"})
+}
@@ -78,14 +78,20 @@ func TestBasicGrant(t *testing.T) {
 
 	grant(t, db, tom, "i:bob", "read", "infra.groups")
 	can(t, db, "i:bob", "read", "infra.groups")
-	cant(t, db, "i:bob", "read", "infra.groups.1") // currently we check for exact grant match, this may change as grants evolve
+	can(t, db, "i:bob", "read", "infra.groups.1") // a grant on a parent applies to its children
 	cant(t, db, "i:bob", "write", "infra.groups")
 
 	grant(t, db, tom, "i:alice", "read", "infra.machines")
 	can(t, db, "i:alice", "read", "infra.machines")
 	cant(t, db, "i:alice", "read", "infra")
-	cant(t, db, "i:alice", "read", "infra.machines.1")
+	can(t, db, "i:alice", "read", "infra.machines.1")
 	cant(t, db, "i:alice", "write", "infra.machines")
+
+	grant(t, db, tom, "i:carol", "read", "staging-*.monitoring")
+	can(t, db, "i:carol", "read", "staging-1.monitoring")
+	can(t, db, "i:carol", "read", "staging-2.monitoring.pods")
+	cant(t, db, "i:carol", "read", "staging-1")
+	cant(t, db, "i:carol", "read", "production.monitoring")
 }
 
 func TestUsersGroupGrant(t *testing.T) {
@@ -177,6 +183,107 @@ func TestInfraRequireInfraRole(t *testing.T) {
 	})
 }
 
+func TestRequireInfraRole_Permissions(t *testing.T) {
+	db := setupDB(t)
+
+	role := &models.Role{Name: "user-manager", Permissions: []string{models.PermissionUsersRead, models.PermissionUsersWrite}}
+	err := data.CreateRole(db, role)
+	assert.NilError(t, err)
+
+	setup := func(t *testing.T, privilege string) *gin.Context {
+		identity := &models.Identity{Name: fmt.Sprintf("infra-%s-%s", privilege, time.Now())}
+		err := data.CreateIdentity(db, identity)
+		assert.NilError(t, err)
+
+		group := &models.Group{Name: fmt.Sprintf("group-%s-%s", privilege, time.Now())}
+		err = data.CreateGroup(db, group)
+		assert.NilError(t, err)
+
+		err = data.AddUsersToGroup(db, group.ID, []uid.ID{identity.ID})
+		assert.NilError(t, err)
+
+		grant(t, db, identity, group.PolyID(), privilege, ResourceInfraAPI)
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("db", db)
+		c.Set("identity", identity)
+		return c
+	}
+
+	t.Run("custom role", func(t *testing.T) {
+		c := setup(t, role.Name)
+
+		_, err := RequireInfraRole(c, models.PermissionUsersWrite)
+		assert.NilError(t, err)
+
+		_, err = RequireInfraRole(c, models.PermissionGrantsRead)
+		assert.ErrorIs(t, err, ErrNotAuthorized)
+
+		_, err = RequireInfraRole(c, models.InfraAdminRole)
+		assert.ErrorIs(t, err, ErrNotAuthorized)
+	})
+
+	t.Run("built-in role", func(t *testing.T) {
+		c := setup(t, models.InfraViewRole)
+
+		_, err := RequireInfraRole(c, models.PermissionGrantsRead)
+		assert.NilError(t, err)
+
+		_, err = RequireInfraRole(c, models.PermissionGrantsWrite)
+		assert.ErrorIs(t, err, ErrNotAuthorized)
+	})
+
+	t.Run("permission", func(t *testing.T) {
+		c := setup(t, models.PermissionGroupsRead)
+
+		_, err := RequireInfraRole(c, models.PermissionGroupsRead)
+		assert.NilError(t, err)
+
+		_, err = RequireInfraRole(c, models.InfraViewRole)
+		assert.ErrorIs(t, err, ErrNotAuthorized)
+	})
+
+	t.Run("all the permissions of a role", func(t *testing.T) {
+		everything := &models.Role{Name: "everything", Permissions: models.Permissions}
+		err := data.CreateRole(db, everything)
+		assert.NilError(t, err)
+
+		c := setup(t, everything.Name)
+
+		_, err = RequireInfraRole(c, models.InfraAdminRole)
+		assert.NilError(t, err)
+	})
+
+	t.Run("deleted role", func(t *testing.T) {
+		temporary := &models.Role{Name: "temporary", Permissions: []string{models.PermissionUsersRead}}
+		err := data.CreateRole(db, temporary)
+		assert.NilError(t, err)
+
+		c := setup(t, temporary.Name)
+
+		err = data.DeleteRoles(db, data.ByID(temporary.ID))
+		assert.NilError(t, err)
+
+		_, err = RequireInfraRole(c, models.PermissionUsersRead)
+		assert.ErrorIs(t, err, ErrNotAuthorized)
+	})
+}
+
+func TestExpiredGrant(t *testing.T) {
+	db := setupDB(t)
+
+	past := time.Now().Add(-time.Minute)
+	future := time.Now().Add(time.Hour)
+
+	err := data.CreateGrant(db, &models.Grant{Subject: "i:steven", Privilege: "read", Resource: "infra.groups", ExpiresAt: &past})
+	assert.NilError(t, err)
+	cant(t, db, "i:steven", "read", "infra.groups")
+
+	err = data.CreateGrant(db, &models.Grant{Subject: "i:bob", Privilege: "read", Resource: "infra.groups", ExpiresAt: &future})
+	assert.NilError(t, err)
+	can(t, db, "i:bob", "read", "infra.groups")
+}
+
 func grant(t *testing.T, db *gorm.DB, currentUser *models.Identity, subject uid.PolymorphicID, privilege, resource string) {
 	err := data.CreateGrant(db, &models.Grant{
 		Subject:   subject,
@@ -188,12 +295,14 @@ func grant(t *testing.T, db *gorm.DB, currentUser *models.Identity, subject uid.
 }
 
 func can(t *testing.T, db *gorm.DB, subject uid.PolymorphicID, privilege, resource string) {
+	t.Helper()
 	canAccess, err := Can(db, subject, privilege, resource)
 	assert.NilError(t, err)
 	assert.Assert(t, canAccess)
 }
 
 func cant(t *testing.T, db *gorm.DB, subject uid.PolymorphicID, privilege, resource string) {
+	t.Helper()
 	canAccess, err := Can(db, subject, privilege, resource)
 	assert.NilError(t, err)
 	assert.Assert(t, !canAccess)
@@ -0,0 +1,29 @@
+package access
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func ListAuditEvents(c *gin.Context, actorID uid.ID, kind string, targetID uid.ID, after, before time.Time, pg *models.Pagination) ([]models.AuditEvent, error) {
+	db, err := RequireInfraRole(c, models.PermissionAuditEventsRead)
+	if err != nil {
+		return nil, HandleAuthErr(err, "audit events", "list", models.InfraAdminRole)
+	}
+
+	selectors := []data.SelectorFunc{
+		data.ByOptionalActorID(actorID),
+		data.ByOptionalTargetKind(kind),
+		data.ByOptionalTargetID(targetID),
+		data.ByOptionalCreatedAfter(after),
+		data.ByOptionalCreatedBefore(before),
+		data.ByPagination(pg),
+	}
+
+	return data.ListAuditEvents(db, selectors...)
+}
@@ -0,0 +1,93 @@
+package access
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// AccessCheck is the result of CheckAccess.
+type AccessCheck struct {
+	Allowed bool
+	// Grants are the grants that allow the access. When the resource is the
+	// infra API they are the grants of each permission that is granted.
+	Grants []models.Grant
+	// Missing are the permissions that are not granted, when the resource is
+	// the infra API.
+	Missing []string
+	// Groups are the groups of the user, which may be the subject of Grants.
+	Groups []models.Group
+}
+
+// CheckAccess checks if the user has the privilege on the resource, and
+// explains the decision with the grants that allow it. It uses the same rules
+// as Can for destinations, and RequireInfraRole for the infra API.
+func CheckAccess(c *gin.Context, userID uid.ID, privilege, resource string) (*AccessCheck, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole, models.InfraConnectorRole}
+	db, err := hasAuthorization(c, userID, isIdentitySelf, models.PermissionGrantsRead)
+	if err != nil {
+		return nil, HandleAuthErr(err, "access", "check", roles...)
+	}
+
+	identity, err := data.GetIdentity(db, data.ByID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	groups, err := data.ListGroups(db, data.ByGroupMember(identity.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	check := &AccessCheck{Groups: groups}
+
+	if resource == ResourceInfraAPI {
+		required, err := privilegePermissions(db, privilege)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(required) == 0 {
+			// an unknown role is never granted
+			return check, nil
+		}
+
+		permissions, err := infraPermissions(db, identity)
+		if err != nil {
+			return nil, err
+		}
+
+		included := make(map[uid.ID]bool)
+		for _, permission := range required {
+			if len(permissions[permission]) == 0 {
+				check.Missing = append(check.Missing, permission)
+				continue
+			}
+
+			for _, grant := range permissions[permission] {
+				if !included[grant.ID] {
+					included[grant.ID] = true
+					check.Grants = append(check.Grants, grant)
+				}
+			}
+		}
+
+		check.Allowed = len(check.Missing) == 0
+		return check, nil
+	}
+
+	subjects := []uid.PolymorphicID{identity.PolyID()}
+	for _, group := range groups {
+		subjects = append(subjects, group.PolyID())
+	}
+
+	check.Grants, err = grantsFor(db, subjects, privilege, resource)
+	if err != nil {
+		return nil, err
+	}
+
+	check.Allowed = len(check.Grants) > 0
+	return check, nil
+}
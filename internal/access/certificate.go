@@ -0,0 +1,90 @@
+package access
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/pki"
+)
+
+// SignCertificate signs a client certificate for the calling identity, for the
+// public key of the PEM encoded certificate signing request csrPEM. The subject
+// of the request is replaced with the calling identity. Certificates can only
+// be requested by users with an unscoped access key, so that a certificate can
+// not be used to renew itself, or to get more access than the key was limited
+// to.
+func SignCertificate(c *gin.Context, cp pki.CertificateProvider, csrPEM []byte) (*x509.Certificate, []byte, error) {
+	identity := AuthenticatedIdentity(c)
+	if identity == nil {
+		return nil, nil, fmt.Errorf("no active identity")
+	}
+
+	// service accounts can only authenticate with access keys
+	if identity.IsServiceAccount() {
+		return nil, nil, fmt.Errorf("%w: client certificates can not be requested for a service account", internal.ErrBadRequest)
+	}
+
+	// does not need authorization check, limited to calling identity
+	key := AuthenticatedAccessKey(c)
+	if key == nil {
+		return nil, nil, fmt.Errorf("%w: client certificates must be requested with an access key", internal.ErrBadRequest)
+	}
+
+	if len(key.Scopes) > 0 {
+		return nil, nil, fmt.Errorf("%w: client certificates can not be requested with a scoped access key", internal.ErrBadRequest)
+	}
+
+	block, _ := pem.Decode(csrPEM)
+	if block == nil || block.Type != "CERTIFICATE REQUEST" {
+		return nil, nil, fmt.Errorf("%w: certificate signing request must be a PEM encoded CERTIFICATE REQUEST", internal.ErrBadRequest)
+	}
+
+	csr, err := x509.ParseCertificateRequest(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid certificate signing request: %s", internal.ErrBadRequest, err)
+	}
+
+	if err := csr.CheckSignature(); err != nil {
+		return nil, nil, fmt.Errorf("%w: invalid certificate signing request: %s", internal.ErrBadRequest, err)
+	}
+
+	pemBytes, err := cp.SignCertificate(x509.CertificateRequest{
+		Subject:            pkix.Name{CommonName: "User " + identity.ID.String()},
+		EmailAddresses:     []string{identity.Name},
+		PublicKeyAlgorithm: csr.PublicKeyAlgorithm,
+		PublicKey:          csr.PublicKey,
+		SignatureAlgorithm: csr.SignatureAlgorithm,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %s", internal.ErrBadRequest, err)
+	}
+
+	block, _ = pem.Decode(pemBytes)
+	if block == nil {
+		return nil, nil, fmt.Errorf("decoding signed certificate")
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing signed certificate: %w", err)
+	}
+
+	// the certificate is only accepted until it is revoked
+	err = data.CreateClientCertificate(getDB(c), &models.ClientCertificate{
+		IdentityID:   identity.ID,
+		SerialNumber: cert.SerialNumber.String(),
+		ExpiresAt:    cert.NotAfter,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("create client certificate: %w", err)
+	}
+
+	return cert, pemBytes, nil
+}
@@ -6,15 +6,17 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 
 	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/generate"
+	"github.com/infrahq/infra/internal/server/authn"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
 )
 
 func CreateCredential(c *gin.Context, user models.Identity) (string, error) {
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	db, err := RequireInfraRole(c, models.PermissionUsersWrite)
 	if err != nil {
 		return "", HandleAuthErr(err, "user", "create", models.InfraAdminRole)
 	}
@@ -42,8 +44,8 @@ func CreateCredential(c *gin.Context, user models.Identity) (string, error) {
 	return tmpPassword, nil
 }
 
-func UpdateCredential(c *gin.Context, user *models.Identity, newPassword string) error {
-	db, err := hasAuthorization(c, user.ID, isIdentitySelf, models.InfraAdminRole)
+func UpdateCredential(c *gin.Context, user *models.Identity, newPassword string, policy authn.PasswordPolicy) error {
+	db, err := hasAuthorization(c, user.ID, isIdentitySelf, models.PermissionUsersWrite)
 	if err != nil {
 		return HandleAuthErr(err, "user", "update", models.InfraAdminRole)
 	}
@@ -53,14 +55,24 @@ func UpdateCredential(c *gin.Context, user *models.Identity, newPassword string)
 		return err
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
-	if err != nil {
-		return fmt.Errorf("hash: %w", err)
+	if !isSelf {
+		if err := requireIdentityPermissions(c, user, nil); err != nil {
+			return HandleAuthErr(err, "user", "update", models.InfraAdminRole)
+		}
 	}
 
 	userCredential, err := data.GetCredential(db, data.ByIdentityID(user.ID))
 	if err != nil {
 		if errors.Is(err, internal.ErrNotFound) && !isSelf {
+			if err := policy.Check(newPassword); err != nil {
+				return err
+			}
+
+			hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+			if err != nil {
+				return fmt.Errorf("hash: %w", err)
+			}
+
 			if err := data.CreateCredential(db, &models.Credential{
 				IdentityID:      user.ID,
 				PasswordHash:    hash,
@@ -68,29 +80,65 @@ func UpdateCredential(c *gin.Context, user *models.Identity, newPassword string)
 			}); err != nil {
 				return fmt.Errorf("creating credentials: %w", err)
 			}
-			return nil
+			return data.RevokeClientCertificates(db, user.ID)
 		}
 		return fmt.Errorf("existing credential: %w", err)
 	}
 
-	userCredential.PasswordHash = hash
+	if err := policy.Check(newPassword, userCredential.PasswordHashes()...); err != nil {
+		return err
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("hash: %w", err)
+	}
+
+	userCredential.SetPasswordHash(hash, policy.HistoryDepth)
 	userCredential.OneTimePassword = !isSelf
 
 	if err := data.SaveCredential(db, userCredential); err != nil {
 		return fmt.Errorf("saving credentials: %w", err)
 	}
 
+	// client certificates signed before the password changed can not be used
+	if err := data.RevokeClientCertificates(db, user.ID); err != nil {
+		return fmt.Errorf("revoking client certificates: %w", err)
+	}
+
 	if isSelf {
 		// if we updated our own password, remove the password-reset scope from our access key.
-		if k, ok := c.Get("key"); ok {
-			if accessKey, ok := k.(*models.AccessKey); ok {
-				accessKey.Scopes = models.CommaSeparatedStrings{}
-				if err = data.SaveAccessKey(db, accessKey); err != nil {
-					return fmt.Errorf("updating access key: %w", err)
-				}
-			}
+		if err := removeRequestKeyScope(c, db, models.ScopePasswordReset); err != nil {
+			return err
 		}
 	}
 
 	return nil
 }
+
+// removeRequestKeyScope removes scope from the access key of the request.
+func removeRequestKeyScope(c *gin.Context, db *gorm.DB, scope string) error {
+	k, ok := c.Get("key")
+	if !ok {
+		return nil
+	}
+
+	accessKey, ok := k.(*models.AccessKey)
+	if !ok || !accessKey.Scopes.Includes(scope) {
+		return nil
+	}
+
+	scopes := models.CommaSeparatedStrings{}
+	for _, s := range accessKey.Scopes {
+		if s != scope {
+			scopes = append(scopes, s)
+		}
+	}
+
+	accessKey.Scopes = scopes
+	if err := data.SaveAccessKey(db, accessKey); err != nil {
+		return fmt.Errorf("updating access key: %w", err)
+	}
+
+	return nil
+}
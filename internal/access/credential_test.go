@@ -1,10 +1,12 @@
 package access
 
 import (
+	"errors"
 	"testing"
 
 	"gotest.tools/v3/assert"
 
+	"github.com/infrahq/infra/internal/server/authn"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
 )
@@ -37,7 +39,7 @@ func TestUpdateCredentials(t *testing.T) {
 	assert.NilError(t, err)
 
 	t.Run("Update user credentials IS single use password", func(t *testing.T) {
-		err := UpdateCredential(c, user, "newPassword")
+		err := UpdateCredential(c, user, "newPassword", authn.PasswordPolicy{})
 		assert.NilError(t, err)
 
 		creds, err := data.GetCredential(db, data.ByIdentityID(user.ID))
@@ -48,11 +50,34 @@ func TestUpdateCredentials(t *testing.T) {
 	t.Run("Update own credentials is NOT single use password", func(t *testing.T) {
 		c.Set("identity", user)
 
-		err := UpdateCredential(c, user, "newPassword")
+		err := UpdateCredential(c, user, "newPassword", authn.PasswordPolicy{})
 		assert.NilError(t, err)
 
 		creds, err := data.GetCredential(db, data.ByIdentityID(user.ID))
 		assert.NilError(t, err)
 		assert.Equal(t, creds.OneTimePassword, false)
 	})
+	t.Run("Update credentials follows the password policy", func(t *testing.T) {
+		c.Set("identity", user)
+		policy := authn.PasswordPolicy{MinLength: 10, RequireNumber: true, HistoryDepth: 2}
+
+		err := UpdateCredential(c, user, "short", policy)
+		var policyErr authn.PasswordPolicyError
+		assert.Assert(t, errors.As(err, &policyErr), err)
+		assert.DeepEqual(t, policyErr.Failures, []string{"must be at least 10 characters", "must contain a number"})
+
+		for _, password := range []string{"first-password-1", "second-password-2"} {
+			err = UpdateCredential(c, user, password, policy)
+			assert.NilError(t, err)
+		}
+
+		err = UpdateCredential(c, user, "first-password-1", policy)
+		assert.ErrorContains(t, err, "must not be one of the last 2 passwords")
+
+		err = UpdateCredential(c, user, "third-password-3", policy)
+		assert.NilError(t, err)
+
+		err = UpdateCredential(c, user, "first-password-1", policy)
+		assert.NilError(t, err)
+	})
 }
@@ -10,7 +10,7 @@ import (
 
 func CreateDestination(c *gin.Context, destination *models.Destination) error {
 	roles := []string{models.InfraAdminRole, models.InfraConnectorRole}
-	db, err := RequireInfraRole(c, roles...)
+	db, err := RequireInfraRole(c, models.PermissionDestinationsRegister)
 	if err != nil {
 		return HandleAuthErr(err, "destination", "create", roles...)
 	}
@@ -20,7 +20,7 @@ func CreateDestination(c *gin.Context, destination *models.Destination) error {
 
 func SaveDestination(c *gin.Context, destination *models.Destination) error {
 	roles := []string{models.InfraAdminRole, models.InfraConnectorRole}
-	db, err := RequireInfraRole(c, roles...)
+	db, err := RequireInfraRole(c, models.PermissionDestinationsRegister)
 	if err != nil {
 		return HandleAuthErr(err, "destination", "update", roles...)
 	}
@@ -33,14 +33,14 @@ func GetDestination(c *gin.Context, id uid.ID) (*models.Destination, error) {
 	return data.GetDestination(db, data.ByID(id))
 }
 
-func ListDestinations(c *gin.Context, uniqueID, name string, pg models.Pagination) ([]models.Destination, error) {
+func ListDestinations(c *gin.Context, uniqueID, name string, pg *models.Pagination) ([]models.Destination, error) {
 	db := getDB(c)
 	return data.ListDestinations(db, data.ByOptionalUniqueID(uniqueID),
 		data.ByOptionalName(name), data.ByPagination(pg))
 }
 
 func DeleteDestination(c *gin.Context, id uid.ID) error {
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	db, err := RequireInfraRole(c, models.PermissionDestinationsWrite)
 	if err != nil {
 		return HandleAuthErr(err, "destination", "delete", models.InfraAdminRole)
 	}
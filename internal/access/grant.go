@@ -2,33 +2,37 @@ package access
 
 import (
 	"errors"
+	"sort"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 
+	"github.com/infrahq/infra/api"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/uid"
 )
 
 func GetGrant(c *gin.Context, id uid.ID) (*models.Grant, error) {
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	roles := []string{models.InfraAdminRole, models.InfraViewRole, models.InfraConnectorRole}
+	db, err := RequireInfraRole(c, models.PermissionGrantsRead)
 	if err != nil {
-		return nil, HandleAuthErr(err, "grant", "get", models.InfraAdminRole)
+		return nil, HandleAuthErr(err, "grant", "get", roles...)
 	}
 
 	return data.GetGrant(db, data.ByID(id))
 }
 
-func ListGrants(c *gin.Context, subject uid.PolymorphicID, resource string, privilege string, pg models.Pagination) ([]models.Grant, error) {
+func ListGrants(c *gin.Context, subject uid.PolymorphicID, resource string, privilege string, pg *models.Pagination) ([]models.Grant, error) {
 	selectors := []data.SelectorFunc{
 		data.ByOptionalResource(resource),
 		data.ByOptionalPrivilege(privilege),
+		data.ByNotExpired(),
 		data.ByPagination(pg),
 	}
 
 	roles := []string{models.InfraAdminRole, models.InfraViewRole, models.InfraConnectorRole}
-	db, err := RequireInfraRole(c, roles...)
+	db, err := RequireInfraRole(c, models.PermissionGrantsRead)
 	if err == nil {
 		selectors = append(selectors, data.ByOptionalSubject(subject))
 		return data.ListGrants(db, selectors...)
@@ -55,6 +59,28 @@ func ListGrants(c *gin.Context, subject uid.PolymorphicID, resource string, priv
 	return nil, err
 }
 
+// ListEffectiveGrants lists the grants to the user, and to the groups the user
+// is a member of.
+func ListEffectiveGrants(c *gin.Context, userID uid.ID) ([]models.Grant, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole, models.InfraConnectorRole}
+	db, err := hasAuthorization(c, userID, isIdentitySelf, models.PermissionGrantsRead)
+	if err != nil {
+		return nil, HandleAuthErr(err, "grants", "list", roles...)
+	}
+
+	identity, err := data.GetIdentity(db, data.ByID(userID))
+	if err != nil {
+		return nil, err
+	}
+
+	subjects, err := identitySubjects(db, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.ListGrants(db, data.BySubjects(subjects), data.ByNotExpired())
+}
+
 func userInGroup(db *gorm.DB, authnUserID uid.ID, groupID uid.ID) bool {
 	groups, err := data.ListGroups(db, data.ByGroupMember(authnUserID), data.ByID(groupID))
 	if err != nil {
@@ -69,12 +95,62 @@ func userInGroup(db *gorm.DB, authnUserID uid.ID, groupID uid.ID) bool {
 	return false
 }
 
+// ListGrantsForResource lists the grants for the resource, and for any resource
+// within it. Grants with wildcards are included when they apply to the resource
+// or to any resource within it.
+func ListGrantsForResource(c *gin.Context, resource string) ([]models.Grant, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole, models.InfraConnectorRole}
+	db, err := RequireInfraRole(c, models.PermissionGrantsRead)
+	if err != nil {
+		return nil, HandleAuthErr(err, "grants", "list", roles...)
+	}
+
+	grants, err := data.ListGrants(db, data.ByResourcePrefix(resource), data.ByNotExpired())
+	if err != nil {
+		return nil, err
+	}
+
+	patterns, err := data.ListGrants(db, data.ByResourcePattern(), data.ByNotExpired())
+	if err != nil {
+		return nil, err
+	}
+
+	listed := make(map[uid.ID]bool, len(grants))
+	for _, grant := range grants {
+		listed[grant.ID] = true
+	}
+
+	for _, grant := range patterns {
+		if !listed[grant.ID] && api.ResourceOverlaps(grant.Resource, resource) {
+			grants = append(grants, grant)
+		}
+	}
+
+	sort.Slice(grants, func(i, j int) bool {
+		return grants[i].ID < grants[j].ID
+	})
+
+	return grants, nil
+}
+
 func CreateGrant(c *gin.Context, grant *models.Grant) error {
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	db, err := RequireInfraRole(c, models.PermissionGrantsWrite)
 	if err != nil {
 		return HandleAuthErr(err, "grant", "create", models.InfraAdminRole)
 	}
 
+	if grant.Resource == ResourceInfraAPI {
+		// prevent granting more access to the infra API than the creator has
+		permissions, err := privilegePermissions(db, grant.Privilege)
+		if err != nil {
+			return err
+		}
+
+		if err := requirePermissions(c, permissions); err != nil {
+			return HandleAuthErr(err, "grant", "create", models.InfraAdminRole)
+		}
+	}
+
 	creator := AuthenticatedIdentity(c)
 
 	grant.CreatedBy = creator.ID
@@ -83,7 +159,7 @@ func CreateGrant(c *gin.Context, grant *models.Grant) error {
 }
 
 func DeleteGrant(c *gin.Context, id uid.ID) error {
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	db, err := RequireInfraRole(c, models.PermissionGrantsWrite)
 	if err != nil {
 		return HandleAuthErr(err, "grant", "delete", models.InfraAdminRole)
 	}
@@ -2,9 +2,11 @@ package access
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/uid"
@@ -21,7 +23,7 @@ func isUserInGroup(c *gin.Context, requestedResourceID uid.ID) (bool, error) {
 	return false, nil
 }
 
-func ListGroups(c *gin.Context, name string, userID uid.ID, pg models.Pagination) ([]models.Group, error) {
+func ListGroups(c *gin.Context, name string, userID uid.ID, pg *models.Pagination) ([]models.Group, error) {
 	var selectors = []data.SelectorFunc{data.ByPagination(pg)}
 	if name != "" {
 		selectors = append(selectors, data.ByName(name))
@@ -31,7 +33,7 @@ func ListGroups(c *gin.Context, name string, userID uid.ID, pg models.Pagination
 	}
 
 	roles := []string{models.InfraAdminRole, models.InfraViewRole, models.InfraConnectorRole}
-	db, err := RequireInfraRole(c, roles...)
+	db, err := RequireInfraRole(c, models.PermissionGroupsRead)
 	if err == nil {
 		return data.ListGroups(db, selectors...)
 	}
@@ -53,7 +55,7 @@ func ListGroups(c *gin.Context, name string, userID uid.ID, pg models.Pagination
 }
 
 func CreateGroup(c *gin.Context, group *models.Group) error {
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	db, err := RequireInfraRole(c, models.PermissionGroupsWrite)
 	if err != nil {
 		return HandleAuthErr(err, "group", "create", models.InfraAdminRole)
 	}
@@ -63,7 +65,7 @@ func CreateGroup(c *gin.Context, group *models.Group) error {
 
 func GetGroup(c *gin.Context, id uid.ID) (*models.Group, error) {
 	roles := []string{models.InfraAdminRole, models.InfraViewRole, models.InfraConnectorRole}
-	db, err := hasAuthorization(c, id, isUserInGroup, roles...)
+	db, err := hasAuthorization(c, id, isUserInGroup, models.PermissionGroupsRead)
 	if err != nil {
 		return nil, HandleAuthErr(err, "group", "get", roles...)
 	}
@@ -72,7 +74,7 @@ func GetGroup(c *gin.Context, id uid.ID) (*models.Group, error) {
 }
 
 func DeleteGroup(c *gin.Context, id uid.ID) error {
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	db, err := RequireInfraRole(c, models.PermissionGroupsWrite)
 	if err != nil {
 		return HandleAuthErr(err, "group", "delete", models.InfraAdminRole)
 	}
@@ -83,3 +85,50 @@ func DeleteGroup(c *gin.Context, id uid.ID) error {
 
 	return data.DeleteGroups(db, selectors...)
 }
+
+func AddUsersToGroup(c *gin.Context, groupID uid.ID, userIDs []uid.ID) error {
+	db, err := RequireInfraRole(c, models.PermissionGroupsWrite)
+	if err != nil {
+		return HandleAuthErr(err, "group", "update", models.InfraAdminRole)
+	}
+
+	if _, err := data.GetGroup(db, data.ByID(groupID)); err != nil {
+		return err
+	}
+
+	if err := requireGroupPermissions(c, groupID); err != nil {
+		return HandleAuthErr(err, "group", "update", models.InfraAdminRole)
+	}
+
+	users, err := data.ListIdentities(db, data.ByIDs(userIDs))
+	if err != nil {
+		return err
+	}
+
+	if len(users) != len(uniqueIDs(userIDs)) {
+		return fmt.Errorf("%w: one or more users do not exist", internal.ErrBadRequest)
+	}
+
+	return data.AddUsersToGroup(db, groupID, userIDs)
+}
+
+func RemoveUsersFromGroup(c *gin.Context, groupID uid.ID, userIDs []uid.ID) error {
+	db, err := RequireInfraRole(c, models.PermissionGroupsWrite)
+	if err != nil {
+		return HandleAuthErr(err, "group", "update", models.InfraAdminRole)
+	}
+
+	if _, err := data.GetGroup(db, data.ByID(groupID)); err != nil {
+		return err
+	}
+
+	return data.RemoveUsersFromGroup(db, groupID, userIDs)
+}
+
+func uniqueIDs(ids []uid.ID) map[uid.ID]struct{} {
+	unique := make(map[uid.ID]struct{}, len(ids))
+	for _, id := range ids {
+		unique[id] = struct{}{}
+	}
+	return unique
+}
@@ -3,8 +3,10 @@ package access
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 
 	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/logging"
@@ -35,7 +37,7 @@ func AuthenticatedIdentity(c *gin.Context) *models.Identity {
 
 func GetIdentity(c *gin.Context, id uid.ID) (*models.Identity, error) {
 	roles := []string{models.InfraAdminRole, models.InfraViewRole, models.InfraConnectorRole}
-	db, err := hasAuthorization(c, id, isIdentitySelf, roles...)
+	db, err := hasAuthorization(c, id, isIdentitySelf, models.PermissionUsersRead)
 	if err != nil {
 		return nil, HandleAuthErr(err, "user", "get", roles...)
 	}
@@ -44,7 +46,7 @@ func GetIdentity(c *gin.Context, id uid.ID) (*models.Identity, error) {
 }
 
 func CreateIdentity(c *gin.Context, identity *models.Identity) error {
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	db, err := RequireInfraRole(c, models.PermissionUsersWrite)
 	if err != nil {
 		return HandleAuthErr(err, "user", "create", models.InfraAdminRole)
 	}
@@ -56,6 +58,19 @@ func InfraConnectorIdentity(c *gin.Context) *models.Identity {
 	return data.InfraConnectorIdentity(getDB(c))
 }
 
+// UnlockIdentity clears the failed password logins of the identity, so that it
+// can login again after being locked out.
+func UnlockIdentity(c *gin.Context, identity *models.Identity) error {
+	db, err := RequireInfraRole(c, models.PermissionUsersWrite)
+	if err != nil {
+		return HandleAuthErr(err, "user", "unlock", models.InfraAdminRole)
+	}
+
+	identity.FailedLoginAttempts = 0
+	identity.LockedUntil = time.Time{}
+	return data.SaveIdentity(db, identity)
+}
+
 // TODO (https://github.com/infrahq/infra/issues/2318) remove provider user, not user.
 func DeleteIdentity(c *gin.Context, id uid.ID) error {
 	self, err := isIdentitySelf(c, id)
@@ -71,39 +86,45 @@ func DeleteIdentity(c *gin.Context, id uid.ID) error {
 		return fmt.Errorf("%w: the connector user can not be deleted", internal.ErrBadRequest)
 	}
 
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	db, err := RequireInfraRole(c, models.PermissionUsersWrite)
 	if err != nil {
 		return HandleAuthErr(err, "user", "delete", models.InfraAdminRole)
 	}
 
-	if err := data.DeleteAccessKeys(db, data.ByIssuedFor(id)); err != nil {
-		return fmt.Errorf("delete identity access keys: %w", err)
-	}
+	// the sessions and access keys of the identity are revoked in the same
+	// transaction that deletes it, so that none remain if the delete fails
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := data.DeleteAccessKeys(tx, data.ByIssuedFor(id)); err != nil {
+			return fmt.Errorf("delete identity access keys: %w", err)
+		}
 
-	// if an identity does not have credentials in the Infra provider this won't be found, but we can proceed
-	credential, err := data.GetCredential(db, data.ByIdentityID(id))
-	if err != nil && !errors.Is(err, internal.ErrNotFound) {
-		return fmt.Errorf("get delete identity creds: %w", err)
-	}
+		// if an identity does not have credentials in the Infra provider this won't be found, but we can proceed
+		credential, err := data.GetCredential(tx, data.ByIdentityID(id))
+		if err != nil && !errors.Is(err, internal.ErrNotFound) {
+			return fmt.Errorf("get delete identity creds: %w", err)
+		}
+
+		if credential != nil {
+			err := data.DeleteCredential(tx, credential.ID)
+			if err != nil {
+				return fmt.Errorf("delete identity creds: %w", err)
+			}
+		}
 
-	if credential != nil {
-		err := data.DeleteCredential(db, credential.ID)
+		err = data.DeleteGrants(tx, data.BySubject(uid.NewIdentityPolymorphicID(id)))
 		if err != nil {
 			return fmt.Errorf("delete identity creds: %w", err)
 		}
-	}
 
-	err = data.DeleteGrants(db, data.BySubject(uid.NewIdentityPolymorphicID(id)))
-	if err != nil {
-		return fmt.Errorf("delete identity creds: %w", err)
-	}
-
-	return data.DeleteIdentity(db, id)
+		return data.DeleteIdentity(tx, id)
+	})
 }
 
-func ListIdentities(c *gin.Context, name string, groupID uid.ID, ids []uid.ID, pg models.Pagination) ([]models.Identity, error) {
+// ListIdentities lists users, and service accounts when showServiceAccounts is
+// true.
+func ListIdentities(c *gin.Context, name string, groupID uid.ID, ids []uid.ID, showServiceAccounts bool, pg *models.Pagination) ([]models.Identity, error) {
 	roles := []string{models.InfraAdminRole, models.InfraViewRole, models.InfraConnectorRole}
-	db, err := RequireInfraRole(c, roles...)
+	db, err := RequireInfraRole(c, models.PermissionUsersRead)
 	if err != nil {
 		return nil, HandleAuthErr(err, "users", "list", roles...)
 	}
@@ -115,6 +136,10 @@ func ListIdentities(c *gin.Context, name string, groupID uid.ID, ids []uid.ID, p
 		data.ByPagination(pg),
 	}
 
+	if !showServiceAccounts {
+		selectors = append(selectors, data.ByIdentityKind(models.UserKind))
+	}
+
 	return data.ListIdentities(db.Preload("Providers"), selectors...)
 }
 
@@ -128,7 +153,10 @@ func GetContextProviderIdentity(c *gin.Context) (*models.Provider, string, error
 	// does not need authorization check, this action is limited to the calling user
 	db := getDB(c)
 
-	accessKey := currentAccessKey(c)
+	accessKey := AuthenticatedAccessKey(c)
+	if accessKey == nil {
+		return nil, "", errors.New("user does not have session with an identity provider")
+	}
 
 	providerUser, err := data.GetProviderUser(db, accessKey.ProviderID, identity.ID)
 	if err != nil {
@@ -156,7 +184,10 @@ func UpdateIdentityInfoFromProvider(c *gin.Context, oidc providers.OIDC) error {
 	// does not need authorization check, this action is limited to the calling user
 	db := getDB(c)
 
-	accessKey := currentAccessKey(c)
+	accessKey := AuthenticatedAccessKey(c)
+	if accessKey == nil {
+		return errors.New("user does not have session with an identity provider")
+	}
 
 	provider, err := data.GetProvider(db, data.ByID(accessKey.ProviderID))
 	if err != nil {
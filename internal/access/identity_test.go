@@ -29,7 +29,7 @@ func TestListIdentities(t *testing.T) {
 	assert.NilError(t, err)
 
 	// test fetch all identities
-	ids, err := ListIdentities(c, "", 0, nil, models.Pagination{})
+	ids, err := ListIdentities(c, "", 0, nil, false, nil)
 	assert.NilError(t, err)
 
 	assert.Equal(t, len(ids), 4) // the two identities created, the admin one used to call these access functions, and the internal connector identity
@@ -41,6 +41,18 @@ func TestListIdentities(t *testing.T) {
 	assert.Equal(t, returnedNames["admin@example.com"], true)
 	assert.Equal(t, returnedNames["active-list-hide-id"], true)
 	assert.Equal(t, returnedNames["unlinked-list-hide-id"], true)
+
+	serviceAccount := &models.Identity{Name: "deploy-bot", Kind: models.ServiceAccountKind}
+	err = data.CreateIdentity(db, serviceAccount)
+	assert.NilError(t, err)
+
+	ids, err = ListIdentities(c, "", 0, nil, false, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(ids), 4) // service accounts are not listed with users
+
+	ids, err = ListIdentities(c, "", 0, nil, true, nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(ids), 5)
 }
 
 func TestDeleteIdentityCleansUpResources(t *testing.T) {
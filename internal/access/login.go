@@ -1,6 +1,9 @@
 package access
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -12,7 +15,7 @@ import (
 // Login uses a login method to authenticate a user
 func Login(c *gin.Context, loginMethod authn.LoginMethod, keyExpiresAt time.Time, keyExtension time.Duration) (*models.AccessKey, string, bool, error) {
 	db := getDB(c)
-	key, bearer, err := authn.Login(c.Request.Context(), db, loginMethod, keyExpiresAt, keyExtension)
+	key, bearer, err := authn.Login(c.Request.Context(), db, loginMethod, keyExpiresAt, keyExtension, sessionClientName(c.Request))
 	if err != nil {
 		return nil, "", false, err
 	}
@@ -27,3 +30,38 @@ func Login(c *gin.Context, loginMethod authn.LoginMethod, keyExpiresAt time.Time
 
 	return key, bearer, requiresUpdate, nil
 }
+
+const maxClientNameLength = 255
+
+// sessionClientName returns a name for the client that made the login request,
+// from its User-Agent and Infra-Version headers. Infra clients send a
+// User-Agent like "Infra/0.13.4 (cli 0.13.4; linux/amd64)", which is shown as
+// "cli 0.13.4 (linux/amd64)". Other User-Agents, such as those of browsers, are
+// used as they are.
+func sessionClientName(req *http.Request) string {
+	userAgent := strings.TrimSpace(req.UserAgent())
+
+	if strings.HasPrefix(userAgent, "Infra/") {
+		start, end := strings.Index(userAgent, "("), strings.LastIndex(userAgent, ")")
+		if start >= 0 && end > start {
+			client, platform, _ := strings.Cut(userAgent[start+1:end], ";")
+			if platform = strings.TrimSpace(platform); platform != "" {
+				return fmt.Sprintf("%s (%s)", strings.TrimSpace(client), platform)
+			}
+			return strings.TrimSpace(client)
+		}
+	}
+
+	if userAgent == "" {
+		if version := req.Header.Get("Infra-Version"); version != "" {
+			return "Infra API " + version
+		}
+		return ""
+	}
+
+	if len(userAgent) > maxClientNameLength {
+		userAgent = userAgent[:maxClientNameLength]
+	}
+
+	return userAgent
+}
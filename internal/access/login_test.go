@@ -0,0 +1,60 @@
+package access
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestSessionClientName(t *testing.T) {
+	testCases := []struct {
+		name         string
+		userAgent    string
+		infraVersion string
+		expected     string
+	}{
+		{
+			name:      "infra client",
+			userAgent: "Infra/0.13.0 (cli 0.13.4; darwin/arm64)",
+			expected:  "cli 0.13.4 (darwin/arm64)",
+		},
+		{
+			name:      "infra client without platform",
+			userAgent: "Infra/0.13.0 (connector 0.13.4)",
+			expected:  "connector 0.13.4",
+		},
+		{
+			name:      "browser",
+			userAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)",
+			expected:  "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)",
+		},
+		{
+			name:         "api version only",
+			infraVersion: "0.13.0",
+			expected:     "Infra API 0.13.0",
+		},
+		{
+			name:     "unknown client",
+			expected: "",
+		},
+		{
+			name:      "long user agent",
+			userAgent: strings.Repeat("a", 300),
+			expected:  strings.Repeat("a", maxClientNameLength),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/login", nil)
+			req.Header.Set("User-Agent", tc.userAgent)
+			if tc.infraVersion != "" {
+				req.Header.Set("Infra-Version", tc.infraVersion)
+			}
+
+			assert.Equal(t, sessionClientName(req), tc.expected)
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package access
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/authn"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// selfMFACredential returns the credential of the user, who must be the
+// authenticated identity.
+func selfMFACredential(c *gin.Context, userID uid.ID) (*models.Credential, error) {
+	isSelf, err := isIdentitySelf(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isSelf {
+		return nil, fmt.Errorf("%w: users can only enable multi-factor authentication for themselves", internal.ErrBadRequest)
+	}
+
+	if err := requireScopeForSelf(c, models.ScopeMFAEnrollment); err != nil {
+		return nil, HandleAuthErr(err, "user", "update", models.ScopeMFAEnrollment)
+	}
+
+	return data.GetCredential(getDB(c), data.ByIdentityID(userID))
+}
+
+// EnrollMFA generates a new TOTP secret for the user. The secret is not
+// required at login until the user confirms it with ConfirmMFA.
+func EnrollMFA(c *gin.Context, userID uid.ID) (string, error) {
+	credential, err := selfMFACredential(c, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if credential.TOTPConfirmed {
+		return "", fmt.Errorf("%w: multi-factor authentication is already enabled", internal.ErrBadRequest)
+	}
+
+	secret, err := authn.GenerateTOTPSecret()
+	if err != nil {
+		return "", err
+	}
+
+	credential.TOTPSecret = models.EncryptedAtRest(secret)
+	credential.TOTPLastStep = 0
+
+	if err := data.SaveCredential(getDB(c), credential); err != nil {
+		return "", fmt.Errorf("save totp secret: %w", err)
+	}
+
+	return secret, nil
+}
+
+// ConfirmMFA enables multi-factor authentication for the user when code is a
+// valid code of the secret from EnrollMFA. It returns the recovery codes of the
+// user.
+func ConfirmMFA(c *gin.Context, userID uid.ID, code string) ([]string, error) {
+	credential, err := selfMFACredential(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if credential.TOTPSecret == "" {
+		return nil, fmt.Errorf("%w: multi-factor authentication has not been enrolled", internal.ErrBadRequest)
+	}
+
+	if credential.TOTPConfirmed {
+		return nil, fmt.Errorf("%w: multi-factor authentication is already enabled", internal.ErrBadRequest)
+	}
+
+	step, ok := authn.ValidateTOTP(string(credential.TOTPSecret), code, time.Now(), credential.TOTPLastStep)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid code", internal.ErrBadRequest)
+	}
+
+	codes, hashes, err := authn.GenerateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	credential.TOTPConfirmed = true
+	credential.TOTPLastStep = step
+	credential.RecoveryCodes = hashes
+
+	db := getDB(c)
+	if err := data.SaveCredential(db, credential); err != nil {
+		return nil, fmt.Errorf("save mfa: %w", err)
+	}
+
+	// the access key can be used for everything now that MFA is enabled
+	if err := removeRequestKeyScope(c, db, models.ScopeMFAEnrollment); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// DisableMFA removes the TOTP secret and recovery codes of the user, so that
+// password logins no longer require a second factor.
+func DisableMFA(c *gin.Context, userID uid.ID) error {
+	db, err := hasAuthorization(c, userID, isIdentitySelf, models.PermissionUsersWrite)
+	if err != nil {
+		return HandleAuthErr(err, "user", "update", models.InfraAdminRole)
+	}
+
+	credential, err := data.GetCredential(db, data.ByIdentityID(userID))
+	if err != nil {
+		return err
+	}
+
+	credential.TOTPSecret = ""
+	credential.TOTPConfirmed = false
+	credential.TOTPLastStep = 0
+	credential.RecoveryCodes = models.CommaSeparatedStrings{}
+
+	if err := data.SaveCredential(db, credential); err != nil {
+		return fmt.Errorf("disable mfa: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package access
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// CreatePasswordResetToken creates a token that the user exchanges at login for
+// an access key that can only be used to set a new password. It is used to
+// invite new users.
+func CreatePasswordResetToken(c *gin.Context, identityID uid.ID, ttl time.Duration) (string, error) {
+	db, err := RequireInfraRole(c, models.PermissionUsersWrite)
+	if err != nil {
+		return "", HandleAuthErr(err, "user", "create", models.InfraAdminRole)
+	}
+
+	return data.CreatePasswordResetToken(db, identityID, ttl)
+}
+
+// PasswordResetCooldown is how long after a password reset token is created
+// for a user that another one can be requested.
+const PasswordResetCooldown = time.Minute
+
+// RequestPasswordReset creates a password reset token for the user of the
+// infra provider named name, which replaces any previous tokens of the user.
+// It does not require authorization, the token must only be sent to the user.
+// A new token is not created within PasswordResetCooldown of the previous one.
+func RequestPasswordReset(c *gin.Context, name string, ttl time.Duration) (*models.Identity, string, error) {
+	db := getDB(c)
+
+	identity, err := data.GetIdentity(db, data.ByName(name))
+	if err != nil {
+		return nil, "", fmt.Errorf("get identity: %w", err)
+	}
+
+	// only the users of the infra provider have a password
+	if _, err := data.GetCredential(db, data.ByIdentityID(identity.ID)); err != nil {
+		return nil, "", fmt.Errorf("get credential: %w", err)
+	}
+
+	// limit the emails that can be sent to a user by requesting resets
+	recent, err := data.ListPasswordResetTokens(db, data.ByIdentityID(identity.ID), data.ByOptionalCreatedAfter(time.Now().UTC().Add(-PasswordResetCooldown)))
+	if err != nil {
+		return nil, "", fmt.Errorf("list password reset tokens: %w", err)
+	}
+
+	if len(recent) > 0 {
+		return nil, "", fmt.Errorf("%w: a password reset was requested recently", internal.ErrTooManyRequests)
+	}
+
+	if err := data.DeletePasswordResetTokens(db, identity.ID); err != nil {
+		return nil, "", fmt.Errorf("delete password reset tokens: %w", err)
+	}
+
+	token, err := data.CreatePasswordResetToken(db, identity.ID, ttl)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return identity, token, nil
+}
@@ -12,7 +12,7 @@ import (
 )
 
 func CreateProvider(c *gin.Context, provider *models.Provider) error {
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	db, err := RequireInfraRole(c, models.PermissionProvidersWrite)
 	if err != nil {
 		return HandleAuthErr(err, "provider", "create", models.InfraAdminRole)
 	}
@@ -26,7 +26,7 @@ func GetProvider(c *gin.Context, id uid.ID) (*models.Provider, error) {
 	return data.GetProvider(db, data.ByID(id))
 }
 
-func ListProviders(c *gin.Context, name string, excludeByName []string, pg models.Pagination) ([]models.Provider, error) {
+func ListProviders(c *gin.Context, name string, excludeByName []string, pg *models.Pagination) ([]models.Provider, error) {
 	db := getDB(c)
 
 	selectors := []data.SelectorFunc{
@@ -42,7 +42,7 @@ func ListProviders(c *gin.Context, name string, excludeByName []string, pg model
 }
 
 func SaveProvider(c *gin.Context, provider *models.Provider) error {
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	db, err := RequireInfraRole(c, models.PermissionProvidersWrite)
 	if err != nil {
 		return HandleAuthErr(err, "provider", "update", models.InfraAdminRole)
 	}
@@ -54,7 +54,7 @@ func SaveProvider(c *gin.Context, provider *models.Provider) error {
 }
 
 func DeleteProvider(c *gin.Context, id uid.ID) error {
-	db, err := RequireInfraRole(c, models.InfraAdminRole)
+	db, err := RequireInfraRole(c, models.PermissionProvidersWrite)
 	if err != nil {
 		return HandleAuthErr(err, "provider", "delete", models.InfraAdminRole)
 	}
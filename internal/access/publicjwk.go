@@ -2,6 +2,7 @@ package access
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gopkg.in/square/go-jose.v2"
@@ -9,17 +10,39 @@ import (
 	"github.com/infrahq/infra/internal/server/data"
 )
 
+// GetPublicJWK returns the public keys of all the signing keys, including the
+// keys that are not used yet and the keys that are being retired. The key that
+// is currently used for signing is first, for connectors that only use the
+// first key.
 func GetPublicJWK(c *gin.Context) ([]jose.JSONWebKey, error) {
 	db := getDB(c)
-	settings, err := data.GetSettings(db)
+	keys, err := data.ListSigningKeys(db)
 	if err != nil {
 		return nil, fmt.Errorf("could not get JWKs: %w", err)
 	}
 
-	var pubKey jose.JSONWebKey
-	if err := pubKey.UnmarshalJSON(settings.PublicJWK); err != nil {
+	active, err := data.ActiveSigningKey(keys, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("could not get JWKs: %w", err)
+	}
+
+	pubKeys := make([]jose.JSONWebKey, 1, len(keys))
+	if err := pubKeys[0].UnmarshalJSON(active.PublicJWK); err != nil {
 		return nil, fmt.Errorf("could not get JWKs: %w", err)
 	}
 
-	return []jose.JSONWebKey{pubKey}, nil
+	for _, key := range keys {
+		if key.ID == active.ID {
+			continue
+		}
+
+		var pubKey jose.JSONWebKey
+		if err := pubKey.UnmarshalJSON(key.PublicJWK); err != nil {
+			return nil, fmt.Errorf("could not get JWKs: %w", err)
+		}
+
+		pubKeys = append(pubKeys, pubKey)
+	}
+
+	return pubKeys, nil
 }
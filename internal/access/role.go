@@ -0,0 +1,181 @@
+package access
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func ListRoles(c *gin.Context, name string, pg *models.Pagination) ([]models.Role, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole}
+	db, err := RequireInfraRole(c, models.PermissionRolesRead)
+	if err != nil {
+		return nil, HandleAuthErr(err, "roles", "list", roles...)
+	}
+
+	return data.ListRoles(db, data.ByOptionalName(name), data.ByPagination(pg))
+}
+
+func GetRole(c *gin.Context, id uid.ID) (*models.Role, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole}
+	db, err := RequireInfraRole(c, models.PermissionRolesRead)
+	if err != nil {
+		return nil, HandleAuthErr(err, "role", "get", roles...)
+	}
+
+	return data.GetRole(db, data.ByID(id))
+}
+
+func CreateRole(c *gin.Context, role *models.Role) error {
+	db, err := RequireInfraRole(c, models.PermissionRolesWrite)
+	if err != nil {
+		return HandleAuthErr(err, "role", "create", models.InfraAdminRole)
+	}
+
+	if _, ok := models.InfraRolePermissions[role.Name]; ok {
+		return fmt.Errorf("%w: %q is a built-in role", internal.ErrBadRequest, role.Name)
+	}
+
+	if err := checkRolePermissions(c, role.Permissions); err != nil {
+		return HandleAuthErr(err, "role", "create", models.InfraAdminRole)
+	}
+
+	role.CreatedBy = AuthenticatedIdentity(c).ID
+
+	return data.CreateRole(db, role)
+}
+
+func UpdateRole(c *gin.Context, id uid.ID, permissions []string) (*models.Role, error) {
+	db, err := RequireInfraRole(c, models.PermissionRolesWrite)
+	if err != nil {
+		return nil, HandleAuthErr(err, "role", "update", models.InfraAdminRole)
+	}
+
+	role, err := data.GetRole(db, data.ByID(id))
+	if err != nil {
+		return nil, err
+	}
+
+	// changing the permissions changes the access of everyone granted the
+	// role, so the removed permissions are checked as well
+	changed := append(append([]string{}, permissions...), role.Permissions...)
+	if err := checkRolePermissions(c, changed); err != nil {
+		return nil, HandleAuthErr(err, "role", "update", models.InfraAdminRole)
+	}
+
+	role.Permissions = permissions
+	if err := data.SaveRole(db, role); err != nil {
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// DeleteRole deletes the role, and all grants of the role.
+func DeleteRole(c *gin.Context, id uid.ID) error {
+	db, err := RequireInfraRole(c, models.PermissionRolesWrite)
+	if err != nil {
+		return HandleAuthErr(err, "role", "delete", models.InfraAdminRole)
+	}
+
+	role, err := data.GetRole(db, data.ByID(id))
+	if err != nil {
+		return err
+	}
+
+	if err := checkRolePermissions(c, role.Permissions); err != nil {
+		return HandleAuthErr(err, "role", "delete", models.InfraAdminRole)
+	}
+
+	return data.DeleteRoles(db, data.ByID(id))
+}
+
+// checkRolePermissions checks that every permission of a role is a permission
+// of the infra API, and that the identity in the context has it. An identity
+// can not define a role with more access than it has.
+func checkRolePermissions(c *gin.Context, permissions []string) error {
+	for _, permission := range permissions {
+		if !models.IsPermission(permission) {
+			return fmt.Errorf("%w: unknown permission %q", internal.ErrBadRequest, permission)
+		}
+	}
+
+	return requirePermissions(c, permissions)
+}
+
+// requirePermissions checks that the identity in the context has all the
+// permissions. When the access key of the request has permission scopes, only
+// those permissions are used.
+func requirePermissions(c *gin.Context, permissions []string) error {
+	identity := AuthenticatedIdentity(c)
+	if identity == nil {
+		return fmt.Errorf("no active identity")
+	}
+
+	granted, err := infraPermissions(getDB(c), identity)
+	if err != nil {
+		return err
+	}
+
+	if key := AuthenticatedAccessKey(c); key != nil {
+		granted = limitToPermissionScopes(granted, key.PermissionScopes())
+	}
+
+	for _, permission := range permissions {
+		if len(granted[permission]) == 0 {
+			return ErrNotAuthorized
+		}
+	}
+
+	return nil
+}
+
+// requireIdentityPermissions checks that the identity in the context has all
+// the permissions on the infra API of the target identity, including the ones
+// granted to its groups. Acting as another identity, by setting its password or
+// creating an access key for it, must not give more access than the caller has.
+// When scopes are set, only those permissions of the target are checked.
+func requireIdentityPermissions(c *gin.Context, target *models.Identity, scopes []string) error {
+	granted, err := infraPermissions(getDB(c), target)
+	if err != nil {
+		return err
+	}
+
+	granted = limitToPermissionScopes(granted, scopes)
+
+	permissions := make([]string, 0, len(granted))
+	for permission := range granted {
+		permissions = append(permissions, permission)
+	}
+
+	return requirePermissions(c, permissions)
+}
+
+// requireGroupPermissions checks that the identity in the context has all the
+// permissions on the infra API that are granted to the group, so that adding a
+// member to the group does not give more access than the caller has.
+func requireGroupPermissions(c *gin.Context, groupID uid.ID) error {
+	db := getDB(c)
+
+	grants, err := data.ListGrants(db, data.BySubject(uid.NewGroupPolymorphicID(groupID)), data.ByResource(ResourceInfraAPI), data.ByNotExpired())
+	if err != nil {
+		return fmt.Errorf("group grants: %w", err)
+	}
+
+	var permissions []string
+	for _, grant := range grants {
+		granted, err := privilegePermissions(db, grant.Privilege)
+		if err != nil {
+			return err
+		}
+
+		permissions = append(permissions, granted...)
+	}
+
+	return requirePermissions(c, permissions)
+}
@@ -0,0 +1,314 @@
+package access
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// CreateSCIMAccessKey creates an access key that an identity provider uses to
+// provision users and groups with SCIM. The key can not be used for any other
+// API.
+func CreateSCIMAccessKey(c *gin.Context, accessKey *models.AccessKey) (string, error) {
+	db, err := RequireInfraRole(c, models.PermissionProvidersWrite)
+	if err != nil {
+		return "", HandleAuthErr(err, "access key", "create", models.InfraAdminRole)
+	}
+
+	provider, err := data.GetProvider(db, data.ByID(accessKey.ProviderID))
+	if err != nil {
+		return "", err
+	}
+
+	if provider.Kind == models.InfraKind {
+		return "", fmt.Errorf("%w: users of the infra provider can not be provisioned with SCIM", internal.ErrBadRequest)
+	}
+
+	accessKey.IssuedFor = AuthenticatedIdentity(c).ID
+	accessKey.Scopes = models.CommaSeparatedStrings{models.ScopeSCIM}
+
+	body, err := data.CreateAccessKey(db, accessKey)
+	if err != nil {
+		return "", fmt.Errorf("create token: %w", err)
+	}
+
+	return body, nil
+}
+
+// requireSCIMProvider checks that the request was authenticated with a SCIM
+// access key, and returns the provider that the key provisions.
+func requireSCIMProvider(c *gin.Context) (*gorm.DB, *models.Provider, error) {
+	key := AuthenticatedAccessKey(c)
+	if key == nil || !key.Scopes.Includes(models.ScopeSCIM) {
+		return nil, nil, fmt.Errorf("%w: a SCIM access key is required", internal.ErrUnauthorized)
+	}
+
+	db := getDB(c)
+
+	provider, err := data.GetProvider(db, data.ByID(key.ProviderID))
+	if err != nil {
+		return nil, nil, fmt.Errorf("scim provider: %w", err)
+	}
+
+	return db, provider, nil
+}
+
+// ListSCIMUsers lists the users of the provider. When name is set only the user
+// with that name is listed.
+func ListSCIMUsers(c *gin.Context, name string, pg *models.Pagination) ([]models.Identity, error) {
+	db, provider, err := requireSCIMProvider(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.ListIdentities(db.Preload("Groups"), data.ByIdentityProviderID(provider.ID), data.ByOptionalName(name), data.ByPagination(pg))
+}
+
+func GetSCIMUser(c *gin.Context, id uid.ID) (*models.Identity, error) {
+	db, provider, err := requireSCIMProvider(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.GetIdentity(db.Preload("Groups"), data.ByID(id), data.ByIdentityProviderID(provider.ID))
+}
+
+// CreateSCIMUser makes the identity a user of the provider. The identity is
+// created when there is no identity with the same name. Service accounts can
+// not be users of a provider.
+func CreateSCIMUser(c *gin.Context, identity *models.Identity) error {
+	db, provider, err := requireSCIMProvider(c)
+	if err != nil {
+		return err
+	}
+
+	existing, err := data.GetIdentity(db, data.ByName(identity.Name))
+	switch {
+	case errors.Is(err, internal.ErrNotFound):
+		identity.CreatedBy = AuthenticatedIdentity(c).ID
+		if err := data.CreateIdentity(db, identity); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		if existing.IsServiceAccount() {
+			return data.UniqueConstraintError{Table: "user", Column: "name"}
+		}
+		if _, err := data.GetProviderUser(db, provider.ID, existing.ID); err == nil {
+			return data.UniqueConstraintError{Table: "user", Column: "name"}
+		}
+		*identity = *existing
+	}
+
+	_, err = data.CreateProviderUser(db, provider, identity)
+	return err
+}
+
+// UpdateSCIMUser saves the name of the identity. Only the identities that are
+// not users of any other provider can be renamed.
+func UpdateSCIMUser(c *gin.Context, identity *models.Identity) error {
+	db, provider, err := requireSCIMProvider(c)
+	if err != nil {
+		return err
+	}
+
+	providerUser, err := data.GetProviderUser(db, provider.ID, identity.ID)
+	if err != nil {
+		return err
+	}
+
+	owned, err := isOnlyProviderUser(db, provider, identity.ID)
+	if err != nil {
+		return err
+	}
+
+	if !owned {
+		return fmt.Errorf("%w: the user is also a user of another provider, and can not be renamed", internal.ErrBadRequest)
+	}
+
+	// the groups loaded with the identity are not saved, memberships are
+	// managed by the groups
+	groups := identity.Groups
+	identity.Groups = nil
+	err = data.SaveIdentity(db, identity)
+	identity.Groups = groups
+	if err != nil {
+		return err
+	}
+
+	providerUser.Email = identity.Name
+	providerUser.LastUpdate = time.Now().UTC()
+	return data.UpdateProviderUser(db, providerUser)
+}
+
+// DeleteSCIMUser removes the identity from the provider, and revokes the access
+// keys it was issued by the provider. The identity is deleted when it is not a
+// user of any other provider.
+func DeleteSCIMUser(c *gin.Context, id uid.ID) error {
+	db, provider, err := requireSCIMProvider(c)
+	if err != nil {
+		return err
+	}
+
+	if _, err := data.GetProviderUser(db, provider.ID, id); err != nil {
+		return err
+	}
+
+	owned, err := isOnlyProviderUser(db, provider, id)
+	if err != nil {
+		return err
+	}
+
+	if err := data.DeleteProviderUsers(db, data.ByIdentityID(id), data.ByProviderID(provider.ID)); err != nil {
+		return err
+	}
+
+	if err := data.DeleteAccessKeys(db, data.ByIssuedFor(id), data.ByProviderID(provider.ID)); err != nil {
+		return err
+	}
+
+	if !owned {
+		return nil
+	}
+
+	return data.DeleteIdentities(db, data.ByID(id))
+}
+
+// isOnlyProviderUser returns true when the identity is a user of the provider,
+// and of no other provider, so that the provider can rename or delete it.
+// Service accounts are never owned by a provider.
+func isOnlyProviderUser(db *gorm.DB, provider *models.Provider, identityID uid.ID) (bool, error) {
+	identity, err := data.GetIdentity(db, data.ByID(identityID))
+	if err != nil {
+		return false, err
+	}
+
+	if identity.IsServiceAccount() {
+		return false, nil
+	}
+
+	users, err := data.ListProviderUsers(db, data.ByIdentityID(identityID))
+	if err != nil {
+		return false, err
+	}
+
+	for _, user := range users {
+		if user.ProviderID != provider.ID {
+			return false, nil
+		}
+	}
+
+	return len(users) > 0, nil
+}
+
+// ListSCIMGroups lists the groups created by the provider. When name is set
+// only the group with that name is listed.
+func ListSCIMGroups(c *gin.Context, name string, pg *models.Pagination) ([]models.Group, error) {
+	db, provider, err := requireSCIMProvider(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.ListGroups(db.Preload("Identities"), data.ByCreatedByProvider(provider.ID), data.ByOptionalName(name), data.ByPagination(pg))
+}
+
+func GetSCIMGroup(c *gin.Context, id uid.ID) (*models.Group, error) {
+	db, provider, err := requireSCIMProvider(c)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.GetGroup(db.Preload("Identities"), data.ByID(id), data.ByCreatedByProvider(provider.ID))
+}
+
+// CreateSCIMGroup creates a group for the provider, with members.
+func CreateSCIMGroup(c *gin.Context, group *models.Group, members []uid.ID) error {
+	db, provider, err := requireSCIMProvider(c)
+	if err != nil {
+		return err
+	}
+
+	if err := checkSCIMMembers(db, provider, members); err != nil {
+		return err
+	}
+
+	group.CreatedBy = AuthenticatedIdentity(c).ID
+	group.CreatedByProvider = provider.ID
+	if err := data.CreateGroup(db, group); err != nil {
+		return err
+	}
+
+	return data.AddUsersToGroup(db, group.ID, members)
+}
+
+// UpdateSCIMGroup saves the name of the group, and adds and removes members.
+func UpdateSCIMGroup(c *gin.Context, group *models.Group, add, remove []uid.ID) error {
+	db, provider, err := requireSCIMProvider(c)
+	if err != nil {
+		return err
+	}
+
+	if group.CreatedByProvider != provider.ID {
+		return internal.ErrNotFound
+	}
+
+	if err := checkSCIMMembers(db, provider, add); err != nil {
+		return err
+	}
+
+	// the members loaded with the group are not saved, they are changed by
+	// add and remove
+	members := group.Identities
+	group.Identities = nil
+	err = data.SaveGroup(db, group)
+	group.Identities = members
+	if err != nil {
+		return err
+	}
+
+	if err := data.AddUsersToGroup(db, group.ID, add); err != nil {
+		return err
+	}
+
+	return data.RemoveUsersFromGroup(db, group.ID, remove)
+}
+
+func DeleteSCIMGroup(c *gin.Context, id uid.ID) error {
+	db, provider, err := requireSCIMProvider(c)
+	if err != nil {
+		return err
+	}
+
+	if _, err := data.GetGroup(db, data.ByID(id), data.ByCreatedByProvider(provider.ID)); err != nil {
+		return err
+	}
+
+	return data.DeleteGroups(db, data.ByID(id))
+}
+
+// checkSCIMMembers checks that every member is a user of the provider.
+func checkSCIMMembers(db *gorm.DB, provider *models.Provider, members []uid.ID) error {
+	if len(members) == 0 {
+		return nil
+	}
+
+	users, err := data.ListIdentities(db, data.ByIDs(members), data.ByIdentityProviderID(provider.ID))
+	if err != nil {
+		return err
+	}
+
+	if len(users) != len(uniqueIDs(members)) {
+		return fmt.Errorf("%w: members must be users of the provider", internal.ErrBadRequest)
+	}
+
+	return nil
+}
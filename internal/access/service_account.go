@@ -0,0 +1,62 @@
+package access
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func ListServiceAccounts(c *gin.Context, name string, pg *models.Pagination) ([]models.Identity, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole, models.InfraConnectorRole}
+	db, err := RequireInfraRole(c, models.PermissionUsersRead)
+	if err != nil {
+		return nil, HandleAuthErr(err, "service accounts", "list", roles...)
+	}
+
+	selectors := []data.SelectorFunc{
+		data.ByOptionalName(name),
+		data.ByIdentityKind(models.ServiceAccountKind),
+		data.ByPagination(pg),
+	}
+
+	return data.ListIdentities(db, selectors...)
+}
+
+// CreateServiceAccount creates an identity that can only authenticate with
+// access keys.
+func CreateServiceAccount(c *gin.Context, identity *models.Identity) error {
+	db, err := RequireInfraRole(c, models.PermissionUsersWrite)
+	if err != nil {
+		return HandleAuthErr(err, "service account", "create", models.InfraAdminRole)
+	}
+
+	identity.Kind = models.ServiceAccountKind
+	if creator := AuthenticatedIdentity(c); creator != nil {
+		identity.CreatedBy = creator.ID
+	}
+
+	return data.CreateIdentity(db, identity)
+}
+
+func DeleteServiceAccount(c *gin.Context, id uid.ID) error {
+	db, err := RequireInfraRole(c, models.PermissionUsersWrite)
+	if err != nil {
+		return HandleAuthErr(err, "service account", "delete", models.InfraAdminRole)
+	}
+
+	identity, err := data.GetIdentity(db, data.ByID(id))
+	if err != nil {
+		return err
+	}
+
+	if !identity.IsServiceAccount() {
+		return fmt.Errorf("%w: %s is not a service account", internal.ErrBadRequest, identity.Name)
+	}
+
+	return DeleteIdentity(c, id)
+}
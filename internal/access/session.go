@@ -0,0 +1,64 @@
+package access
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// ListSessions lists the sessions of a user, which are the access keys that
+// were created when the user logged in.
+func ListSessions(c *gin.Context, userID uid.ID, pg *models.Pagination) ([]models.AccessKey, error) {
+	roles := []string{models.InfraAdminRole, models.InfraViewRole}
+	db, err := hasAuthorization(c, userID, isIdentitySelf, models.PermissionAccessKeysRead)
+	if err != nil {
+		return nil, HandleAuthErr(err, "sessions", "list", roles...)
+	}
+
+	selectors := []data.SelectorFunc{
+		data.ByIssuedFor(userID),
+		data.BySession(),
+		data.ByNotExpiredOrExtended(),
+		data.ByPagination(pg),
+	}
+
+	return data.ListAccessKeys(db.Preload("Provider"), selectors...)
+}
+
+// RevokeSession deletes a session of a user, so that it can no longer be used.
+func RevokeSession(c *gin.Context, userID, sessionID uid.ID) error {
+	db, err := hasAuthorization(c, userID, isIdentitySelf, models.PermissionAccessKeysWrite)
+	if err != nil {
+		return HandleAuthErr(err, "session", "revoke", models.InfraAdminRole)
+	}
+
+	session, err := data.GetAccessKey(db, data.ByID(sessionID), data.ByIssuedFor(userID), data.BySession())
+	if err != nil {
+		return err
+	}
+
+	return data.DeleteAccessKey(db, session.ID)
+}
+
+// RevokeSessions deletes all the sessions of a user, and revokes their client
+// certificates. When excludeCurrent is true the session used to make the
+// request is not deleted, client certificates are always revoked.
+func RevokeSessions(c *gin.Context, userID uid.ID, excludeCurrent bool) error {
+	db, err := hasAuthorization(c, userID, isIdentitySelf, models.PermissionAccessKeysWrite)
+	if err != nil {
+		return HandleAuthErr(err, "sessions", "revoke", models.InfraAdminRole)
+	}
+
+	selectors := []data.SelectorFunc{data.ByIssuedFor(userID), data.BySession()}
+	if key := AuthenticatedAccessKey(c); excludeCurrent && key != nil {
+		selectors = append(selectors, data.NotIDs([]uid.ID{key.ID}))
+	}
+
+	if err := data.DeleteAccessKeys(db, selectors...); err != nil {
+		return err
+	}
+
+	return data.RevokeClientCertificates(db, userID)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/infrahq/infra/internal/server/authn"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/uid"
@@ -44,11 +45,16 @@ func SignupEnabled(c *gin.Context) (bool, error) {
 }
 
 // Signup creates a user identity using the supplied name and password and
-// grants the identity "admin" access to Infra.
-func Signup(c *gin.Context, name, password string) (*models.Identity, error) {
+// grants the identity "admin" access to Infra. The password must follow the
+// password policy.
+func Signup(c *gin.Context, name, password string, policy authn.PasswordPolicy) (*models.Identity, error) {
 	// no authorization is setup yet
 	db := getDB(c)
 
+	if err := policy.Check(password); err != nil {
+		return nil, err
+	}
+
 	identity := &models.Identity{Name: name}
 
 	if err := data.CreateIdentity(db, identity); err != nil {
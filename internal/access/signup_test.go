@@ -111,7 +111,7 @@ func TestSignupEnabled(t *testing.T) {
 		err = data.CreateProvider(db, &provider)
 		assert.NilError(t, err)
 
-		identity, err := Signup(c, user, pass)
+		identity, err := Signup(c, user, pass, authn.PasswordPolicy{})
 		assert.NilError(t, err)
 		assert.Equal(t, identity.Name, user)
 
@@ -120,7 +120,7 @@ func TestSignupEnabled(t *testing.T) {
 		assert.Equal(t, enabled, false)
 
 		// check "admin" user can login
-		userPassLogin := authn.NewPasswordCredentialAuthentication(user, pass)
+		userPassLogin := authn.NewPasswordCredentialAuthentication(user, pass, "", authn.PasswordLoginOptions{})
 		key, _, requiresUpdate, err := Login(c, userPassLogin, time.Now().Add(time.Hour), time.Hour)
 		assert.NilError(t, err)
 		assert.Equal(t, identity.ID, key.IssuedFor)
@@ -129,7 +129,7 @@ func TestSignupEnabled(t *testing.T) {
 		c.Set("identity", identity)
 
 		// check "admin" can create token
-		_, err = CreateToken(c)
+		_, err = CreateToken(c, "")
 		assert.NilError(t, err)
 	})
 }
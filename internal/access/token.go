@@ -1,22 +1,45 @@
 package access
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
 )
 
-func CreateToken(c *gin.Context) (token *models.Token, err error) {
+// CreateToken creates a token for the calling identity to present to the
+// destination named destination. An access key limited to destinations can
+// only create tokens for those destinations.
+func CreateToken(c *gin.Context, destination string) (token *models.Token, err error) {
 	identity := AuthenticatedIdentity(c)
 	if identity == nil {
 		return nil, fmt.Errorf("no active identity")
 	}
 
-	// does not need authorization check, limited to calling identity
+	// limited to calling identity, but the access key must allow it
+	scope := models.ScopeTokens
+	if key := AuthenticatedAccessKey(c); key != nil && len(key.DestinationScopes()) > 0 {
+		scope = models.ScopeDestinationPrefix + destination
+	}
+
+	if err := requireScopeForSelf(c, scope); err != nil {
+		return nil, HandleAuthErr(err, "token", "create", scope)
+	}
+
 	db := getDB(c)
 
-	return data.CreateIdentityToken(db, identity.ID)
+	if destination != "" {
+		if _, err := data.GetDestination(db, data.ByName(destination)); err != nil {
+			if errors.Is(err, internal.ErrNotFound) {
+				return nil, fmt.Errorf("%w: unknown destination %q", internal.ErrBadRequest, destination)
+			}
+			return nil, err
+		}
+	}
+
+	return data.CreateIdentityToken(db, identity.ID, destination)
 }
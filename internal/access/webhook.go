@@ -0,0 +1,63 @@
+package access
+
+import (
+	"github.com/gin-gonic/gin"
+
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func ListWebhooks(c *gin.Context, pg *models.Pagination) ([]models.Webhook, error) {
+	db, err := RequireInfraRole(c, models.PermissionWebhooksRead)
+	if err != nil {
+		return nil, HandleAuthErr(err, "webhooks", "list", models.InfraAdminRole)
+	}
+
+	return data.ListWebhooks(db, data.ByPagination(pg))
+}
+
+func GetWebhook(c *gin.Context, id uid.ID) (*models.Webhook, error) {
+	db, err := RequireInfraRole(c, models.PermissionWebhooksRead)
+	if err != nil {
+		return nil, HandleAuthErr(err, "webhook", "get", models.InfraAdminRole)
+	}
+
+	return data.GetWebhook(db, data.ByID(id))
+}
+
+func CreateWebhook(c *gin.Context, webhook *models.Webhook) error {
+	db, err := RequireInfraRole(c, models.PermissionWebhooksWrite)
+	if err != nil {
+		return HandleAuthErr(err, "webhook", "create", models.InfraAdminRole)
+	}
+
+	return data.CreateWebhook(db, webhook)
+}
+
+func DeleteWebhook(c *gin.Context, id uid.ID) error {
+	db, err := RequireInfraRole(c, models.PermissionWebhooksWrite)
+	if err != nil {
+		return HandleAuthErr(err, "webhook", "delete", models.InfraAdminRole)
+	}
+
+	if _, err := data.GetWebhook(db, data.ByID(id)); err != nil {
+		return err
+	}
+
+	return data.DeleteWebhooks(db, data.ByID(id))
+}
+
+func ListWebhookDeliveries(c *gin.Context, webhookID uid.ID, pg *models.Pagination) ([]models.WebhookDelivery, error) {
+	db, err := RequireInfraRole(c, models.PermissionWebhooksRead)
+	if err != nil {
+		return nil, HandleAuthErr(err, "webhook deliveries", "list", models.InfraAdminRole)
+	}
+
+	if _, err := data.GetWebhook(db, data.ByID(webhookID)); err != nil {
+		return nil, err
+	}
+
+	// attempts that are still queued are not listed
+	return data.ListWebhookDeliveries(db, data.ByWebhookID(webhookID), data.ByAttemptedWebhookDelivery(), data.ByPagination(pg))
+}
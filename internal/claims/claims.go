@@ -1,5 +1,8 @@
 package claims
 
+// Issuer is the issuer claim of the tokens created by the server.
+const Issuer = "InfraHQ"
+
 type Custom struct {
 	Name   string   `json:"name" validate:"required"`
 	Groups []string `json:"groups"`
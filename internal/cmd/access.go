@@ -0,0 +1,345 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func newAccessCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "access",
+		Short: "Request, approve, and check access",
+		Group: "Management commands:",
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := rootPreRun(cmd.Flags()); err != nil {
+				return err
+			}
+			return mustBeLoggedIn()
+		},
+	}
+
+	cmd.AddCommand(newAccessRequestCmd(cli))
+	cmd.AddCommand(newAccessListCmd(cli))
+	cmd.AddCommand(newAccessApproveCmd(cli))
+	cmd.AddCommand(newAccessDenyCmd(cli))
+	cmd.AddCommand(newAccessCheckCmd(cli))
+
+	return cmd
+}
+
+type accessRequestOptions struct {
+	Role     string
+	Reason   string
+	Duration time.Duration
+}
+
+func newAccessRequestCmd(cli *CLI) *cobra.Command {
+	var options accessRequestOptions
+
+	cmd := &cobra.Command{
+		Use:   "request DESTINATION",
+		Short: "Request access to a destination",
+		Example: `# Request admin access to a namespace for 4 hours
+$ infra access request production.payments --role cluster-admin --duration 4h --reason "incident 1234"`,
+		Args: ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := defaultAPIClient()
+			if err != nil {
+				return err
+			}
+
+			if options.Duration < 0 {
+				return fmt.Errorf("duration must be positive")
+			}
+
+			req := &api.CreateAccessRequestRequest{
+				Privilege: options.Role,
+				Resource:  args[0],
+				Reason:    options.Reason,
+				Duration:  api.Duration(options.Duration),
+			}
+
+			logging.S.Debugf("call server: create access request %#v", req)
+			request, err := client.CreateAccessRequest(req)
+			if err != nil {
+				return err
+			}
+
+			cli.Output("Requested %q access to %q, the request ID is %s", request.Privilege, request.Resource, request.ID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Role, "role", models.BasePermissionConnect, "Type of access being requested")
+	cmd.Flags().StringVar(&options.Reason, "reason", "", "Why the access is needed")
+	cmd.Flags().DurationVar(&options.Duration, "duration", 0, "How long the access is needed for (ex: 4h)")
+	return cmd
+}
+
+type accessListOptions struct {
+	Status string
+	Format string
+}
+
+func newAccessListCmd(cli *CLI) *cobra.Command {
+	var options accessListOptions
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List access requests",
+		Example: `# List access requests waiting for approval
+$ infra access list --status pending`,
+		Args: NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := defaultAPIClient()
+			if err != nil {
+				return err
+			}
+
+			logging.S.Debug("call server: list access requests")
+			requests, err := client.ListAccessRequests(api.ListAccessRequestsRequest{Status: options.Status})
+			if err != nil {
+				return err
+			}
+
+			if options.Format == "json" {
+				jsonOutput, err := json.Marshal(requests)
+				if err != nil {
+					return err
+				}
+				cli.Output(string(jsonOutput))
+				return nil
+			}
+
+			type row struct {
+				ID       string `header:"ID"`
+				User     string `header:"USER"`
+				Access   string `header:"ACCESS"`
+				Resource string `header:"DESTINATION"`
+				Duration string `header:"DURATION"`
+				Status   string `header:"STATUS"`
+				Reason   string `header:"REASON"`
+			}
+
+			userNames := make(map[uid.ID]string)
+
+			var rows []row
+			for _, request := range requests.Items {
+				name, ok := userNames[request.RequestedBy]
+				if !ok {
+					name = request.RequestedBy.String()
+					if user, err := client.GetUser(request.RequestedBy); err == nil {
+						name = user.Name
+					}
+					userNames[request.RequestedBy] = name
+				}
+
+				duration := "-"
+				if request.Duration > 0 {
+					duration = request.Duration.String()
+				}
+
+				rows = append(rows, row{
+					ID:       request.ID.String(),
+					User:     name,
+					Access:   request.Privilege,
+					Resource: request.Resource,
+					Duration: duration,
+					Status:   request.Status,
+					Reason:   request.Reason,
+				})
+			}
+
+			if len(rows) > 0 {
+				printTable(rows, cli.Stdout)
+			} else {
+				cli.Output("No access requests found")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Status, "status", "", "Only show requests with this status (pending, approved, or denied)")
+	addFormatFlag(cmd.Flags(), &options.Format)
+	return cmd
+}
+
+func newAccessApproveCmd(cli *CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:   "approve ID",
+		Short: "Approve an access request",
+		Example: `# Approve an access request
+$ infra access approve 4yJ3n3D8E2`,
+		Args: ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reviewAccessRequest(cli, args[0], true)
+		},
+	}
+}
+
+func newAccessDenyCmd(cli *CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:   "deny ID",
+		Short: "Deny an access request",
+		Example: `# Deny an access request
+$ infra access deny 4yJ3n3D8E2`,
+		Args: ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return reviewAccessRequest(cli, args[0], false)
+		},
+	}
+}
+
+func reviewAccessRequest(cli *CLI, rawID string, approve bool) error {
+	id, err := uid.Parse([]byte(rawID))
+	if err != nil {
+		return fmt.Errorf("invalid access request ID %q", rawID)
+	}
+
+	client, err := defaultAPIClient()
+	if err != nil {
+		return err
+	}
+
+	review, operation := client.DenyAccessRequest, "deny"
+	if approve {
+		review, operation = client.ApproveAccessRequest, "approve"
+	}
+
+	logging.S.Debugf("call server: %s access request %s", operation, id)
+	request, err := review(id)
+	if err != nil {
+		if api.ErrorStatusCode(err) == 403 {
+			logging.S.Debug(err)
+			return Error{
+				Message: fmt.Sprintf("Cannot %s access request: you are not an approver for this resource", operation),
+			}
+		}
+		return err
+	}
+
+	cli.Output("Access request %s for %q access to %q was %s", request.ID, request.Privilege, request.Resource, request.Status)
+	return nil
+}
+
+type accessCheckOptions struct {
+	Role   string
+	Format string
+}
+
+func newAccessCheckCmd(cli *CLI) *cobra.Command {
+	var options accessCheckOptions
+
+	cmd := &cobra.Command{
+		Use:   "check USER DESTINATION",
+		Short: "Check if a user has access to a destination",
+		Long: `Check if a user has access to a destination, and show the grants that allow it.
+The command exits with an error if the access is not allowed.`,
+		Example: `# Check if a user can view a namespace
+$ infra access check janedoe@example.com production.payments --role view
+
+# Check if a user has an infra role
+$ infra access check janedoe@example.com infra --role admin`,
+		Args: ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := defaultAPIClient()
+			if err != nil {
+				return err
+			}
+
+			user, err := getUserByName(client, args[0])
+			if err != nil {
+				if errors.Is(err, ErrUserNotFound) {
+					return Error{Message: fmt.Sprintf("Cannot check access: %s", err)}
+				}
+				return err
+			}
+
+			req := api.CheckAccessRequest{
+				User:      user.ID,
+				Privilege: options.Role,
+				Resource:  args[1],
+			}
+
+			logging.S.Debugf("call server: check access %#v", req)
+			check, err := client.CheckAccess(req)
+			if err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.S.Debug(err)
+					return Error{
+						Message: "Cannot check access: missing privileges to view grants of other users",
+					}
+				}
+				return err
+			}
+
+			if options.Format == "json" {
+				jsonOutput, err := json.Marshal(check)
+				if err != nil {
+					return err
+				}
+				cli.Output(string(jsonOutput))
+			} else {
+				printAccessCheck(cli, user, req, check)
+			}
+
+			if !check.Allowed {
+				return Error{Message: fmt.Sprintf("Access denied: %q does not have %q access to %q", user.Name, req.Privilege, req.Resource)}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Role, "role", models.BasePermissionConnect, "Type of access to check")
+	addFormatFlag(cmd.Flags(), &options.Format)
+	return cmd
+}
+
+func printAccessCheck(cli *CLI, user *api.User, req api.CheckAccessRequest, check *api.CheckAccessResponse) {
+	if !check.Allowed {
+		if len(check.Missing) > 0 {
+			cli.Output("Missing permissions: %s", strings.Join(check.Missing, ", "))
+		}
+		return
+	}
+
+	cli.Output("%q has %q access to %q", user.Name, req.Privilege, req.Resource)
+
+	type row struct {
+		Via      string `header:"VIA"`
+		Access   string `header:"ACCESS"`
+		Resource string `header:"DESTINATION"`
+	}
+
+	var rows []row
+	for _, grant := range check.Grants {
+		via := "user"
+		if grant.GroupName != "" {
+			via = "group " + grant.GroupName
+		}
+
+		rows = append(rows, row{
+			Via:      via,
+			Access:   grant.Grant.Privilege,
+			Resource: grant.Grant.Resource,
+		})
+	}
+
+	if len(rows) > 0 {
+		cli.Output("")
+		printTable(rows, cli.Stdout)
+	}
+}
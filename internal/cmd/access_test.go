@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAccessCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+
+	requestID := uid.ID(7000)
+
+	setup := func(t *testing.T) chan *http.Request {
+		requestCh := make(chan *http.Request, 1)
+
+		handler := func(resp http.ResponseWriter, req *http.Request) {
+			switch {
+			case requestMatches(req, http.MethodGet, "/api/users/"+uid.ID(3000).String()):
+				writeResponse(t, resp, api.User{ID: 3000, Name: "requester@example.com"})
+				return
+			case requestMatches(req, http.MethodGet, "/api/access-requests"):
+				writeResponse(t, resp, api.ListResponse[api.AccessRequest]{
+					Count: 1,
+					Items: []api.AccessRequest{{
+						ID:          requestID,
+						RequestedBy: 3000,
+						Privilege:   "cluster-admin",
+						Resource:    "production.payments",
+						Duration:    api.Duration(4 * time.Hour),
+						Status:      "pending",
+						Reason:      "incident",
+					}},
+				})
+				return
+			case requestMatches(req, http.MethodGet, "/api/users"):
+				writeResponse(t, resp, api.ListResponse[api.User]{
+					Count: 1,
+					Items: []api.User{{ID: 3000, Name: "requester@example.com"}},
+				})
+				return
+			case requestMatches(req, http.MethodGet, "/api/authz/check"):
+				allowed := req.URL.Query().Get("privilege") == "view"
+				result := api.CheckAccessResponse{Allowed: allowed}
+				if allowed {
+					result.Grants = []api.CheckAccessGrant{{
+						Grant:     api.Grant{ID: 8000, Group: 4000, Privilege: "view", Resource: "production.*"},
+						GroupName: "developers",
+					}}
+				}
+				writeResponse(t, resp, result)
+				return
+			}
+
+			requestCh <- req
+
+			var body api.CreateAccessRequestRequest
+			if req.URL.Path == "/api/access-requests" {
+				err := json.NewDecoder(req.Body).Decode(&body)
+				assert.Check(t, err)
+			}
+
+			status := "pending"
+			switch req.URL.Path {
+			case "/api/access-requests/" + requestID.String() + "/approve":
+				status = "approved"
+			case "/api/access-requests/" + requestID.String() + "/deny":
+				status = "denied"
+			}
+
+			resp.WriteHeader(http.StatusCreated)
+			writeResponse(t, resp, api.AccessRequest{
+				ID:        requestID,
+				Privilege: "cluster-admin",
+				Resource:  "production.payments",
+				Status:    status,
+				Reason:    body.Reason,
+				Duration:  body.Duration,
+			})
+		}
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+		t.Cleanup(srv.Close)
+
+		cfg := newTestClientConfig(srv, api.User{})
+		err := writeConfig(&cfg)
+		assert.NilError(t, err)
+
+		return requestCh
+	}
+
+	t.Run("request", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "access", "request", "production.payments",
+			"--role", "cluster-admin", "--duration", "4h", "--reason", "incident")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.Method, http.MethodPost)
+		assert.Equal(t, req.URL.Path, "/api/access-requests")
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), `Requested "cluster-admin" access to "production.payments"`))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "access", "list")
+		assert.NilError(t, err)
+
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), requestID.String()))
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), "requester@example.com"))
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), "4h0m0s"))
+	})
+
+	t.Run("approve", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "access", "approve", requestID.String())
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.Method, http.MethodPost)
+		assert.Equal(t, req.URL.Path, "/api/access-requests/"+requestID.String()+"/approve")
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), "was approved"))
+	})
+
+	t.Run("deny", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "access", "deny", requestID.String())
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.URL.Path, "/api/access-requests/"+requestID.String()+"/deny")
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), "was denied"))
+	})
+
+	t.Run("check allowed", func(t *testing.T) {
+		setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "access", "check", "requester@example.com", "production.payments", "--role", "view")
+		assert.NilError(t, err)
+
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), `"requester@example.com" has "view" access to "production.payments"`))
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), "group developers"))
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), "production.*"))
+	})
+
+	t.Run("check denied", func(t *testing.T) {
+		setup(t)
+		ctx, _ := PatchCLI(context.Background())
+
+		err := Run(ctx, "access", "check", "requester@example.com", "production.payments", "--role", "edit")
+		assert.ErrorContains(t, err, "Access denied")
+	})
+
+	t.Run("invalid ID", func(t *testing.T) {
+		setup(t)
+		ctx, _ := PatchCLI(context.Background())
+
+		err := Run(ctx, "access", "approve", "not-an-id")
+		assert.ErrorContains(t, err, "invalid access request ID")
+	})
+}
@@ -160,7 +160,7 @@ func syncKubeConfig(ctx context.Context, cancel context.CancelFunc) {
 		fileLogger.Sugar().Errorf("agent failed to get user destination grants: %v\n", err)
 		cancel()
 	}
-	if err := writeKubeconfig(user, destinations.Items, grants.Items); err != nil {
+	if err := writeKubeconfig(user, destinations.Items, grants); err != nil {
 		fileLogger.Sugar().Errorf("agent failed to update kube config: %v\n", err)
 		cancel()
 	}
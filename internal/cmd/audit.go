@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/logging"
+)
+
+func newAuditCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "View the audit log",
+		Group: "Management commands:",
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := rootPreRun(cmd.Flags()); err != nil {
+				return err
+			}
+			return mustBeLoggedIn()
+		},
+	}
+
+	cmd.AddCommand(newAuditListCmd(cli))
+
+	return cmd
+}
+
+type auditListOptions struct {
+	UserName string
+	Resource string
+	Since    time.Duration
+	Format   string
+}
+
+func newAuditListCmd(cli *CLI) *cobra.Command {
+	var options auditListOptions
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List audit events",
+		Example: `# List all audit events
+$ infra audit list
+
+# List changes to grants made by a user in the last day
+$ infra audit list --user janedoe@example.com --resource grants --since 24h`,
+		Args: NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := defaultAPIClient()
+			if err != nil {
+				return err
+			}
+
+			req := api.ListAuditEventsRequest{Resource: options.Resource}
+
+			if options.UserName != "" {
+				user, err := getUserByName(client, options.UserName)
+				if err != nil {
+					return err
+				}
+				req.Actor = user.ID
+			}
+
+			if options.Since > 0 {
+				req.After = api.Time(time.Now().Add(-options.Since))
+			}
+
+			logging.S.Debug("call server: list audit events")
+			events, err := client.ListAuditEvents(req)
+			if err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.S.Debug(err)
+					return Error{
+						Message: "Cannot list audit events: missing privileges for ListAuditEvents",
+					}
+				}
+				return err
+			}
+
+			if options.Format == "json" {
+				jsonOutput, err := json.Marshal(events)
+				if err != nil {
+					return err
+				}
+				cli.Output(string(jsonOutput))
+				return nil
+			}
+
+			type row struct {
+				Time     string `header:"TIME"`
+				User     string `header:"USER"`
+				Action   string `header:"ACTION"`
+				Resource string `header:"RESOURCE"`
+				Result   int    `header:"RESULT"`
+			}
+
+			var rows []row
+			for _, event := range events.Items {
+				user := event.ActorName
+				if user == "" {
+					user = event.Actor.String()
+				}
+
+				resource := event.Resource
+				if event.ResourceID != 0 {
+					resource = fmt.Sprintf("%s/%s", event.Resource, event.ResourceID)
+				}
+
+				rows = append(rows, row{
+					Time:     event.Created.Time().Local().Format(time.RFC3339),
+					User:     user,
+					Action:   event.Method + " " + event.Route,
+					Resource: resource,
+					Result:   event.ResultCode,
+				})
+			}
+
+			if len(rows) > 0 {
+				printTable(rows, cli.Stdout)
+			} else {
+				cli.Output("No audit events found")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&options.UserName, "user", "", "Only show events for requests made by this user")
+	cmd.Flags().StringVar(&options.Resource, "resource", "", "Only show events for this kind of resource (ex: grants, users)")
+	cmd.Flags().DurationVar(&options.Since, "since", 0, "Only show events that occurred within this duration (ex: 24h)")
+	addFormatFlag(cmd.Flags(), &options.Format)
+
+	return cmd
+}
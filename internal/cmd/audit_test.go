@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAuditListCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+
+	setup := func(t *testing.T) chan url.Values {
+		queryCh := make(chan url.Values, 1)
+
+		handler := func(resp http.ResponseWriter, req *http.Request) {
+			// the command does a lookup for user ID
+			if requestMatches(req, http.MethodGet, "/api/users") {
+				resp.WriteHeader(http.StatusOK)
+				err := json.NewEncoder(resp).Encode(api.ListResponse[api.User]{
+					Count: 1,
+					Items: []api.User{{ID: uid.ID(12345678), Name: req.URL.Query().Get("name")}},
+				})
+				assert.Check(t, err)
+				return
+			}
+
+			if !requestMatches(req, http.MethodGet, "/api/audit-events") {
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			queryCh <- req.URL.Query()
+
+			resp.WriteHeader(http.StatusOK)
+			err := json.NewEncoder(resp).Encode(api.ListResponse[api.AuditEvent]{
+				Count: 1,
+				Items: []api.AuditEvent{
+					{
+						Created:    api.Time(time.Now()),
+						Actor:      uid.ID(12345678),
+						ActorName:  "admin@example.com",
+						Method:     http.MethodDelete,
+						Route:      "/api/grants/:id",
+						Resource:   "grants",
+						ResourceID: uid.ID(4567),
+						ResultCode: http.StatusNoContent,
+					},
+				},
+			})
+			assert.Check(t, err)
+		}
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+		t.Cleanup(srv.Close)
+
+		cfg := newTestClientConfig(srv, api.User{})
+		err := writeConfig(&cfg)
+		assert.NilError(t, err)
+
+		return queryCh
+	}
+
+	t.Run("list all", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "audit", "list")
+		assert.NilError(t, err)
+
+		query := <-ch
+		assert.Equal(t, query.Get("actor"), "")
+		assert.Equal(t, query.Get("after"), "")
+
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), "DELETE /api/grants/:id"))
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), "grants/"+uid.ID(4567).String()))
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), "admin@example.com"))
+	})
+
+	t.Run("with filters", func(t *testing.T) {
+		ch := setup(t)
+		ctx, _ := PatchCLI(context.Background())
+
+		err := Run(ctx, "audit", "list", "--user", "admin@example.com", "--resource", "grants", "--since", "24h")
+		assert.NilError(t, err)
+
+		query := <-ch
+		assert.Equal(t, query.Get("actor"), uid.ID(12345678).String())
+		assert.Equal(t, query.Get("resource"), "grants")
+
+		var after api.Time
+		err = after.UnmarshalText([]byte(query.Get("after")))
+		assert.NilError(t, err)
+		assert.Assert(t, time.Since(after.Time()) > 23*time.Hour)
+	})
+}
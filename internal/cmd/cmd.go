@@ -108,11 +108,22 @@ func httpTransportForHostConfig(config *ClientHostConfig) *http.Transport {
 		}
 	}
 
+	var certificates []tls.Certificate
+	if config.ClientCertificate != "" {
+		cert, err := tls.LoadX509KeyPair(config.ClientCertificate, config.ClientKey)
+		if err != nil {
+			logging.S.Warnf("Failed to load client certificate: %v", err)
+		} else {
+			certificates = append(certificates, cert)
+		}
+	}
+
 	return &http.Transport{
 		TLSClientConfig: &tls.Config{
 			//nolint:gosec // We may purposely set insecureskipverify via a flag
 			InsecureSkipVerify: config.SkipTLSVerify,
 			RootCAs:            pool,
+			Certificates:       certificates,
 		},
 	}
 }
@@ -248,10 +259,14 @@ func NewRootCmd(cli *CLI) *cobra.Command {
 	// Management commands:
 	rootCmd.AddCommand(newDestinationsCmd(cli))
 	rootCmd.AddCommand(newGrantsCmd(cli))
+	rootCmd.AddCommand(newAccessCmd(cli))
 	rootCmd.AddCommand(newUsersCmd(cli))
+	rootCmd.AddCommand(newServiceAccountsCmd(cli))
 	rootCmd.AddCommand(newGroupsCmd(cli))
 	rootCmd.AddCommand(newKeysCmd(cli))
+	rootCmd.AddCommand(newSessionsCmd(cli))
 	rootCmd.AddCommand(newProvidersCmd(cli))
+	rootCmd.AddCommand(newAuditCmd(cli))
 
 	// Other commands:
 	rootCmd.AddCommand(newInfoCmd(cli))
@@ -106,20 +106,18 @@ func TestUse(t *testing.T) {
 
 				_, err = resp.Write(bytes)
 				assert.NilError(t, err)
-			case req.URL.Path == "/api/grants":
-				grants := api.ListResponse[api.Grant]{
-					Items: []api.Grant{
+			case req.URL.Path == "/api/grants/effective" && query.Get("user") == userID.String():
+				grants := api.ListResponse[api.EffectiveGrant]{
+					Items: []api.EffectiveGrant{
 						{
-							ID:        uid.New(),
-							User:      userID,
 							Resource:  "cluster",
 							Privilege: "admin",
+							Grants:    []api.Grant{{ID: uid.New(), User: userID, Resource: "cluster", Privilege: "admin"}},
 						},
 						{
-							ID:        uid.New(),
-							User:      userID,
 							Resource:  "cluster.namespace",
 							Privilege: "admin",
+							Grants:    []api.Grant{{ID: uid.New(), User: userID, Resource: "cluster.namespace", Privilege: "admin"}},
 						},
 					},
 				}
@@ -127,13 +125,6 @@ func TestUse(t *testing.T) {
 				bytes, err := json.Marshal(grants)
 				assert.NilError(t, err)
 
-				_, err = resp.Write(bytes)
-				assert.NilError(t, err)
-			case req.URL.Path == "/api/groups" && query.Get("userID") == userID.String():
-				groups := api.ListResponse[api.Group]{}
-				bytes, err := json.Marshal(groups)
-				assert.NilError(t, err)
-
 				_, err = resp.Write(bytes)
 				assert.NilError(t, err)
 			case req.URL.Path == fmt.Sprintf("/api/users/%s", userID):
@@ -176,9 +167,9 @@ func TestUse(t *testing.T) {
 
 		assert.Equal(t, len(kubeconfig.Clusters), 2)
 		assert.Equal(t, len(kubeconfig.Contexts), 2)
-		assert.Equal(t, len(kubeconfig.AuthInfos), 1)
+		assert.Equal(t, len(kubeconfig.AuthInfos), 2)
 		assert.Equal(t, kubeconfig.CurrentContext, "infra:cluster")
-		assert.Assert(t, is.Contains(kubeconfig.AuthInfos, "testuser@example.com"))
+		assert.Assert(t, is.Contains(kubeconfig.AuthInfos, "infra:cluster"))
 	})
 
 	t.Run("UseNamespace", func(t *testing.T) {
@@ -192,9 +183,9 @@ func TestUse(t *testing.T) {
 
 		assert.Equal(t, len(kubeconfig.Clusters), 2)
 		assert.Equal(t, len(kubeconfig.Contexts), 2)
-		assert.Equal(t, len(kubeconfig.AuthInfos), 1)
+		assert.Equal(t, len(kubeconfig.AuthInfos), 2)
 		assert.Equal(t, kubeconfig.CurrentContext, "infra:cluster:namespace")
-		assert.Assert(t, is.Contains(kubeconfig.AuthInfos, "testuser@example.com"))
+		assert.Assert(t, is.Contains(kubeconfig.AuthInfos, "infra:cluster:namespace"))
 	})
 
 	t.Run("InfraUse", func(t *testing.T) {
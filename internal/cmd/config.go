@@ -6,9 +6,11 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/logging"
 	"github.com/infrahq/infra/uid"
 )
 
@@ -38,11 +40,16 @@ type ClientHostConfig struct {
 	// TrustedCertificate is the PEM encoded TLS certificate used by the server
 	// that was verified and trusted by the user as part of login.
 	TrustedCertificate string `json:"trusted-certificate"`
+	// ClientCertificate and ClientKey are the files of the client certificate
+	// and its private key, when the user logged in with a client certificate
+	// instead of an access key.
+	ClientCertificate string `json:"client-certificate,omitempty"`
+	ClientKey         string `json:"client-key,omitempty"`
 }
 
 // checks if user is logged in to the given session (ClientHostConfig)
 func (c *ClientHostConfig) isLoggedIn() bool {
-	return c.AccessKey != "" && c.Name != "" && c.UserID != 0
+	return (c.AccessKey != "" || c.ClientCertificate != "") && c.Name != "" && c.UserID != 0
 }
 
 func (c *ClientHostConfig) isExpired() bool {
@@ -64,6 +71,51 @@ func infraHomeDir() (string, error) {
 	return infraDir, nil
 }
 
+// writeClientCertificate writes the client certificate for host, and its
+// private key, to ~/.infra/certs. It returns the names of the files.
+func writeClientCertificate(host string, certPEM, keyPEM []byte) (certFile, keyFile string, err error) {
+	infraDir, err := infraHomeDir()
+	if err != nil {
+		return "", "", err
+	}
+
+	certsDir := filepath.Join(infraDir, "certs")
+	if err := os.MkdirAll(certsDir, 0o700); err != nil {
+		return "", "", err
+	}
+
+	name := strings.ReplaceAll(host, ":", "_")
+	certFile = filepath.Join(certsDir, name+".crt")
+	keyFile = filepath.Join(certsDir, name+".key")
+
+	if err := ioutil.WriteFile(certFile, certPEM, 0o600); err != nil {
+		return "", "", err
+	}
+
+	if err := ioutil.WriteFile(keyFile, keyPEM, 0o600); err != nil {
+		return "", "", err
+	}
+
+	return certFile, keyFile, nil
+}
+
+// removeClientCertificate removes the files of the client certificate of
+// hostConfig.
+func removeClientCertificate(hostConfig *ClientHostConfig) {
+	for _, file := range []string{hostConfig.ClientCertificate, hostConfig.ClientKey} {
+		if file == "" {
+			continue
+		}
+
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			logging.S.Debugf("err: %s", err)
+		}
+	}
+
+	hostConfig.ClientCertificate = ""
+	hostConfig.ClientKey = ""
+}
+
 func readConfig() (*ClientConfig, error) {
 	infraDir, err := infraHomeDir()
 	if err != nil {
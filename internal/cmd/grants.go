@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/ssoroka/slice"
@@ -20,6 +21,7 @@ type grantsCmdOptions struct {
 	IsGroup     bool
 	Role        string
 	Force       bool
+	Duration    time.Duration
 }
 
 func newGrantsCmd(cli *CLI) *cobra.Command {
@@ -95,7 +97,7 @@ func newGrantsListCmd(cli *CLI) *cobra.Command {
 }
 
 func userGrants(cli *CLI, client *api.Client, grants *api.ListResponse[api.Grant]) (int, error) {
-	users, err := client.ListUsers(api.ListUsersRequest{})
+	users, err := client.ListUsers(api.ListUsersRequest{ShowServiceAccounts: true})
 	if err != nil {
 		return 0, err
 	}
@@ -284,6 +286,12 @@ $ infra grants add johndoe@example.com staging --role viewer
 
 # Assign a user a role within Infra
 $ infra grants add johndoe@example.com infra --role admin
+
+# Grant a group access to the monitoring namespace of every staging cluster
+$ infra grants add --group sre 'staging-*.monitoring' --role view
+
+# Grant a user access to a destination for 4 hours
+$ infra grants add johndoe@example.com production --duration 4h
 `,
 		Args: ExactArgs(2),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -296,6 +304,7 @@ $ infra grants add johndoe@example.com infra --role admin
 	cmd.Flags().BoolVarP(&options.IsGroup, "group", "g", false, "When set, creates a grant for a group instead of a user")
 	cmd.Flags().StringVar(&options.Role, "role", models.BasePermissionConnect, "Type of access that the user or group will be given")
 	cmd.Flags().BoolVar(&options.Force, "force", false, "Create grant even if requested user, destination, or role are unknown")
+	cmd.Flags().DurationVar(&options.Duration, "duration", 0, "Revoke the grant automatically after this duration (ex: 4h)")
 	return cmd
 }
 
@@ -350,6 +359,10 @@ func addGrant(cli *CLI, cmdOptions grantsCmdOptions) error {
 		groupID = group.ID
 	}
 
+	if cmdOptions.Duration < 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+
 	if err := checkResourcesPrivileges(client, cmdOptions.Destination, cmdOptions.Role); err != nil {
 		if !cmdOptions.Force {
 			return err
@@ -362,6 +375,9 @@ func addGrant(cli *CLI, cmdOptions grantsCmdOptions) error {
 		Privilege: cmdOptions.Role,
 		Resource:  cmdOptions.Destination,
 	}
+	if cmdOptions.Duration > 0 {
+		createGrantReq.Expires = api.Time(time.Now().Add(cmdOptions.Duration))
+	}
 	logging.S.Debugf("call server: create grant %#v", createGrantReq)
 	_, err = client.CreateGrant(createGrantReq)
 	if err != nil {
@@ -374,6 +390,11 @@ func addGrant(cli *CLI, cmdOptions grantsCmdOptions) error {
 		return err
 	}
 
+	if cmdOptions.Duration > 0 {
+		cli.Output("Created grant to %q for %q, expires in %s", cmdOptions.Destination, cmdOptions.Name, cmdOptions.Duration)
+		return nil
+	}
+
 	cli.Output("Created grant to %q for %q", cmdOptions.Destination, cmdOptions.Name)
 
 	return nil
@@ -413,7 +434,7 @@ func checkUserGroup(client *api.Client, subject string, isGroup bool) (userID ui
 
 // checkResourcesPrivileges checks if the requested destination (e.g. cluster), optional
 // resource (e.g. namespace), and role exist. destination "infra" and role "connect" are
-// reserved values and will always pass checks
+// reserved values and will always pass checks. Destinations with wildcards are not checked.
 func checkResourcesPrivileges(client *api.Client, resource, privilege string) error {
 	parts := strings.SplitN(resource, ".", 2)
 	destination := parts[0]
@@ -426,7 +447,7 @@ func checkResourcesPrivileges(client *api.Client, resource, privilege string) er
 	supportedResources := make(map[string]struct{})
 	supportedRoles := make(map[string]struct{})
 
-	if destination != "infra" {
+	if destination != "infra" && !api.IsResourcePattern(destination) {
 		logging.S.Debugf("call server: list destinations named %q", destination)
 		destinations, err := client.ListDestinations(api.ListDestinationsRequest{Name: destination})
 		if err != nil {
@@ -447,7 +468,7 @@ func checkResourcesPrivileges(client *api.Client, resource, privilege string) er
 			}
 		}
 
-		if subresource != "" {
+		if subresource != "" && !api.IsResourcePattern(subresource) {
 			if _, ok := supportedResources[subresource]; !ok {
 				return Error{Message: fmt.Sprintf("Namespace %q not detected in destination %q; to ignore, run with '--force'", subresource, destination)}
 			}
@@ -9,6 +9,7 @@ import (
 	"path"
 	"strings"
 	"testing"
+	"time"
 
 	"gotest.tools/v3/assert"
 
@@ -148,6 +149,25 @@ func TestGrantsAddCmd(t *testing.T) {
 		assert.DeepEqual(t, createReq, expected)
 	})
 
+	t.Run("add grant with duration", func(t *testing.T) {
+		ch := setup(t)
+		ctx := context.Background()
+		err := Run(ctx, "grants", "add", "existing@example.com", "the-destination", "--duration", "4h")
+		assert.NilError(t, err)
+
+		createReq := <-ch
+		expires := createReq.Expires.Time()
+		assert.Assert(t, time.Until(expires) > 3*time.Hour+59*time.Minute, expires)
+		assert.Assert(t, time.Until(expires) <= 4*time.Hour, expires)
+
+		createReq.Expires = api.Time{}
+		expected := api.CreateGrantRequest{
+			User:      3000,
+			Privilege: "connect",
+			Resource:  "the-destination",
+		}
+		assert.DeepEqual(t, createReq, expected)
+	})
 	t.Run("add grant for nonexistent user", func(t *testing.T) {
 		_ = setup(t)
 		err := Run(context.Background(), "grants", "add", "nonexistent", "destination")
@@ -7,6 +7,8 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/uid"
 )
 
 func getGroupByName(client *api.Client, name string) (*api.Group, error) {
@@ -54,6 +56,8 @@ func newGroupsCmd(cli *CLI) *cobra.Command {
 	cmd.AddCommand(newGroupsAddCmd(cli))
 	cmd.AddCommand(newGroupsListCmd(cli))
 	cmd.AddCommand(newGroupsRemoveCmd(cli))
+	cmd.AddCommand(newGroupsAddUserCmd(cli))
+	cmd.AddCommand(newGroupsRemoveUserCmd(cli))
 
 	return cmd
 }
@@ -176,3 +180,87 @@ $ infra groups remove Engineering`,
 
 	return cmd
 }
+
+func newGroupsAddUserCmd(cli *CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:   "adduser USER GROUP",
+		Short: "Add a user to a group",
+		Args:  ExactArgs(2),
+		Example: `# Add a user to a group
+$ infra groups adduser johndoe@example.com Engineering`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			userName, groupName := args[0], args[1]
+
+			client, err := defaultAPIClient()
+			if err != nil {
+				return err
+			}
+
+			user, err := getUserByName(client, userName)
+			if err != nil {
+				return err
+			}
+
+			group, err := getGroupByName(client, groupName)
+			if err != nil {
+				return err
+			}
+
+			req := &api.UpdateUsersInGroupRequest{GroupID: group.ID, UserIDs: []uid.ID{user.ID}}
+			if err := client.AddUsersToGroup(req); err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.S.Debug(err)
+					return Error{
+						Message: "Cannot add user to group: missing privileges for AddUsersToGroup",
+					}
+				}
+				return err
+			}
+
+			cli.Output("Added user %q to group %q", user.Name, group.Name)
+			return nil
+		},
+	}
+}
+
+func newGroupsRemoveUserCmd(cli *CLI) *cobra.Command {
+	return &cobra.Command{
+		Use:   "removeuser USER GROUP",
+		Short: "Remove a user from a group",
+		Args:  ExactArgs(2),
+		Example: `# Remove a user from a group
+$ infra groups removeuser johndoe@example.com Engineering`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			userName, groupName := args[0], args[1]
+
+			client, err := defaultAPIClient()
+			if err != nil {
+				return err
+			}
+
+			user, err := getUserByName(client, userName)
+			if err != nil {
+				return err
+			}
+
+			group, err := getGroupByName(client, groupName)
+			if err != nil {
+				return err
+			}
+
+			req := &api.UpdateUsersInGroupRequest{GroupID: group.ID, UserIDs: []uid.ID{user.ID}}
+			if err := client.RemoveUsersFromGroup(req); err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.S.Debug(err)
+					return Error{
+						Message: "Cannot remove user from group: missing privileges for RemoveUsersFromGroup",
+					}
+				}
+				return err
+			}
+
+			cli.Output("Removed user %q from group %q", user.Name, group.Name)
+			return nil
+		},
+	}
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestGroupsUserCmds(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+
+	var (
+		userID  = uid.ID(12345678)
+		groupID = uid.ID(23456789)
+	)
+
+	type request struct {
+		Method string
+		Path   string
+		Body   api.UpdateUsersInGroupRequest
+	}
+
+	setup := func(t *testing.T) chan request {
+		requestCh := make(chan request, 1)
+
+		handler := func(resp http.ResponseWriter, req *http.Request) {
+			switch {
+			case requestMatches(req, http.MethodGet, "/api/users"):
+				resp.WriteHeader(http.StatusOK)
+				err := json.NewEncoder(resp).Encode(api.ListResponse[api.User]{
+					Count: 1,
+					Items: []api.User{{ID: userID, Name: req.URL.Query().Get("name")}},
+				})
+				assert.Check(t, err)
+				return
+			case requestMatches(req, http.MethodGet, "/api/groups"):
+				resp.WriteHeader(http.StatusOK)
+				err := json.NewEncoder(resp).Encode(api.ListResponse[api.Group]{
+					Count: 1,
+					Items: []api.Group{{ID: groupID, Name: req.URL.Query().Get("name")}},
+				})
+				assert.Check(t, err)
+				return
+			}
+
+			r := request{Method: req.Method, Path: req.URL.Path}
+			err := json.NewDecoder(req.Body).Decode(&r.Body)
+			assert.Check(t, err)
+			requestCh <- r
+
+			if req.Method == http.MethodDelete {
+				resp.WriteHeader(http.StatusNoContent)
+				return
+			}
+			resp.WriteHeader(http.StatusOK)
+			_, _ = resp.Write([]byte(`{}`))
+		}
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+		t.Cleanup(srv.Close)
+
+		cfg := newTestClientConfig(srv, api.User{})
+		err := writeConfig(&cfg)
+		assert.NilError(t, err)
+
+		return requestCh
+	}
+
+	t.Run("add user", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "groups", "adduser", "johndoe@example.com", "Engineering")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.Method, http.MethodPut)
+		assert.Equal(t, req.Path, "/api/groups/"+groupID.String()+"/users")
+		assert.DeepEqual(t, req.Body.UserIDs, []uid.ID{userID})
+		assert.Equal(t, bufs.Stdout.String(), "Added user \"johndoe@example.com\" to group \"Engineering\"\n")
+	})
+
+	t.Run("remove user", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "groups", "removeuser", "johndoe@example.com", "Engineering")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.Method, http.MethodDelete)
+		assert.Equal(t, req.Path, "/api/groups/"+groupID.String()+"/users")
+		assert.DeepEqual(t, req.Body.UserIDs, []uid.ID{userID})
+		assert.Equal(t, bufs.Stdout.String(), "Removed user \"johndoe@example.com\" from group \"Engineering\"\n")
+	})
+}
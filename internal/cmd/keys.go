@@ -30,6 +30,7 @@ func newKeysCmd(cli *CLI) *cobra.Command {
 	cmd.AddCommand(newKeysListCmd(cli))
 	cmd.AddCommand(newKeysAddCmd(cli))
 	cmd.AddCommand(newKeysRemoveCmd(cli))
+	cmd.AddCommand(newKeysRotateCmd(cli))
 
 	return cmd
 }
@@ -38,19 +39,32 @@ type keyCreateOptions struct {
 	Name              string
 	TTL               time.Duration
 	ExtensionDeadline time.Duration
+	Scopes            []string
 }
 
 func newKeysAddCmd(cli *CLI) *cobra.Command {
 	var options keyCreateOptions
 
 	cmd := &cobra.Command{
-		Use:   "add USER|connector",
+		Use:   "add USER|SERVICEACCOUNT|connector",
 		Short: "Create an access key",
-		Long:  `Create an access key for a user or a connector.`,
+		Long:  `Create an access key for a user, a service account, or a connector.`,
 		Example: `
 # Create an access key named 'example-key' for a user that expires in 12 hours
 $ infra keys add user@example.com --ttl=12h --name example-key
 
+# Create an access key for a service account
+$ infra keys add deploy-bot
+
+# Create an access key that can only be used to create tokens for destinations
+$ infra keys add deploy-bot --scope tokens
+
+# Create an access key that can only be used to create tokens for the production destination
+$ infra keys add deploy-bot --scope destination:production
+
+# Create an access key that can only read grants
+$ infra keys add deploy-bot --scope read-only --scope grants:read
+
 # Create an access key to add a Kubernetes connection to Infra
 $ infra keys add connector
 `,
@@ -86,6 +100,7 @@ $ infra keys add connector
 				Name:              options.Name,
 				TTL:               api.Duration(options.TTL),
 				ExtensionDeadline: api.Duration(options.ExtensionDeadline),
+				Scopes:            options.Scopes,
 			})
 			if err != nil {
 				if api.ErrorStatusCode(err) == 403 {
@@ -106,6 +121,7 @@ $ infra keys add connector
 	cmd.Flags().StringVar(&options.Name, "name", "", "The name of the access key")
 	cmd.Flags().DurationVar(&options.TTL, "ttl", ThirtyDays, "The total time that the access key will be valid for")
 	cmd.Flags().DurationVar(&options.ExtensionDeadline, "extension-deadline", ThirtyDays, "A specified deadline that the access key must be used within to remain valid")
+	cmd.Flags().StringSliceVar(&options.Scopes, "scope", nil, "Limit the access key to read-only, tokens, destination:<name>, or a permission such as grants:read")
 
 	return cmd
 }
@@ -171,6 +187,72 @@ func newKeysRemoveCmd(cli *CLI) *cobra.Command {
 	return cmd
 }
 
+func newKeysRotateCmd(cli *CLI) *cobra.Command {
+	var gracePeriod time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "rotate KEY",
+		Short: "Replace the secret of an access key",
+		Long: `Replace the secret of an access key. The key keeps its name, scopes, and expiry.
+The previous secret remains valid for the grace period, so that anything using
+the key can be updated before it stops working.`,
+		Example: `# Rotate an access key, and keep the previous secret valid for an hour
+$ infra keys rotate deploy-key
+
+# Rotate an access key, and stop accepting the previous secret immediately
+$ infra keys rotate deploy-key --grace-period 0`,
+		Args: ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := defaultAPIClient()
+			if err != nil {
+				return err
+			}
+
+			logging.S.Debugf("call server: list access keys named %q", args[0])
+			keys, err := client.ListAccessKeys(api.ListAccessKeysRequest{Name: args[0]})
+			if err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.S.Debug(err)
+					return Error{
+						Message: "Cannot rotate key: missing privileges for ListKeys",
+					}
+				}
+				return err
+			}
+
+			if keys.Count == 0 {
+				return Error{Message: fmt.Sprintf("No access keys named %q", args[0])}
+			}
+
+			logging.S.Debugf("call server: rotate access key %s", keys.Items[0].ID)
+			resp, err := client.RotateAccessKey(&api.RotateAccessKeyRequest{
+				ID:          keys.Items[0].ID,
+				GracePeriod: api.Duration(gracePeriod),
+			})
+			if err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.S.Debug(err)
+					return Error{
+						Message: "Cannot rotate key: missing privileges for RotateKey",
+					}
+				}
+				return err
+			}
+
+			cli.Output("Rotated access key %q", resp.Name)
+			if gracePeriod > 0 {
+				cli.Output("The previous secret of the key is valid for %s", gracePeriod)
+			}
+			cli.Output("Key: %s", resp.AccessKey)
+			return nil
+		},
+	}
+
+	cmd.Flags().DurationVar(&gracePeriod, "grace-period", time.Hour, "How long the previous secret of the access key remains valid")
+
+	return cmd
+}
+
 type keyListOptions struct {
 	UserName    string
 	ShowExpired bool
@@ -235,6 +317,7 @@ func newKeysListCmd(cli *CLI) *cobra.Command {
 				Created           string `header:"CREATED"`
 				Expires           string `header:"EXPIRES"`
 				ExtensionDeadline string `header:"EXTENSION DEADLINE"`
+				Scopes            string `header:"SCOPES"`
 			}
 
 			var rows []row
@@ -249,6 +332,7 @@ func newKeysListCmd(cli *CLI) *cobra.Command {
 					Created:           HumanTime(k.Created.Time(), "never"),
 					Expires:           HumanTime(k.Expires.Time(), "never"),
 					ExtensionDeadline: HumanTime(k.ExtensionDeadline.Time(), "never"),
+					Scopes:            strings.Join(k.Scopes, ", "),
 				})
 			}
 
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
@@ -73,7 +74,7 @@ func TestKeysAddCmd(t *testing.T) {
 		ch := setup(t)
 
 		ctx := context.Background()
-		err := Run(ctx, "keys", "add", "--ttl=400h", "--extension-deadline=5h", "--name=the-name", "my-user")
+		err := Run(ctx, "keys", "add", "--ttl=400h", "--extension-deadline=5h", "--name=the-name", "--scope=read-only", "--scope=grants:read", "my-user")
 		assert.NilError(t, err)
 
 		req := <-ch
@@ -82,6 +83,7 @@ func TestKeysAddCmd(t *testing.T) {
 			Name:              "the-name",
 			TTL:               api.Duration(400 * time.Hour),
 			ExtensionDeadline: api.Duration(5 * time.Hour),
+			Scopes:            []string{"read-only", "grants:read"},
 		}
 		assert.DeepEqual(t, expected, req)
 	})
@@ -104,6 +106,94 @@ func TestKeysAddCmd(t *testing.T) {
 	})
 }
 
+func TestKeysRotateCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+
+	setup := func(t *testing.T) chan api.RotateAccessKeyRequest {
+		requestCh := make(chan api.RotateAccessKeyRequest, 1)
+
+		handler := func(resp http.ResponseWriter, req *http.Request) {
+			// the command does a lookup for the key ID
+			if requestMatches(req, http.MethodGet, "/api/access-keys") {
+				var items []api.AccessKey
+				if req.URL.Query().Get("name") == "deploy-key" {
+					items = append(items, api.AccessKey{ID: uid.ID(12345678), Name: "deploy-key"})
+				}
+
+				resp.WriteHeader(http.StatusOK)
+				err := json.NewEncoder(resp).Encode(api.ListResponse[api.AccessKey]{
+					Count: len(items),
+					Items: items,
+				})
+				assert.Check(t, err)
+				return
+			}
+
+			if !requestMatches(req, http.MethodPost, "/api/access-keys/"+uid.ID(12345678).String()+"/rotate") {
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			defer close(requestCh)
+			var rotateRequest api.RotateAccessKeyRequest
+			err := json.NewDecoder(req.Body).Decode(&rotateRequest)
+			assert.Check(t, err)
+
+			resp.WriteHeader(http.StatusCreated)
+			err = json.NewEncoder(resp).Encode(&api.CreateAccessKeyResponse{
+				ID:        uid.ID(12345678),
+				Name:      "deploy-key",
+				AccessKey: "the-rotated-access-key",
+			})
+			assert.Check(t, err)
+			requestCh <- rotateRequest
+		}
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+		t.Cleanup(srv.Close)
+
+		cfg := newTestClientConfig(srv, api.User{})
+		err := writeConfig(&cfg)
+		assert.NilError(t, err)
+
+		return requestCh
+	}
+
+	t.Run("default grace period", func(t *testing.T) {
+		ch := setup(t)
+
+		ctx, bufs := PatchCLI(context.Background())
+		err := Run(ctx, "keys", "rotate", "deploy-key")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.GracePeriod, api.Duration(time.Hour))
+		assert.Assert(t, strings.Contains(bufs.Stdout.String(), "Key: the-rotated-access-key"))
+		assert.Assert(t, strings.Contains(bufs.Stdout.String(), "valid for 1h0m0s"))
+	})
+
+	t.Run("without a grace period", func(t *testing.T) {
+		ch := setup(t)
+
+		ctx, bufs := PatchCLI(context.Background())
+		err := Run(ctx, "keys", "rotate", "--grace-period=0", "deploy-key")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.GracePeriod, api.Duration(0))
+		assert.Assert(t, !strings.Contains(bufs.Stdout.String(), "valid for"))
+	})
+
+	t.Run("key does not exist", func(t *testing.T) {
+		setup(t)
+
+		err := Run(context.Background(), "keys", "rotate", "other-key")
+		assert.ErrorContains(t, err, `No access keys named "other-key"`)
+	})
+}
+
 func requestMatches(req *http.Request, method string, path string) bool {
 	return req.Method == method && req.URL.Path == path
 }
@@ -181,6 +271,7 @@ func TestKeysListCmd(t *testing.T) {
 						IssuedForName: "clerk",
 						Created:       api.Time(base.Add(4 * time.Hour)),
 						Expires:       api.Time(base.Add(30 * time.Hour)),
+						Scopes:        []string{"read-only", "grants:read"},
 					},
 				},
 			})
@@ -67,26 +67,33 @@ func updateKubeConfig(client *api.Client, id uid.ID) error {
 		return err
 	}
 
-	grants, err := client.ListGrants(api.ListGrantsRequest{User: id})
+	grants, err := listEffectiveGrants(client, id)
 	if err != nil {
 		return err
 	}
 
-	groups, err := client.ListGroups(api.ListGroupsRequest{UserID: id})
+	return writeKubeconfig(user, destinations.Items, grants)
+}
+
+// listEffectiveGrants returns a grant for each privilege of the user, including
+// the privileges granted to the groups the user is a member of.
+func listEffectiveGrants(client *api.Client, userID uid.ID) ([]api.Grant, error) {
+	effective, err := client.ListEffectiveGrants(api.ListEffectiveGrantsRequest{User: userID})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	for _, g := range groups.Items {
-		groupGrants, err := client.ListGrants(api.ListGrantsRequest{Group: g.ID})
-		if err != nil {
-			return err
-		}
-
-		grants.Items = append(grants.Items, groupGrants.Items...)
+	grants := make([]api.Grant, 0, len(effective.Items))
+	for _, e := range effective.Items {
+		grants = append(grants, api.Grant{
+			User:      userID,
+			Privilege: e.Privilege,
+			Resource:  e.Resource,
+			Expires:   e.Expires,
+		})
 	}
 
-	return writeKubeconfig(user, destinations.Items, grants.Items)
+	return grants, nil
 }
 
 func writeKubeconfig(user *api.User, destinations []api.Destination, grants []api.Grant) error {
@@ -100,92 +107,68 @@ func writeKubeconfig(user *api.User, destinations []api.Destination, grants []ap
 	keep := make(map[string]bool)
 
 	for _, g := range grants {
-		parts := strings.Split(g.Resource, ".")
-
-		cluster := parts[0]
-
-		var namespace string
-		if len(parts) > 1 {
-			namespace = parts[1]
-		}
-
-		context := "infra:" + cluster
-
-		if namespace != "" {
-			context += ":" + namespace
-		}
-
-		var (
-			url    string
-			ca     []byte
-			exists bool
-		)
-
 		for _, d := range destinations {
-			// eg resource:  "foo.bar"
-			// eg dest name: "foo"
-			if strings.HasPrefix(g.Resource, d.Name) {
-				url = d.Connection.URL
-				ca = []byte(d.Connection.CA)
-				exists = true
-
-				break
-			}
-		}
-
-		if !exists {
-			continue
-		}
-
-		u, err := urlx.Parse(url)
-		if err != nil {
-			return err
-		}
-
-		u.Scheme = "https"
-
-		logging.S.Debugf("creating kubeconfig for %s", context)
-
-		kubeConfig.Clusters[context] = &clientcmdapi.Cluster{
-			Server:                   u.String(),
-			CertificateAuthorityData: ca,
-		}
-
-		// use existing kubeContext if possible which may contain
-		// user-defined overrides. preserve them if possible
-		kubeContext, ok := kubeConfig.Contexts[context]
-		if !ok {
-			kubeContext = &clientcmdapi.Context{
-				Cluster:   context,
-				AuthInfo:  user.Name,
-				Namespace: namespace,
+			for _, namespace := range grantNamespaces(g.Resource, d) {
+				context := "infra:" + d.Name
+
+				if namespace != "" {
+					context += ":" + namespace
+				}
+
+				u, err := urlx.Parse(d.Connection.URL)
+				if err != nil {
+					return err
+				}
+
+				u.Scheme = "https"
+
+				logging.S.Debugf("creating kubeconfig for %s", context)
+
+				kubeConfig.Clusters[context] = &clientcmdapi.Cluster{
+					Server:                   u.String(),
+					CertificateAuthorityData: []byte(d.Connection.CA),
+				}
+
+				// use existing kubeContext if possible which may contain
+				// user-defined overrides. preserve them if possible
+				kubeContext, ok := kubeConfig.Contexts[context]
+				if !ok {
+					kubeContext = &clientcmdapi.Context{
+						Cluster:   context,
+						Namespace: namespace,
+					}
+				}
+
+				// each context has its own user, which creates tokens that are
+				// only accepted by the destination of the context
+				kubeContext.AuthInfo = context
+
+				if namespace != "" {
+					// force the namespace if defined by Infra
+					if kubeContext.Namespace != namespace {
+						kubeContext.Namespace = namespace
+					}
+				}
+
+				kubeConfig.Contexts[context] = kubeContext
+
+				executable, err := os.Executable()
+				if err != nil {
+					return err
+				}
+
+				kubeConfig.AuthInfos[context] = &clientcmdapi.AuthInfo{
+					Exec: &clientcmdapi.ExecConfig{
+						Command:         executable,
+						Args:            []string{"tokens", "add", "--destination", d.Name},
+						APIVersion:      "client.authentication.k8s.io/v1beta1",
+						InteractiveMode: clientcmdapi.IfAvailableExecInteractiveMode,
+					},
+				}
+
+				keep[context] = true
 			}
 		}
-
-		if namespace != "" {
-			// force the namespace if defined by Infra
-			if kubeContext.Namespace != namespace {
-				kubeContext.Namespace = namespace
-			}
-		}
-
-		kubeConfig.Contexts[context] = kubeContext
-
-		executable, err := os.Executable()
-		if err != nil {
-			return err
-		}
-
-		kubeConfig.AuthInfos[user.Name] = &clientcmdapi.AuthInfo{
-			Exec: &clientcmdapi.ExecConfig{
-				Command:         executable,
-				Args:            []string{"tokens", "add"},
-				APIVersion:      "client.authentication.k8s.io/v1beta1",
-				InteractiveMode: clientcmdapi.IfAvailableExecInteractiveMode,
-			},
-		}
-
-		keep[context] = true
 	}
 
 	// cleanup others
@@ -207,6 +190,11 @@ func writeKubeconfig(user *api.User, destinations []api.Destination, grants []ap
 		}
 	}
 
+	// earlier versions shared one user between all the contexts
+	if authInfo, ok := kubeConfig.AuthInfos[user.Name]; ok && isInfraAuthInfo(authInfo) {
+		delete(kubeConfig.AuthInfos, user.Name)
+	}
+
 	configPath := defaultConfig.ConfigAccess().GetDefaultFilename()
 
 	configDir := filepath.Dir(configPath)
@@ -234,6 +222,43 @@ func writeKubeconfig(user *api.User, destinations []api.Destination, grants []ap
 	return nil
 }
 
+// grantNamespaces returns the namespaces of the destination that a grant on
+// resource applies to. The namespace is empty when the grant applies to the
+// whole destination. Wildcards in the namespace are expanded using the
+// namespaces known to the destination.
+func grantNamespaces(resource string, destination api.Destination) []string {
+	parts := strings.SplitN(resource, ".", 2)
+	if !api.ResourceMatches(parts[0], destination.Name) {
+		return nil
+	}
+
+	switch {
+	case len(parts) == 1:
+		return []string{""}
+	case !api.IsResourcePattern(parts[1]):
+		return []string{parts[1]}
+	}
+
+	var namespaces []string
+	for _, namespace := range destination.Resources {
+		if api.ResourceMatches(parts[1], namespace) {
+			namespaces = append(namespaces, namespace)
+		}
+	}
+
+	return namespaces
+}
+
+// isInfraAuthInfo returns true if authInfo is a kubeconfig user that creates
+// tokens with this CLI.
+func isInfraAuthInfo(authInfo *clientcmdapi.AuthInfo) bool {
+	if authInfo.Exec == nil || len(authInfo.Exec.Args) < 2 {
+		return false
+	}
+
+	return authInfo.Exec.Args[0] == "tokens" && authInfo.Exec.Args[1] == "add"
+}
+
 func clearKubeconfig() error {
 	defaultConfig := clientConfig()
 
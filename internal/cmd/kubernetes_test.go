@@ -46,12 +46,12 @@ func TestWriteKubeconfig(t *testing.T) {
 		},
 		Contexts: map[string]*clientcmdapi.Context{
 			"infra:cluster": {
-				AuthInfo: "user",
+				AuthInfo: "infra:cluster",
 				Cluster:  "infra:cluster",
 			},
 		},
 		AuthInfos: map[string]*clientcmdapi.AuthInfo{
-			"user": {},
+			"infra:cluster": {},
 		},
 	}
 
@@ -65,6 +65,61 @@ func TestWriteKubeconfig(t *testing.T) {
 		cmpopts.IgnoreFields(clientcmdapi.AuthInfo{}, "LocationOfOrigin"),
 		cmpopts.IgnoreFields(clientcmdapi.AuthInfo{}, "Exec"),
 	)
+
+	// tokens are created for the destination of the context
+	assert.DeepEqual(t, actual.AuthInfos["infra:cluster"].Exec.Args, []string{"tokens", "add", "--destination", "cluster"})
+}
+
+func TestWriteKubeconfig_RemovesSharedUser(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home)
+
+	kubeconfig := filepath.Join(home, "kubeconfig")
+	t.Setenv("KUBECONFIG", kubeconfig)
+
+	existing := clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			"infra:cluster": {Server: "https://cluster.example.com"},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			"infra:cluster": {AuthInfo: "user", Cluster: "infra:cluster"},
+		},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			"user": {
+				Exec: &clientcmdapi.ExecConfig{
+					Command:    "infra",
+					Args:       []string{"tokens", "add"},
+					APIVersion: "client.authentication.k8s.io/v1beta1",
+				},
+			},
+			"other": {Token: "token"},
+		},
+	}
+
+	err := clientcmd.WriteToFile(existing, kubeconfig)
+	assert.NilError(t, err)
+
+	user := api.User{Name: "user"}
+	destinations := []api.Destination{
+		{
+			Name: "cluster",
+			Connection: api.DestinationConnection{
+				URL: "cluster.example.com",
+				CA:  destinationCA,
+			},
+		},
+	}
+	grants := []api.Grant{{Resource: "cluster"}}
+
+	err = writeKubeconfig(&user, destinations, grants)
+	assert.NilError(t, err)
+
+	actual, err := clientConfig().RawConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, actual.Contexts["infra:cluster"].AuthInfo, "infra:cluster")
+	assert.Assert(t, actual.AuthInfos["user"] == nil)
+	assert.Assert(t, actual.AuthInfos["other"] != nil)
 }
 
 func TestWriteKubeconfig_UserNamespaceOverride(t *testing.T) {
@@ -174,3 +229,29 @@ func TestWriteKubeconfig_UserNamespaceOverrideResetNamespacedContext(t *testing.
 	assert.NilError(t, err)
 	assert.Equal(t, actual.Contexts["infra:cluster:default"].Namespace, "default")
 }
+
+func TestGrantNamespaces(t *testing.T) {
+	destination := api.Destination{
+		Name:      "staging-1",
+		Resources: []string{"default", "team-a", "team-b", "monitoring"},
+	}
+
+	testCases := []struct {
+		resource string
+		expected []string
+	}{
+		{resource: "staging-1", expected: []string{""}},
+		{resource: "staging-*", expected: []string{""}},
+		{resource: "production", expected: nil},
+		{resource: "staging-1.team-a", expected: []string{"team-a"}},
+		{resource: "staging-1.unknown", expected: []string{"unknown"}},
+		{resource: "staging-1.team-*", expected: []string{"team-a", "team-b"}},
+		{resource: "*.monitoring", expected: []string{"monitoring"}},
+		{resource: "production.monitoring", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		actual := grantNamespaces(tc.resource, destination)
+		assert.DeepEqual(t, actual, tc.expected)
+	}
+}
@@ -41,7 +41,7 @@ func list(cli *CLI) error {
 	}
 
 	gs := make(map[string]map[string]struct{})
-	for _, g := range grants.Items {
+	for _, g := range grants {
 		// aggregate privileges
 		if gs[g.Resource] == nil {
 			gs[g.Resource] = make(map[string]struct{})
@@ -104,10 +104,10 @@ func list(cli *CLI) error {
 		cli.Output("You have not been granted access to any active destinations")
 	}
 
-	return writeKubeconfig(user, destinations.Items, grants.Items)
+	return writeKubeconfig(user, destinations.Items, grants)
 }
 
-func getUserDestinationGrants(client *api.Client) (*api.User, *api.ListResponse[api.Destination], *api.ListResponse[api.Grant], error) {
+func getUserDestinationGrants(client *api.Client) (*api.User, *api.ListResponse[api.Destination], []api.Grant, error) {
 	config, err := currentHostConfig()
 	if err != nil {
 		return nil, nil, nil, err
@@ -122,25 +122,11 @@ func getUserDestinationGrants(client *api.Client) (*api.User, *api.ListResponse[
 		return nil, nil, nil, err
 	}
 
-	grants, err := client.ListGrants(api.ListGrantsRequest{User: config.UserID})
+	grants, err := listEffectiveGrants(client, config.UserID)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 
-	groups, err := client.ListGroups(api.ListGroupsRequest{UserID: config.UserID})
-	if err != nil {
-		return nil, nil, nil, err
-	}
-
-	for _, g := range groups.Items {
-		groupGrants, err := client.ListGrants(api.ListGrantsRequest{Group: g.ID})
-		if err != nil {
-			return nil, nil, nil, err
-		}
-
-		grants.Items = append(grants.Items, groupGrants.Items...)
-	}
-
 	destinations, err := client.ListDestinations(api.ListDestinationsRequest{})
 	if err != nil {
 		return nil, nil, nil, err
@@ -2,8 +2,12 @@ package cmd
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
 	"crypto/tls"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
 	"errors"
 	"fmt"
 	"net/http"
@@ -27,6 +31,7 @@ import (
 	"github.com/infrahq/infra/internal/cmd/types"
 	"github.com/infrahq/infra/internal/generate"
 	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/pki"
 )
 
 type loginCmdOptions struct {
@@ -38,6 +43,8 @@ type loginCmdOptions struct {
 	TrustedFingerprint string
 	NonInteractive     bool
 	NoAgent            bool
+	PasswordResetToken string
+	ClientCertificate  bool
 }
 
 type loginMethod int8
@@ -67,7 +74,13 @@ $ infra login infraexampleserver.com
 $ infra login --provider okta
 
 # Login with an access key
-$ infra login --key 1M4CWy9wF5.fAKeKEy5sMLH9ZZzAur0ZIjy`,
+$ infra login --key 1M4CWy9wF5.fAKeKEy5sMLH9ZZzAur0ZIjy
+
+# Set a password with the token from an invitation or password reset email
+$ infra login infraexampleserver.com --password-reset-token 6QWbAJRkPUwW6oA1C4Sk4d8Zz5Ks3dhq
+
+# Login and authenticate with a short-lived client certificate
+$ infra login infraexampleserver.com --cert`,
 		Args:  MaxArgs(1),
 		Group: "Core commands:",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -85,6 +98,8 @@ $ infra login --key 1M4CWy9wF5.fAKeKEy5sMLH9ZZzAur0ZIjy`,
 	cmd.Flags().Var((*types.StringOrFile)(&options.TrustedCertificate), "tls-trusted-cert", "TLS certificate or CA used by the server")
 	cmd.Flags().StringVar(&options.TrustedFingerprint, "tls-trusted-fingerprint", "", "SHA256 fingerprint of the server TLS certificate")
 	cmd.Flags().BoolVar(&options.NoAgent, "no-agent", false, "Skip starting the Infra agent in the background")
+	cmd.Flags().StringVar(&options.PasswordResetToken, "password-reset-token", "", "Set a new password with the token from an invitation or password reset email")
+	cmd.Flags().BoolVar(&options.ClientCertificate, "cert", false, "Authenticate with a client certificate instead of an access key")
 	addNonInteractiveFlag(cmd.Flags(), &options.NonInteractive)
 	return cmd
 }
@@ -136,12 +151,17 @@ func login(cli *CLI, options loginCmdOptions) error {
 			return err
 		}
 
-		return loginToInfra(cli, lc, loginReq, options.NoAgent)
+		return loginToInfra(cli, lc, loginReq, options)
 	}
 
 	switch {
 	case options.AccessKey != "":
 		loginReq.AccessKey = options.AccessKey
+	case options.PasswordResetToken != "":
+		if options.NonInteractive {
+			return Error{Message: "Non-interactive login only supports access keys; run 'infra login SERVER --non-interactive --key KEY"}
+		}
+		loginReq.PasswordReset = &api.LoginRequestPasswordReset{Token: options.PasswordResetToken}
 	case options.Provider != "":
 		if options.NonInteractive {
 			return Error{Message: "Non-interactive login only supports access keys; run 'infra login SERVER --non-interactive --key KEY"}
@@ -178,7 +198,7 @@ func login(cli *CLI, options loginCmdOptions) error {
 		}
 	}
 
-	return loginToInfra(cli, lc, loginReq, options.NoAgent)
+	return loginToInfra(cli, lc, loginReq, options)
 }
 
 func equalHosts(x, y string) bool {
@@ -191,16 +211,37 @@ func equalHosts(x, y string) bool {
 	return false
 }
 
-func loginToInfra(cli *CLI, lc loginClient, loginReq *api.LoginRequest, noAgent bool) error {
+func loginToInfra(cli *CLI, lc loginClient, loginReq *api.LoginRequest, options loginCmdOptions) error {
 	logging.S.Debug("call server: login")
 	loginRes, err := lc.APIClient.Login(loginReq)
+	if err != nil && isMFARequired(err) {
+		var code string
+		code, err = promptMFACode(cli)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case loginReq.PasswordCredentials != nil:
+			loginReq.PasswordCredentials.MFACode = code
+		case loginReq.PasswordReset != nil:
+			loginReq.PasswordReset.MFACode = code
+		}
+
+		logging.S.Debug("call server: login with mfa code")
+		loginRes, err = lc.APIClient.Login(loginReq)
+	}
 	if err != nil {
 		if api.ErrorStatusCode(err) == http.StatusUnauthorized || api.ErrorStatusCode(err) == http.StatusNotFound {
 			switch {
 			case loginReq.AccessKey != "":
 				return &LoginError{Message: "your access key may be invalid"}
+			case loginReq.PasswordCredentials != nil && loginReq.PasswordCredentials.MFACode != "":
+				return &LoginError{Message: "your username, password, or authentication code may be invalid"}
 			case loginReq.PasswordCredentials != nil:
 				return &LoginError{Message: "your username or password may be invalid"}
+			case loginReq.PasswordReset != nil:
+				return &LoginError{Message: "your password reset token may be invalid or expired"}
 			case loginReq.OIDC != nil:
 				return &LoginError{Message: "please contact an administrator and check identity provider configurations"}
 			}
@@ -212,9 +253,15 @@ func loginToInfra(cli *CLI, lc loginClient, loginReq *api.LoginRequest, noAgent
 	lc.APIClient.AccessKey = loginRes.AccessKey
 
 	if loginRes.PasswordUpdateRequired {
-		fmt.Fprintf(cli.Stderr, "  Your password has expired. Please update your password (min. length 8).\n")
+		var oldPassword string
+		if loginReq.PasswordCredentials != nil {
+			oldPassword = loginReq.PasswordCredentials.Password
+			fmt.Fprintf(cli.Stderr, "  Your password has expired. Please update your password (min. length 8).\n")
+		} else {
+			fmt.Fprintf(cli.Stderr, "  Please set a new password (min. length 8).\n")
+		}
 
-		password, err := promptSetPassword(cli, loginReq.PasswordCredentials.Password)
+		password, err := promptSetPassword(cli, oldPassword)
 		if err != nil {
 			return err
 		}
@@ -227,6 +274,20 @@ func loginToInfra(cli *CLI, lc loginClient, loginReq *api.LoginRequest, noAgent
 		fmt.Fprintf(os.Stderr, "  Updated password.\n")
 	}
 
+	if loginRes.MFAEnrollmentRequired {
+		fmt.Fprintf(cli.Stderr, "  Your administrator requires multi-factor authentication. Please enable it to continue.\n")
+
+		if err := enrollMFA(cli, lc.APIClient, loginRes.UserID); err != nil {
+			return err
+		}
+	}
+
+	if options.ClientCertificate {
+		if err := useClientCertificate(&lc, loginRes); err != nil {
+			return err
+		}
+	}
+
 	if err := updateInfraConfig(lc, loginReq, loginRes); err != nil {
 		return err
 	}
@@ -241,7 +302,7 @@ func loginToInfra(cli *CLI, lc loginClient, loginReq *api.LoginRequest, noAgent
 		logging.S.Errorf("unable to check background agent: %v", err)
 	}
 
-	if !backgroundAgentRunning && !noAgent {
+	if !backgroundAgentRunning && !options.NoAgent {
 		// the agent is started in a separate command so that it continues after the login command has finished
 		if err := execAgent(); err != nil {
 			// user still has a valid session, so do not fail
@@ -271,6 +332,8 @@ func updateInfraConfig(lc loginClient, loginReq *api.LoginRequest, loginRes *api
 	if lc.TrustedCertificate != "" {
 		clientHostConfig.TrustedCertificate = lc.TrustedCertificate
 	}
+	clientHostConfig.ClientCertificate = lc.ClientCertificate
+	clientHostConfig.ClientKey = lc.ClientKey
 
 	if loginReq.OIDC != nil {
 		clientHostConfig.ProviderID = loginReq.OIDC.ProviderID
@@ -289,6 +352,79 @@ func updateInfraConfig(lc loginClient, loginReq *api.LoginRequest, loginRes *api
 	return nil
 }
 
+// useClientCertificate replaces the access key from login with a client
+// certificate signed by the server. The certificate and its private key are
+// written to ~/.infra, and the access key is revoked, so that the certificate
+// is the only credential that is kept.
+func useClientCertificate(lc *loginClient, loginRes *api.LoginResponse) error {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return fmt.Errorf("generating key: %w", err)
+	}
+
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "User " + loginRes.UserID.String()},
+	}, key)
+	if err != nil {
+		return fmt.Errorf("creating certificate signing request: %w", err)
+	}
+
+	logging.S.Debug("call server: sign certificate")
+	signed, err := lc.APIClient.SignCertificate(&api.SignCertificateRequest{
+		CertificateSigningRequest: api.PEM(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})),
+	})
+	if err != nil {
+		if api.ErrorStatusCode(err) == http.StatusNotImplemented {
+			return Error{Message: "The server does not accept client certificates; login without --cert, or ask an administrator to enable them"}
+		}
+		return err
+	}
+
+	keyPEM, err := pki.MarshalPrivateKey(key)
+	if err != nil {
+		return err
+	}
+
+	cert, err := tls.X509KeyPair([]byte(signed.Certificate), keyPEM)
+	if err != nil {
+		return fmt.Errorf("reading client certificate: %w", err)
+	}
+
+	u, err := urlx.Parse(lc.APIClient.URL)
+	if err != nil {
+		return err
+	}
+
+	certFile, keyFile, err := writeClientCertificate(u.Host, []byte(signed.Certificate), keyPEM)
+	if err != nil {
+		return err
+	}
+
+	logging.S.Debug("call server: logout")
+	if err := lc.APIClient.Logout(); err != nil {
+		return fmt.Errorf("revoking access key: %w", err)
+	}
+
+	t, ok := lc.APIClient.HTTP.Transport.(*http.Transport)
+	if !ok {
+		return fmt.Errorf("could not update infra config")
+	}
+
+	// use a new transport, so that connections made without the client
+	// certificate are not reused
+	t = t.Clone()
+	t.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	lc.APIClient.HTTP.Transport = t
+	lc.APIClient.AccessKey = ""
+
+	lc.ClientCertificate = certFile
+	lc.ClientKey = keyFile
+	loginRes.AccessKey = ""
+	loginRes.Expires = signed.Expires
+
+	return nil
+}
+
 func oidcflow(host string, clientId string) (string, error) {
 	// find out what the authorization endpoint is
 	provider, err := oidc.NewProvider(context.Background(), fmt.Sprintf("https://%s", host))
@@ -406,6 +542,10 @@ type loginClient struct {
 	// TrustedCertificate is a PEM encoded certificate that has been trusted by
 	// the user for TLS communication with the server.
 	TrustedCertificate string
+	// ClientCertificate and ClientKey are the files of the client certificate
+	// that authenticates the user, when logging in with --cert.
+	ClientCertificate string
+	ClientKey         string
 }
 
 // Only used when logging in or switching to a new session, since user has no credentials. Otherwise, use defaultAPIClient().
@@ -564,6 +704,23 @@ func promptLocalLogin(cli *CLI) (*api.LoginRequestPasswordCredentials, error) {
 	}, nil
 }
 
+// isMFARequired returns true if the login failed because the user has enabled
+// multi-factor authentication, and the login request did not include a code.
+func isMFARequired(err error) bool {
+	var apiErr api.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusUnauthorized {
+		return false
+	}
+
+	for _, fieldErr := range apiErr.FieldErrors {
+		if fieldErr.FieldName == "mfaCode" {
+			return true
+		}
+	}
+
+	return false
+}
+
 func promptAccessKeyLogin(cli *CLI) (string, error) {
 	var accessKey string
 	err := survey.AskOne(
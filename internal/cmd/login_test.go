@@ -5,6 +5,8 @@ import (
 	"encoding/pem"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -25,6 +27,7 @@ import (
 	"github.com/infrahq/infra/internal/certs"
 	"github.com/infrahq/infra/internal/race"
 	"github.com/infrahq/infra/internal/server"
+	"github.com/infrahq/infra/internal/server/authn"
 	"github.com/infrahq/infra/uid"
 )
 
@@ -125,6 +128,104 @@ func TestLoginCmd_SetupAdminOnFirstLogin(t *testing.T) {
 	})
 }
 
+func TestLoginCmd_MFA(t *testing.T) {
+	dir := setupEnv(t)
+
+	opts := defaultServerOptions(dir)
+	opts.Addr = server.ListenerOptions{HTTPS: "127.0.0.1:0", HTTP: "127.0.0.1:0"}
+
+	srv, err := server.New(opts)
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	setupCertManager(t, opts.TLSCache, srv.Addrs.HTTPS.String())
+	go func() {
+		assert.Check(t, srv.Run(ctx))
+	}()
+
+	runStep(t, "first login prompts for setup", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		t.Cleanup(cancel)
+
+		console := newConsole(t)
+		ctx = PatchCLIWithPTY(ctx, console.Tty())
+
+		g, ctx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			return Run(ctx, "login", srv.Addrs.HTTPS.String(), "--skip-tls-verify", "--no-agent")
+		})
+
+		exp := expector{console: console}
+		exp.ExpectString(t, "Email:")
+		exp.Send(t, "admin@example.com\n")
+		exp.ExpectString(t, "Password")
+		exp.Send(t, "password\n")
+		exp.ExpectString(t, "Confirm")
+		exp.Send(t, "password\n")
+		exp.ExpectString(t, "Logged in as")
+		assert.NilError(t, g.Wait())
+	})
+
+	var secret string
+	runStep(t, "enable mfa", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		t.Cleanup(cancel)
+
+		console := newConsole(t)
+		ctx = PatchCLIWithPTY(ctx, console.Tty())
+
+		g, ctx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			return Run(ctx, "users", "edit", "admin@example.com", "--enable-mfa")
+		})
+
+		secretPattern := regexp.MustCompile(`Secret: ([A-Z2-7]{32})`)
+		out, err := console.Expect(expect.Regexp(secretPattern))
+		assert.NilError(t, err)
+		secret = secretPattern.FindStringSubmatch(out)[1]
+
+		code, err := authn.TOTPCode(secret, time.Now())
+		assert.NilError(t, err)
+
+		exp := expector{console: console}
+		exp.ExpectString(t, "Authentication Code:")
+		exp.Send(t, code+"\n")
+		exp.ExpectString(t, "Enabled multi-factor authentication")
+		assert.NilError(t, g.Wait())
+	})
+
+	runStep(t, "login prompts for a code", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(ctx)
+		t.Cleanup(cancel)
+
+		console := newConsole(t)
+		ctx = PatchCLIWithPTY(ctx, console.Tty())
+
+		g, ctx := errgroup.WithContext(ctx)
+		g.Go(func() error {
+			return Run(ctx, "login", "--non-interactive=false", srv.Addrs.HTTPS.String(), "--skip-tls-verify", "--no-agent")
+		})
+
+		// the code used to enable mfa can not be used again, use the next one
+		code, err := authn.TOTPCode(secret, time.Now().Add(30*time.Second))
+		assert.NilError(t, err)
+
+		exp := expector{console: console}
+		exp.ExpectString(t, "Login with username and password")
+		exp.Send(t, "\n")
+		exp.ExpectString(t, "Username:")
+		exp.Send(t, "admin@example.com\n")
+		exp.ExpectString(t, "Password:")
+		exp.Send(t, "password\n")
+		exp.ExpectString(t, "Authentication Code:")
+		exp.Send(t, code+"\n")
+		exp.ExpectString(t, "Logged in as")
+		assert.NilError(t, g.Wait())
+	})
+}
+
 func TestLoginCmd_Options(t *testing.T) {
 	dir := setupEnv(t)
 
@@ -187,6 +288,79 @@ func TestLoginCmd_Options(t *testing.T) {
 	})
 }
 
+func TestLoginCmd_ClientCertificate(t *testing.T) {
+	dir := setupEnv(t)
+
+	opts := defaultServerOptions(dir)
+	opts.Addr = server.ListenerOptions{HTTPS: "127.0.0.1:0", HTTP: "127.0.0.1:0"}
+	opts.EnableClientCertificates = true
+	adminAccessKey := "aaaaaaaaaa.bbbbbbbbbbbbbbbbbbbbbbbb"
+	opts.Config.Users = []server.User{
+		{
+			Name:      "admin@example.com",
+			AccessKey: adminAccessKey,
+		},
+	}
+	opts.Config.Grants = []server.Grant{
+		{
+			User:     "admin@example.com",
+			Role:     "admin",
+			Resource: "infra",
+		},
+	}
+	srv, err := server.New(opts)
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	setupCertManager(t, opts.TLSCache, srv.Addrs.HTTPS.String())
+	go func() {
+		assert.Check(t, srv.Run(ctx))
+	}()
+
+	runStep(t, "login with a client certificate", func(t *testing.T) {
+		err := Run(ctx, "login", srv.Addrs.HTTPS.String(), "--skip-tls-verify", "--no-agent", "--key", adminAccessKey, "--cert")
+		assert.NilError(t, err)
+	})
+
+	var hostConfig ClientHostConfig
+	runStep(t, "login stored the certificate instead of an access key", func(t *testing.T) {
+		cfg, err := readConfig()
+		assert.NilError(t, err)
+		assert.Equal(t, len(cfg.Hosts), 1)
+		hostConfig = cfg.Hosts[0]
+
+		assert.Equal(t, hostConfig.AccessKey, "")
+		assert.Equal(t, hostConfig.Name, "admin@example.com")
+		assert.Equal(t, filepath.Dir(hostConfig.ClientCertificate), filepath.Join(dir, ".infra", "certs"))
+		assert.DeepEqual(t, hostConfig.Expires, api.Time(time.Now().Add(24*time.Hour)), cmpApiTimeWithThreshold(20*time.Second))
+
+		for _, file := range []string{hostConfig.ClientCertificate, hostConfig.ClientKey} {
+			fi, err := os.Stat(file)
+			assert.NilError(t, err)
+			assert.Equal(t, fi.Mode().Perm(), os.FileMode(0o600))
+		}
+	})
+
+	runStep(t, "requests are authenticated with the certificate", func(t *testing.T) {
+		ctx, bufs := PatchCLI(ctx)
+		err := Run(ctx, "users", "list")
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(bufs.Stdout.String(), "admin@example.com"))
+	})
+
+	runStep(t, "logout removes the certificate", func(t *testing.T) {
+		err := Run(ctx, "logout")
+		assert.NilError(t, err)
+
+		_, err = os.Stat(hostConfig.ClientCertificate)
+		assert.Assert(t, os.IsNotExist(err))
+		_, err = os.Stat(hostConfig.ClientKey)
+		assert.Assert(t, os.IsNotExist(err))
+	})
+}
+
 var cmpClientHostConfig = cmp.Options{
 	cmp.FilterPath(
 		opt.PathField(ClientHostConfig{}, "AccessKey"),
@@ -69,11 +69,20 @@ func logoutOfServer(hostConfig *ClientHostConfig) (success bool) {
 	}
 
 	client := apiClient(hostConfig.Host, hostConfig.AccessKey, httpTransportForHostConfig(hostConfig))
+	accessKey := hostConfig.AccessKey
 
 	hostConfig.AccessKey = ""
 	hostConfig.UserID = 0
 	hostConfig.Name = ""
 
+	// a client certificate is only revoked with the other sessions of the
+	// user, or by a password change, so logout removes the local copy
+	removeClientCertificate(hostConfig)
+	if accessKey == "" {
+		logging.S.Debugf("removed client certificate for server [%s]", hostConfig.Host)
+		return true
+	}
+
 	err := client.Logout()
 	switch {
 	case api.ErrorStatusCode(err) == http.StatusUnauthorized:
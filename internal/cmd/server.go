@@ -39,6 +39,8 @@ func newServerCmd() *cobra.Command {
 				return err
 			}
 
+			options.ConfigFile = configFilename
+
 			tlsCache, err := canonicalPath(options.TLSCache)
 			if err != nil {
 				return err
@@ -99,8 +101,26 @@ func defaultServerOptions(infraDir string) server.Options {
 		EnableTelemetry:          true,
 		SessionDuration:          24 * time.Hour * 30, // 30 days
 		SessionExtensionDeadline: 24 * time.Hour * 3,  // 3 days
+		SigningKeyRotation:       24 * time.Hour * 30, // 30 days
 		EnableSignup:             true,
 
+		LoginLockout: server.LoginLockoutOptions{
+			UserAttempts: 5,
+			IPAttempts:   20,
+			Delay:        time.Second,
+			Duration:     15 * time.Minute,
+		},
+
+		PasswordPolicy: server.PasswordPolicyOptions{
+			MinLength: 8,
+		},
+
+		Email: server.EmailOptions{
+			SMTPPort:            587,
+			InvitationExpiry:    72 * time.Hour,
+			PasswordResetExpiry: time.Hour,
+		},
+
 		Addr: server.ListenerOptions{
 			HTTP:    ":80",
 			HTTPS:   ":443",
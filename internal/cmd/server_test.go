@@ -50,6 +50,9 @@ func TestServerCmd_LoadOptions(t *testing.T) {
 		assert.DeepEqual(t, expected, actual)
 	}
 
+	// configFile is set by the setup of test cases that write a config file
+	var configFile string
+
 	testCases := []testCase{
 		{
 			name: "config filename specified as env var",
@@ -61,11 +64,13 @@ func TestServerCmd_LoadOptions(t *testing.T) {
 				dir := fs.NewDir(t, t.Name(),
 					fs.WithFile("cfg.yaml", content))
 
-				t.Setenv("INFRA_SERVER_CONFIG_FILE", dir.Join("cfg.yaml"))
+				configFile = dir.Join("cfg.yaml")
+				t.Setenv("INFRA_SERVER_CONFIG_FILE", configFile)
 			},
 			expected: func(t *testing.T) server.Options {
 				expected := defaultServerOptions(filepath.Join(dir, ".infra"))
 				expected.Addr.HTTP = "127.0.0.1:1455"
+				expected.ConfigFile = configFile
 				return expected
 			},
 		},
@@ -104,7 +109,8 @@ func TestServerCmd_LoadOptions(t *testing.T) {
 `
 				dir := fs.NewDir(t, t.Name(),
 					fs.WithFile("cfg.yaml", content))
-				cmd.SetArgs([]string{"--config-file", dir.Join("cfg.yaml")})
+				configFile = dir.Join("cfg.yaml")
+				cmd.SetArgs([]string{"--config-file", configFile})
 			},
 			expected: func(t *testing.T) server.Options {
 				expected := defaultServerOptions(filepath.Join(dir, ".infra"))
@@ -113,6 +119,7 @@ func TestServerCmd_LoadOptions(t *testing.T) {
 					Host:   "127.0.1.2:34567",
 				}
 				expected.UI.Enabled = true
+				expected.ConfigFile = configFile
 				return expected
 			},
 		},
@@ -126,6 +133,32 @@ enableTelemetry: false # default is true
 enableSignup: false    # default is true
 sessionDuration: 3m
 sessionExtensionDeadline: 1m
+signingKeyRotation: 168h
+requireAdminMFA: true
+enableClientCertificates: true
+
+email:
+  smtpHost: smtp.example.com
+  smtpPort: 25
+  smtpUsername: infra
+  smtpPassword: env:SMTP_PASSWORD
+  from: Infra <infra@example.com>
+  baseURL: https://infra.example.com
+  invitationExpiry: 24h
+  passwordResetExpiry: 30m
+
+loginLockout:
+  userAttempts: 3
+  ipAttempts: 10
+  delay: 2s
+  duration: 5m
+
+passwordPolicy:
+  minLength: 12
+  requireUppercase: true
+  requireSymbol: true
+  historyDepth: 3
+  denyListFile: /etc/infra/passwords.txt
 
 dbFile: /db/file
 dbEncryptionKey: /this-is-the-path
@@ -181,7 +214,8 @@ users:
 
 				dir := fs.NewDir(t, t.Name(),
 					fs.WithFile("cfg.yaml", content))
-				cmd.SetArgs([]string{"--config-file", dir.Join("cfg.yaml")})
+				configFile = dir.Join("cfg.yaml")
+				cmd.SetArgs([]string{"--config-file", configFile})
 			},
 			expected: func(t *testing.T) server.Options {
 				return server.Options{
@@ -190,6 +224,35 @@ users:
 					SessionDuration:          3 * time.Minute,
 					SessionExtensionDeadline: 1 * time.Minute,
 
+					LoginLockout: server.LoginLockoutOptions{
+						UserAttempts: 3,
+						IPAttempts:   10,
+						Delay:        2 * time.Second,
+						Duration:     5 * time.Minute,
+					},
+
+					PasswordPolicy: server.PasswordPolicyOptions{
+						MinLength:        12,
+						RequireUppercase: true,
+						RequireSymbol:    true,
+						HistoryDepth:     3,
+						DenyListFile:     "/etc/infra/passwords.txt",
+					},
+					SigningKeyRotation:       7 * 24 * time.Hour,
+					RequireAdminMFA:          true,
+					EnableClientCertificates: true,
+
+					Email: server.EmailOptions{
+						SMTPHost:            "smtp.example.com",
+						SMTPPort:            25,
+						SMTPUsername:        "infra",
+						SMTPPassword:        "env:SMTP_PASSWORD",
+						From:                "Infra <infra@example.com>",
+						BaseURL:             "https://infra.example.com",
+						InvitationExpiry:    24 * time.Hour,
+						PasswordResetExpiry: 30 * time.Minute,
+					},
+
 					DBEncryptionKey:         "/this-is-the-path",
 					DBEncryptionKeyProvider: "the-provider",
 					DBFile:                  "/db/file",
@@ -260,6 +323,7 @@ users:
 							},
 						},
 					},
+					ConfigFile: configFile,
 				}
 			},
 		},
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/logging"
+)
+
+func newServiceAccountsCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "serviceaccounts",
+		Short:   "Manage service accounts",
+		Long:    "Manage service accounts, which are identities for automation that can only authenticate with access keys.",
+		Aliases: []string{"serviceaccount", "sa"},
+		Group:   "Management commands:",
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := rootPreRun(cmd.Flags()); err != nil {
+				return err
+			}
+			return mustBeLoggedIn()
+		},
+	}
+
+	cmd.AddCommand(newServiceAccountsAddCmd(cli))
+	cmd.AddCommand(newServiceAccountsListCmd(cli))
+	cmd.AddCommand(newServiceAccountsRemoveCmd(cli))
+
+	return cmd
+}
+
+func newServiceAccountsAddCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add NAME",
+		Short: "Create a service account",
+		Args:  ExactArgs(1),
+		Example: `# Create a service account, and an access key for it
+$ infra serviceaccounts add deploy-bot
+$ infra keys add deploy-bot`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := defaultAPIClient()
+			if err != nil {
+				return err
+			}
+
+			logging.S.Debugf("call server: create service account named %q", args[0])
+			if _, err := client.CreateServiceAccount(&api.CreateServiceAccountRequest{Name: args[0]}); err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.S.Debug(err)
+					return Error{
+						Message: "Cannot add service accounts: missing privileges for CreateServiceAccount",
+					}
+				}
+				return err
+			}
+
+			cli.Output("Added service account %q", args[0])
+			return nil
+		},
+	}
+
+	return cmd
+}
+
+func newServiceAccountsListCmd(cli *CLI) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List service accounts",
+		Args:    NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := defaultAPIClient()
+			if err != nil {
+				return err
+			}
+
+			logging.S.Debug("call server: list service accounts")
+			serviceAccounts, err := client.ListServiceAccounts(api.ListServiceAccountsRequest{})
+			if err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.S.Debug(err)
+					return Error{
+						Message: "Cannot list service accounts: missing privileges for ListServiceAccounts",
+					}
+				}
+				return err
+			}
+
+			switch format {
+			case "json":
+				jsonOutput, err := json.Marshal(serviceAccounts)
+				if err != nil {
+					return err
+				}
+				cli.Output(string(jsonOutput))
+			default:
+				type row struct {
+					Name       string `header:"Name"`
+					LastSeenAt string `header:"Last Seen"`
+					Created    string `header:"Created"`
+				}
+
+				var rows []row
+				for _, serviceAccount := range serviceAccounts.Items {
+					rows = append(rows, row{
+						Name:       serviceAccount.Name,
+						LastSeenAt: HumanTime(serviceAccount.LastSeenAt.Time(), "never"),
+						Created:    HumanTime(serviceAccount.Created.Time(), "never"),
+					})
+				}
+
+				if len(rows) > 0 {
+					printTable(rows, cli.Stdout)
+				} else {
+					cli.Output("No service accounts found")
+				}
+			}
+
+			return nil
+		},
+	}
+
+	addFormatFlag(cmd.Flags(), &format)
+	return cmd
+}
+
+func newServiceAccountsRemoveCmd(cli *CLI) *cobra.Command {
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:     "remove NAME",
+		Aliases: []string{"rm"},
+		Short:   "Delete a service account and its access keys",
+		Args:    ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			client, err := defaultAPIClient()
+			if err != nil {
+				return err
+			}
+
+			logging.S.Debugf("call server: list service accounts named %q", name)
+			serviceAccounts, err := client.ListServiceAccounts(api.ListServiceAccountsRequest{Name: name})
+			if err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.S.Debug(err)
+					return Error{
+						Message: "Cannot delete service accounts: missing privileges for ListServiceAccounts",
+					}
+				}
+				return err
+			}
+
+			if serviceAccounts.Count == 0 && !force {
+				return Error{Message: fmt.Sprintf("No service account named %q", name)}
+			}
+
+			for _, serviceAccount := range serviceAccounts.Items {
+				logging.S.Debugf("call server: delete service account %s", serviceAccount.ID)
+				if err := client.DeleteServiceAccount(serviceAccount.ID); err != nil {
+					if api.ErrorStatusCode(err) == 403 {
+						logging.S.Debug(err)
+						return Error{
+							Message: "Cannot delete service accounts: missing privileges for DeleteServiceAccount",
+						}
+					}
+					return err
+				}
+
+				cli.Output("Removed service account %q", serviceAccount.Name)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Exit successfully even if service account does not exist")
+
+	return cmd
+}
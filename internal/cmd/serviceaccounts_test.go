@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestServiceAccountsCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+
+	serviceAccountID := uid.ID(5000)
+
+	setup := func(t *testing.T) chan *http.Request {
+		requestCh := make(chan *http.Request, 1)
+
+		handler := func(resp http.ResponseWriter, req *http.Request) {
+			switch {
+			case requestMatches(req, http.MethodGet, "/api/service-accounts"):
+				list := api.ListResponse[api.User]{}
+				if name := req.URL.Query().Get("name"); name == "" || name == "deploy-bot" {
+					list.Count = 1
+					list.Items = []api.User{{ID: serviceAccountID, Name: "deploy-bot", Kind: "service-account"}}
+				}
+				writeResponse(t, resp, list)
+				return
+			case requestMatches(req, http.MethodPost, "/api/service-accounts"):
+				var body api.CreateServiceAccountRequest
+				err := json.NewDecoder(req.Body).Decode(&body)
+				assert.Check(t, err)
+
+				resp.WriteHeader(http.StatusCreated)
+				writeResponse(t, resp, api.User{ID: serviceAccountID, Name: body.Name, Kind: "service-account"})
+			case requestMatches(req, http.MethodDelete, "/api/service-accounts/"+serviceAccountID.String()):
+				resp.WriteHeader(http.StatusNoContent)
+			default:
+				resp.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			requestCh <- req
+		}
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+		t.Cleanup(srv.Close)
+
+		cfg := newTestClientConfig(srv, api.User{})
+		err := writeConfig(&cfg)
+		assert.NilError(t, err)
+
+		return requestCh
+	}
+
+	t.Run("add", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "serviceaccounts", "add", "deploy-bot")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.Method, http.MethodPost)
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), `Added service account "deploy-bot"`))
+	})
+
+	t.Run("list", func(t *testing.T) {
+		setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "serviceaccounts", "list")
+		assert.NilError(t, err)
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), "deploy-bot"))
+	})
+
+	t.Run("remove", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "serviceaccounts", "remove", "deploy-bot")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.Method, http.MethodDelete)
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), `Removed service account "deploy-bot"`))
+	})
+
+	t.Run("remove unknown", func(t *testing.T) {
+		setup(t)
+
+		err := Run(context.Background(), "serviceaccounts", "remove", "unknown")
+		assert.ErrorContains(t, err, `No service account named "unknown"`)
+	})
+}
@@ -0,0 +1,237 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/uid"
+)
+
+func newSessionsCmd(cli *CLI) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "sessions",
+		Short:   "Manage login sessions",
+		Long:    "Manage login sessions. A session is created each time a user logs in, and is valid until it expires or is revoked.",
+		Aliases: []string{"session"},
+		Group:   "Management commands:",
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := rootPreRun(cmd.Flags()); err != nil {
+				return err
+			}
+			return mustBeLoggedIn()
+		},
+	}
+
+	cmd.AddCommand(newSessionsListCmd(cli))
+	cmd.AddCommand(newSessionsRevokeCmd(cli))
+
+	return cmd
+}
+
+// sessionsUserID returns the ID of the user named userName, or the ID of the
+// logged in user when userName is empty.
+func sessionsUserID(client *api.Client, userName string) (userID uid.ID, self bool, err error) {
+	config, err := currentHostConfig()
+	if err != nil {
+		return 0, false, err
+	}
+
+	if userName == "" {
+		return config.UserID, true, nil
+	}
+
+	user, err := getUserByName(client, userName)
+	if err != nil {
+		if api.ErrorStatusCode(err) == 403 {
+			logging.S.Debug(err)
+			return 0, false, Error{
+				Message: "Cannot manage sessions: missing privileges for GetUser",
+			}
+		}
+		return 0, false, err
+	}
+
+	return user.ID, user.ID == config.UserID, nil
+}
+
+func newSessionsListCmd(cli *CLI) *cobra.Command {
+	var userName string
+	var format string
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List login sessions",
+		Args:    NoArgs,
+		Example: `# List your sessions
+$ infra sessions list
+
+# List the sessions of another user
+$ infra sessions list --user user@example.com`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := defaultAPIClient()
+			if err != nil {
+				return err
+			}
+
+			userID, _, err := sessionsUserID(client, userName)
+			if err != nil {
+				return err
+			}
+
+			logging.S.Debugf("call server: list sessions for user %s", userID)
+			sessions, err := client.ListUserSessions(api.ListUserSessionsRequest{ID: userID})
+			if err != nil {
+				if api.ErrorStatusCode(err) == 403 {
+					logging.S.Debug(err)
+					return Error{
+						Message: "Cannot list sessions: missing privileges for ListUserSessions",
+					}
+				}
+				return err
+			}
+
+			switch format {
+			case "json":
+				jsonOutput, err := json.Marshal(sessions)
+				if err != nil {
+					return err
+				}
+				cli.Output(string(jsonOutput))
+			default:
+				type row struct {
+					ID         string `header:"ID"`
+					ClientName string `header:"CLIENT"`
+					Provider   string `header:"PROVIDER"`
+					Created    string `header:"CREATED"`
+					LastUsed   string `header:"LAST USED"`
+					Expires    string `header:"EXPIRES"`
+					Current    string `header:"CURRENT"`
+				}
+
+				var rows []row
+				for _, session := range sessions.Items {
+					current := ""
+					if session.Current {
+						current = "*"
+					}
+
+					clientName := session.ClientName
+					if clientName == "" {
+						clientName = "unknown"
+					}
+
+					rows = append(rows, row{
+						ID:         session.ID.String(),
+						ClientName: clientName,
+						Provider:   session.ProviderName,
+						Created:    HumanTime(session.Created.Time(), "never"),
+						LastUsed:   HumanTime(session.LastUsed.Time(), "never"),
+						Expires:    HumanTime(session.Expires.Time(), "never"),
+						Current:    current,
+					})
+				}
+
+				if len(rows) > 0 {
+					printTable(rows, cli.Stdout)
+				} else {
+					cli.Output("No sessions found")
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&userName, "user", "", "The name of a user to list sessions for")
+	addFormatFlag(cmd.Flags(), &format)
+	return cmd
+}
+
+func newSessionsRevokeCmd(cli *CLI) *cobra.Command {
+	var userName string
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "revoke [SESSION]",
+		Short: "Revoke login sessions",
+		Long: `Revoke a login session, so that it can no longer be used. Use --all to revoke all
+the sessions of a user. When revoking all of your own sessions, the session
+used by this command is kept.`,
+		Example: `# Revoke one of your sessions
+$ infra sessions revoke 2toC3Pb6Ffh
+
+# Log out everywhere else
+$ infra sessions revoke --all
+
+# Revoke all the sessions of another user
+$ infra sessions revoke --all --user user@example.com`,
+		Args: MaxArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all == (len(args) == 1) {
+				return Error{Message: "Specify either a session to revoke or --all"}
+			}
+
+			client, err := defaultAPIClient()
+			if err != nil {
+				return err
+			}
+
+			userID, self, err := sessionsUserID(client, userName)
+			if err != nil {
+				return err
+			}
+
+			if all {
+				logging.S.Debugf("call server: revoke sessions for user %s", userID)
+				err := client.RevokeUserSessions(api.RevokeUserSessionsRequest{ID: userID, ExcludeCurrent: self})
+				if err != nil {
+					if api.ErrorStatusCode(err) == 403 {
+						logging.S.Debug(err)
+						return Error{
+							Message: "Cannot revoke sessions: missing privileges for RevokeUserSessions",
+						}
+					}
+					return err
+				}
+
+				if self {
+					cli.Output("Revoked all other sessions")
+				} else {
+					cli.Output("Revoked all sessions of %q", userName)
+				}
+				return nil
+			}
+
+			sessionID, err := uid.Parse([]byte(args[0]))
+			if err != nil {
+				return Error{Message: fmt.Sprintf("Invalid session %q", args[0])}
+			}
+
+			logging.S.Debugf("call server: revoke session %s for user %s", sessionID, userID)
+			if err := client.RevokeUserSession(userID, sessionID); err != nil {
+				switch api.ErrorStatusCode(err) {
+				case 403:
+					logging.S.Debug(err)
+					return Error{
+						Message: "Cannot revoke sessions: missing privileges for RevokeUserSession",
+					}
+				case 404:
+					return Error{Message: fmt.Sprintf("No session %q", args[0])}
+				}
+				return err
+			}
+
+			cli.Output("Revoked session %s", sessionID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&userName, "user", "", "The name of a user to revoke sessions for")
+	cmd.Flags().BoolVar(&all, "all", false, "Revoke all sessions")
+	return cmd
+}
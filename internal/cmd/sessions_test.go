@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestSessionsCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+
+	selfID := uid.ID(1000)
+	otherID := uid.ID(2000)
+	sessionID := uid.ID(3000)
+
+	setup := func(t *testing.T) chan *http.Request {
+		requestCh := make(chan *http.Request, 1)
+
+		handler := func(resp http.ResponseWriter, req *http.Request) {
+			switch {
+			case requestMatches(req, http.MethodGet, "/api/users"):
+				writeResponse(t, resp, api.ListResponse[api.User]{
+					Count: 1,
+					Items: []api.User{{ID: otherID, Name: "other@example.com"}},
+				})
+				return
+			case requestMatches(req, http.MethodGet, "/api/users/"+selfID.String()+"/sessions"):
+				writeResponse(t, resp, api.ListResponse[api.Session]{
+					Count: 1,
+					Items: []api.Session{{ID: sessionID, ClientName: "cli 0.13.4 (linux/amd64)", ProviderName: "infra", Current: true}},
+				})
+			case requestMatches(req, http.MethodDelete, "/api/users/"+selfID.String()+"/sessions/"+sessionID.String()),
+				requestMatches(req, http.MethodDelete, "/api/users/"+selfID.String()+"/sessions"),
+				requestMatches(req, http.MethodDelete, "/api/users/"+otherID.String()+"/sessions"):
+				resp.WriteHeader(http.StatusNoContent)
+			default:
+				resp.WriteHeader(http.StatusNotFound)
+				writeResponse(t, resp, api.Error{Code: http.StatusNotFound, Message: "not found"})
+				return
+			}
+
+			requestCh <- req
+		}
+
+		srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+		t.Cleanup(srv.Close)
+
+		cfg := newTestClientConfig(srv, api.User{ID: selfID})
+		err := writeConfig(&cfg)
+		assert.NilError(t, err)
+
+		return requestCh
+	}
+
+	t.Run("list", func(t *testing.T) {
+		setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "sessions", "list")
+		assert.NilError(t, err)
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), sessionID.String()))
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), "cli 0.13.4 (linux/amd64)"))
+	})
+
+	t.Run("revoke a session", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "sessions", "revoke", sessionID.String())
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.Method, http.MethodDelete)
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), "Revoked session "+sessionID.String()))
+	})
+
+	t.Run("revoke an unknown session", func(t *testing.T) {
+		setup(t)
+
+		err := Run(context.Background(), "sessions", "revoke", uid.ID(4000).String())
+		assert.ErrorContains(t, err, "No session")
+	})
+
+	t.Run("revoke all of your sessions", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "sessions", "revoke", "--all")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.URL.Path, "/api/users/"+selfID.String()+"/sessions")
+		assert.Equal(t, req.URL.Query().Get("excludeCurrent"), "true")
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), "Revoked all other sessions"))
+	})
+
+	t.Run("revoke all sessions of another user", func(t *testing.T) {
+		ch := setup(t)
+		ctx, bufs := PatchCLI(context.Background())
+
+		err := Run(ctx, "sessions", "revoke", "--all", "--user", "other@example.com")
+		assert.NilError(t, err)
+
+		req := <-ch
+		assert.Equal(t, req.URL.Path, "/api/users/"+otherID.String()+"/sessions")
+		assert.Equal(t, req.URL.Query().Get("excludeCurrent"), "false")
+		assert.Assert(t, is.Contains(bufs.Stdout.String(), `Revoked all sessions of "other@example.com"`))
+	})
+
+	t.Run("revoke without a session", func(t *testing.T) {
+		setup(t)
+
+		err := Run(context.Background(), "sessions", "revoke")
+		assert.ErrorContains(t, err, "Specify either a session to revoke or --all")
+	})
+}
@@ -7,6 +7,8 @@ import (
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clientauthenticationv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+
+	"github.com/infrahq/infra/api"
 )
 
 func newTokensCmd(cli *CLI) *cobra.Command {
@@ -28,23 +30,28 @@ func newTokensCmd(cli *CLI) *cobra.Command {
 }
 
 func newTokensAddCmd(cli *CLI) *cobra.Command {
-	return &cobra.Command{
+	var destination string
+
+	cmd := &cobra.Command{
 		Use:   "add",
 		Short: "Create a token",
 		Args:  NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
-			return tokensCreate(cli)
+			return tokensCreate(cli, destination)
 		},
 	}
+
+	cmd.Flags().StringVar(&destination, "destination", "", "The name of the destination to create the token for")
+	return cmd
 }
 
-func tokensCreate(cli *CLI) error {
+func tokensCreate(cli *CLI, destination string) error {
 	client, err := defaultAPIClient()
 	if err != nil {
 		return err
 	}
 
-	token, err := client.CreateToken()
+	token, err := client.CreateToken(&api.CreateTokenRequest{Destination: destination})
 	if err != nil {
 		return err
 	}
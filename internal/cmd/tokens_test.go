@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	clientauthenticationv1beta1 "k8s.io/client-go/pkg/apis/clientauthentication/v1beta1"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestTokensAddCmd(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("USERPROFILE", home) // for windows
+
+	var createReq api.CreateTokenRequest
+	handler := func(resp http.ResponseWriter, req *http.Request) {
+		if !requestMatches(req, http.MethodPost, "/api/tokens") {
+			resp.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		assert.NilError(t, json.NewDecoder(req.Body).Decode(&createReq))
+		resp.WriteHeader(http.StatusCreated)
+		writeResponse(t, resp, api.CreateTokenResponse{
+			Token:   "the-token",
+			Expires: api.Time(time.Now().Add(5 * time.Minute)),
+		})
+	}
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(handler))
+	t.Cleanup(srv.Close)
+
+	cfg := newTestClientConfig(srv, api.User{ID: uid.ID(1000)})
+	assert.NilError(t, writeConfig(&cfg))
+
+	ctx, bufs := PatchCLI(context.Background())
+	err := Run(ctx, "tokens", "add", "--destination", "production")
+	assert.NilError(t, err)
+
+	assert.Equal(t, createReq.Destination, "production")
+
+	var execCredential clientauthenticationv1beta1.ExecCredential
+	assert.NilError(t, json.Unmarshal(bufs.Stdout.Bytes(), &execCredential))
+	assert.Equal(t, execCredential.Status.Token, "the-token")
+}
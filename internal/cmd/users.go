@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/mail"
 	"strings"
 
@@ -13,6 +14,7 @@ import (
 	"github.com/infrahq/infra/api"
 	"github.com/infrahq/infra/internal/generate"
 	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/uid"
 )
 
 func newUsersCmd(cli *CLI) *cobra.Command {
@@ -73,6 +75,10 @@ $ infra users add johndoe@example.com`,
 
 			cli.Output("Added user %q", args[0])
 
+			if createResp.InvitationSent {
+				cli.Output("Sent an invitation to %q", args[0])
+			}
+
 			if createResp.OneTimePassword != "" {
 				cli.Output("Password: %s", createResp.OneTimePassword)
 			}
@@ -85,24 +91,53 @@ $ infra users add johndoe@example.com`,
 }
 
 func newUsersEditCmd(cli *CLI) *cobra.Command {
-	var editPassword bool
+	var editPassword, unlock, enableMFA, disableMFA bool
 
 	cmd := &cobra.Command{
 		Use:   "edit USER",
 		Short: "Update a user",
 		Example: `# Set a new password for a user
-$ infra users edit janedoe@example.com --password`,
+$ infra users edit janedoe@example.com --password
+
+# Unlock a user that is locked out after too many failed logins
+$ infra users edit janedoe@example.com --unlock
+
+# Enable multi-factor authentication for your user
+$ infra users edit janedoe@example.com --enable-mfa`,
 		Args: ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if !editPassword {
+			if !editPassword && !unlock && !enableMFA && !disableMFA {
 				return errors.New("Please specify a field to update. For options, run 'infra users edit --help'")
 			}
 
+			if enableMFA && disableMFA {
+				return errors.New("Only one of --enable-mfa and --disable-mfa can be specified")
+			}
+
+			if unlock {
+				if err := unlockUser(cli, args[0]); err != nil {
+					return err
+				}
+			}
+
+			if enableMFA || disableMFA {
+				if err := updateUserMFA(cli, args[0], enableMFA); err != nil {
+					return err
+				}
+			}
+
+			if !editPassword {
+				return nil
+			}
+
 			return updateUser(cli, args[0])
 		},
 	}
 
 	cmd.Flags().BoolVar(&editPassword, "password", false, "Set a new password")
+	cmd.Flags().BoolVar(&unlock, "unlock", false, "Unlock a user that is locked out after too many failed logins")
+	cmd.Flags().BoolVar(&enableMFA, "enable-mfa", false, "Enable multi-factor authentication for your user")
+	cmd.Flags().BoolVar(&disableMFA, "disable-mfa", false, "Disable multi-factor authentication")
 
 	return cmd
 }
@@ -247,6 +282,129 @@ func CreateUser(req *api.CreateUserRequest) (*api.CreateUserResponse, error) {
 	return resp, nil
 }
 
+func unlockUser(cli *CLI, name string) error {
+	client, err := defaultAPIClient()
+	if err != nil {
+		return err
+	}
+
+	user, err := getUserByName(client, name)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return Error{Message: fmt.Sprintf("Cannot unlock user: %s", err)}
+		}
+		return err
+	}
+
+	logging.S.Debugf("call server: unlock user %s", user.ID)
+	if _, err := client.UpdateUser(&api.UpdateUserRequest{ID: user.ID, Unlock: true}); err != nil {
+		if api.ErrorStatusCode(err) == 403 {
+			logging.S.Debug(err)
+			return Error{Message: "Cannot unlock user: missing privileges for UpdateUser"}
+		}
+		return err
+	}
+
+	cli.Output("  Unlocked user %q", name)
+	return nil
+}
+
+func updateUserMFA(cli *CLI, name string, enable bool) error {
+	client, err := defaultAPIClient()
+	if err != nil {
+		return err
+	}
+
+	if enable {
+		isSelf, err := isUserSelf(name)
+		if err != nil {
+			return err
+		}
+
+		if !isSelf {
+			return Error{Message: "Cannot enable multi-factor authentication: users can only enable it for themselves"}
+		}
+
+		config, err := currentHostConfig()
+		if err != nil {
+			return err
+		}
+
+		return enrollMFA(cli, client, config.UserID)
+	}
+
+	user, err := getUserByName(client, name)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return Error{Message: fmt.Sprintf("Cannot disable multi-factor authentication: %s", err)}
+		}
+		return err
+	}
+
+	logging.S.Debugf("call server: disable mfa for user %s", user.ID)
+	if err := client.DisableUserMFA(user.ID); err != nil {
+		if api.ErrorStatusCode(err) == 403 {
+			logging.S.Debug(err)
+			return Error{Message: "Cannot disable multi-factor authentication: missing privileges for DisableUserMFA"}
+		}
+		return err
+	}
+
+	cli.Output("  Disabled multi-factor authentication for %q", name)
+	return nil
+}
+
+// enrollMFA enables multi-factor authentication for the user, by showing the
+// secret to add to an authenticator app, and confirming it with a code.
+func enrollMFA(cli *CLI, client *api.Client, userID uid.ID) error {
+	logging.S.Debugf("call server: enroll mfa for user %s", userID)
+	enrollment, err := client.EnrollUserMFA(userID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cli.Stderr, "  Add this account to your authenticator app with the URI, or the secret:\n\n")
+	fmt.Fprintf(cli.Stderr, "    %s\n\n", enrollment.URI)
+	fmt.Fprintf(cli.Stderr, "    Secret: %s\n\n", enrollment.Secret)
+
+	for {
+		code, err := promptMFACode(cli)
+		if err != nil {
+			return err
+		}
+
+		logging.S.Debugf("call server: confirm mfa for user %s", userID)
+		confirmed, err := client.ConfirmUserMFA(&api.ConfirmUserMFARequest{ID: userID, Code: code})
+		if err != nil {
+			if api.ErrorStatusCode(err) == http.StatusBadRequest {
+				cli.Output("  Invalid code. Please try again.")
+				continue
+			}
+			return err
+		}
+
+		fmt.Fprintf(cli.Stderr, "  Enabled multi-factor authentication. Keep these recovery codes somewhere safe,\n")
+		fmt.Fprintf(cli.Stderr, "  each can be used once instead of a code from your authenticator app:\n\n")
+		for _, recoveryCode := range confirmed.RecoveryCodes {
+			cli.Output("    %s", recoveryCode)
+		}
+		cli.Output("")
+
+		return nil
+	}
+}
+
+func promptMFACode(cli *CLI) (string, error) {
+	var code string
+	err := survey.AskOne(
+		&survey.Input{Message: "Authentication Code:"},
+		&code,
+		cli.surveyIO,
+		survey.WithValidator(survey.Required),
+	)
+	return strings.TrimSpace(code), err
+}
+
 func updateUser(cli *CLI, name string) error {
 	client, err := defaultAPIClient()
 	if err != nil {
@@ -321,8 +479,9 @@ func updateUser(cli *CLI, name string) error {
 	return nil
 }
 
+// getUserByName returns the user or service account named name.
 func getUserByName(client *api.Client, name string) (*api.User, error) {
-	users, err := client.ListUsers(api.ListUsersRequest{Name: name})
+	users, err := client.ListUsers(api.ListUsersRequest{Name: name, ShowServiceAccounts: true})
 	if err != nil {
 		return nil, err
 	}
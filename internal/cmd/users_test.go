@@ -55,6 +55,11 @@ func TestUsersCmd(t *testing.T) {
 				return
 			}
 
+			if strings.HasSuffix(req.URL.Path, "/mfa") && req.Method == http.MethodDelete {
+				resp.WriteHeader(http.StatusNoContent)
+				return
+			}
+
 			if strings.Contains(req.URL.Path, "/api/users") {
 				switch req.Method {
 				case http.MethodPost:
@@ -94,6 +99,16 @@ func TestUsersCmd(t *testing.T) {
 					assert.NilError(t, err)
 					_, _ = resp.Write(b)
 					return
+				case http.MethodPut:
+					var updateUserReq api.UpdateUserRequest
+					err := json.NewDecoder(req.Body).Decode(&updateUserReq)
+					assert.NilError(t, err)
+					assert.Assert(t, updateUserReq.Unlock)
+
+					b, err := json.Marshal(api.User{Name: "new-user@example.com"})
+					assert.NilError(t, err)
+					_, _ = resp.Write(b)
+					return
 				case http.MethodDelete:
 					id := req.URL.Path[len("/api/users/"):]
 
@@ -152,6 +167,31 @@ func TestUsersCmd(t *testing.T) {
 		assert.ErrorContains(t, err, "Please specify a field to update. For options, run 'infra users edit --help'")
 	})
 
+	t.Run("edit user unlock", func(t *testing.T) {
+		setup(t)
+		err := Run(context.Background(), "users", "add", "new-user@example.com")
+		assert.NilError(t, err)
+
+		ctx, bufs := PatchCLI(context.Background())
+		err = Run(ctx, "users", "edit", "new-user@example.com", "--unlock")
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(bufs.Stdout.String(), `Unlocked user "new-user@example.com"`))
+	})
+
+	t.Run("edit user disable mfa", func(t *testing.T) {
+		setup(t)
+		err := Run(context.Background(), "users", "add", "new-user@example.com")
+		assert.NilError(t, err)
+
+		ctx, bufs := PatchCLI(context.Background())
+		err = Run(ctx, "users", "edit", "new-user@example.com", "--disable-mfa")
+		assert.NilError(t, err)
+		assert.Assert(t, strings.Contains(bufs.Stdout.String(), `Disabled multi-factor authentication for "new-user@example.com"`))
+
+		err = Run(ctx, "users", "edit", "new-user@example.com", "--disable-mfa", "--enable-mfa")
+		assert.ErrorContains(t, err, "Only one of --enable-mfa and --disable-mfa can be specified")
+	})
+
 	t.Run("edit without required argument", func(t *testing.T) {
 		err := Run(context.Background(), "users", "edit")
 		assert.ErrorContains(t, err, `"infra users edit" requires exactly 1 argument`)
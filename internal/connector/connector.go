@@ -48,19 +48,30 @@ type Options struct {
 
 type jwkCache struct {
 	mu          sync.Mutex
-	key         *jose.JSONWebKey
+	keys        []jose.JSONWebKey
 	lastChecked time.Time
 
 	client  *http.Client
 	baseURL string
 }
 
-func (j *jwkCache) getJWK() (*jose.JSONWebKey, error) {
+// getJWK returns the key with the key ID kid. When kid is empty the first key
+// is returned, for tokens from servers that do not set the kid header. The
+// keys are fetched again when they are older than JWKCacheRefresh, or when kid
+// is not one of the keys and the keys were fetched more than
+// JWKCacheMinRefresh ago.
+func (j *jwkCache) getJWK(kid string) (*jose.JSONWebKey, error) {
 	j.mu.Lock()
 	defer j.mu.Unlock()
 
 	if !j.lastChecked.IsZero() && time.Now().Before(j.lastChecked.Add(JWKCacheRefresh)) {
-		return j.key, nil
+		if key := findJWK(j.keys, kid); key != nil {
+			return key, nil
+		}
+
+		if time.Now().Before(j.lastChecked.Add(JWKCacheMinRefresh)) {
+			return nil, fmt.Errorf("no jwk with key id %q", kid)
+		}
 	}
 
 	req, err := http.NewRequestWithContext(context.TODO(), http.MethodGet, fmt.Sprintf("%s/.well-known/jwks.json", j.baseURL), nil)
@@ -93,13 +104,42 @@ func (j *jwkCache) getJWK() (*jose.JSONWebKey, error) {
 	}
 
 	j.lastChecked = time.Now().UTC()
-	j.key = &response.Keys[0]
+	j.keys = response.Keys
 
-	return &response.Keys[0], nil
+	key := findJWK(j.keys, kid)
+	if key == nil {
+		return nil, fmt.Errorf("no jwk with key id %q", kid)
+	}
+
+	return key, nil
+}
+
+// findJWK returns the key with the key ID kid, or the first key when kid is
+// empty. It returns nil when there is no such key.
+func findJWK(keys []jose.JSONWebKey, kid string) *jose.JSONWebKey {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if kid == "" {
+		return &keys[0]
+	}
+
+	for i := range keys {
+		if keys[i].KeyID == kid {
+			return &keys[i]
+		}
+	}
+
+	return nil
 }
 
 var JWKCacheRefresh = 5 * time.Minute
 
+// JWKCacheMinRefresh limits how often the keys are fetched for tokens signed
+// with an unknown key.
+var JWKCacheMinRefresh = 10 * time.Second
+
 type BearerTransport struct {
 	Token     string
 	Transport http.RoundTripper
@@ -113,9 +153,56 @@ func (b *BearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	return b.Transport.RoundTrip(req)
 }
 
-type getJWKFunc func() (*jose.JSONWebKey, error)
+// AccessKeyRefresh is how often the access key is read again from its secret
+// source, so that a rotated access key is used without restarting.
+var AccessKeyRefresh = 30 * time.Second
+
+// accessKeyTransport sets the access key of the connector on requests to the
+// server. The key is read from its secret source by load.
+type accessKeyTransport struct {
+	secret    string
+	storage   map[string]secrets.SecretStorage
+	Transport http.RoundTripper
 
-func jwtMiddleware(getJWK getJWKFunc) gin.HandlerFunc {
+	mu        sync.RWMutex
+	accessKey string
+}
+
+func (t *accessKeyTransport) load() error {
+	accessKey, err := secrets.GetSecret(t.secret, t.storage)
+	if err != nil {
+		return err
+	}
+
+	accessKey = strings.TrimSpace(accessKey)
+
+	t.mu.Lock()
+	changed := t.accessKey != "" && t.accessKey != accessKey
+	t.accessKey = accessKey
+	t.mu.Unlock()
+
+	if changed {
+		logging.S.Info("access key changed, using the new access key")
+	}
+
+	return nil
+}
+
+func (t *accessKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.RLock()
+	accessKey := t.accessKey
+	t.mu.RUnlock()
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessKey))
+	return t.Transport.RoundTrip(req)
+}
+
+// getJWKFunc returns the key with the key ID kid, from the kid header of a token.
+type getJWKFunc func(kid string) (*jose.JSONWebKey, error)
+
+// jwtMiddleware authenticates requests with a token from the server. Only
+// tokens created for destination are accepted.
+func jwtMiddleware(destination string, getJWK getJWKFunc) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authorization := c.GetHeader("Authorization")
 
@@ -135,7 +222,12 @@ func jwtMiddleware(getJWK getJWKFunc) gin.HandlerFunc {
 			return
 		}
 
-		key, err := getJWK()
+		var kid string
+		if len(tok.Headers) > 0 {
+			kid = tok.Headers[0].KeyID
+		}
+
+		key, err := getJWK(kid)
 		if err != nil {
 			logging.L.Debug("could not get jwk")
 			c.AbortWithStatus(http.StatusUnauthorized)
@@ -143,6 +235,11 @@ func jwtMiddleware(getJWK getJWKFunc) gin.HandlerFunc {
 			return
 		}
 
+		expected := jwt.Expected{
+			Issuer:   claims.Issuer,
+			Audience: jwt.Audience{destination},
+		}
+
 		var claims struct {
 			jwt.Claims
 			claims.Custom
@@ -156,9 +253,8 @@ func jwtMiddleware(getJWK getJWKFunc) gin.HandlerFunc {
 			return
 		}
 
-		err = claims.Claims.Validate(jwt.Expected{
-			Time: time.Now().UTC(),
-		})
+		expected.Time = time.Now().UTC()
+		err = claims.Claims.Validate(expected)
 
 		switch {
 		case errors.Is(err, jwt.ErrExpired):
@@ -223,20 +319,27 @@ func proxyMiddleware(proxy *httputil.ReverseProxy, bearerToken string) gin.Handl
 	}
 }
 
-// UpdateRoles converts infra grants to role-bindings in the current cluster
-func updateRoles(c *api.Client, k *kubernetes.Kubernetes, grants []api.Grant) error {
+// updateRoles updates the role bindings in the cluster to match the grants. The
+// grants for other destinations are ignored. Grants with a wildcard namespace
+// are bound in each of the namespaces that match.
+func updateRoles(c *api.Client, k *kubernetes.Kubernetes, name string, namespaces []string, grants []api.Grant) error {
 	logging.L.Debug("syncing local grants from infra configuration")
 
 	crSubjects := make(map[string][]rbacv1.Subject)                           // cluster-role: subject
 	crnSubjects := make(map[kubernetes.ClusterRoleNamespace][]rbacv1.Subject) // cluster-role+namespace: subject
 
 	for _, g := range grants {
-		var name, kind string
+		var subjectName, kind string
 
 		if g.Privilege == "connect" {
 			continue
 		}
 
+		parts := strings.Split(g.Resource, ".")
+		if !api.ResourceMatches(parts[0], name) {
+			continue
+		}
+
 		switch {
 		case g.Group != 0:
 			group, err := c.GetGroup(g.Group)
@@ -244,7 +347,7 @@ func updateRoles(c *api.Client, k *kubernetes.Kubernetes, grants []api.Grant) er
 				return err
 			}
 
-			name = group.Name
+			subjectName = group.Name
 			kind = rbacv1.GroupKind
 		case g.User != 0:
 			user, err := c.GetUser(g.User)
@@ -252,31 +355,27 @@ func updateRoles(c *api.Client, k *kubernetes.Kubernetes, grants []api.Grant) er
 				return err
 			}
 
-			name = user.Name
+			subjectName = user.Name
 			kind = rbacv1.UserKind
 		}
 
 		subj := rbacv1.Subject{
 			APIGroup: "rbac.authorization.k8s.io",
 			Kind:     kind,
-			Name:     name,
+			Name:     subjectName,
 		}
 
-		parts := strings.Split(g.Resource, ".")
-
-		var crn kubernetes.ClusterRoleNamespace
-
 		switch len(parts) {
 		// <cluster>
 		case 1:
-			crn.ClusterRole = g.Privilege
 			crSubjects[g.Privilege] = append(crSubjects[g.Privilege], subj)
 
 		// <cluster>.<namespace>
 		case 2:
-			crn.ClusterRole = g.Privilege
-			crn.Namespace = parts[1]
-			crnSubjects[crn] = append(crnSubjects[crn], subj)
+			for _, namespace := range expandNamespace(parts[1], namespaces) {
+				crn := kubernetes.ClusterRoleNamespace{ClusterRole: g.Privilege, Namespace: namespace}
+				crnSubjects[crn] = append(crnSubjects[crn], subj)
+			}
 
 		default:
 			logging.S.Warnf("invalid grant resource: %s", g.Resource)
@@ -295,6 +394,23 @@ func updateRoles(c *api.Client, k *kubernetes.Kubernetes, grants []api.Grant) er
 	return nil
 }
 
+// expandNamespace returns the namespaces that match the namespace of a grant,
+// which may use wildcards.
+func expandNamespace(pattern string, namespaces []string) []string {
+	if !api.IsResourcePattern(pattern) {
+		return []string{pattern}
+	}
+
+	var matches []string
+	for _, namespace := range namespaces {
+		if api.ResourceMatches(pattern, namespace) {
+			matches = append(matches, namespace)
+		}
+	}
+
+	return matches
+}
+
 type CertCache struct {
 	mu     sync.Mutex
 	caCert []byte
@@ -448,25 +564,36 @@ func Run(ctx context.Context, options Options) error {
 		InsecureSkipVerify: options.SkipTLSVerify,
 	}
 
-	accessKey, err := secrets.GetSecret(options.AccessKey, basicSecretStorage)
-	if err != nil {
+	accessKey := &accessKeyTransport{
+		secret:    options.AccessKey,
+		storage:   basicSecretStorage,
+		Transport: transport,
+	}
+	if err := accessKey.load(); err != nil {
 		return err
 	}
 
+	// the access key is set by accessKeyTransport
 	client := &api.Client{
-		Name:      "connector",
-		Version:   internal.Version,
-		URL:       u.String(),
-		AccessKey: accessKey,
+		Name:    "connector",
+		Version: internal.Version,
+		URL:     u.String(),
 		HTTP: http.Client{
-			Transport: transport,
+			Transport: accessKey,
 		},
 	}
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	repeat.Start(ctx, AccessKeyRefresh, func(context.Context) {
+		if err := accessKey.load(); err != nil {
+			logging.S.Errorf("failed to reload access key: %v", err)
+		}
+	})
+
 	repeat.Start(ctx, 5*time.Second, syncWithServer(k8s, client, destination, certCache, caCertPEM))
+	go syncGrants(ctx, k8s, client, destination.Name)
 
 	ginutil.SetMode()
 	router := gin.New()
@@ -524,7 +651,7 @@ func Run(ctx context.Context, options Options) error {
 
 	router.Use(
 		metrics.Middleware(promRegistry),
-		jwtMiddleware(cache.getJWK),
+		jwtMiddleware(destination.Name, cache.getJWK),
 		proxyMiddleware(proxy, k8s.Config.BearerToken),
 	)
 	tlsServer := &http.Server{
@@ -611,31 +738,84 @@ func syncWithServer(k8s *kubernetes.Kubernetes, client *api.Client, destination
 				return
 			}
 		}
+	}
+}
 
-		grants, err := client.ListGrants(api.ListGrantsRequest{Resource: destination.Name})
-		if err != nil {
-			logging.S.Errorf("error listing grants: %v", err)
-			return
-		}
+// grantsPollInterval is how long syncGrants waits before watching grants again
+// after a watch fails. The grants are polled once each time a watch fails.
+const grantsPollInterval = 5 * time.Second
+
+// syncGrants keeps the role bindings in the cluster up to date with the grants
+// for the destination. It watches the server for changes to the grants, and
+// falls back to polling them when the watch fails, for example because the
+// server does not support watching grants.
+func syncGrants(ctx context.Context, k8s *kubernetes.Kubernetes, client *api.Client, name string) {
+	var revision string
 
-		for _, n := range namespaces {
-			g, err := client.ListGrants(api.ListGrantsRequest{Resource: fmt.Sprintf("%s.%s", destination.Name, n)})
+	for {
+		err := client.WatchGrants(ctx, api.WatchGrantsRequest{Resource: name, Revision: revision}, func(resp api.WatchGrantsResponse) error {
+			namespaces, err := k8s.Namespaces()
 			if err != nil {
-				logging.S.Errorf("error listing grants: %v", err)
-				return
+				return fmt.Errorf("could not get kubernetes namespaces: %w", err)
 			}
 
-			grants.Items = append(grants.Items, g.Items...)
+			if err := updateRoles(client, k8s, name, namespaces, resp.Items); err != nil {
+				return fmt.Errorf("error updating grants: %w", err)
+			}
+
+			revision = resp.Revision
+			return nil
+		})
+
+		if ctx.Err() != nil {
+			return
 		}
 
-		err = updateRoles(client, k8s, grants.Items)
-		if err != nil {
-			logging.S.Errorf("error updating grants: %v", err)
+		if err == nil {
+			// the server ended the watch, resume it from the last revision
+			continue
+		}
+
+		logging.S.Warnf("watching grants failed, polling instead: %v", err)
+
+		// the role bindings no longer match revision, so the next watch
+		// starts from the current grants
+		revision = ""
+		if err := pollGrants(k8s, client, name); err != nil {
+			logging.S.Errorf("%v", err)
+		}
+
+		timer := time.NewTimer(grantsPollInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
 			return
+		case <-timer.C:
 		}
 	}
 }
 
+// pollGrants lists the grants for the destination and its namespaces, and
+// updates the role bindings in the cluster. All grants are listed so that
+// grants with wildcards are included.
+func pollGrants(k8s *kubernetes.Kubernetes, client *api.Client, name string) error {
+	namespaces, err := k8s.Namespaces()
+	if err != nil {
+		return fmt.Errorf("could not get kubernetes namespaces: %w", err)
+	}
+
+	grants, err := client.ListGrants(api.ListGrantsRequest{})
+	if err != nil {
+		return fmt.Errorf("error listing grants: %w", err)
+	}
+
+	if err := updateRoles(client, k8s, name, namespaces, grants.Items); err != nil {
+		return fmt.Errorf("error updating grants: %w", err)
+	}
+
+	return nil
+}
+
 // createOrUpdateDestination creates a destination in the infra server if it does not exist and updates it if it does
 func createOrUpdateDestination(client *api.Client, local *api.Destination) error {
 	if local.ID != 0 {
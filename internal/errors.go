@@ -15,4 +15,6 @@ var (
 	ErrNotFound       = fmt.Errorf("record not found")
 	ErrBadRequest     = fmt.Errorf("bad request")
 	ErrNotImplemented = fmt.Errorf("not implemented")
+	// ErrTooManyRequests means the request was rejected because too many similar requests failed
+	ErrTooManyRequests = fmt.Errorf("too many requests")
 )
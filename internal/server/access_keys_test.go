@@ -0,0 +1,270 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAPI_ScopedAccessKeys(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	// the signing key of destination tokens
+	_, err := data.InitializeSettings(srv.db)
+	assert.NilError(t, err)
+
+	user := &models.Identity{Name: "ci@example.com"}
+	assert.NilError(t, data.CreateIdentity(srv.db, user))
+	_, err = data.CreateProviderUser(srv.db, data.InfraProvider(srv.db), user)
+	assert.NilError(t, err)
+	assert.NilError(t, data.CreateGrant(srv.db, &models.Grant{
+		Subject:   uid.NewIdentityPolymorphicID(user.ID),
+		Privilege: models.InfraAdminRole,
+		Resource:  "infra",
+	}))
+
+	createKey := func(t *testing.T, key string, scopes ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		return callAPI(t, routes, http.MethodPost, "/api/access-keys", key, api.CreateAccessKeyRequest{
+			UserID:            user.ID,
+			TTL:               api.Duration(time.Hour),
+			ExtensionDeadline: api.Duration(time.Hour),
+			Scopes:            scopes,
+		})
+	}
+
+	scopedKey := func(t *testing.T, scopes ...string) string {
+		t.Helper()
+		resp := createKey(t, adminAccessKey(srv), scopes...)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var created api.CreateAccessKeyResponse
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &created))
+		assert.DeepEqual(t, created.Scopes, scopes)
+		return created.AccessKey
+	}
+
+	t.Run("read-only", func(t *testing.T) {
+		key := scopedKey(t, models.ScopeReadOnly)
+
+		resp := callAPI(t, routes, http.MethodGet, "/api/grants", key, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/groups", key, api.CreateGroupRequest{Name: "readers"})
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/tokens", key, nil)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("tokens", func(t *testing.T) {
+		key := scopedKey(t, models.ScopeTokens)
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/tokens", key, nil)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/grants", key, nil)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("permissions", func(t *testing.T) {
+		key := scopedKey(t, models.PermissionGrantsRead)
+
+		resp := callAPI(t, routes, http.MethodGet, "/api/grants", key, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/users", key, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		// the key is limited for its own identity too
+		resp = callAPI(t, routes, http.MethodGet, "/api/users/"+user.ID.String(), key, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/tokens", key, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/users/"+user.ID.String()+"/mfa", key, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("destinations", func(t *testing.T) {
+		for _, name := range []string{"production", "staging"} {
+			assert.NilError(t, data.CreateDestination(srv.db, &models.Destination{Name: name, UniqueID: name}))
+		}
+
+		key := scopedKey(t, models.ScopeDestinationPrefix+"production")
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/tokens", key, api.CreateTokenRequest{Destination: "production"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/tokens", key, api.CreateTokenRequest{Destination: "staging"})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/tokens", key, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/grants", key, nil)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("invalid scopes", func(t *testing.T) {
+		for _, scope := range []string{"everything", models.ScopePasswordReset, models.ScopeSCIM, models.ScopeDestinationPrefix} {
+			resp := createKey(t, adminAccessKey(srv), scope)
+			assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+		}
+	})
+
+	t.Run("keys created with a scoped key", func(t *testing.T) {
+		key := scopedKey(t, models.PermissionAccessKeysWrite, models.PermissionGrantsRead)
+
+		resp := createKey(t, key)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		resp = createKey(t, key, models.PermissionGrantsRead, models.PermissionUsersWrite)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		resp = createKey(t, key, models.PermissionGrantsRead)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+	})
+
+	t.Run("exchanged keys keep the scopes", func(t *testing.T) {
+		key := scopedKey(t, models.ScopeReadOnly)
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/login", "", api.LoginRequest{AccessKey: key})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var loginResp api.LoginResponse
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &loginResp))
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/groups", loginResp.AccessKey, api.CreateGroupRequest{Name: "readers"})
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("list shows scopes", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodGet, "/api/access-keys?user_id="+user.ID.String(), adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var keys api.ListResponse[api.AccessKey]
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &keys))
+		assert.Assert(t, keys.Count > 0)
+		for _, key := range keys.Items {
+			assert.Assert(t, len(key.Scopes) > 0, "key %v has no scopes", key.Name)
+		}
+	})
+}
+
+func TestAPI_RotateAccessKey(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	user := &models.Identity{Name: "deploy@example.com"}
+	assert.NilError(t, data.CreateIdentity(srv.db, user))
+
+	createKey := func(t *testing.T) api.CreateAccessKeyResponse {
+		t.Helper()
+		resp := callAPI(t, routes, http.MethodPost, "/api/access-keys", adminAccessKey(srv), api.CreateAccessKeyRequest{
+			UserID:            user.ID,
+			TTL:               api.Duration(time.Hour),
+			ExtensionDeadline: api.Duration(time.Hour),
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var created api.CreateAccessKeyResponse
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &created))
+		return created
+	}
+
+	rotate := func(t *testing.T, id uid.ID, key string, gracePeriod time.Duration) *httptest.ResponseRecorder {
+		t.Helper()
+		return callAPI(t, routes, http.MethodPost, "/api/access-keys/"+id.String()+"/rotate", key, api.RotateAccessKeyRequest{
+			GracePeriod: api.Duration(gracePeriod),
+		})
+	}
+
+	userPath := "/api/users/" + user.ID.String()
+
+	t.Run("rotate own key with a grace period", func(t *testing.T) {
+		created := createKey(t)
+
+		resp := rotate(t, created.ID, created.AccessKey, time.Hour)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var rotated api.CreateAccessKeyResponse
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &rotated))
+		assert.Equal(t, rotated.ID, created.ID)
+		assert.Equal(t, rotated.Name, created.Name)
+		assert.Assert(t, rotated.AccessKey != created.AccessKey)
+
+		resp = callAPI(t, routes, http.MethodGet, userPath, rotated.AccessKey, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, userPath, created.AccessKey, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+	})
+
+	t.Run("rotate without a grace period", func(t *testing.T) {
+		created := createKey(t)
+
+		resp := rotate(t, created.ID, adminAccessKey(srv), 0)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var rotated api.CreateAccessKeyResponse
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &rotated))
+
+		resp = callAPI(t, routes, http.MethodGet, userPath, rotated.AccessKey, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, userPath, created.AccessKey, nil)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("rotate with the previous secret", func(t *testing.T) {
+		created := createKey(t)
+
+		resp := rotate(t, created.ID, created.AccessKey, time.Hour)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var rotated api.CreateAccessKeyResponse
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &rotated))
+
+		resp = rotate(t, created.ID, created.AccessKey, time.Hour)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		// the current secret still works
+		resp = callAPI(t, routes, http.MethodGet, userPath, rotated.AccessKey, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+	})
+
+	t.Run("rotate a key of another user", func(t *testing.T) {
+		created := createKey(t)
+
+		adminKey, err := data.ValidateAccessKey(srv.db, adminAccessKey(srv))
+		assert.NilError(t, err)
+
+		resp := rotate(t, adminKey.ID, created.AccessKey, time.Hour)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("negative grace period", func(t *testing.T) {
+		created := createKey(t)
+
+		resp := rotate(t, created.ID, adminAccessKey(srv), -time.Hour)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("key does not exist", func(t *testing.T) {
+		resp := rotate(t, uid.New(), adminAccessKey(srv), time.Hour)
+		assert.Equal(t, resp.Code, http.StatusNotFound, resp.Body.String())
+	})
+}
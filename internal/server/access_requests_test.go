@@ -0,0 +1,167 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAPI_AccessRequests(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	var (
+		requester = &models.Identity{Name: "requester@example.com"}
+		approver  = &models.Identity{Name: "approver@example.com"}
+		other     = &models.Identity{Name: "other@example.com"}
+	)
+	createIdentities(t, srv.db, requester, approver, other)
+
+	approvers := &models.Group{Name: "approvers", Identities: []models.Identity{*approver}}
+	createGroups(t, srv.db, approvers)
+
+	accessKey := func(t *testing.T, identity *models.Identity) string {
+		t.Helper()
+		key, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+			IssuedFor:  identity.ID,
+			ProviderID: data.InfraProvider(srv.db).ID,
+			ExpiresAt:  time.Now().Add(time.Minute),
+		})
+		assert.NilError(t, err)
+		return key
+	}
+
+	var (
+		requesterKey = accessKey(t, requester)
+		approverKey  = accessKey(t, approver)
+		otherKey     = accessKey(t, other)
+	)
+
+	decode := func(t *testing.T, resp *httptest.ResponseRecorder, target interface{}) {
+		t.Helper()
+		err := json.NewDecoder(resp.Body).Decode(target)
+		assert.NilError(t, err)
+	}
+
+	// members of the approvers group may approve requests for production and
+	// any namespace within it
+	resp := callAPI(t, routes, http.MethodPost, "/api/access-approvers", requesterKey,
+		api.CreateAccessApproverRequest{Group: approvers.ID, Resource: "production"})
+	assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+	resp = callAPI(t, routes, http.MethodPost, "/api/access-approvers", adminAccessKey(srv),
+		api.CreateAccessApproverRequest{Group: approvers.ID, Resource: "production"})
+	assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+	var request api.AccessRequest
+	t.Run("create request", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/access-requests", requesterKey, api.CreateAccessRequestRequest{
+			Privilege: "cluster-admin",
+			Resource:  "production.payments",
+			Reason:    "incident",
+			Duration:  api.Duration(time.Hour),
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		decode(t, resp, &request)
+		assert.Equal(t, request.RequestedBy, requester.ID)
+		assert.Equal(t, request.Status, models.AccessRequestPending)
+		assert.Equal(t, request.Duration, api.Duration(time.Hour))
+	})
+
+	t.Run("list requests", func(t *testing.T) {
+		var requests api.ListResponse[api.AccessRequest]
+
+		resp := callAPI(t, routes, http.MethodGet, "/api/access-requests", approverKey, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		decode(t, resp, &requests)
+		assert.Equal(t, requests.Count, 1)
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/access-requests", requesterKey, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		decode(t, resp, &requests)
+		assert.Equal(t, requests.Count, 1)
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/access-requests", otherKey, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		decode(t, resp, &requests)
+		assert.Equal(t, requests.Count, 0)
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/access-requests/"+request.ID.String(), otherKey, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("approve without permission", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/access-requests/"+request.ID.String()+"/approve", otherKey, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/access-requests/"+request.ID.String()+"/approve", requesterKey, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("approve", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/access-requests/"+request.ID.String()+"/approve", approverKey, nil)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var approved api.AccessRequest
+		decode(t, resp, &approved)
+		assert.Equal(t, approved.Status, models.AccessRequestApproved)
+		assert.Equal(t, approved.ReviewedBy, approver.ID)
+		assert.Assert(t, approved.GrantID != 0)
+
+		grant, err := data.GetGrant(srv.db, data.ByID(approved.GrantID))
+		assert.NilError(t, err)
+		assert.Equal(t, grant.Subject, requester.PolyID())
+		assert.Equal(t, grant.Privilege, "cluster-admin")
+		assert.Equal(t, grant.Resource, "production.payments")
+		assert.Equal(t, grant.CreatedBy, approver.ID)
+		assert.Assert(t, grant.ExpiresAt != nil)
+		assert.Assert(t, time.Until(*grant.ExpiresAt) > 59*time.Minute)
+
+		// a request can only be reviewed once
+		resp = callAPI(t, routes, http.MethodPost, "/api/access-requests/"+request.ID.String()+"/deny", approverKey, nil)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("deny", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/access-requests", requesterKey, api.CreateAccessRequestRequest{
+			Privilege: "cluster-admin",
+			Resource:  "staging",
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var created api.AccessRequest
+		decode(t, resp, &created)
+
+		// the approvers group can not approve requests for other resources
+		resp = callAPI(t, routes, http.MethodPost, "/api/access-requests/"+created.ID.String()+"/deny", approverKey, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/access-requests/"+created.ID.String()+"/deny", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var denied api.AccessRequest
+		decode(t, resp, &denied)
+		assert.Equal(t, denied.Status, models.AccessRequestDenied)
+		assert.Equal(t, denied.GrantID, uid.ID(0))
+
+		grants, err := data.ListGrants(srv.db, data.BySubject(requester.PolyID()), data.ByResource("staging"))
+		assert.NilError(t, err)
+		assert.Equal(t, len(grants), 0)
+	})
+
+	t.Run("unknown request", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/access-requests/"+uid.New().String()+"/approve", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusNotFound, resp.Body.String())
+	})
+}
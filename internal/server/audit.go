@@ -0,0 +1,178 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+type auditSnapshotFunc func(db *gorm.DB, id uid.ID) (interface{}, error)
+
+// auditSnapshots load the current state of a resource for the audit log. They
+// are keyed by the kind of resource, which is taken from the route path. The
+// snapshots use the API representation of the resource so that secrets are
+// never written to the audit log.
+var auditSnapshots = map[string]auditSnapshotFunc{
+	"users":            auditSnapshot(data.GetIdentity, (*models.Identity).ToAPI),
+	"groups":           auditSnapshot(data.GetGroup, (*models.Group).ToAPI),
+	"grants":           auditSnapshot(data.GetGrant, (*models.Grant).ToAPI),
+	"providers":        auditSnapshot(data.GetProvider, (*models.Provider).ToAPI),
+	"destinations":     auditSnapshot(data.GetDestination, (*models.Destination).ToAPI),
+	"access-keys":      auditSnapshot(data.GetAccessKey, (*models.AccessKey).ToAPI),
+	"access-requests":  auditSnapshot(data.GetAccessRequest, (*models.AccessRequest).ToAPI),
+	"access-approvers": auditSnapshot(data.GetAccessApprover, (*models.AccessApprover).ToAPI),
+	"webhooks":         auditSnapshot(data.GetWebhook, (*models.Webhook).ToAPI),
+	"roles":            auditSnapshot(data.GetRole, (*models.Role).ToAPI),
+	"service-accounts": auditSnapshot(data.GetIdentity, (*models.Identity).ToAPI),
+}
+
+func auditSnapshot[M, R any](get func(*gorm.DB, ...data.SelectorFunc) (*M, error), toAPI func(*M) *R) auditSnapshotFunc {
+	return func(db *gorm.DB, id uid.ID) (interface{}, error) {
+		model, err := get(db, data.ByID(id))
+		if err != nil {
+			return nil, err
+		}
+		return toAPI(model), nil
+	}
+}
+
+// auditRecord accumulates the details of a mutating API request so that it can
+// be written to the audit log once the response has been sent.
+type auditRecord struct {
+	event    models.AuditEvent
+	snapshot auditSnapshotFunc
+}
+
+// startAudit begins an audit record for the request. It must be called before
+// the route handler so that the state of the target can be captured before
+// it is modified.
+func startAudit(c *gin.Context, method, routePath string) *auditRecord {
+	record := &auditRecord{
+		event: models.AuditEvent{
+			Method:     method,
+			Route:      routePath,
+			Path:       c.Request.URL.Path,
+			TargetKind: auditTargetKind(routePath),
+		},
+	}
+	record.snapshot = auditSnapshots[record.event.TargetKind]
+
+	if id, err := uid.Parse([]byte(c.Param("id"))); err == nil {
+		record.event.TargetID = id
+	}
+
+	db := auditDB(c)
+	if db != nil && record.snapshot != nil && record.event.TargetID != 0 {
+		record.event.Before = record.takeSnapshot(db)
+	}
+
+	return record
+}
+
+// setTarget records the ID of a resource created by the request, which is
+// not available from the route path.
+func (r *auditRecord) setTarget(resp interface{}) {
+	if r.event.TargetID != 0 {
+		return
+	}
+
+	v := reflect.Indirect(reflect.ValueOf(resp))
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	if f := v.FieldByName("ID"); f.IsValid() {
+		switch id := f.Interface().(type) {
+		case uid.ID:
+			r.event.TargetID = id
+		case string: // SCIM resources
+			if parsed, err := uid.Parse([]byte(id)); err == nil {
+				r.event.TargetID = parsed
+			}
+		}
+	}
+}
+
+// finish writes the audit record to the database. It must be called after the
+// response status has been written.
+func (r *auditRecord) finish(c *gin.Context) {
+	db := auditDB(c)
+	if db == nil {
+		return
+	}
+
+	r.event.ResultCode = c.Writer.Status()
+
+	if identity := access.AuthenticatedIdentity(c); identity != nil {
+		r.event.ActorID = identity.ID
+		r.event.ActorName = identity.Name
+		r.event.ActorKind = identity.Kind
+	}
+
+	if key := access.AuthenticatedAccessKey(c); key != nil {
+		r.event.AccessKeyID = key.ID
+	}
+
+	succeeded := r.event.ResultCode >= 200 && r.event.ResultCode < 300
+	if succeeded && r.event.Method != http.MethodDelete && r.snapshot != nil && r.event.TargetID != 0 {
+		r.event.After = r.takeSnapshot(db)
+	}
+
+	if err := data.CreateAuditEvent(db, &r.event); err != nil {
+		logging.S.Errorf("failed to write audit event for %s %s: %s", r.event.Method, r.event.Path, err)
+	}
+}
+
+func (r *auditRecord) takeSnapshot(db *gorm.DB) string {
+	value, err := r.snapshot(db, r.event.TargetID)
+	if err != nil {
+		logging.S.Debugf("audit snapshot of %s %s: %s", r.event.TargetKind, r.event.TargetID, err)
+		return ""
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		logging.S.Debugf("audit snapshot of %s %s: %s", r.event.TargetKind, r.event.TargetID, err)
+		return ""
+	}
+
+	return string(raw)
+}
+
+// auditTargetKind returns the kind of resource from a route path, for example
+// "grants" from /api/grants/:id, or "users" from /scim/v2/Users/:id.
+func auditTargetKind(routePath string) string {
+	parts := strings.Split(strings.Trim(routePath, "/"), "/")
+	if parts[0] == "scim" {
+		if len(parts) < 3 {
+			return ""
+		}
+		return strings.ToLower(parts[2])
+	}
+
+	if len(parts) < 2 {
+		return ""
+	}
+
+	return parts[1]
+}
+
+func auditDB(c *gin.Context) *gorm.DB {
+	raw, ok := c.Get("db")
+	if !ok {
+		return nil
+	}
+
+	db, _ := raw.(*gorm.DB)
+	return db
+}
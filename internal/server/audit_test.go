@@ -0,0 +1,164 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAPI_AuditEvents(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	admin, err := data.GetIdentity(srv.db, data.ByName("admin@example.com"))
+	assert.NilError(t, err)
+
+	user := &models.Identity{Name: "someone@example.com"}
+	createIdentities(t, srv.db, user)
+
+	// create and then delete a grant to produce some audit events
+	body := jsonBody(t, api.CreateGrantRequest{User: user.ID, Privilege: "view", Resource: "res1"})
+	req := httptest.NewRequest(http.MethodPost, "/api/grants", body)
+	req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+	req.Header.Set("Infra-Version", "0.13.0")
+	resp := httptest.NewRecorder()
+	routes.ServeHTTP(resp, req)
+	assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+	var grant api.Grant
+	err = json.NewDecoder(resp.Body).Decode(&grant)
+	assert.NilError(t, err)
+
+	req = httptest.NewRequest(http.MethodDelete, "/api/grants/"+grant.ID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+	req.Header.Set("Infra-Version", "0.13.0")
+	resp = httptest.NewRecorder()
+	routes.ServeHTTP(resp, req)
+	assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+	// a failed request is recorded as well
+	req = httptest.NewRequest(http.MethodDelete, "/api/grants/"+uid.New().String(), nil)
+	req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+	req.Header.Set("Infra-Version", "0.13.0")
+	resp = httptest.NewRecorder()
+	routes.ServeHTTP(resp, req)
+	assert.Equal(t, resp.Code, http.StatusNotFound, resp.Body.String())
+
+	userKey, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+		IssuedFor:  user.ID,
+		ProviderID: data.InfraProvider(srv.db).ID,
+		ExpiresAt:  time.Now().Add(time.Minute),
+	})
+	assert.NilError(t, err)
+
+	type testCase struct {
+		urlPath  string
+		key      string
+		expected func(t *testing.T, resp *httptest.ResponseRecorder)
+	}
+
+	run := func(t *testing.T, tc testCase) {
+		req := httptest.NewRequest(http.MethodGet, tc.urlPath, nil)
+		req.Header.Set("Authorization", "Bearer "+tc.key)
+		req.Header.Set("Infra-Version", "0.13.0")
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+
+		tc.expected(t, resp)
+	}
+
+	decode := func(t *testing.T, resp *httptest.ResponseRecorder) []api.AuditEvent {
+		t.Helper()
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		var actual api.ListResponse[api.AuditEvent]
+		err := json.NewDecoder(resp.Body).Decode(&actual)
+		assert.NilError(t, err)
+		return actual.Items
+	}
+
+	testCases := map[string]testCase{
+		"not authorized": {
+			urlPath: "/api/audit-events",
+			key:     userKey,
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+			},
+		},
+		"filter by resource": {
+			urlPath: "/api/audit-events?resource=grants",
+			key:     adminAccessKey(srv),
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				events := decode(t, resp)
+				assert.Equal(t, len(events), 3)
+
+				// newest first
+				failed, deleted, created := events[0], events[1], events[2]
+
+				assert.Equal(t, failed.ResultCode, http.StatusNotFound)
+				assert.Equal(t, failed.Before, "")
+
+				assert.Equal(t, deleted.Method, http.MethodDelete)
+				assert.Equal(t, deleted.Route, "/api/grants/:id")
+				assert.Equal(t, deleted.ResourceID, grant.ID)
+				assert.Equal(t, deleted.ResultCode, http.StatusNoContent)
+				assert.Equal(t, deleted.Actor, admin.ID)
+				assert.Equal(t, deleted.ActorName, "admin@example.com")
+				assert.Assert(t, deleted.AccessKeyID != 0)
+				assert.Assert(t, deleted.Before != "")
+				assert.Equal(t, deleted.After, "")
+
+				assert.Equal(t, created.Method, http.MethodPost)
+				assert.Equal(t, created.ResourceID, grant.ID)
+				assert.Equal(t, created.ResultCode, http.StatusCreated)
+				assert.Equal(t, created.Before, "")
+
+				var after api.Grant
+				err := json.Unmarshal([]byte(created.After), &after)
+				assert.NilError(t, err)
+				assert.Equal(t, after.Privilege, "view")
+				assert.Equal(t, after.User, user.ID)
+			},
+		},
+		"filter by resource ID": {
+			urlPath: "/api/audit-events?resourceID=" + grant.ID.String(),
+			key:     adminAccessKey(srv),
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				events := decode(t, resp)
+				assert.Equal(t, len(events), 2)
+			},
+		},
+		"filter by actor": {
+			urlPath: "/api/audit-events?actor=" + user.ID.String(),
+			key:     adminAccessKey(srv),
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				events := decode(t, resp)
+				assert.Equal(t, len(events), 0)
+			},
+		},
+		"filter by time": {
+			urlPath: "/api/audit-events?after=" + time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+			key:     adminAccessKey(srv),
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				events := decode(t, resp)
+				assert.Equal(t, len(events), 0)
+			},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			run(t, tc)
+		})
+	}
+}
@@ -19,9 +19,13 @@ type LoginMethod interface {
 
 type AuthScope struct {
 	PasswordResetOnly bool
+	MFAEnrollmentOnly bool
+	// KeyScopes are added to the scopes of the new access key, so that an
+	// exchanged access key is limited like the key it was exchanged for.
+	KeyScopes []string
 }
 
-func Login(ctx context.Context, db *gorm.DB, loginMethod LoginMethod, keyExpiresAt time.Time, keyExtension time.Duration) (*models.AccessKey, string, error) {
+func Login(ctx context.Context, db *gorm.DB, loginMethod LoginMethod, keyExpiresAt time.Time, keyExtension time.Duration, clientName string) (*models.AccessKey, string, error) {
 	// challenge the user to authenticate
 	identity, provider, scope, err := loginMethod.Authenticate(ctx, db)
 	if err != nil {
@@ -37,12 +41,21 @@ func Login(ctx context.Context, db *gorm.DB, loginMethod LoginMethod, keyExpires
 		ExpiresAt:         keyExpiresAt,
 		ExtensionDeadline: time.Now().UTC().Add(keyExtension),
 		Extension:         keyExtension,
+		Session:           true,
+		ClientName:        clientName,
+		LastUsedAt:        time.Now().UTC(),
 	}
 
 	if scope.PasswordResetOnly {
 		accessKey.Scopes = append(accessKey.Scopes, models.ScopePasswordReset)
 	}
 
+	if scope.MFAEnrollmentOnly {
+		accessKey.Scopes = append(accessKey.Scopes, models.ScopeMFAEnrollment)
+	}
+
+	accessKey.Scopes = append(accessKey.Scopes, scope.KeyScopes...)
+
 	bearer, err := data.CreateAccessKey(db, accessKey)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create access key after login: %w", err)
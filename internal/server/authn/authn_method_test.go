@@ -52,23 +52,25 @@ func TestLogin(t *testing.T) {
 	assert.NilError(t, err)
 
 	t.Run("failed login does not create access key", func(t *testing.T) {
-		authn := NewPasswordCredentialAuthentication(username, "invalid password")
-		_, bearer, err := Login(ctx, db, authn, time.Now().Add(1*time.Minute), time.Minute)
+		authn := NewPasswordCredentialAuthentication(username, "invalid password", "", PasswordLoginOptions{})
+		_, bearer, err := Login(ctx, db, authn, time.Now().Add(1*time.Minute), time.Minute, "")
 
 		assert.ErrorContains(t, err, "failed to login")
 		assert.Equal(t, bearer, "")
 	})
 
 	t.Run("successful login does creates access key for authenticated identity", func(t *testing.T) {
-		authn := NewPasswordCredentialAuthentication("gohan@example.com", password)
+		authn := NewPasswordCredentialAuthentication("gohan@example.com", password, "", PasswordLoginOptions{})
 		exp := time.Now().Add(1 * time.Minute)
 		ext := 1 * time.Minute
-		key, bearer, err := Login(ctx, db, authn, exp, ext)
+		key, bearer, err := Login(ctx, db, authn, exp, ext, "cli 0.13.4 (linux/amd64)")
 
 		assert.NilError(t, err)
 		assert.Assert(t, bearer != "")
 		assert.Equal(t, key.IssuedFor, user.ID)
 		assert.Equal(t, key.ExpiresAt, exp)
 		assert.Equal(t, key.Extension, ext)
+		assert.Assert(t, key.Session)
+		assert.Equal(t, key.ClientName, "cli 0.13.4 (linux/amd64)")
 	})
 }
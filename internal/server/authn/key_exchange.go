@@ -40,7 +40,7 @@ func (a *keyExchangeAuthn) Authenticate(_ context.Context, db *gorm.DB) (*models
 		return nil, nil, AuthScope{}, fmt.Errorf("user is not valid: %w", err) // the user was probably deleted
 	}
 
-	return identity, data.InfraProvider(db), AuthScope{}, nil
+	return identity, data.InfraProvider(db), AuthScope{KeyScopes: validatedRequestKey.Scopes}, nil
 }
 
 func (a *keyExchangeAuthn) Name() string {
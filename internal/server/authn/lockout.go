@@ -0,0 +1,134 @@
+package authn
+
+import (
+	"sync"
+	"time"
+
+	"github.com/infrahq/infra/internal"
+)
+
+// LockoutPolicy limits failed password logins. After each failed login the
+// next login is not allowed for Delay, which doubles with each failure. After
+// Attempts failed logins, logins are not allowed for Duration. A zero
+// LockoutPolicy does not limit logins.
+type LockoutPolicy struct {
+	Attempts int
+	Delay    time.Duration
+	Duration time.Duration
+}
+
+// failed returns the count of failed logins, and the time logins are locked
+// until, after another failed login at now. Failures are forgotten once logins
+// have been allowed again for Duration.
+func (p LockoutPolicy) failed(count int, lockedUntil, now time.Time) (int, time.Time) {
+	if p.Attempts <= 0 {
+		return count, lockedUntil
+	}
+
+	if !lockedUntil.IsZero() && now.After(lockedUntil.Add(p.Duration)) {
+		count = 0
+	}
+
+	count++
+	if count >= p.Attempts {
+		return count, now.Add(p.Duration)
+	}
+
+	delay := p.Delay
+	for i := 1; i < count && delay < p.Duration; i++ {
+		delay *= 2
+	}
+
+	if delay > p.Duration {
+		delay = p.Duration
+	}
+
+	return count, now.Add(delay)
+}
+
+// maxLoginLimiterIPs is the number of source IPs a LoginLimiter tracks failed
+// logins for.
+const maxLoginLimiterIPs = 10000
+
+// LoginLimiter tracks failed password logins from each source IP, and limits
+// the logins from an IP using a LockoutPolicy. At most maxIPs are tracked,
+// once there are more the IP that was locked until the earliest time is
+// forgotten.
+type LoginLimiter struct {
+	policy LockoutPolicy
+	now    func() time.Time
+	maxIPs int
+
+	mu       sync.Mutex
+	failures map[string]*ipFailures
+}
+
+type ipFailures struct {
+	count       int
+	lockedUntil time.Time
+}
+
+func NewLoginLimiter(policy LockoutPolicy) *LoginLimiter {
+	return &LoginLimiter{
+		policy:   policy,
+		now:      time.Now,
+		maxIPs:   maxLoginLimiterIPs,
+		failures: make(map[string]*ipFailures),
+	}
+}
+
+// Allow returns an error if logins from ip are not allowed.
+func (l *LoginLimiter) Allow(ip string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, ok := l.failures[ip]
+	if ok && l.now().Before(f.lockedUntil) {
+		return internal.ErrTooManyRequests
+	}
+
+	return nil
+}
+
+// Failed records a failed login from ip.
+func (l *LoginLimiter) Failed(ip string) {
+	if l.policy.Attempts <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	// forget the IPs that have been allowed to login again for long enough
+	for key, f := range l.failures {
+		if now.After(f.lockedUntil.Add(l.policy.Duration)) {
+			delete(l.failures, key)
+		}
+	}
+
+	f, ok := l.failures[ip]
+	if !ok {
+		if len(l.failures) >= l.maxIPs {
+			l.forgetEarliest()
+		}
+
+		f = &ipFailures{}
+		l.failures[ip] = f
+	}
+
+	f.count, f.lockedUntil = l.policy.failed(f.count, f.lockedUntil, now)
+}
+
+// forgetEarliest removes the IP that is locked until the earliest time.
+func (l *LoginLimiter) forgetEarliest() {
+	var earliest string
+	for key, f := range l.failures {
+		if earliest == "" || f.lockedUntil.Before(l.failures[earliest].lockedUntil) {
+			earliest = key
+		}
+	}
+
+	delete(l.failures, earliest)
+}
@@ -0,0 +1,129 @@
+package authn
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestLockoutPolicy_Failed(t *testing.T) {
+	policy := LockoutPolicy{Attempts: 4, Delay: time.Second, Duration: time.Minute}
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	var count int
+	var lockedUntil time.Time
+
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, time.Minute, time.Minute}
+	for i, delay := range expected {
+		count, lockedUntil = policy.failed(count, lockedUntil, now)
+		assert.Equal(t, count, i+1)
+		assert.Equal(t, lockedUntil, now.Add(delay))
+	}
+
+	t.Run("failures are forgotten", func(t *testing.T) {
+		later := lockedUntil.Add(policy.Duration + time.Second)
+		count, lockedUntil := policy.failed(count, lockedUntil, later)
+		assert.Equal(t, count, 1)
+		assert.Equal(t, lockedUntil, later.Add(time.Second))
+	})
+
+	t.Run("zero policy", func(t *testing.T) {
+		count, lockedUntil := LockoutPolicy{}.failed(0, time.Time{}, now)
+		assert.Equal(t, count, 0)
+		assert.Assert(t, lockedUntil.IsZero())
+	})
+}
+
+func TestLoginLimiter(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewLoginLimiter(LockoutPolicy{Attempts: 2, Delay: time.Second, Duration: time.Minute})
+	limiter.now = func() time.Time { return now }
+
+	assert.NilError(t, limiter.Allow("10.0.0.1"))
+
+	limiter.Failed("10.0.0.1")
+	assert.Assert(t, errors.Is(limiter.Allow("10.0.0.1"), internal.ErrTooManyRequests))
+	assert.NilError(t, limiter.Allow("10.0.0.2"))
+
+	now = now.Add(2 * time.Second)
+	assert.NilError(t, limiter.Allow("10.0.0.1"))
+
+	limiter.Failed("10.0.0.1")
+	now = now.Add(30 * time.Second)
+	assert.Assert(t, errors.Is(limiter.Allow("10.0.0.1"), internal.ErrTooManyRequests))
+
+	now = now.Add(time.Minute)
+	assert.NilError(t, limiter.Allow("10.0.0.1"))
+
+	// failures are forgotten after the IP has been allowed for Duration
+	now = now.Add(time.Minute)
+	limiter.Failed("10.0.0.2")
+	_, ok := limiter.failures["10.0.0.1"]
+	assert.Assert(t, !ok)
+
+	t.Run("the number of IPs is limited", func(t *testing.T) {
+		limiter := NewLoginLimiter(LockoutPolicy{Attempts: 5, Delay: time.Second, Duration: time.Minute})
+		limiter.now = func() time.Time { return now }
+		limiter.maxIPs = 2
+
+		limiter.Failed("10.0.0.1")
+		limiter.Failed("10.0.0.1")
+		limiter.Failed("10.0.0.2")
+		limiter.Failed("10.0.0.3")
+		assert.Equal(t, len(limiter.failures), 2)
+
+		_, ok := limiter.failures["10.0.0.2"]
+		assert.Assert(t, !ok)
+		assert.Assert(t, errors.Is(limiter.Allow("10.0.0.1"), internal.ErrTooManyRequests))
+	})
+}
+
+func TestPasswordCredentialAuthentication_Lockout(t *testing.T) {
+	db := setupDB(t)
+
+	user := &models.Identity{Name: "vegeta@example.com"}
+	assert.NilError(t, data.CreateIdentity(db, user))
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	assert.NilError(t, err)
+	assert.NilError(t, data.CreateCredential(db, &models.Credential{IdentityID: user.ID, PasswordHash: hash}))
+
+	policy := LockoutPolicy{Attempts: 2, Delay: time.Millisecond, Duration: time.Hour}
+	login := func(password string) error {
+		_, _, _, err := NewPasswordCredentialAuthentication(user.Name, password, "", PasswordLoginOptions{Lockout: policy}).Authenticate(context.Background(), db)
+		return err
+	}
+
+	assert.ErrorContains(t, login("wrong-password"), "could not verify password")
+	identity, err := data.GetIdentity(db, data.ByID(user.ID))
+	assert.NilError(t, err)
+	assert.Equal(t, identity.FailedLoginAttempts, 1)
+
+	time.Sleep(2 * time.Millisecond)
+	assert.ErrorContains(t, login("wrong-password"), "could not verify password")
+
+	// the correct password is rejected while locked out
+	err = login("password123")
+	assert.Assert(t, errors.Is(err, ErrLockedOut), err)
+
+	identity, err = data.GetIdentity(db, data.ByID(user.ID))
+	assert.NilError(t, err)
+	assert.Equal(t, identity.FailedLoginAttempts, 2)
+
+	identity.LockedUntil = time.Now().Add(-time.Second)
+	assert.NilError(t, data.SaveIdentity(db, identity))
+
+	assert.NilError(t, login("password123"))
+	identity, err = data.GetIdentity(db, data.ByID(user.ID))
+	assert.NilError(t, err)
+	assert.Equal(t, identity.FailedLoginAttempts, 0)
+	assert.Assert(t, identity.LockedUntil.IsZero())
+}
@@ -59,6 +59,10 @@ func (a *oidcAuthn) Authenticate(ctx context.Context, db *gorm.DB) (*models.Iden
 		}
 	}
 
+	if identity.IsServiceAccount() {
+		return nil, nil, AuthScope{}, errServiceAccountLogin
+	}
+
 	providerUser, err := data.CreateProviderUser(db, provider, identity)
 	if err != nil {
 		return nil, nil, AuthScope{}, fmt.Errorf("add user for provider login: %w", err)
@@ -2,25 +2,60 @@ package authn
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
 )
 
+// ErrLockedOut is returned when password login is not allowed for an identity
+// because of too many failed logins.
+var ErrLockedOut = errors.New("locked out after too many failed logins")
+
+// ErrMFARequired is returned when the password of a user is valid, but the user
+// has enabled multi-factor authentication and did not send a code.
+var ErrMFARequired = errors.New("multi-factor authentication code required")
+
+var errInvalidMFACode = errors.New("could not verify multi-factor authentication code")
+
+// errServiceAccountLogin is returned when a service account attempts to login
+// with anything other than an access key.
+var errServiceAccountLogin = errors.New("service accounts can only authenticate with access keys")
+
+// PasswordLoginOptions configure password logins.
+type PasswordLoginOptions struct {
+	Lockout LockoutPolicy
+	// RequireAdminMFA limits the logins of users with the admin role who have
+	// not enabled multi-factor authentication to enabling it.
+	RequireAdminMFA bool
+	// Now returns the current time. It defaults to time.Now.
+	Now func() time.Time
+}
+
 // passwordCredentialAuthn allows presenting username/password credentials in exchange for an access key
 type passwordCredentialAuthn struct {
 	Username string
 	Password string
+	MFACode  string
+	Options  PasswordLoginOptions
 }
 
-func NewPasswordCredentialAuthentication(username, password string) LoginMethod {
+func NewPasswordCredentialAuthentication(username, password, mfaCode string, opts PasswordLoginOptions) LoginMethod {
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+
 	return &passwordCredentialAuthn{
 		Username: username,
 		Password: password,
+		MFACode:  mfaCode,
+		Options:  opts,
 	}
 }
 
@@ -31,6 +66,15 @@ func (a *passwordCredentialAuthn) Authenticate(_ context.Context, db *gorm.DB) (
 		return nil, nil, scope, fmt.Errorf("could not get identity for username: %w", err)
 	}
 
+	if identity.IsServiceAccount() {
+		return nil, nil, scope, errServiceAccountLogin
+	}
+
+	now := a.Options.Now()
+	if now.Before(identity.LockedUntil) {
+		return nil, nil, scope, fmt.Errorf("%w: identity is locked until %s", ErrLockedOut, identity.LockedUntil.Format(time.RFC3339))
+	}
+
 	// Infra users can have only one username/password combo, look it up
 	userCredential, err := data.GetCredential(db, data.ByIdentityID(identity.ID))
 	if err != nil {
@@ -41,18 +85,102 @@ func (a *passwordCredentialAuthn) Authenticate(_ context.Context, db *gorm.DB) (
 	err = bcrypt.CompareHashAndPassword(userCredential.PasswordHash, []byte(a.Password))
 	if err != nil {
 		// this probably means the password was wrong
+		if err := a.failed(db, identity, now); err != nil {
+			return nil, nil, scope, err
+		}
+
 		return nil, nil, scope, fmt.Errorf("could not verify password: %w", err)
 	}
 
+	if err := verifySecondFactor(db, userCredential, a.MFACode, now); err != nil {
+		if errors.Is(err, errInvalidMFACode) {
+			if err := a.failed(db, identity, now); err != nil {
+				return nil, nil, scope, err
+			}
+		}
+
+		return nil, nil, scope, err
+	}
+
+	if identity.FailedLoginAttempts > 0 {
+		identity.FailedLoginAttempts, identity.LockedUntil = 0, time.Time{}
+		if err := data.SaveIdentity(db, identity); err != nil {
+			return nil, nil, scope, fmt.Errorf("reset failed logins: %w", err)
+		}
+	}
+
 	if userCredential.OneTimePassword {
 		// scope the login down to Password Reset Only
 		scope.PasswordResetOnly = true
 	}
 
+	if a.Options.RequireAdminMFA && !userCredential.TOTPConfirmed {
+		admin, err := isInfraAdmin(db, identity)
+		if err != nil {
+			return nil, nil, scope, err
+		}
+
+		// scope the login down to enabling multi-factor authentication
+		scope.MFAEnrollmentOnly = admin
+	}
+
 	// authentication was a success
 	return identity, data.InfraProvider(db), scope, nil // password login is always for infra users
 }
 
+// failed records a failed login of identity.
+func (a *passwordCredentialAuthn) failed(db *gorm.DB, identity *models.Identity, now time.Time) error {
+	identity.FailedLoginAttempts, identity.LockedUntil = a.Options.Lockout.failed(identity.FailedLoginAttempts, identity.LockedUntil, now)
+	if err := data.SaveIdentity(db, identity); err != nil {
+		return fmt.Errorf("save failed login: %w", err)
+	}
+
+	return nil
+}
+
+// verifySecondFactor checks the multi-factor authentication code of the user,
+// if the user has enabled it.
+func verifySecondFactor(db *gorm.DB, credential *models.Credential, code string, now time.Time) error {
+	if !credential.TOTPConfirmed {
+		return nil
+	}
+
+	if code == "" {
+		return ErrMFARequired
+	}
+
+	if !VerifyMFA(credential, code, now) {
+		return errInvalidMFACode
+	}
+
+	if err := data.SaveCredential(db, credential); err != nil {
+		return fmt.Errorf("save used mfa code: %w", err)
+	}
+
+	return nil
+}
+
+// isInfraAdmin returns true if the identity, or one of its groups, has the
+// admin role for the infra API.
+func isInfraAdmin(db *gorm.DB, identity *models.Identity) (bool, error) {
+	groups, err := data.ListGroups(db, data.ByGroupMember(identity.ID))
+	if err != nil {
+		return false, fmt.Errorf("list groups: %w", err)
+	}
+
+	subjects := []uid.PolymorphicID{identity.PolyID()}
+	for _, group := range groups {
+		subjects = append(subjects, group.PolyID())
+	}
+
+	grants, err := data.ListGrants(db, data.BySubjects(subjects), data.ByPrivilege(models.InfraAdminRole), data.ByResource("infra"), data.ByNotExpired())
+	if err != nil {
+		return false, fmt.Errorf("list grants: %w", err)
+	}
+
+	return len(grants) > 0, nil
+}
+
 func (a *passwordCredentialAuthn) Name() string {
 	return "credentials"
 }
@@ -3,6 +3,7 @@ package authn
 import (
 	"context"
 	"testing"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -36,7 +37,7 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 				err = data.CreateCredential(db, &creds)
 				assert.NilError(t, err)
 
-				return NewPasswordCredentialAuthentication(username, oneTimePassword)
+				return NewPasswordCredentialAuthentication(username, oneTimePassword, "", PasswordLoginOptions{})
 			},
 			"verify": func(t *testing.T, identity *models.Identity, provider *models.Provider, err error) {
 				assert.NilError(t, err)
@@ -64,7 +65,7 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 				err = data.CreateCredential(db, &creds)
 				assert.NilError(t, err)
 
-				return NewPasswordCredentialAuthentication(username, password)
+				return NewPasswordCredentialAuthentication(username, password, "", PasswordLoginOptions{})
 			},
 			"verify": func(t *testing.T, identity *models.Identity, provider *models.Provider, err error) {
 				assert.NilError(t, err)
@@ -92,7 +93,7 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 				err = data.CreateCredential(db, &creds)
 				assert.NilError(t, err)
 
-				userPassLogin := NewPasswordCredentialAuthentication(username, password)
+				userPassLogin := NewPasswordCredentialAuthentication(username, password, "", PasswordLoginOptions{})
 
 				_, _, _, err = userPassLogin.Authenticate(context.Background(), db)
 				assert.NilError(t, err)
@@ -112,7 +113,7 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 				err := data.CreateIdentity(db, user)
 				assert.NilError(t, err)
 
-				return NewPasswordCredentialAuthentication(username, "")
+				return NewPasswordCredentialAuthentication(username, "", "", PasswordLoginOptions{})
 			},
 			"verify": func(t *testing.T, identity *models.Identity, provider *models.Provider, err error) {
 				assert.ErrorContains(t, err, "record not found")
@@ -138,7 +139,7 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 				err = data.CreateCredential(db, &creds)
 				assert.NilError(t, err)
 
-				return NewPasswordCredentialAuthentication(username, "invalidPassword")
+				return NewPasswordCredentialAuthentication(username, "invalidPassword", "", PasswordLoginOptions{})
 			},
 			"verify": func(t *testing.T, identity *models.Identity, provider *models.Provider, err error) {
 				assert.ErrorContains(t, err, "hashedPassword is not the hash of the given password")
@@ -164,15 +165,35 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 				err = data.CreateCredential(db, &creds)
 				assert.NilError(t, err)
 
-				return NewPasswordCredentialAuthentication(username, "")
+				return NewPasswordCredentialAuthentication(username, "", "", PasswordLoginOptions{})
 			},
 			"verify": func(t *testing.T, identity *models.Identity, provider *models.Provider, err error) {
 				assert.ErrorContains(t, err, "hashedPassword is not the hash of the given password")
 			},
 		},
+		"ServiceAccountFails": {
+			"setup": func(t *testing.T, db *gorm.DB) LoginMethod {
+				username := "shenron"
+				serviceAccount := &models.Identity{Name: username, Kind: models.ServiceAccountKind}
+				err := data.CreateIdentity(db, serviceAccount)
+				assert.NilError(t, err)
+
+				password := "password123"
+				hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+				assert.NilError(t, err)
+
+				err = data.CreateCredential(db, &models.Credential{IdentityID: serviceAccount.ID, PasswordHash: hash})
+				assert.NilError(t, err)
+
+				return NewPasswordCredentialAuthentication(username, password, "", PasswordLoginOptions{})
+			},
+			"verify": func(t *testing.T, identity *models.Identity, provider *models.Provider, err error) {
+				assert.ErrorIs(t, err, errServiceAccountLogin)
+			},
+		},
 		"EmptyUsernameAndPasswordFails": {
 			"setup": func(t *testing.T, db *gorm.DB) LoginMethod {
-				return NewPasswordCredentialAuthentication("", "whatever")
+				return NewPasswordCredentialAuthentication("", "whatever", "", PasswordLoginOptions{})
 			},
 			"verify": func(t *testing.T, identity *models.Identity, provider *models.Provider, err error) {
 				assert.ErrorContains(t, err, "record not found")
@@ -195,3 +216,105 @@ func TestPasswordCredentialAuthentication(t *testing.T) {
 		})
 	}
 }
+
+func TestPasswordCredentialAuthentication_MFA(t *testing.T) {
+	db := setupDB(t)
+	now := time.Unix(1234567890, 0)
+
+	createUser := func(t *testing.T, name string, credential models.Credential) *models.Identity {
+		t.Helper()
+		user := &models.Identity{Name: name}
+		assert.NilError(t, data.CreateIdentity(db, user))
+
+		hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+		assert.NilError(t, err)
+
+		credential.IdentityID = user.ID
+		credential.PasswordHash = hash
+		assert.NilError(t, data.CreateCredential(db, &credential))
+		return user
+	}
+
+	opts := PasswordLoginOptions{
+		Lockout: LockoutPolicy{Attempts: 3, Duration: time.Hour},
+		Now:     func() time.Time { return now },
+	}
+
+	login := func(name, code string, opts PasswordLoginOptions) (AuthScope, error) {
+		_, _, scope, err := NewPasswordCredentialAuthentication(name, "password123", code, opts).Authenticate(context.Background(), db)
+		return scope, err
+	}
+
+	_, hashes, err := GenerateRecoveryCodes()
+	assert.NilError(t, err)
+
+	user := createUser(t, "krillin@example.com", models.Credential{
+		TOTPSecret:    models.EncryptedAtRest(rfc6238Secret),
+		TOTPConfirmed: true,
+		RecoveryCodes: hashes,
+	})
+
+	t.Run("code is required", func(t *testing.T) {
+		_, err := login(user.Name, "", opts)
+		assert.ErrorIs(t, err, ErrMFARequired)
+
+		identity, err := data.GetIdentity(db, data.ByID(user.ID))
+		assert.NilError(t, err)
+		assert.Equal(t, identity.FailedLoginAttempts, 0)
+	})
+
+	t.Run("invalid code is a failed login", func(t *testing.T) {
+		_, err := login(user.Name, "000000", opts)
+		assert.ErrorContains(t, err, "could not verify multi-factor authentication code")
+
+		identity, err := data.GetIdentity(db, data.ByID(user.ID))
+		assert.NilError(t, err)
+		assert.Equal(t, identity.FailedLoginAttempts, 1)
+	})
+
+	t.Run("valid code", func(t *testing.T) {
+		_, err := login(user.Name, "005924", opts)
+		assert.NilError(t, err)
+
+		identity, err := data.GetIdentity(db, data.ByID(user.ID))
+		assert.NilError(t, err)
+		assert.Equal(t, identity.FailedLoginAttempts, 0)
+	})
+
+	t.Run("code can not be used again", func(t *testing.T) {
+		_, err := login(user.Name, "005924", opts)
+		assert.ErrorContains(t, err, "could not verify multi-factor authentication code")
+
+		later := opts
+		later.Now = func() time.Time { return now.Add(time.Minute) }
+		code, err := TOTPCode(rfc6238Secret, now.Add(time.Minute))
+		assert.NilError(t, err)
+
+		_, err = login(user.Name, code, later)
+		assert.NilError(t, err)
+	})
+
+	t.Run("admin without mfa is limited to enrollment", func(t *testing.T) {
+		admin := createUser(t, "bulma@example.com", models.Credential{})
+		assert.NilError(t, data.CreateGrant(db, &models.Grant{Subject: admin.PolyID(), Privilege: models.InfraAdminRole, Resource: "infra"}))
+
+		scope, err := login(admin.Name, "", opts)
+		assert.NilError(t, err)
+		assert.Equal(t, scope.MFAEnrollmentOnly, false)
+
+		requireMFA := opts
+		requireMFA.RequireAdminMFA = true
+		scope, err = login(admin.Name, "", requireMFA)
+		assert.NilError(t, err)
+		assert.Equal(t, scope.MFAEnrollmentOnly, true)
+
+		scope, err = login(user.Name, "", requireMFA)
+		assert.ErrorIs(t, err, ErrMFARequired)
+		assert.Equal(t, scope.MFAEnrollmentOnly, false)
+
+		notAdmin := createUser(t, "yamcha@example.com", models.Credential{})
+		scope, err = login(notAdmin.Name, "", requireMFA)
+		assert.NilError(t, err)
+		assert.Equal(t, scope.MFAEnrollmentOnly, false)
+	})
+}
@@ -0,0 +1,124 @@
+package authn
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordPolicy is the set of rules that passwords of the infra provider must
+// follow.
+type PasswordPolicy struct {
+	MinLength        int
+	RequireLowercase bool
+	RequireUppercase bool
+	RequireNumber    bool
+	RequireSymbol    bool
+	// HistoryDepth is the number of previous passwords of a user that can not
+	// be used again.
+	HistoryDepth int
+	// Denied are passwords that can not be used, because they are known to be
+	// common or breached. They are stored in lower case.
+	Denied map[string]bool
+}
+
+// PasswordPolicyError is returned when a password does not follow the
+// PasswordPolicy. Each of Failures is a rule the password does not follow.
+type PasswordPolicyError struct {
+	Failures []string
+}
+
+func (e PasswordPolicyError) Error() string {
+	return "password " + strings.Join(e.Failures, ", ")
+}
+
+// LoadDenyList reads the passwords that can not be used from a file with one
+// password on each line.
+func LoadDenyList(path string) (map[string]bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("password deny list: %w", err)
+	}
+	defer f.Close()
+
+	denied := make(map[string]bool)
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			denied[strings.ToLower(line)] = true
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("password deny list: %w", err)
+	}
+
+	return denied, nil
+}
+
+// Check returns a PasswordPolicyError if password does not follow the policy.
+// previousHashes are the bcrypt hashes of the current and previous passwords of
+// the user, most recent first.
+func (p PasswordPolicy) Check(password string, previousHashes ...[]byte) error {
+	var failures []string
+
+	if len(password) < p.MinLength {
+		failures = append(failures, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+
+	var lower, upper, number, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			number = true
+		case unicode.IsPunct(r), unicode.IsSymbol(r), unicode.IsSpace(r):
+			symbol = true
+		}
+	}
+
+	if p.RequireLowercase && !lower {
+		failures = append(failures, "must contain a lowercase letter")
+	}
+
+	if p.RequireUppercase && !upper {
+		failures = append(failures, "must contain an uppercase letter")
+	}
+
+	if p.RequireNumber && !number {
+		failures = append(failures, "must contain a number")
+	}
+
+	if p.RequireSymbol && !symbol {
+		failures = append(failures, "must contain a symbol")
+	}
+
+	if p.Denied[strings.ToLower(password)] {
+		failures = append(failures, "is a commonly used password")
+	}
+
+	if len(previousHashes) > p.HistoryDepth {
+		previousHashes = previousHashes[:p.HistoryDepth]
+	}
+
+	for _, hash := range previousHashes {
+		if bcrypt.CompareHashAndPassword(hash, []byte(password)) == nil {
+			failures = append(failures, fmt.Sprintf("must not be one of the last %d passwords", p.HistoryDepth))
+			break
+		}
+	}
+
+	if len(failures) > 0 {
+		return PasswordPolicyError{Failures: failures}
+	}
+
+	return nil
+}
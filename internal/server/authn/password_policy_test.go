@@ -0,0 +1,58 @@
+package authn
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+	"gotest.tools/v3/assert"
+)
+
+func TestPasswordPolicy_Check(t *testing.T) {
+	denyList := filepath.Join(t.TempDir(), "passwords.txt")
+	err := os.WriteFile(denyList, []byte("password123\n\nQwerty-12345\n"), 0o600)
+	assert.NilError(t, err)
+
+	denied, err := LoadDenyList(denyList)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, denied, map[string]bool{"password123": true, "qwerty-12345": true})
+
+	previous, err := bcrypt.GenerateFromPassword([]byte("Previous-pass-1"), bcrypt.MinCost)
+	assert.NilError(t, err)
+
+	policy := PasswordPolicy{
+		MinLength:        10,
+		RequireLowercase: true,
+		RequireUppercase: true,
+		RequireNumber:    true,
+		RequireSymbol:    true,
+		HistoryDepth:     1,
+		Denied:           denied,
+	}
+
+	testCases := map[string][]string{
+		"Valid-password-1": nil,
+		"short":            {"must be at least 10 characters", "must contain an uppercase letter", "must contain a number", "must contain a symbol"},
+		"ALLUPPERCASE-1":   {"must contain a lowercase letter"},
+		"qWERTY-12345":     {"is a commonly used password"},
+		"Previous-pass-1":  {"must not be one of the last 1 passwords"},
+	}
+
+	for password, expected := range testCases {
+		t.Run(password, func(t *testing.T) {
+			err := policy.Check(password, previous)
+			if expected == nil {
+				assert.NilError(t, err)
+				return
+			}
+
+			assert.DeepEqual(t, err, PasswordPolicyError{Failures: expected})
+		})
+	}
+
+	t.Run("history depth of zero allows reuse", func(t *testing.T) {
+		policy := PasswordPolicy{}
+		assert.NilError(t, policy.Check("Previous-pass-1", previous))
+	})
+}
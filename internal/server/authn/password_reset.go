@@ -0,0 +1,73 @@
+package authn
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+// passwordResetAuthn exchanges the single use token from an invitation or
+// password reset email for an access key that can only set a new password.
+type passwordResetAuthn struct {
+	Token   string
+	MFACode string
+	Now     func() time.Time
+}
+
+func NewPasswordResetAuthentication(token, mfaCode string) LoginMethod {
+	return &passwordResetAuthn{
+		Token:   token,
+		MFACode: mfaCode,
+		Now:     time.Now,
+	}
+}
+
+func (a *passwordResetAuthn) Authenticate(_ context.Context, db *gorm.DB) (*models.Identity, *models.Provider, AuthScope, error) {
+	scope := AuthScope{}
+
+	token, err := data.GetPasswordResetToken(db, a.Token)
+	if err != nil {
+		return nil, nil, scope, fmt.Errorf("could not get password reset token: %w", err)
+	}
+
+	identity, err := data.GetIdentity(db, data.ByID(token.IdentityID))
+	if err != nil {
+		return nil, nil, scope, fmt.Errorf("could not get identity for password reset token: %w", err)
+	}
+
+	if identity.IsServiceAccount() {
+		return nil, nil, scope, errServiceAccountLogin
+	}
+
+	credential, err := data.GetCredential(db, data.ByIdentityID(identity.ID))
+	if err != nil {
+		return nil, nil, scope, fmt.Errorf("could not get credential for password reset token: %w", err)
+	}
+
+	// a reset must not skip the second factor of a user that enabled it
+	if err := verifySecondFactor(db, credential, a.MFACode, a.Now()); err != nil {
+		return nil, nil, scope, err
+	}
+
+	// the tokens are single use, using one also invalidates any others
+	if err := data.DeletePasswordResetTokens(db, identity.ID); err != nil {
+		return nil, nil, scope, fmt.Errorf("delete password reset tokens: %w", err)
+	}
+
+	scope.PasswordResetOnly = true
+
+	return identity, data.InfraProvider(db), scope, nil
+}
+
+func (a *passwordResetAuthn) Name() string {
+	return "password-reset"
+}
+
+func (a *passwordResetAuthn) RequiresUpdate(_ *gorm.DB) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,73 @@
+package authn
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestPasswordResetAuthentication(t *testing.T) {
+	db := setupDB(t)
+
+	createUser := func(t *testing.T, name string, credential models.Credential) *models.Identity {
+		t.Helper()
+		user := &models.Identity{Name: name}
+		assert.NilError(t, data.CreateIdentity(db, user))
+
+		hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+		assert.NilError(t, err)
+
+		credential.IdentityID = user.ID
+		credential.PasswordHash = hash
+		assert.NilError(t, data.CreateCredential(db, &credential))
+		return user
+	}
+
+	t.Run("token is single use", func(t *testing.T) {
+		user := createUser(t, "piccolo@example.com", models.Credential{})
+		token, err := data.CreatePasswordResetToken(db, user.ID, time.Hour)
+		assert.NilError(t, err)
+
+		login := NewPasswordResetAuthentication(token, "")
+		identity, _, scope, err := login.Authenticate(context.Background(), db)
+		assert.NilError(t, err)
+		assert.Equal(t, identity.ID, user.ID)
+		assert.Equal(t, scope.PasswordResetOnly, true)
+
+		requiresUpdate, err := login.RequiresUpdate(db)
+		assert.NilError(t, err)
+		assert.Equal(t, requiresUpdate, true)
+
+		_, _, _, err = NewPasswordResetAuthentication(token, "").Authenticate(context.Background(), db)
+		assert.ErrorContains(t, err, "could not get password reset token")
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		_, _, _, err := NewPasswordResetAuthentication("not-a-token", "").Authenticate(context.Background(), db)
+		assert.ErrorContains(t, err, "could not get password reset token")
+	})
+
+	t.Run("mfa code is required", func(t *testing.T) {
+		now := time.Unix(1234567890, 0)
+		user := createUser(t, "tien@example.com", models.Credential{
+			TOTPSecret:    models.EncryptedAtRest(rfc6238Secret),
+			TOTPConfirmed: true,
+		})
+		token, err := data.CreatePasswordResetToken(db, user.ID, time.Hour)
+		assert.NilError(t, err)
+
+		_, _, _, err = NewPasswordResetAuthentication(token, "").Authenticate(context.Background(), db)
+		assert.ErrorIs(t, err, ErrMFARequired)
+
+		login := &passwordResetAuthn{Token: token, MFACode: "005924", Now: func() time.Time { return now }}
+		_, _, scope, err := login.Authenticate(context.Background(), db)
+		assert.NilError(t, err)
+		assert.Equal(t, scope.PasswordResetOnly, true)
+	})
+}
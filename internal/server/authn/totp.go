@@ -0,0 +1,183 @@
+package authn
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" // nolint:gosec // TOTP uses HMAC-SHA1 for compatibility with authenticator apps
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/infrahq/infra/internal/generate"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+// Time-based one-time passwords (RFC 6238) use the defaults that authenticator
+// apps support: HMAC-SHA1, 6 digits and a 30 second period.
+const (
+	totpPeriod = 30
+	totpDigits = 6
+	// totpSkew is the number of periods before and after the current period
+	// that codes are accepted for, to allow for clock drift.
+	totpSkew = 1
+
+	totpIssuer = "Infra"
+
+	recoveryCodeCount   = 10
+	recoveryCodeLength  = 10
+	recoveryCodeCharset = "0123456789abcdefghijklmnopqrstuvwxyz"
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTOTPSecret returns a new random base32 encoded TOTP secret.
+func GenerateTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("generate totp secret: %w", err)
+	}
+
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// TOTPURI returns the otpauth URI of the secret, which authenticator apps read
+// from a QR code to add the account.
+func TOTPURI(account, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", totpIssuer)
+
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + totpIssuer + ":" + account,
+		RawQuery: query.Encode(),
+	}
+
+	return u.String()
+}
+
+// TOTPCode returns the code of the secret at t.
+func TOTPCode(secret string, t time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return hotp(key, totpStep(t)), nil
+}
+
+// ValidateTOTP checks that code is the code of the secret within totpSkew
+// periods of t. Codes of steps up to lastStep have already been used and are
+// not valid. It returns the step of the code, which should be saved as the new
+// lastStep.
+func ValidateTOTP(secret, code string, t time.Time, lastStep int64) (int64, bool) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+
+	code = strings.TrimSpace(code)
+	current := totpStep(t)
+
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		if step <= lastStep {
+			continue
+		}
+
+		if subtle.ConstantTimeCompare([]byte(hotp(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// GenerateRecoveryCodes returns new recovery codes, and the hashes of the codes
+// that are stored to validate them.
+func GenerateRecoveryCodes() (codes []string, hashes []string, err error) {
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generate.CryptoRandom(recoveryCodeLength, recoveryCodeCharset)
+		if err != nil {
+			return nil, nil, fmt.Errorf("generate recovery code: %w", err)
+		}
+
+		code = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+		codes = append(codes, code)
+		hashes = append(hashes, recoveryCodeHash(code))
+	}
+
+	return codes, hashes, nil
+}
+
+// VerifyMFA checks that code is a valid TOTP code or an unused recovery code
+// of the credential. The credential is updated so that the code can not be
+// used again, and must be saved by the caller.
+func VerifyMFA(credential *models.Credential, code string, t time.Time) bool {
+	if step, ok := ValidateTOTP(string(credential.TOTPSecret), code, t, credential.TOTPLastStep); ok {
+		credential.TOTPLastStep = step
+		return true
+	}
+
+	hash := recoveryCodeHash(code)
+	for i, h := range credential.RecoveryCodes {
+		if subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1 {
+			remaining := models.CommaSeparatedStrings{}
+			remaining = append(remaining, credential.RecoveryCodes[:i]...)
+			credential.RecoveryCodes = append(remaining, credential.RecoveryCodes[i+1:]...)
+			return true
+		}
+	}
+
+	return false
+}
+
+func recoveryCodeHash(code string) string {
+	code = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := totpEncoding.DecodeString(strings.TrimRight(secret, "="))
+	if err != nil {
+		return nil, fmt.Errorf("decode totp secret: %w", err)
+	}
+
+	if len(key) == 0 {
+		return nil, fmt.Errorf("decode totp secret: empty secret")
+	}
+
+	return key, nil
+}
+
+func totpStep(t time.Time) int64 {
+	return t.Unix() / totpPeriod
+}
+
+// hotp returns the HMAC-based one-time password (RFC 4226) of key at counter.
+func hotp(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
@@ -0,0 +1,127 @@
+package authn
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+// rfc6238Secret is the base32 encoded SHA1 secret of the RFC 6238 test vectors.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	// the RFC 6238 test vectors, truncated to 6 digits
+	cases := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range cases {
+		code, err := TOTPCode(rfc6238Secret, time.Unix(unix, 0))
+		assert.NilError(t, err)
+		assert.Equal(t, code, expected, "time %d", unix)
+	}
+
+	_, err := TOTPCode("not base32!", time.Unix(59, 0))
+	assert.ErrorContains(t, err, "decode totp secret")
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	step := now.Unix() / totpPeriod
+
+	t.Run("current code", func(t *testing.T) {
+		got, ok := ValidateTOTP(rfc6238Secret, "005924", now, 0)
+		assert.Assert(t, ok)
+		assert.Equal(t, got, step)
+	})
+
+	t.Run("codes within the skew", func(t *testing.T) {
+		previous, err := TOTPCode(rfc6238Secret, now.Add(-totpPeriod*time.Second))
+		assert.NilError(t, err)
+		got, ok := ValidateTOTP(rfc6238Secret, previous, now, 0)
+		assert.Assert(t, ok)
+		assert.Equal(t, got, step-1)
+
+		next, err := TOTPCode(rfc6238Secret, now.Add(totpPeriod*time.Second))
+		assert.NilError(t, err)
+		got, ok = ValidateTOTP(rfc6238Secret, next, now, 0)
+		assert.Assert(t, ok)
+		assert.Equal(t, got, step+1)
+	})
+
+	t.Run("codes outside the skew", func(t *testing.T) {
+		old, err := TOTPCode(rfc6238Secret, now.Add(-2*totpPeriod*time.Second))
+		assert.NilError(t, err)
+		_, ok := ValidateTOTP(rfc6238Secret, old, now, 0)
+		assert.Assert(t, !ok)
+	})
+
+	t.Run("used codes", func(t *testing.T) {
+		_, ok := ValidateTOTP(rfc6238Secret, "005924", now, step)
+		assert.Assert(t, !ok)
+	})
+
+	t.Run("invalid codes", func(t *testing.T) {
+		_, ok := ValidateTOTP(rfc6238Secret, "000000", now, 0)
+		assert.Assert(t, !ok)
+
+		_, ok = ValidateTOTP(rfc6238Secret, "", now, 0)
+		assert.Assert(t, !ok)
+
+		_, ok = ValidateTOTP("", "005924", now, 0)
+		assert.Assert(t, !ok)
+	})
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	assert.NilError(t, err)
+	assert.Equal(t, len(secret), 32)
+
+	_, err = TOTPCode(secret, time.Now())
+	assert.NilError(t, err)
+}
+
+func TestTOTPURI(t *testing.T) {
+	uri := TOTPURI("alice@example.com", rfc6238Secret)
+	assert.Equal(t, uri, "otpauth://totp/Infra:alice@example.com?issuer=Infra&secret="+rfc6238Secret)
+}
+
+func TestVerifyMFA(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+
+	codes, hashes, err := GenerateRecoveryCodes()
+	assert.NilError(t, err)
+	assert.Equal(t, len(codes), recoveryCodeCount)
+	assert.Equal(t, len(hashes), recoveryCodeCount)
+
+	credential := &models.Credential{
+		TOTPSecret:    models.EncryptedAtRest(rfc6238Secret),
+		TOTPConfirmed: true,
+		RecoveryCodes: hashes,
+	}
+
+	assert.Assert(t, VerifyMFA(credential, "005924", now))
+	assert.Equal(t, credential.TOTPLastStep, now.Unix()/totpPeriod)
+	assert.Assert(t, !VerifyMFA(credential, "005924", now), "code must not be used again")
+
+	assert.Assert(t, VerifyMFA(credential, codes[3], now))
+	assert.Equal(t, len(credential.RecoveryCodes), recoveryCodeCount-1)
+	assert.Assert(t, !VerifyMFA(credential, codes[3], now), "recovery code must not be used again")
+
+	// recovery codes are not case sensitive, and the dash is optional
+	withoutDash := codes[0][:5] + codes[0][6:]
+	assert.Assert(t, VerifyMFA(credential, strings.ToUpper(withoutDash), now))
+	assert.Equal(t, len(credential.RecoveryCodes), recoveryCodeCount-2)
+
+	assert.Assert(t, !VerifyMFA(credential, "not-a-code", now))
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAPI_CheckAccess(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	alice := &models.Identity{Name: "alice@example.com"}
+	bob := &models.Identity{Name: "bob@example.com"}
+	createIdentities(t, srv.db, alice, bob)
+
+	developers := &models.Group{Name: "developers"}
+	createGroups(t, srv.db, developers)
+	assert.NilError(t, data.AddUsersToGroup(srv.db, developers.ID, []uid.ID{alice.ID}))
+
+	grants := []*models.Grant{
+		{Subject: alice.PolyID(), Privilege: "edit", Resource: "production.web"},
+		{Subject: developers.PolyID(), Privilege: "view", Resource: "staging.*"},
+		{Subject: alice.PolyID(), Privilege: models.InfraViewRole, Resource: "infra"},
+	}
+	for _, grant := range grants {
+		assert.NilError(t, data.CreateGrant(srv.db, grant))
+	}
+
+	aliceKey, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+		IssuedFor:  alice.ID,
+		ProviderID: data.InfraProvider(srv.db).ID,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	})
+	assert.NilError(t, err)
+
+	bobKey, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+		IssuedFor:  bob.ID,
+		ProviderID: data.InfraProvider(srv.db).ID,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	})
+	assert.NilError(t, err)
+
+	check := func(t *testing.T, key string, user uid.ID, privilege, resource string) (*httptest.ResponseRecorder, api.CheckAccessResponse) {
+		t.Helper()
+		query := url.Values{"user": {user.String()}, "privilege": {privilege}, "resource": {resource}}
+		req := httptest.NewRequest(http.MethodGet, "/api/authz/check?"+query.Encode(), nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set("Infra-Version", "0.13.0")
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+
+		var result api.CheckAccessResponse
+		if resp.Code == http.StatusOK {
+			assert.NilError(t, json.NewDecoder(resp.Body).Decode(&result))
+		}
+		return resp, result
+	}
+
+	t.Run("direct grant", func(t *testing.T) {
+		resp, result := check(t, adminAccessKey(srv), alice.ID, "edit", "production.web")
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		assert.Assert(t, result.Allowed)
+		assert.Equal(t, len(result.Grants), 1)
+		assert.Equal(t, result.Grants[0].Grant.ID, grants[0].ID)
+		assert.Equal(t, result.Grants[0].GroupName, "")
+	})
+
+	t.Run("grant through a group with a wildcard", func(t *testing.T) {
+		resp, result := check(t, adminAccessKey(srv), alice.ID, "view", "staging.web")
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		assert.Assert(t, result.Allowed)
+		assert.Equal(t, len(result.Grants), 1)
+		assert.Equal(t, result.Grants[0].Grant.ID, grants[1].ID)
+		assert.Equal(t, result.Grants[0].GroupName, "developers")
+	})
+
+	t.Run("denied", func(t *testing.T) {
+		resp, result := check(t, adminAccessKey(srv), alice.ID, "edit", "staging.web")
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		assert.Assert(t, !result.Allowed)
+		assert.Equal(t, len(result.Grants), 0)
+	})
+
+	t.Run("infra role", func(t *testing.T) {
+		resp, result := check(t, adminAccessKey(srv), alice.ID, models.InfraViewRole, "infra")
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		assert.Assert(t, result.Allowed)
+		assert.Equal(t, len(result.Grants), 1)
+		assert.Equal(t, result.Grants[0].Grant.ID, grants[2].ID)
+
+		resp, result = check(t, adminAccessKey(srv), alice.ID, models.InfraAdminRole, "infra")
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		assert.Assert(t, !result.Allowed)
+		assert.Assert(t, len(result.Missing) > 0)
+		for _, permission := range result.Missing {
+			assert.Assert(t, permission != models.PermissionUsersRead)
+		}
+	})
+
+	t.Run("users can check their own access", func(t *testing.T) {
+		resp, result := check(t, bobKey, bob.ID, "view", "staging.web")
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		assert.Assert(t, !result.Allowed)
+	})
+
+	t.Run("checking other users requires grants:read", func(t *testing.T) {
+		resp, _ := check(t, bobKey, alice.ID, "edit", "production.web")
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp, result := check(t, aliceKey, bob.ID, "view", "staging.web")
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+		assert.Assert(t, !result.Allowed)
+	})
+}
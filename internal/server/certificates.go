@@ -0,0 +1,109 @@
+package server
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/pki"
+	"github.com/infrahq/infra/uid"
+)
+
+// newCertificateProvider loads the certificate authority that signs client
+// certificates, creating it the first time. The CA is rotated at startup once
+// the active certificate has used half its lifetime, so the previous
+// certificate stays valid for longer than any certificate it signed.
+func newCertificateProvider(db *gorm.DB) (pki.CertificateProvider, error) {
+	cp, err := pki.NewNativeCertificateProvider(db, pki.NativeCertificateProviderConfig{})
+	if err != nil {
+		return nil, err
+	}
+
+	activeCAs := cp.ActiveCAs()
+	if len(activeCAs) == 0 {
+		logging.S.Info("creating certificate authority for client certificates")
+		return cp, cp.CreateCA()
+	}
+
+	active := activeCAs[len(activeCAs)-1]
+	if lifetime := active.NotAfter.Sub(active.NotBefore); time.Until(active.NotAfter) < lifetime/2 {
+		logging.S.Info("rotating certificate authority for client certificates")
+		return cp, cp.RotateCA()
+	}
+
+	return cp, nil
+}
+
+// hasClientCertificate returns true if the request was made over TLS with a
+// client certificate.
+func hasClientCertificate(c *gin.Context) bool {
+	return c.Request.TLS != nil && len(c.Request.TLS.PeerCertificates) > 0
+}
+
+// RequireClientCertificate checks the client certificate of the request was
+// signed by one of the active CAs of cp, and authenticates the identity it was
+// signed for.
+func RequireClientCertificate(c *gin.Context, cp pki.CertificateProvider) error {
+	db, ok := c.MustGet("db").(*gorm.DB)
+	if !ok {
+		return errors.New("unknown db type in context")
+	}
+
+	roots := x509.NewCertPool()
+	for _, ca := range cp.ActiveCAs() {
+		ca := ca
+		roots.AddCert(&ca)
+	}
+
+	peerCerts := c.Request.TLS.PeerCertificates
+	intermediates := x509.NewCertPool()
+	for _, cert := range peerCerts[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	cert := peerCerts[0]
+	_, err := cert.Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	if err != nil {
+		return fmt.Errorf("%w: invalid client certificate: %s", internal.ErrUnauthorized, err)
+	}
+
+	name := cert.Subject.CommonName
+	if !strings.HasPrefix(name, "User ") {
+		return fmt.Errorf("%w: client certificate is not a user certificate", internal.ErrUnauthorized)
+	}
+
+	id, err := uid.Parse([]byte(strings.TrimPrefix(name, "User ")))
+	if err != nil {
+		return fmt.Errorf("%w: invalid client certificate subject: %s", internal.ErrUnauthorized, err)
+	}
+
+	identity, err := data.GetIdentity(db, data.ByID(id))
+	if err != nil {
+		return fmt.Errorf("%w: identity for client certificate: %s", internal.ErrUnauthorized, err)
+	}
+
+	if _, err := data.GetClientCertificate(db, identity.ID, cert.SerialNumber.String()); err != nil {
+		return fmt.Errorf("%w: client certificate was revoked: %s", internal.ErrUnauthorized, err)
+	}
+
+	identity.LastSeenAt = time.Now().UTC()
+	if err = data.SaveIdentity(db, identity); err != nil {
+		return fmt.Errorf("identity update fail: %w", err)
+	}
+
+	c.Set("identity", identity)
+
+	return nil
+}
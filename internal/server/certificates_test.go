@@ -0,0 +1,200 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/pki"
+)
+
+func TestAPI_SignCertificate(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	admin, err := data.GetIdentity(srv.db, data.ByName("admin@example.com"))
+	assert.NilError(t, err)
+
+	withAccessKey := func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+	}
+
+	withCertificate := func(cert *x509.Certificate) func(*http.Request) {
+		return func(req *http.Request) {
+			req.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		}
+	}
+
+	newCSR := func(t *testing.T) api.SignCertificateRequest {
+		t.Helper()
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		assert.NilError(t, err)
+
+		csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+			Subject: pkix.Name{CommonName: "User someone-else"},
+		}, key)
+		assert.NilError(t, err)
+
+		csrPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE REQUEST", Bytes: csr})
+		return api.SignCertificateRequest{CertificateSigningRequest: api.PEM(csrPEM)}
+	}
+
+	signCertificateWith := func(t *testing.T, auth func(*http.Request)) *x509.Certificate {
+		t.Helper()
+		resp := callAPI(t, routes, http.MethodPost, "/api/certificates", "", newCSR(t), auth)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var signed api.SignCertificateResponse
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &signed))
+
+		block, _ := pem.Decode([]byte(signed.Certificate))
+		assert.Assert(t, block != nil)
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		assert.NilError(t, err)
+		return cert
+	}
+
+	signCertificate := func(t *testing.T) *x509.Certificate {
+		t.Helper()
+		return signCertificateWith(t, withAccessKey)
+	}
+
+	t.Run("not enabled", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/certificates", adminAccessKey(srv), newCSR(t))
+		assert.Equal(t, resp.Code, http.StatusNotImplemented, resp.Body.String())
+	})
+
+	srv.certificates, err = newCertificateProvider(srv.db)
+	assert.NilError(t, err)
+
+	t.Run("sign a certificate", func(t *testing.T) {
+		cert := signCertificate(t)
+		assert.Equal(t, cert.Subject.CommonName, "User "+admin.ID.String())
+		assert.DeepEqual(t, cert.EmailAddresses, []string{"admin@example.com"})
+		assert.Assert(t, cert.NotAfter.Before(time.Now().Add(25*time.Hour)))
+	})
+
+	t.Run("invalid certificate signing request", func(t *testing.T) {
+		body := api.SignCertificateRequest{CertificateSigningRequest: "not a certificate signing request"}
+		resp := callAPI(t, routes, http.MethodPost, "/api/certificates", adminAccessKey(srv), body)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("authenticate with a certificate", func(t *testing.T) {
+		cert := signCertificate(t)
+
+		resp := callAPI(t, routes, http.MethodGet, "/api/users/"+admin.ID.String(), "", nil, withCertificate(cert))
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var user api.User
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &user))
+		assert.Equal(t, user.ID, admin.ID)
+	})
+
+	t.Run("a certificate can not renew itself", func(t *testing.T) {
+		cert := signCertificate(t)
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/certificates", "", newCSR(t), withCertificate(cert))
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("certificate from another CA", func(t *testing.T) {
+		keyPair, err := pki.MakeUserCert("User "+admin.ID.String(), time.Hour)
+		assert.NilError(t, err)
+
+		resp := callAPI(t, routes, http.MethodGet, "/api/users/"+admin.ID.String(), "", nil, withCertificate(keyPair.Cert))
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("the CA is loaded from the database", func(t *testing.T) {
+		cert := signCertificate(t)
+
+		srv.certificates, err = newCertificateProvider(srv.db)
+		assert.NilError(t, err)
+
+		resp := callAPI(t, routes, http.MethodGet, "/api/users/"+admin.ID.String(), "", nil, withCertificate(cert))
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+	})
+
+	alice := &models.Identity{Name: "alice@example.com"}
+	createIdentities(t, srv.db, alice)
+
+	newAccessKey := func(t *testing.T, key *models.AccessKey) func(*http.Request) {
+		t.Helper()
+		key.IssuedFor = alice.ID
+		key.ProviderID = data.InfraProvider(srv.db).ID
+		key.ExpiresAt = time.Now().Add(time.Hour)
+
+		secret, err := data.CreateAccessKey(srv.db, key)
+		assert.NilError(t, err)
+		return func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+secret)
+		}
+	}
+
+	t.Run("scoped access key", func(t *testing.T) {
+		auth := newAccessKey(t, &models.AccessKey{Scopes: models.CommaSeparatedStrings{models.PermissionUsersRead}})
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/certificates", "", newCSR(t), auth)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("service account", func(t *testing.T) {
+		bot := &models.Identity{Name: "deploy-bot", Kind: models.ServiceAccountKind}
+		createIdentities(t, srv.db, bot)
+
+		secret, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+			IssuedFor:  bot.ID,
+			ProviderID: data.InfraProvider(srv.db).ID,
+			ExpiresAt:  time.Now().Add(time.Hour),
+		})
+		assert.NilError(t, err)
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/certificates", "", newCSR(t), func(req *http.Request) {
+			req.Header.Set("Authorization", "Bearer "+secret)
+		})
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("revoked by revoking sessions", func(t *testing.T) {
+		cert := signCertificateWith(t, newAccessKey(t, &models.AccessKey{Session: true}))
+
+		resp := callAPI(t, routes, http.MethodGet, "/api/users/"+alice.ID.String(), "", nil, withCertificate(cert))
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodDelete, "/api/users/"+alice.ID.String()+"/sessions", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/users/"+alice.ID.String(), "", nil, withCertificate(cert))
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+		cert = signCertificateWith(t, newAccessKey(t, &models.AccessKey{Session: true}))
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/users/"+alice.ID.String(), "", nil, withCertificate(cert))
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+	})
+
+	t.Run("revoked by a password reset", func(t *testing.T) {
+		cert := signCertificateWith(t, newAccessKey(t, &models.AccessKey{Session: true}))
+
+		resp := callAPI(t, routes, http.MethodPut, "/api/users/"+alice.ID.String(), adminAccessKey(srv), api.UpdateUserRequest{Password: "password123"})
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/users/"+alice.ID.String(), "", nil, withCertificate(cert))
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+}
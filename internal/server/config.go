@@ -730,6 +730,7 @@ func (Server) loadGrant(db *gorm.DB, input Grant) (*models.Grant, error) {
 
 func (s Server) loadUsers(db *gorm.DB, users []User) error {
 	keep := make([]uid.ID, 0, len(users)+1)
+	keepKeys := make([]uid.ID, 0)
 
 	for _, i := range users {
 		user, err := s.loadUser(db, i)
@@ -738,6 +739,15 @@ func (s Server) loadUsers(db *gorm.DB, users []User) error {
 		}
 
 		keep = append(keep, user.ID)
+
+		accessKey, err := s.loadAccessKey(db, user, i.AccessKey)
+		if err != nil {
+			return err
+		}
+
+		if accessKey != nil {
+			keepKeys = append(keepKeys, accessKey.ID)
+		}
 	}
 
 	// remove any users previously defined by config
@@ -745,6 +755,11 @@ func (s Server) loadUsers(db *gorm.DB, users []User) error {
 		return err
 	}
 
+	// remove any access keys previously defined by config
+	if err := data.DeleteAccessKeys(db, data.NotIDs(keepKeys), data.CreatedBy(models.CreatedBySystem)); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -784,10 +799,6 @@ func (s Server) loadUser(db *gorm.DB, input User) (*models.Identity, error) {
 		return nil, err
 	}
 
-	if err := s.loadAccessKey(db, identity, input.AccessKey); err != nil {
-		return nil, err
-	}
-
 	return identity, nil
 }
 
@@ -801,17 +812,21 @@ func (s Server) loadCredential(db *gorm.DB, identity *models.Identity, password
 		return err
 	}
 
-	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return err
-	}
-
 	credential, err := data.GetCredential(db, data.ByIdentityID(identity.ID))
 	if err != nil {
 		if !errors.Is(err, internal.ErrNotFound) {
 			return err
 		}
 
+		if err := s.passwords.Check(password); err != nil {
+			return fmt.Errorf("user %q: %w", identity.Name, err)
+		}
+
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+
 		credential := &models.Credential{
 			IdentityID:   identity.ID,
 			PasswordHash: hash,
@@ -828,7 +843,21 @@ func (s Server) loadCredential(db *gorm.DB, identity *models.Identity, password
 		return nil
 	}
 
-	credential.PasswordHash = hash
+	if bcrypt.CompareHashAndPassword(credential.PasswordHash, []byte(password)) == nil {
+		// the password has not changed since the config was last loaded
+		return nil
+	}
+
+	if err := s.passwords.Check(password, credential.PasswordHashes()...); err != nil {
+		return fmt.Errorf("user %q: %w", identity.Name, err)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	credential.SetPasswordHash(hash, s.passwords.HistoryDepth)
 
 	if err := data.SaveCredential(db, credential); err != nil {
 		return err
@@ -837,25 +866,25 @@ func (s Server) loadCredential(db *gorm.DB, identity *models.Identity, password
 	return nil
 }
 
-func (s Server) loadAccessKey(db *gorm.DB, identity *models.Identity, key string) error {
+func (s Server) loadAccessKey(db *gorm.DB, identity *models.Identity, key string) (*models.AccessKey, error) {
 	if key == "" {
-		return nil
+		return nil, nil
 	}
 
 	key, err := secrets.GetSecret(key, s.secrets)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	keyID, secret, ok := strings.Cut(key, ".")
 	if !ok {
-		return fmt.Errorf("invalid access key format")
+		return nil, fmt.Errorf("invalid access key format")
 	}
 
 	accessKey, err := data.GetAccessKey(db, data.ByKeyID(keyID))
 	if err != nil {
 		if !errors.Is(err, internal.ErrNotFound) {
-			return err
+			return nil, err
 		}
 
 		accessKey := &models.AccessKey{
@@ -864,28 +893,30 @@ func (s Server) loadAccessKey(db *gorm.DB, identity *models.Identity, key string
 			KeyID:      keyID,
 			Secret:     secret,
 			ProviderID: data.InfraProvider(db).ID,
+			CreatedBy:  models.CreatedBySystem,
 		}
 
 		if _, err := data.CreateAccessKey(db, accessKey); err != nil {
-			return err
+			return nil, err
 		}
 
 		if _, err := data.CreateProviderUser(db, data.InfraProvider(db), identity); err != nil {
-			return err
+			return nil, err
 		}
 
-		return nil
+		return accessKey, nil
 	}
 
 	if accessKey.IssuedFor != identity.ID {
-		return fmt.Errorf("access key assigned to %q is already assigned to another user, a user's access key must have a unique ID", identity.Name)
+		return nil, fmt.Errorf("access key assigned to %q is already assigned to another user, a user's access key must have a unique ID", identity.Name)
 	}
 
 	accessKey.Secret = secret
+	accessKey.CreatedBy = models.CreatedBySystem
 
 	if err := data.SaveAccessKey(db, accessKey); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return accessKey, nil
 }
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/infrahq/infra/internal/logging"
+)
+
+// configReloadInterval is how often the config file is checked for changes.
+const configReloadInterval = 10 * time.Second
+
+// watchConfig reloads the config when the config file changes from checksum,
+// or when the process receives SIGHUP. The file is polled instead of watched
+// for events because a mounted ConfigMap is updated by replacing a symlink,
+// which most file watchers do not report.
+func (s *Server) watchConfig(ctx context.Context, interval time.Duration, checksum []byte) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			logging.S.Info("received SIGHUP, reloading config")
+		case <-ticker.C:
+			current, err := configChecksum(s.options.ConfigFile)
+			if err != nil {
+				logging.S.Warnf("config reload: %s", err)
+				continue
+			}
+
+			if bytes.Equal(current, checksum) {
+				continue
+			}
+
+			logging.S.Infof("config file %s changed, reloading config", s.options.ConfigFile)
+		}
+
+		// the checksum is updated even when the config is invalid, so that
+		// the same invalid config is only reported once
+		checksum, _ = configChecksum(s.options.ConfigFile)
+
+		if err := s.reloadConfig(); err != nil {
+			logging.S.Errorf("failed to reload config, the previous config is still in use: %s", err)
+			continue
+		}
+
+		logging.S.Info("config reloaded")
+	}
+}
+
+// reloadConfig reads the providers, grants, and users from the config file and
+// loads them. The config is loaded in a single transaction, so an invalid
+// config leaves the previous config in place.
+func (s *Server) reloadConfig() error {
+	options := Options{Version: s.options.Version}
+	flags := pflag.NewFlagSet("reload", pflag.ContinueOnError)
+	if err := ApplyOptions(&options, s.options.ConfigFile, flags); err != nil {
+		return err
+	}
+
+	return s.loadConfig(options.Config)
+}
+
+func configChecksum(filename string) ([]byte, error) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	sum := sha256.Sum256(raw)
+	return sum[:], nil
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/poll"
+
+	"github.com/infrahq/infra/internal/server/data"
+)
+
+func TestReloadConfig(t *testing.T) {
+	s := setupServer(t)
+	s.options.ConfigFile = filepath.Join(t.TempDir(), "infra.yaml")
+
+	writeFile := func(t *testing.T, content string) {
+		t.Helper()
+		err := os.WriteFile(s.options.ConfigFile, []byte(content), 0o600)
+		assert.NilError(t, err)
+	}
+
+	countGrants := func(t *testing.T) int {
+		t.Helper()
+		grants, err := data.ListGrants(s.db, data.ByResource("example-cluster"))
+		assert.NilError(t, err)
+		return len(grants)
+	}
+
+	writeFile(t, `
+grants:
+  - user: alice@example.com
+    role: view
+    resource: example-cluster
+  - group: Everyone
+    role: view
+    resource: example-cluster
+`)
+
+	err := s.reloadConfig()
+	assert.NilError(t, err)
+	assert.Equal(t, countGrants(t), 2)
+
+	t.Run("invalid config keeps the previous config", func(t *testing.T) {
+		writeFile(t, `
+grants:
+  - user: alice@example.com
+    group: Everyone
+    role: admin
+    resource: example-cluster
+`)
+
+		err := s.reloadConfig()
+		assert.ErrorContains(t, err, "excluded_with")
+		assert.Equal(t, countGrants(t), 2)
+	})
+
+	t.Run("removed grants are pruned", func(t *testing.T) {
+		writeFile(t, `
+grants:
+  - user: alice@example.com
+    role: view
+    resource: example-cluster
+`)
+
+		err := s.reloadConfig()
+		assert.NilError(t, err)
+		assert.Equal(t, countGrants(t), 1)
+	})
+}
+
+func TestWatchConfig(t *testing.T) {
+	s := setupServer(t)
+	s.options.ConfigFile = filepath.Join(t.TempDir(), "infra.yaml")
+
+	err := os.WriteFile(s.options.ConfigFile, []byte("grants: []\n"), 0o600)
+	assert.NilError(t, err)
+
+	checksum, err := configChecksum(s.options.ConfigFile)
+	assert.NilError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.watchConfig(ctx, 10*time.Millisecond, checksum)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	err = os.WriteFile(s.options.ConfigFile, []byte(`
+grants:
+  - user: alice@example.com
+    role: view
+    resource: example-cluster
+`), 0o600)
+	assert.NilError(t, err)
+
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		_, err := data.GetGrant(s.db, data.ByResource("example-cluster"))
+		if err != nil {
+			return poll.Continue("grant not created: %v", err)
+		}
+		return poll.Success()
+	}, poll.WithTimeout(5*time.Second), poll.WithDelay(10*time.Millisecond))
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/sha256"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/mitchellh/mapstructure"
 	"golang.org/x/crypto/bcrypt"
@@ -13,6 +14,7 @@ import (
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
 
+	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/cmd/cliopts"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
@@ -464,6 +466,25 @@ func TestLoadConfigWithUsers(t *testing.T) {
 	assert.Equal(t, bytes.Compare(key.SecretChecksum, chksm[:]), 0) // 0 means the byte slices are equal
 }
 
+func TestLoadConfigWithUsers_PasswordPolicy(t *testing.T) {
+	s := setupServer(t, func(_ *testing.T, opts *Options) {
+		opts.PasswordPolicy = PasswordPolicyOptions{MinLength: 12}
+	})
+
+	config := Config{Users: []User{{Name: "alice", Password: "password"}}}
+	err := s.loadConfig(config)
+	assert.ErrorContains(t, err, `user "alice": password must be at least 12 characters`)
+
+	config = Config{Users: []User{{Name: "alice", Password: "a-longer-password"}}}
+	err = s.loadConfig(config)
+	assert.NilError(t, err)
+
+	// loading the same password again is not a reuse of the password
+	s.passwords.HistoryDepth = 3
+	err = s.loadConfig(config)
+	assert.NilError(t, err)
+}
+
 func TestLoadConfigWithUserGrants_OptionalRole(t *testing.T) {
 	s := setupServer(t)
 
@@ -793,6 +814,94 @@ func TestLoadConfigUpdate(t *testing.T) {
 	var group models.Group
 	err = s.db.Where("name = ?", "Everyone").First(&group).Error
 	assert.NilError(t, err)
+
+	err = s.db.Model(&models.Credential{}).Count(&credentials).Error
+	assert.NilError(t, err)
+	assert.Equal(t, int64(0), credentials)
+
+	err = s.db.Model(&models.AccessKey{}).Count(&accessKeys).Error
+	assert.NilError(t, err)
+	assert.Equal(t, int64(0), accessKeys)
+}
+
+func TestLoadConfigPruneLeavesAPIRecords(t *testing.T) {
+	s := setupServer(t)
+
+	config := Config{
+		Users: []User{
+			{
+				Name:      "c3po@example.com",
+				AccessKey: "TllVlekkUz.NFnxSlaPQLosgkNsyzaMttfC",
+			},
+		},
+		Grants: []Grant{
+			{
+				User:     "c3po@example.com",
+				Role:     "admin",
+				Resource: "test-cluster",
+			},
+		},
+	}
+
+	err := s.loadConfig(config)
+	assert.NilError(t, err)
+
+	// records created by the API are not managed by config
+	r2d2 := &models.Identity{Name: "r2d2@example.com"}
+	err = data.CreateIdentity(s.db, r2d2)
+	assert.NilError(t, err)
+
+	err = data.CreateGrant(s.db, &models.Grant{
+		Subject:   r2d2.PolyID(),
+		Privilege: "view",
+		Resource:  "test-cluster",
+		CreatedBy: r2d2.ID,
+	})
+	assert.NilError(t, err)
+
+	_, err = data.CreateAccessKey(s.db, &models.AccessKey{
+		IssuedFor:  r2d2.ID,
+		ProviderID: data.InfraProvider(s.db).ID,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	})
+	assert.NilError(t, err)
+
+	c3po, err := data.GetIdentity(s.db, data.ByName("c3po@example.com"))
+	assert.NilError(t, err)
+
+	t.Run("access key removed from a user", func(t *testing.T) {
+		config.Users[0].AccessKey = ""
+
+		err := s.loadConfig(config)
+		assert.NilError(t, err)
+
+		_, err = data.GetIdentity(s.db, data.ByID(c3po.ID))
+		assert.NilError(t, err)
+
+		keys, err := data.ListAccessKeys(s.db, data.ByIssuedFor(c3po.ID))
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 0)
+	})
+
+	t.Run("user and grant removed", func(t *testing.T) {
+		err := s.loadConfig(Config{})
+		assert.NilError(t, err)
+
+		_, err = data.GetIdentity(s.db, data.ByID(c3po.ID))
+		assert.ErrorIs(t, err, internal.ErrNotFound)
+
+		grants, err := data.ListGrants(s.db, data.ByResource("test-cluster"))
+		assert.NilError(t, err)
+		assert.Equal(t, len(grants), 1)
+		assert.Equal(t, grants[0].Subject, r2d2.PolyID())
+
+		_, err = data.GetIdentity(s.db, data.ByID(r2d2.ID))
+		assert.NilError(t, err)
+
+		keys, err := data.ListAccessKeys(s.db, data.ByIssuedFor(r2d2.ID))
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 1)
+	})
 }
 
 func TestLoadAccessKey(t *testing.T) {
@@ -806,11 +915,11 @@ func TestLoadAccessKey(t *testing.T) {
 	err := data.CreateIdentity(s.db, bob)
 	assert.NilError(t, err)
 
-	err = s.loadAccessKey(s.db, bob, testAccessKey)
+	_, err = s.loadAccessKey(s.db, bob, testAccessKey)
 	assert.NilError(t, err)
 
 	t.Run("access key can be reloaded for the same identity it was issued for", func(t *testing.T) {
-		err = s.loadAccessKey(s.db, bob, testAccessKey)
+		_, err = s.loadAccessKey(s.db, bob, testAccessKey)
 		assert.NilError(t, err)
 	})
 
@@ -819,7 +928,7 @@ func TestLoadAccessKey(t *testing.T) {
 		err = data.CreateIdentity(s.db, alice)
 		assert.NilError(t, err)
 
-		err = s.loadAccessKey(s.db, alice, testAccessKey)
+		_, err = s.loadAccessKey(s.db, alice, testAccessKey)
 		assert.Error(t, err, "access key assigned to \"alice\" is already assigned to another user, a user's access key must have a unique ID")
 	})
 }
@@ -77,6 +77,26 @@ func SaveAccessKey(db *gorm.DB, key *models.AccessKey) error {
 	return save(db, key)
 }
 
+// RotateAccessKey replaces the secret of the access key with a new one. The
+// previous secret remains valid for gracePeriod, so that clients of the key can
+// be updated before it stops working. It returns the new access key.
+func RotateAccessKey(db *gorm.DB, key *models.AccessKey, gracePeriod time.Duration) (body string, err error) {
+	secret, err := generate.CryptoRandom(models.AccessKeySecretLength, generate.CharsetAlphaNumeric)
+	if err != nil {
+		return "", err
+	}
+
+	key.PreviousSecretChecksum = key.SecretChecksum
+	key.PreviousSecretExpiresAt = time.Now().UTC().Add(gracePeriod)
+	key.Secret = secret
+
+	if err := SaveAccessKey(db, key); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s.%s", key.KeyID, key.Secret), nil
+}
+
 func ListAccessKeys(db *gorm.DB, selectors ...SelectorFunc) ([]models.AccessKey, error) {
 	return list[models.AccessKey](db, selectors...)
 }
@@ -102,6 +122,16 @@ func DeleteAccessKeys(db *gorm.DB, selectors ...SelectorFunc) error {
 	return deleteAll[models.AccessKey](db, ByIDs(ids))
 }
 
+// BySession selects the access keys that were created by a login.
+func BySession() SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("session = ?", true)
+	}
+}
+
+// ValidateAccessKey returns the access key for authnKey. UsedPreviousSecret is
+// set on the returned key when authnKey has the secret that was replaced by
+// the last rotation.
 func ValidateAccessKey(db *gorm.DB, authnKey string) (*models.AccessKey, error) {
 	keyID, secret, ok := strings.Cut(authnKey, ".")
 	if !ok {
@@ -116,19 +146,38 @@ func ValidateAccessKey(db *gorm.DB, authnKey string) (*models.AccessKey, error)
 	sum := secretChecksum(secret)
 
 	if subtle.ConstantTimeCompare(t.SecretChecksum, sum) != 1 {
-		return nil, fmt.Errorf("access key invalid secret")
+		// the secret that was replaced by a rotation is valid until the end of
+		// the grace period
+		if time.Now().UTC().After(t.PreviousSecretExpiresAt) || subtle.ConstantTimeCompare(t.PreviousSecretChecksum, sum) != 1 {
+			return nil, fmt.Errorf("access key invalid secret")
+		}
+
+		t.UsedPreviousSecret = true
 	}
 
-	if time.Now().UTC().After(t.ExpiresAt) {
+	now := time.Now().UTC()
+	if now.After(t.ExpiresAt) {
 		return nil, fmt.Errorf("token expired")
 	}
 
+	// avoid writing to the database on every request, LastUsedAt only needs
+	// to be accurate to lastUsedUpdateInterval
+	save := now.Sub(t.LastUsedAt) > lastUsedUpdateInterval
+
 	if !t.ExtensionDeadline.IsZero() {
-		if time.Now().UTC().After(t.ExtensionDeadline) {
+		if now.After(t.ExtensionDeadline) {
 			return nil, fmt.Errorf("token extension deadline exceeded")
 		}
 
-		t.ExtensionDeadline = time.Now().UTC().Add(t.Extension)
+		if t.ExtensionDeadline.Before(now.Add(lastUsedUpdateInterval)) {
+			save = true
+		}
+
+		t.ExtensionDeadline = now.Add(t.Extension)
+	}
+
+	if save {
+		t.LastUsedAt = now
 		if err := SaveAccessKey(db, t); err != nil {
 			return nil, err
 		}
@@ -136,3 +185,7 @@ func ValidateAccessKey(db *gorm.DB, authnKey string) (*models.AccessKey, error)
 
 	return t, nil
 }
+
+// lastUsedUpdateInterval is how often ValidateAccessKey saves the LastUsedAt
+// and ExtensionDeadline of an access key that is used.
+const lastUsedUpdateInterval = time.Minute
@@ -117,6 +117,80 @@ func TestCheckAccessKeySecret(t *testing.T) {
 	})
 }
 
+func TestRotateAccessKey(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *gorm.DB) {
+		user := &models.Identity{Name: "rotate@infrahq.com"}
+		err := CreateIdentity(db, user)
+		assert.NilError(t, err)
+
+		createKey := func(t *testing.T) (string, *models.AccessKey) {
+			key := &models.AccessKey{
+				IssuedFor:  user.ID,
+				ProviderID: InfraProvider(db).ID,
+				ExpiresAt:  time.Now().Add(time.Hour),
+			}
+
+			body, err := CreateAccessKey(db, key)
+			assert.NilError(t, err)
+			return body, key
+		}
+
+		t.Run("previous secret valid during grace period", func(t *testing.T) {
+			previous, key := createKey(t)
+
+			rotated, err := RotateAccessKey(db, key, time.Minute)
+			assert.NilError(t, err)
+			assert.Assert(t, rotated != previous)
+
+			previousKeyID, _, _ := strings.Cut(previous, ".")
+			rotatedKeyID, _, _ := strings.Cut(rotated, ".")
+			assert.Equal(t, rotatedKeyID, previousKeyID)
+
+			validated, err := ValidateAccessKey(db, rotated)
+			assert.NilError(t, err)
+			assert.Equal(t, validated.ID, key.ID)
+			assert.Assert(t, !validated.UsedPreviousSecret)
+
+			validated, err = ValidateAccessKey(db, previous)
+			assert.NilError(t, err)
+			assert.Equal(t, validated.ID, key.ID)
+			assert.Assert(t, validated.UsedPreviousSecret)
+		})
+
+		t.Run("previous secret invalid after grace period", func(t *testing.T) {
+			previous, key := createKey(t)
+
+			rotated, err := RotateAccessKey(db, key, 0)
+			assert.NilError(t, err)
+
+			_, err = ValidateAccessKey(db, rotated)
+			assert.NilError(t, err)
+
+			_, err = ValidateAccessKey(db, previous)
+			assert.Error(t, err, "access key invalid secret")
+		})
+
+		t.Run("only the last secret is kept", func(t *testing.T) {
+			first, key := createKey(t)
+
+			second, err := RotateAccessKey(db, key, time.Minute)
+			assert.NilError(t, err)
+
+			third, err := RotateAccessKey(db, key, time.Minute)
+			assert.NilError(t, err)
+
+			_, err = ValidateAccessKey(db, third)
+			assert.NilError(t, err)
+
+			_, err = ValidateAccessKey(db, second)
+			assert.NilError(t, err)
+
+			_, err = ValidateAccessKey(db, first)
+			assert.Error(t, err, "access key invalid secret")
+		})
+	})
+}
+
 func TestDeleteAccessKey(t *testing.T) {
 	runDBTests(t, func(t *testing.T, db *gorm.DB) {
 		_, token := createTestAccessKey(t, db, time.Minute*5)
@@ -153,6 +227,33 @@ func TestCheckAccessKeyPastExtensionDeadline(t *testing.T) {
 	})
 }
 
+func TestValidateAccessKey_LastUsedAt(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *gorm.DB) {
+		body, key := createTestAccessKey(t, db, time.Hour)
+
+		validated, err := ValidateAccessKey(db, body)
+		assert.NilError(t, err)
+		lastUsed := validated.LastUsedAt
+		assert.Assert(t, !lastUsed.IsZero())
+
+		// recently used keys are not saved again
+		validated, err = ValidateAccessKey(db, body)
+		assert.NilError(t, err)
+		assert.Equal(t, validated.LastUsedAt, lastUsed)
+
+		stale := time.Now().UTC().Add(-2 * lastUsedUpdateInterval)
+		assert.NilError(t, db.Model(key).Update("last_used_at", stale).Error)
+
+		validated, err = ValidateAccessKey(db, body)
+		assert.NilError(t, err)
+		assert.Assert(t, validated.LastUsedAt.After(stale))
+
+		saved, err := GetAccessKey(db, ByID(key.ID))
+		assert.NilError(t, err)
+		assert.Assert(t, saved.LastUsedAt.After(stale))
+	})
+}
+
 func createTestAccessKey(t *testing.T, db *gorm.DB, sessionDuration time.Duration) (string, *models.AccessKey) {
 	user := &models.Identity{Name: "tmp@infrahq.com"}
 	err := CreateIdentity(db, user)
@@ -0,0 +1,113 @@
+package data
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func CreateAccessRequest(db *gorm.DB, request *models.AccessRequest) error {
+	return add(db, request)
+}
+
+func GetAccessRequest(db *gorm.DB, selectors ...SelectorFunc) (*models.AccessRequest, error) {
+	return get[models.AccessRequest](db, selectors...)
+}
+
+func ListAccessRequests(db *gorm.DB, selectors ...SelectorFunc) ([]models.AccessRequest, error) {
+	db = orderBy(db, "created_at", true)
+	return list[models.AccessRequest](db, selectors...)
+}
+
+func SaveAccessRequest(db *gorm.DB, request *models.AccessRequest) error {
+	return save(db, request)
+}
+
+func ByOptionalRequestedBy(id uid.ID) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		if id == 0 {
+			return db
+		}
+
+		return db.Where("requested_by = ?", id)
+	}
+}
+
+func ByOptionalStatus(status string) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		if status == "" {
+			return db
+		}
+
+		return db.Where("status = ?", status)
+	}
+}
+
+// ByRequestedByOrResources selects access requests made by the identity, or
+// for any of the resources, including resources within them.
+func ByRequestedByOrResources(id uid.ID, resources []string) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		query := strings.Builder{}
+		query.WriteString("(requested_by = ?")
+		args := []interface{}{id}
+		for _, resource := range resources {
+			query.WriteString(" OR resource = ? OR resource LIKE ?")
+			args = append(args, resource, resource+".%")
+		}
+		query.WriteString(")")
+
+		return db.Where(query.String(), args...)
+	}
+}
+
+func CreateAccessApprover(db *gorm.DB, approver *models.AccessApprover) error {
+	// check first if it exists
+	approvers, err := list[models.AccessApprover](db, BySubject(approver.Subject), ByResource(approver.Resource))
+	if err != nil {
+		return err
+	}
+
+	if len(approvers) > 0 {
+		*approver = approvers[0]
+		return nil
+	}
+
+	return add(db, approver)
+}
+
+func GetAccessApprover(db *gorm.DB, selectors ...SelectorFunc) (*models.AccessApprover, error) {
+	return get[models.AccessApprover](db, selectors...)
+}
+
+func ListAccessApprovers(db *gorm.DB, selectors ...SelectorFunc) ([]models.AccessApprover, error) {
+	return list[models.AccessApprover](db, selectors...)
+}
+
+func DeleteAccessApprovers(db *gorm.DB, selectors ...SelectorFunc) error {
+	toDelete, err := ListAccessApprovers(db, selectors...)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uid.ID, 0)
+	for _, a := range toDelete {
+		ids = append(ids, a.ID)
+	}
+
+	return deleteAll[models.AccessApprover](db, ByIDs(ids))
+}
+
+func BySubjects(subjects []uid.PolymorphicID) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("subject IN (?)", subjects)
+	}
+}
+
+func ByResources(resources []string) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("resource IN (?)", resources)
+	}
+}
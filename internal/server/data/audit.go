@@ -0,0 +1,69 @@
+package data
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func CreateAuditEvent(db *gorm.DB, event *models.AuditEvent) error {
+	return add(db, event)
+}
+
+func ListAuditEvents(db *gorm.DB, selectors ...SelectorFunc) ([]models.AuditEvent, error) {
+	db = orderBy(db, "created_at", true)
+	return list[models.AuditEvent](db, selectors...)
+}
+
+func ByOptionalActorID(id uid.ID) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		if id == 0 {
+			return db
+		}
+
+		return db.Where("actor_id = ?", id)
+	}
+}
+
+func ByOptionalTargetKind(kind string) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		if kind == "" {
+			return db
+		}
+
+		return db.Where("target_kind = ?", kind)
+	}
+}
+
+func ByOptionalTargetID(id uid.ID) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		if id == 0 {
+			return db
+		}
+
+		return db.Where("target_id = ?", id)
+	}
+}
+
+func ByOptionalCreatedAfter(t time.Time) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		if t.IsZero() {
+			return db
+		}
+
+		return db.Where("created_at > ?", t)
+	}
+}
+
+func ByOptionalCreatedBefore(t time.Time) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		if t.IsZero() {
+			return db
+		}
+
+		return db.Where("created_at < ?", t)
+	}
+}
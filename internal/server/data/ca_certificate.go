@@ -0,0 +1,32 @@
+package data
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// ListCACertificates returns the certificates of the certificate authority
+// ordered by the time they expire, oldest first.
+func ListCACertificates(db *gorm.DB, selectors ...SelectorFunc) ([]models.CACertificate, error) {
+	selectors = append(selectors, OrderBy("expires_at"))
+	return list[models.CACertificate](db, selectors...)
+}
+
+// SaveCACertificates replaces the stored certificates of the certificate
+// authority with certs.
+func SaveCACertificates(db *gorm.DB, certs []models.CACertificate) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		ids := make([]uid.ID, 0, len(certs))
+		for i := range certs {
+			if err := add(tx, &certs[i]); err != nil {
+				return err
+			}
+
+			ids = append(ids, certs[i].ID)
+		}
+
+		return deleteAll[models.CACertificate](tx, NotIDs(ids))
+	})
+}
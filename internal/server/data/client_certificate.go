@@ -0,0 +1,47 @@
+package data
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// CreateClientCertificate records a client certificate that was signed for an
+// identity. The expired certificates of the identity are deleted.
+func CreateClientCertificate(db *gorm.DB, cert *models.ClientCertificate) error {
+	err := deleteAll[models.ClientCertificate](db, ByIdentityID(cert.IdentityID), ByExpiredClientCertificate(time.Now().UTC()))
+	if err != nil {
+		return err
+	}
+
+	return add(db, cert)
+}
+
+// GetClientCertificate returns the client certificate of the identity with the
+// serial number. It returns ErrNotFound when the certificate was revoked.
+func GetClientCertificate(db *gorm.DB, identityID uid.ID, serialNumber string) (*models.ClientCertificate, error) {
+	return get[models.ClientCertificate](db, ByIdentityID(identityID), BySerialNumber(serialNumber))
+}
+
+// RevokeClientCertificates revokes all the client certificates that were
+// signed for the identity.
+func RevokeClientCertificates(db *gorm.DB, identityID uid.ID) error {
+	return deleteAll[models.ClientCertificate](db, ByIdentityID(identityID))
+}
+
+func BySerialNumber(serialNumber string) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("serial_number = ?", serialNumber)
+	}
+}
+
+// ByExpiredClientCertificate selects the client certificates that expired
+// before now.
+func ByExpiredClientCertificate(now time.Time) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("expires_at < ?", now)
+	}
+}
@@ -0,0 +1,42 @@
+package data
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestClientCertificates(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *gorm.DB) {
+		elaine := &models.Identity{Name: "ebenes@infrahq.com"}
+		assert.NilError(t, CreateIdentity(db, elaine))
+
+		expired := &models.ClientCertificate{IdentityID: elaine.ID, SerialNumber: "1", ExpiresAt: time.Now().Add(-time.Minute)}
+		assert.NilError(t, CreateClientCertificate(db, expired))
+
+		cert := &models.ClientCertificate{IdentityID: elaine.ID, SerialNumber: "2", ExpiresAt: time.Now().Add(time.Hour)}
+		assert.NilError(t, CreateClientCertificate(db, cert))
+
+		got, err := GetClientCertificate(db, elaine.ID, "2")
+		assert.NilError(t, err)
+		assert.Equal(t, got.ID, cert.ID)
+
+		// expired certificates are deleted when another is created
+		_, err = GetClientCertificate(db, elaine.ID, "1")
+		assert.Assert(t, errors.Is(err, internal.ErrNotFound))
+
+		_, err = GetClientCertificate(db, 12345, "2")
+		assert.Assert(t, errors.Is(err, internal.ErrNotFound))
+
+		assert.NilError(t, RevokeClientCertificates(db, elaine.ID))
+
+		_, err = GetClientCertificate(db, elaine.ID, "2")
+		assert.Assert(t, errors.Is(err, internal.ErrNotFound))
+	})
+}
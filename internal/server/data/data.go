@@ -115,6 +115,10 @@ func list[T models.Modelable](db *gorm.DB, selectors ...SelectorFunc) ([]T, erro
 		db = selector(db)
 	}
 
+	if pg, ok := paginationFrom(db); ok {
+		return listPage[T](db, pg)
+	}
+
 	result := make([]T, 0)
 	if err := db.Model((*T)(nil)).Find(&result).Error; err != nil {
 		return nil, err
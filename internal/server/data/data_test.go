@@ -172,7 +172,7 @@ func TestPaginationSelector(t *testing.T) {
 
 		pg := models.Pagination{Page: 1, Limit: 10}
 
-		actual, err := ListIdentities(db, ByPagination(pg))
+		actual, err := ListIdentities(db, ByPagination(&pg))
 		assert.NilError(t, err)
 		assert.Equal(t, len(actual), 10)
 		for i := 0; i < pg.Limit; i++ {
@@ -180,7 +180,7 @@ func TestPaginationSelector(t *testing.T) {
 		}
 
 		pg.Page = 2
-		actual, err = ListIdentities(db, ByPagination(pg))
+		actual, err = ListIdentities(db, ByPagination(&pg))
 		assert.NilError(t, err)
 		assert.Equal(t, len(actual), 10)
 		for i := 0; i < pg.Limit; i++ {
@@ -188,7 +188,7 @@ func TestPaginationSelector(t *testing.T) {
 		}
 
 		pg.Page = 3
-		actual, err = ListIdentities(db, ByPagination(pg))
+		actual, err = ListIdentities(db, ByPagination(&pg))
 		assert.NilError(t, err)
 		assert.Equal(t, len(actual), 6)
 
@@ -197,7 +197,7 @@ func TestPaginationSelector(t *testing.T) {
 		}
 
 		pg.Page, pg.Limit = 1, 26
-		actual, err = ListIdentities(db, ByPagination(pg))
+		actual, err = ListIdentities(db, ByPagination(&pg))
 		assert.NilError(t, err)
 		for i, user := range actual {
 			assert.Equal(t, user.Name, letters[i])
@@ -205,3 +205,63 @@ func TestPaginationSelector(t *testing.T) {
 
 	})
 }
+
+func TestPaginationSelector_Cursor(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *gorm.DB) {
+		letters := make([]string, 0, 26)
+		for r := 'a'; r < 'a'+26; r++ {
+			letters = append(letters, string(r))
+			err := db.Create(&models.Identity{Name: string(r)}).Error
+			assert.NilError(t, err)
+		}
+
+		pg := models.Pagination{Limit: 10}
+		actual, err := ListIdentities(db, ByPagination(&pg))
+		assert.NilError(t, err)
+		assert.Equal(t, pg.TotalCount, 26)
+		assert.Equal(t, actual[len(actual)-1].Name, "j")
+		assert.Equal(t, pg.NextCursor, actual[len(actual)-1].ID)
+
+		// a record added to an earlier page is not repeated on the next page
+		err = db.Create(&models.Identity{Name: "aa"}).Error
+		assert.NilError(t, err)
+
+		names := func(identities []models.Identity) []string {
+			var result []string
+			for _, identity := range identities {
+				result = append(result, identity.Name)
+			}
+			return result
+		}
+
+		pg = models.Pagination{Limit: 10, Cursor: pg.NextCursor}
+		actual, err = ListIdentities(db, ByPagination(&pg))
+		assert.NilError(t, err)
+		assert.DeepEqual(t, names(actual), letters[10:20])
+		assert.Equal(t, pg.TotalCount, 27)
+		assert.Assert(t, pg.NextCursor != 0)
+
+		pg = models.Pagination{Limit: 10, Cursor: pg.NextCursor}
+		actual, err = ListIdentities(db, ByPagination(&pg))
+		assert.NilError(t, err)
+		assert.DeepEqual(t, names(actual), letters[20:])
+		assert.Equal(t, pg.NextCursor, uid.ID(0))
+	})
+}
+
+func TestPaginationSelector_Offset(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *gorm.DB) {
+		for r := 'a'; r < 'a'+26; r++ {
+			err := db.Create(&models.Identity{Name: string(r)}).Error
+			assert.NilError(t, err)
+		}
+
+		pg := models.Pagination{Offset: 3, Limit: 2}
+		actual, err := ListIdentities(db, ByPagination(&pg))
+		assert.NilError(t, err)
+		assert.Equal(t, pg.TotalCount, 26)
+		assert.Equal(t, len(actual), 2)
+		assert.Equal(t, actual[0].Name, "d")
+		assert.Equal(t, actual[1].Name, "e")
+	})
+}
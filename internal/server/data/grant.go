@@ -1,6 +1,9 @@
 package data
 
 import (
+	"fmt"
+	"time"
+
 	"gorm.io/gorm"
 
 	"github.com/infrahq/infra/internal/server/models"
@@ -9,14 +12,19 @@ import (
 
 func CreateGrant(db *gorm.DB, grant *models.Grant) error {
 	// check first if it exists
-	grants, err := list[models.Grant](db, BySubject(grant.Subject), ByResource(grant.Resource))
+	grants, err := list[models.Grant](db, BySubject(grant.Subject), ByResource(grant.Resource), ByNotExpired())
 	if err != nil {
 		return err
 	}
 
 	for _, existingGrant := range grants {
 		if existingGrant.Privilege == grant.Privilege {
-			// exact match exists, no need to store it twice.
+			// exact match exists, no need to store it twice. Extend the
+			// existing grant if the new one is valid for longer.
+			if existingGrant.ExpiresAt != nil && (grant.ExpiresAt == nil || grant.ExpiresAt.After(*existingGrant.ExpiresAt)) {
+				existingGrant.ExpiresAt = grant.ExpiresAt
+				return save(db, &existingGrant)
+			}
 			return nil
 		}
 	}
@@ -24,6 +32,21 @@ func CreateGrant(db *gorm.DB, grant *models.Grant) error {
 	return add(db, grant)
 }
 
+// GrantsVersion returns a value that changes whenever a grant is created,
+// updated, or deleted.
+func GrantsVersion(db *gorm.DB) (string, error) {
+	var count int64
+	var updated, deleted string
+	row := db.Unscoped().Model(&models.Grant{}).
+		Select("COUNT(*), COALESCE(CAST(MAX(updated_at) AS TEXT), ''), COALESCE(CAST(MAX(deleted_at) AS TEXT), '')").
+		Row()
+	if err := row.Scan(&count, &updated, &deleted); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d/%s/%s", count, updated, deleted), nil
+}
+
 func GetGrant(db *gorm.DB, selectors ...SelectorFunc) (*models.Grant, error) {
 	return get[models.Grant](db, selectors...)
 }
@@ -46,6 +69,18 @@ func DeleteGrants(db *gorm.DB, selectors ...SelectorFunc) error {
 	return deleteAll[models.Grant](db, ByIDs(ids))
 }
 
+// DeleteExpiredGrants removes all grants that have expired.
+func DeleteExpiredGrants(db *gorm.DB) error {
+	return DeleteGrants(db, ByExpired())
+}
+
+// ByExpired selects grants with an expiry that has passed.
+func ByExpired() SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("grants.expires_at IS NOT NULL AND grants.expires_at <= ?", time.Now())
+	}
+}
+
 func ByOptionalPrivilege(s string) SelectorFunc {
 	return func(db *gorm.DB) *gorm.DB {
 		if s == "" {
@@ -77,3 +112,20 @@ func ByResource(s string) SelectorFunc {
 		return db.Where("resource = ?", s)
 	}
 }
+
+// ByResourcePattern selects grants with a resource that uses wildcards.
+func ByResourcePattern() SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("resource LIKE ?", "%*%")
+	}
+}
+
+// ByResourcePrefix selects grants for the resource, and for any resource within
+// it. For example, the prefix "production" selects grants for "production" and
+// "production.web", but not for "production-2".
+func ByResourcePrefix(prefix string) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		child := prefix + "."
+		return db.Where("resource = ? OR substr(resource, 1, ?) = ?", prefix, len(child), child)
+	}
+}
@@ -2,6 +2,7 @@ package data
 
 import (
 	"testing"
+	"time"
 
 	"gorm.io/gorm"
 	"gotest.tools/v3/assert"
@@ -30,3 +31,88 @@ func TestDuplicateGrant(t *testing.T) {
 		assert.Assert(t, is.Len(grants, 1))
 	})
 }
+
+func TestCreateGrant_ExtendsExpiry(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *gorm.DB) {
+		soon := time.Now().Add(time.Minute)
+		later := time.Now().Add(time.Hour)
+
+		g := models.Grant{Subject: "i:1234567", Privilege: "view", Resource: "infra", ExpiresAt: &soon}
+		err := CreateGrant(db, &g)
+		assert.NilError(t, err)
+
+		g2 := models.Grant{Subject: "i:1234567", Privilege: "view", Resource: "infra", ExpiresAt: &later}
+		err = CreateGrant(db, &g2)
+		assert.NilError(t, err)
+
+		grants, err := ListGrants(db, BySubject("i:1234567"), ByResource("infra"))
+		assert.NilError(t, err)
+		assert.Assert(t, is.Len(grants, 1))
+		assert.Assert(t, grants[0].ExpiresAt.Equal(later))
+
+		// a grant without an expiry replaces the expiry
+		g3 := models.Grant{Subject: "i:1234567", Privilege: "view", Resource: "infra"}
+		err = CreateGrant(db, &g3)
+		assert.NilError(t, err)
+
+		grants, err = ListGrants(db, BySubject("i:1234567"), ByResource("infra"))
+		assert.NilError(t, err)
+		assert.Assert(t, is.Len(grants, 1))
+		assert.Assert(t, grants[0].ExpiresAt == nil)
+	})
+}
+
+func TestDeleteExpiredGrants(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *gorm.DB) {
+		past := time.Now().Add(-time.Minute)
+		future := time.Now().Add(time.Hour)
+
+		expired := models.Grant{Subject: "i:1234567", Privilege: "view", Resource: "expired", ExpiresAt: &past}
+		active := models.Grant{Subject: "i:1234567", Privilege: "view", Resource: "active", ExpiresAt: &future}
+		permanent := models.Grant{Subject: "i:1234567", Privilege: "view", Resource: "permanent"}
+
+		for _, g := range []*models.Grant{&expired, &active, &permanent} {
+			err := CreateGrant(db, g)
+			assert.NilError(t, err)
+		}
+
+		grants, err := ListGrants(db, BySubject("i:1234567"), ByNotExpired())
+		assert.NilError(t, err)
+		assert.Assert(t, is.Len(grants, 2))
+
+		err = DeleteExpiredGrants(db)
+		assert.NilError(t, err)
+
+		grants, err = ListGrants(db, BySubject("i:1234567"))
+		assert.NilError(t, err)
+		assert.Assert(t, is.Len(grants, 2))
+		for _, g := range grants {
+			assert.Assert(t, g.Resource != "expired")
+		}
+
+		_, err = GetGrant(db.Unscoped(), ByID(expired.ID))
+		assert.NilError(t, err, "expired grant should be soft deleted")
+	})
+}
+
+func TestByResourcePrefix(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *gorm.DB) {
+		for _, resource := range []string{"production", "production.web", "production.web.pods", "production-2", "production_2.web", "staging"} {
+			err := CreateGrant(db, &models.Grant{Subject: "i:1234567", Privilege: "view", Resource: resource})
+			assert.NilError(t, err)
+		}
+
+		grants, err := ListGrants(db, ByResourcePrefix("production"))
+		assert.NilError(t, err)
+
+		var resources []string
+		for _, g := range grants {
+			resources = append(resources, g.Resource)
+		}
+		assert.DeepEqual(t, resources, []string{"production", "production.web", "production.web.pods"})
+
+		grants, err = ListGrants(db, ByResourcePrefix("production_"))
+		assert.NilError(t, err)
+		assert.Assert(t, is.Len(grants, 0))
+	})
+}
@@ -1,6 +1,8 @@
 package data
 
 import (
+	"fmt"
+
 	"gorm.io/gorm"
 
 	"github.com/infrahq/infra/internal/server/models"
@@ -16,7 +18,7 @@ func GetGroup(db *gorm.DB, selectors ...SelectorFunc) (*models.Group, error) {
 }
 
 func ListGroups(db *gorm.DB, selectors ...SelectorFunc) ([]models.Group, error) {
-	db = db.Order("name ASC")
+	db = orderBy(db, "name", false)
 	return list[models.Group](db, selectors...)
 }
 
@@ -28,6 +30,17 @@ func ByGroupMember(id uid.ID) SelectorFunc {
 	}
 }
 
+// ByCreatedByProvider selects groups that were created by the provider.
+func ByCreatedByProvider(providerID uid.ID) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("created_by_provider = ?", providerID)
+	}
+}
+
+func SaveGroup(db *gorm.DB, group *models.Group) error {
+	return save(db, group)
+}
+
 func DeleteGroups(db *gorm.DB, selectors ...SelectorFunc) error {
 	toDelete, err := ListGroups(db, selectors...)
 	if err != nil {
@@ -46,3 +59,34 @@ func DeleteGroups(db *gorm.DB, selectors ...SelectorFunc) error {
 
 	return deleteAll[models.Group](db, ByIDs(ids))
 }
+
+func AddUsersToGroup(db *gorm.DB, groupID uid.ID, idsToAdd []uid.ID) error {
+	for _, id := range idsToAdd {
+		var ids []uid.ID
+		if err := db.Raw("SELECT identity_id FROM identities_groups WHERE identity_id = ? AND group_id = ?", id, groupID).Scan(&ids).Error; err != nil {
+			return fmt.Errorf("select: %w", handleError(err))
+		}
+
+		if len(ids) == 0 {
+			err := db.Exec("insert into identities_groups (identity_id, group_id) values (?, ?)", id, groupID).Error
+			if err != nil {
+				return fmt.Errorf("insert: %w", handleError(err))
+			}
+		}
+	}
+
+	return nil
+}
+
+func RemoveUsersFromGroup(db *gorm.DB, groupID uid.ID, idsToRemove []uid.ID) error {
+	if len(idsToRemove) == 0 {
+		return nil
+	}
+
+	err := db.Exec("delete from identities_groups where group_id = ? and identity_id in (?)", groupID, idsToRemove).Error
+	if err != nil {
+		return fmt.Errorf("delete: %w", handleError(err))
+	}
+
+	return nil
+}
@@ -93,6 +93,10 @@ func AssignIdentityToGroups(db *gorm.DB, user *models.Identity, provider *models
 }
 
 func CreateIdentity(db *gorm.DB, identity *models.Identity) error {
+	if identity.Kind == "" {
+		identity.Kind = models.UserKind
+	}
+
 	return add(db, identity)
 }
 
@@ -101,7 +105,7 @@ func GetIdentity(db *gorm.DB, selectors ...SelectorFunc) (*models.Identity, erro
 }
 
 func ListIdentities(db *gorm.DB, selectors ...SelectorFunc) ([]models.Identity, error) {
-	db = db.Order("name ASC")
+	db = orderBy(db, "name", false)
 	return list[models.Identity](db, selectors...)
 }
 
@@ -123,11 +127,36 @@ func DeleteIdentities(db *gorm.DB, selectors ...SelectorFunc) error {
 		if err != nil {
 			return err
 		}
+
+		if err := DeleteAccessKeys(db, ByIssuedFor(i.ID)); err != nil {
+			return err
+		}
+
+		if err := deleteAll[models.Credential](db, ByIdentityID(i.ID)); err != nil {
+			return err
+		}
+
+		if err := DeleteProviderUsers(db, ByIdentityID(i.ID)); err != nil {
+			return err
+		}
 	}
 
 	return deleteAll[models.Identity](db, ByIDs(ids))
 }
 
+// ByIdentityProviderID selects identities that are users of the provider.
+func ByIdentityProviderID(providerID uid.ID) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("id IN (SELECT identity_id FROM provider_users WHERE provider_id = ? AND deleted_at IS NULL)", providerID)
+	}
+}
+
+func ByIdentityKind(kind models.IdentityKind) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("kind = ?", kind)
+	}
+}
+
 func SaveIdentity(db *gorm.DB, identity *models.Identity) error {
 	return save(db, identity)
 }
@@ -471,6 +471,8 @@ func migrate(db *gorm.DB) error {
 		},
 		addKindToProviders(),
 		dropCertificateTables(),
+		addKindToIdentities(),
+		backfillAccessKeySessions(),
 		// next one here
 	})
 
@@ -508,6 +510,16 @@ func initializeSchema(db *gorm.DB) error {
 		&models.EncryptionKey{},
 		&models.Credential{},
 		&models.ProviderUser{},
+		&models.AuditEvent{},
+		&models.AccessRequest{},
+		&models.AccessApprover{},
+		&models.Webhook{},
+		&models.WebhookDelivery{},
+		&models.Role{},
+		&models.PasswordResetToken{},
+		&models.SigningKey{},
+		&models.CACertificate{},
+		&models.ClientCertificate{},
 	}
 
 	for _, table := range tables {
@@ -549,3 +561,93 @@ func dropCertificateTables() *gormigrate.Migration {
 		},
 	}
 }
+
+// set the kind of existing identities, which were all users before service
+// accounts were added
+func addKindToIdentities() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202206211530",
+		Migrate: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasTable(&models.Identity{}) {
+				// the table is created with the kind column by initializeSchema
+				return nil
+			}
+
+			if !tx.Migrator().HasColumn(&models.Identity{}, "kind") {
+				logging.S.Debug("migrating identity table kind")
+				if err := tx.Migrator().AddColumn(&models.Identity{}, "kind"); err != nil {
+					return err
+				}
+			}
+
+			return tx.Table("identities").Where("kind IS NULL OR kind = ?", "").Update("kind", models.UserKind).Error
+		},
+	}
+}
+
+// mark the access keys that were created by a login before sessions were
+// added. Those keys have no creator, and the default name of a key, which is
+// the name of the identity and the ID of the key.
+func backfillAccessKeySessions() *gormigrate.Migration {
+	return &gormigrate.Migration{
+		ID: "202207011200",
+		Migrate: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasTable(&models.AccessKey{}) {
+				// the table is created with the session column by initializeSchema
+				return nil
+			}
+
+			for _, column := range []string{"session", "created_by"} {
+				if !tx.Migrator().HasColumn(&models.AccessKey{}, column) {
+					if err := tx.Migrator().AddColumn(&models.AccessKey{}, column); err != nil {
+						return err
+					}
+				}
+			}
+
+			var keys []struct {
+				ID        uid.ID
+				Name      string
+				IssuedFor uid.ID
+			}
+
+			err := tx.Table("access_keys").
+				Select("id, name, issued_for").
+				Where("deleted_at IS NULL").
+				Where("created_by IS NULL OR created_by = 0").
+				Where("session IS NULL OR session = ?", false).
+				Scan(&keys).Error
+			if err != nil {
+				return err
+			}
+
+			var identities []struct {
+				ID   uid.ID
+				Name string
+			}
+
+			if err := tx.Table("identities").Select("id, name").Scan(&identities).Error; err != nil {
+				return err
+			}
+
+			names := make(map[uid.ID]string, len(identities))
+			for _, identity := range identities {
+				names[identity.ID] = identity.Name
+			}
+
+			var sessions []uid.ID
+			for _, key := range keys {
+				if key.Name == "" || key.Name == fmt.Sprintf("%s-%s", names[key.IssuedFor], key.ID) {
+					sessions = append(sessions, key.ID)
+				}
+			}
+
+			if len(sessions) == 0 {
+				return nil
+			}
+
+			logging.S.Debugf("migrating %d access keys to sessions", len(sessions))
+			return tx.Table("access_keys").Where("id IN ?", sessions).Update("session", true).Error
+		},
+	}
+}
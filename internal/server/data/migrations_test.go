@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	gocmp "github.com/google/go-cmp/cmp"
 	"github.com/infrahq/secrets"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/internal/testing/patch"
+	"github.com/infrahq/infra/uid"
 )
 
 // see loadSQL for setting up your own migration test
@@ -178,3 +180,40 @@ func tableExists(t *testing.T, db *gorm.DB, name string) bool {
 	assert.NilError(t, err)
 	return count > 0
 }
+
+func TestMigration_AddKindToIdentities(t *testing.T) {
+	driver := setupWithNoMigrations(t, func(db *gorm.DB) {
+		loadSQL(t, db, "202206161733")
+	})
+
+	db, err := NewDB(driver, nil)
+	assert.NilError(t, err)
+
+	connector, err := GetIdentity(db, ByName("connector"))
+	assert.NilError(t, err)
+	assert.Equal(t, connector.Kind, models.UserKind)
+}
+
+func TestMigration_BackfillAccessKeySessions(t *testing.T) {
+	login, named := uid.ID(1001), uid.ID(1002)
+
+	driver := setupWithNoMigrations(t, func(db *gorm.DB) {
+		loadSQL(t, db, "202206161733")
+
+		stmt := "INSERT INTO access_keys(id, name, issued_for, provider_id, expires_at, key_id) VALUES(?, ?, ?, ?, ?, ?)"
+		expires := time.Now().Add(time.Hour)
+		assert.NilError(t, db.Exec(stmt, login, "connector-"+login.String(), 60484726289670145, 60484726289670144, expires, "loginkey01").Error)
+		assert.NilError(t, db.Exec(stmt, named, "deploy", 60484726289670145, 60484726289670144, expires, "namedkey01").Error)
+	})
+
+	db, err := NewDB(driver, nil)
+	assert.NilError(t, err)
+
+	key, err := GetAccessKey(db, ByID(login))
+	assert.NilError(t, err)
+	assert.Assert(t, key.Session)
+
+	key, err = GetAccessKey(db, ByID(named))
+	assert.NilError(t, err)
+	assert.Assert(t, !key.Session)
+}
@@ -0,0 +1,125 @@
+package data
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+const (
+	paginationSetting = "infra:pagination"
+	orderSetting      = "infra:order"
+)
+
+// listOrder is the column used to sort a list. It is also the key used for
+// cursor pagination, with the primary key used to break ties.
+type listOrder struct {
+	column string
+	desc   bool
+}
+
+// orderBy sorts a list by column. Use it in place of db.Order so that the
+// order can be used to paginate the list with a cursor.
+func orderBy(db *gorm.DB, column string, desc bool) *gorm.DB {
+	return db.
+		Order(clause.OrderByColumn{Column: clause.Column{Name: column}, Desc: desc}).
+		Set(orderSetting, listOrder{column: column, desc: desc})
+}
+
+// ByPagination limits a list to a single page. The TotalCount and NextCursor
+// of pg are set when the list is queried.
+func ByPagination(pg *models.Pagination) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		if pg == nil || (pg.Page == 0 && pg.Limit == 0 && pg.Cursor == 0 && pg.Offset == 0) {
+			return db
+		}
+
+		return db.Set(paginationSetting, pg)
+	}
+}
+
+func paginationFrom(db *gorm.DB) (*models.Pagination, bool) {
+	v, ok := db.Get(paginationSetting)
+	if !ok {
+		return nil, false
+	}
+
+	pg, ok := v.(*models.Pagination)
+	return pg, ok
+}
+
+// listPage queries a single page of a list. Pages that follow a cursor use
+// keyset pagination, so that records added or removed from earlier pages do
+// not cause records to be skipped or repeated.
+func listPage[T models.Modelable](db *gorm.DB, pg *models.Pagination) ([]T, error) {
+	var total int64
+	if err := db.Session(&gorm.Session{}).Model((*T)(nil)).Count(&total).Error; err != nil {
+		return nil, err
+	}
+	pg.TotalCount = int(total)
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	table := stmt.Schema.Table
+
+	order := listOrder{column: "id"}
+	if v, ok := db.Get(orderSetting); ok {
+		order = v.(listOrder)
+	}
+
+	query := db.Session(&gorm.Session{})
+	switch {
+	case pg.Cursor != 0:
+		query = query.Where(keysetCondition(table, order, pg))
+	case pg.Offset > 0:
+		query = query.Offset(pg.Offset)
+	case pg.Page > 1:
+		query = query.Offset(pg.Limit * (pg.Page - 1))
+	}
+
+	// order by the primary key so that the order is stable when the order
+	// column has duplicate values
+	query = query.Order(clause.OrderByColumn{Column: clause.Column{Table: table, Name: "id"}, Desc: order.desc})
+
+	if pg.Limit > 0 {
+		// query one extra record to find out if there is another page
+		query = query.Limit(pg.Limit + 1)
+	}
+
+	result := make([]T, 0)
+	if err := query.Model((*T)(nil)).Find(&result).Error; err != nil {
+		return nil, err
+	}
+
+	pg.NextCursor = 0
+	if pg.Limit > 0 && len(result) > pg.Limit {
+		result = result[:pg.Limit]
+		pg.NextCursor = result[len(result)-1].Primary()
+	}
+
+	return result, nil
+}
+
+// keysetCondition selects the records that sort after the cursor. The order
+// column value of the cursor record is read with a subquery so that the cursor
+// only needs to contain the ID.
+func keysetCondition(table string, order listOrder, pg *models.Pagination) clause.Expr {
+	cmp := ">"
+	if order.desc {
+		cmp = "<"
+	}
+
+	if order.column == "id" {
+		return gorm.Expr(fmt.Sprintf("%s.id %s ?", table, cmp), pg.Cursor)
+	}
+
+	value := fmt.Sprintf("(SELECT %[2]s FROM %[1]s WHERE id = ?)", table, order.column)
+	return gorm.Expr(
+		fmt.Sprintf("(%[1]s.%[2]s %[3]s %[4]s OR (%[1]s.%[2]s = %[4]s AND %[1]s.id %[3]s ?))", table, order.column, cmp, value),
+		pg.Cursor, pg.Cursor, pg.Cursor)
+}
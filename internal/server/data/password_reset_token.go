@@ -0,0 +1,68 @@
+package data
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/generate"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// passwordResetTokenLength is the length of the tokens sent to users.
+const passwordResetTokenLength = 32
+
+// CreatePasswordResetToken creates a token for the identity that expires after
+// ttl, and returns the token.
+func CreatePasswordResetToken(db *gorm.DB, identityID uid.ID, ttl time.Duration) (string, error) {
+	token, err := generate.CryptoRandom(passwordResetTokenLength, generate.CharsetAlphaNumeric)
+	if err != nil {
+		return "", err
+	}
+
+	prt := &models.PasswordResetToken{
+		IdentityID:    identityID,
+		TokenChecksum: secretChecksum(token),
+		ExpiresAt:     time.Now().UTC().Add(ttl),
+	}
+
+	if err := add(db, prt); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// GetPasswordResetToken returns the unexpired password reset token that token
+// is the secret of.
+func GetPasswordResetToken(db *gorm.DB, token string) (*models.PasswordResetToken, error) {
+	prt, err := get[models.PasswordResetToken](db, ByTokenChecksum(secretChecksum(token)))
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().UTC().After(prt.ExpiresAt) {
+		return nil, fmt.Errorf("%w: password reset token expired", internal.ErrNotFound)
+	}
+
+	return prt, nil
+}
+
+func ListPasswordResetTokens(db *gorm.DB, selectors ...SelectorFunc) ([]models.PasswordResetToken, error) {
+	return list[models.PasswordResetToken](db, selectors...)
+}
+
+// DeletePasswordResetTokens deletes the password reset tokens of the identity,
+// so that none of them can be used again.
+func DeletePasswordResetTokens(db *gorm.DB, identityID uid.ID) error {
+	return deleteAll[models.PasswordResetToken](db, ByIdentityID(identityID))
+}
+
+func ByTokenChecksum(checksum []byte) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("token_checksum = ?", checksum)
+	}
+}
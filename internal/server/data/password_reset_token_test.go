@@ -0,0 +1,40 @@
+package data
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestPasswordResetToken(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *gorm.DB) {
+		elaine := &models.Identity{Name: "ebenes@infrahq.com"}
+		assert.NilError(t, CreateIdentity(db, elaine))
+
+		token, err := CreatePasswordResetToken(db, elaine.ID, time.Hour)
+		assert.NilError(t, err)
+		assert.Equal(t, len(token), passwordResetTokenLength)
+
+		prt, err := GetPasswordResetToken(db, token)
+		assert.NilError(t, err)
+		assert.Equal(t, prt.IdentityID, elaine.ID)
+
+		_, err = GetPasswordResetToken(db, "not-the-token")
+		assert.Assert(t, errors.Is(err, internal.ErrNotFound))
+
+		expired, err := CreatePasswordResetToken(db, elaine.ID, -time.Minute)
+		assert.NilError(t, err)
+		_, err = GetPasswordResetToken(db, expired)
+		assert.ErrorContains(t, err, "password reset token expired")
+
+		assert.NilError(t, DeletePasswordResetTokens(db, elaine.ID))
+		_, err = GetPasswordResetToken(db, token)
+		assert.Assert(t, errors.Is(err, internal.ErrNotFound))
+	})
+}
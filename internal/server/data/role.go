@@ -0,0 +1,44 @@
+package data
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func CreateRole(db *gorm.DB, role *models.Role) error {
+	return add(db, role)
+}
+
+func GetRole(db *gorm.DB, selectors ...SelectorFunc) (*models.Role, error) {
+	return get[models.Role](db, selectors...)
+}
+
+func ListRoles(db *gorm.DB, selectors ...SelectorFunc) ([]models.Role, error) {
+	db = orderBy(db, "name", false)
+	return list[models.Role](db, selectors...)
+}
+
+func SaveRole(db *gorm.DB, role *models.Role) error {
+	return save(db, role)
+}
+
+// DeleteRoles deletes the roles, and the grants of those roles.
+func DeleteRoles(db *gorm.DB, selectors ...SelectorFunc) error {
+	toDelete, err := ListRoles(db, selectors...)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uid.ID, 0)
+	for _, r := range toDelete {
+		ids = append(ids, r.ID)
+
+		if err := DeleteGrants(db, ByPrivilege(r.Name), ByResource("infra")); err != nil {
+			return err
+		}
+	}
+
+	return deleteAll[models.Role](db, ByIDs(ids))
+}
@@ -6,7 +6,6 @@ import (
 
 	"gorm.io/gorm"
 
-	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/uid"
 )
 
@@ -124,10 +123,7 @@ func ByUserID(userID uid.ID) SelectorFunc {
 
 func ByNotExpired() SelectorFunc {
 	return func(db *gorm.DB) *gorm.DB {
-		return db.
-			Where("expires_at > ?", time.Now().UTC()).
-			Or("expires_at is ?", time.Time{}).
-			Or("expires_at is null")
+		return db.Where("(expires_at > ? OR expires_at = ? OR expires_at is null)", time.Now().UTC(), time.Time{})
 	}
 }
 
@@ -140,16 +136,6 @@ func ByNotExpiredOrExtended() SelectorFunc {
 	}
 }
 
-func ByPagination(pg models.Pagination) SelectorFunc {
-	return func(db *gorm.DB) *gorm.DB {
-		if pg.Page == 0 && pg.Limit == 0 {
-			return db
-		}
-		resultsForPage := pg.Limit * (pg.Page - 1)
-		return db.Offset(resultsForPage).Limit(pg.Limit)
-	}
-}
-
 func CreatedBy(id uid.ID) SelectorFunc {
 	return func(db *gorm.DB) *gorm.DB {
 		return db.Where("created_by = ?", id)
@@ -0,0 +1,194 @@
+package data
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+// IdentityTokenLifetime is how long a token created by CreateIdentityToken is
+// valid.
+const IdentityTokenLifetime = 5 * time.Minute
+
+// SigningKeyPublishLead is how long a new signing key is published before it
+// is used. Destinations cache the published keys, so this must be longer than
+// the time they wait before fetching the keys again.
+var SigningKeyPublishLead = time.Hour
+
+// signingKeyRetention is how long a signing key is kept after the next key
+// becomes active, so that the tokens it signed can be verified until they
+// expire. It includes the clock drift allowed for by the token NotBefore.
+const signingKeyRetention = IdentityTokenLifetime + 5*time.Minute
+
+// newSigningKey generates an ed25519 signing key that is used from activeAt.
+func newSigningKey(activeAt time.Time) (*models.SigningKey, error) {
+	pubkey, seckey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	sec := jose.JSONWebKey{Key: seckey, KeyID: "", Algorithm: string(jose.ED25519), Use: "sig"}
+
+	thumb, err := sec.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	sec.KeyID = base64.URLEncoding.EncodeToString(thumb)
+
+	pub := jose.JSONWebKey{Key: pubkey, KeyID: sec.KeyID, Algorithm: string(jose.ED25519), Use: "sig"}
+
+	secs, err := sec.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	pubs, err := pub.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SigningKey{
+		KeyID:      sec.KeyID,
+		PrivateJWK: secs,
+		PublicJWK:  pubs,
+		ActiveAt:   activeAt.UTC(),
+	}, nil
+}
+
+func CreateSigningKey(db *gorm.DB, key *models.SigningKey) error {
+	return add(db, key)
+}
+
+// ListSigningKeys returns the signing keys ordered by the time they become
+// active, oldest first.
+func ListSigningKeys(db *gorm.DB, selectors ...SelectorFunc) ([]models.SigningKey, error) {
+	selectors = append(selectors, OrderBy("active_at"))
+	return list[models.SigningKey](db, selectors...)
+}
+
+// ActiveSigningKey returns the key from keys that is used for signing at now.
+// keys must be ordered as returned by ListSigningKeys.
+func ActiveSigningKey(keys []models.SigningKey, now time.Time) (*models.SigningKey, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+
+	active := &keys[0]
+	for i := range keys {
+		if keys[i].ActiveAt.After(now) {
+			break
+		}
+		active = &keys[i]
+	}
+
+	return active, nil
+}
+
+// initializeSigningKeys creates the first signing key. The key of settings is
+// used when it exists, so that tokens signed before the upgrade stay valid,
+// and is then removed from settings so that it is only stored once.
+func initializeSigningKeys(db *gorm.DB, settings *models.Settings) error {
+	count, err := Count[models.SigningKey](db)
+	if err != nil {
+		return err
+	}
+
+	if count > 0 {
+		return clearSettingsSigningKey(db, settings)
+	}
+
+	if len(settings.PrivateJWK) == 0 {
+		key, err := newSigningKey(time.Now())
+		if err != nil {
+			return err
+		}
+
+		return CreateSigningKey(db, key)
+	}
+
+	var sec jose.JSONWebKey
+	if err := sec.UnmarshalJSON(settings.PrivateJWK); err != nil {
+		return err
+	}
+
+	err = CreateSigningKey(db, &models.SigningKey{
+		KeyID:      sec.KeyID,
+		PrivateJWK: settings.PrivateJWK,
+		PublicJWK:  settings.PublicJWK,
+		ActiveAt:   settings.CreatedAt.UTC(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return clearSettingsSigningKey(db, settings)
+}
+
+// clearSettingsSigningKey removes the key of settings once it has been
+// imported as a signing key.
+func clearSettingsSigningKey(db *gorm.DB, settings *models.Settings) error {
+	if len(settings.PrivateJWK) == 0 && len(settings.PublicJWK) == 0 {
+		return nil
+	}
+
+	settings.PrivateJWK = nil
+	settings.PublicJWK = nil
+	return SaveSettings(db, settings)
+}
+
+// RotateSigningKeys creates the next signing key once the newest key has been
+// active for interval, less SigningKeyPublishLead so that the next key is
+// published before it is used. A rotation interval of zero disables creating
+// new keys. Keys that are no longer used are deleted once the tokens they
+// signed have expired.
+func RotateSigningKeys(db *gorm.DB, interval time.Duration, now time.Time) error {
+	keys, err := ListSigningKeys(db)
+	if err != nil {
+		return err
+	}
+
+	if len(keys) == 0 {
+		return errors.New("no signing keys")
+	}
+
+	for i := 0; i < len(keys)-1; i++ {
+		if now.Sub(keys[i+1].ActiveAt) > signingKeyRetention {
+			logging.S.Debugf("deleting signing key %s", keys[i].KeyID)
+			if err := delete[models.SigningKey](db, keys[i].ID); err != nil {
+				return err
+			}
+		}
+	}
+
+	newest := keys[len(keys)-1]
+	if interval <= 0 || newest.ActiveAt.After(now) {
+		return nil
+	}
+
+	activeAt := newest.ActiveAt.Add(interval)
+	if activeAt.After(now.Add(SigningKeyPublishLead)) {
+		return nil
+	}
+
+	if earliest := now.Add(SigningKeyPublishLead); activeAt.Before(earliest) {
+		activeAt = earliest
+	}
+
+	key, err := newSigningKey(activeAt)
+	if err != nil {
+		return err
+	}
+
+	logging.S.Infof("created signing key %s, which will be used from %s", key.KeyID, activeAt.Format(time.RFC3339))
+	return CreateSigningKey(db, key)
+}
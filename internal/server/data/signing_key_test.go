@@ -0,0 +1,137 @@
+package data
+
+import (
+	"testing"
+	"time"
+
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+	"gorm.io/gorm"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestInitializeSettings_ImportsSettingsKey(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *gorm.DB) {
+		key, err := newSigningKey(time.Now())
+		assert.NilError(t, err)
+
+		settings := &models.Settings{PrivateJWK: key.PrivateJWK, PublicJWK: key.PublicJWK}
+		assert.NilError(t, db.Create(settings).Error)
+
+		_, err = InitializeSettings(db)
+		assert.NilError(t, err)
+
+		keys, err := ListSigningKeys(db)
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 1)
+		assert.Equal(t, keys[0].KeyID, key.KeyID)
+
+		// the key is only stored as a signing key
+		saved, err := GetSettings(db)
+		assert.NilError(t, err)
+		assert.Equal(t, len(saved.PrivateJWK), 0)
+		assert.Equal(t, len(saved.PublicJWK), 0)
+
+		// initializing again does not create another key
+		_, err = InitializeSettings(db)
+		assert.NilError(t, err)
+
+		keys, err = ListSigningKeys(db)
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 1)
+	})
+}
+
+func TestRotateSigningKeys(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *gorm.DB) {
+		_, err := InitializeSettings(db)
+		assert.NilError(t, err)
+
+		keys, err := ListSigningKeys(db)
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 1)
+		first := keys[0]
+
+		interval := 24 * time.Hour
+		start := first.ActiveAt
+
+		// not due yet
+		assert.NilError(t, RotateSigningKeys(db, interval, start.Add(time.Hour)))
+		keys, err = ListSigningKeys(db)
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 1)
+
+		// the next key is published before it is used
+		now := start.Add(interval - SigningKeyPublishLead + time.Minute)
+		assert.NilError(t, RotateSigningKeys(db, interval, now))
+		keys, err = ListSigningKeys(db)
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 2)
+		next := keys[1]
+		assert.Equal(t, next.ActiveAt.UTC(), now.Add(SigningKeyPublishLead).UTC())
+
+		active, err := ActiveSigningKey(keys, now)
+		assert.NilError(t, err)
+		assert.Equal(t, active.KeyID, first.KeyID)
+
+		// only one key is scheduled at a time
+		assert.NilError(t, RotateSigningKeys(db, interval, now.Add(time.Minute)))
+		keys, err = ListSigningKeys(db)
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 2)
+
+		// the next key is used once it is active, and the old key is kept
+		// until the tokens it signed expire
+		now = next.ActiveAt.Add(time.Minute)
+		active, err = ActiveSigningKey(keys, now)
+		assert.NilError(t, err)
+		assert.Equal(t, active.KeyID, next.KeyID)
+
+		assert.NilError(t, RotateSigningKeys(db, interval, now))
+		keys, err = ListSigningKeys(db)
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 2)
+
+		now = next.ActiveAt.Add(signingKeyRetention + time.Minute)
+		assert.NilError(t, RotateSigningKeys(db, interval, now))
+		keys, err = ListSigningKeys(db)
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 1)
+		assert.Equal(t, keys[0].KeyID, next.KeyID)
+
+		// rotation can be disabled
+		assert.NilError(t, RotateSigningKeys(db, 0, now.Add(10*interval)))
+		keys, err = ListSigningKeys(db)
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 1)
+	})
+}
+
+func TestCreateIdentityToken_KeyID(t *testing.T) {
+	runDBTests(t, func(t *testing.T, db *gorm.DB) {
+		_, err := InitializeSettings(db)
+		assert.NilError(t, err)
+
+		identity := &models.Identity{Name: "kramer@example.com"}
+		assert.NilError(t, CreateIdentity(db, identity))
+
+		token, err := CreateIdentityToken(db, identity.ID, "")
+		assert.NilError(t, err)
+
+		keys, err := ListSigningKeys(db)
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 1)
+
+		tok, err := jwt.ParseSigned(token.Token)
+		assert.NilError(t, err)
+		assert.Equal(t, tok.Headers[0].KeyID, keys[0].KeyID)
+
+		var pub jose.JSONWebKey
+		assert.NilError(t, pub.UnmarshalJSON(keys[0].PublicJWK))
+
+		var claims jwt.Claims
+		assert.NilError(t, tok.Claims(pub, &claims))
+	})
+}
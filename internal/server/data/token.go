@@ -18,14 +18,20 @@ var signatureAlgorithmFromKeyAlgorithm = map[string]string{
 	"ED25519": "EdDSA", // elliptic curve 25519
 }
 
-func createJWT(db *gorm.DB, identity *models.Identity, groups []string, expires time.Time) (string, error) {
-	settings, err := GetSettings(db)
+func createJWT(db *gorm.DB, identity *models.Identity, groups []string, audience string, expires time.Time) (string, error) {
+	keys, err := ListSigningKeys(db)
 	if err != nil {
 		return "", err
 	}
 
+	key, err := ActiveSigningKey(keys, time.Now().UTC())
+	if err != nil {
+		return "", err
+	}
+
+	// the KeyID of the JWK is set as the kid header of the token
 	var sec jose.JSONWebKey
-	if err := sec.UnmarshalJSON(settings.PrivateJWK); err != nil {
+	if err := sec.UnmarshalJSON(key.PrivateJWK); err != nil {
 		return "", err
 	}
 
@@ -44,11 +50,16 @@ func createJWT(db *gorm.DB, identity *models.Identity, groups []string, expires
 	now := time.Now().UTC()
 
 	claim := jwt.Claims{
+		Issuer:    claims.Issuer,
 		NotBefore: jwt.NewNumericDate(now.Add(time.Minute * -5)), // adjust for clock drift
 		Expiry:    jwt.NewNumericDate(expires),
 		IssuedAt:  jwt.NewNumericDate(time.Now().UTC()),
 	}
 
+	if audience != "" {
+		claim.Audience = jwt.Audience{audience}
+	}
+
 	custom := claims.Custom{
 		Name:   identity.Name,
 		Groups: groups,
@@ -63,7 +74,10 @@ func createJWT(db *gorm.DB, identity *models.Identity, groups []string, expires
 	return raw, nil
 }
 
-func CreateIdentityToken(db *gorm.DB, identityID uid.ID) (token *models.Token, err error) {
+// CreateIdentityToken creates a token for an identity to present to a
+// destination. When destination is set the token has the destination name as
+// its audience, and is only accepted by that destination.
+func CreateIdentityToken(db *gorm.DB, identityID uid.ID, destination string) (token *models.Token, err error) {
 	identity, err := GetIdentity(db, ByID(identityID))
 	if err != nil {
 		return nil, err
@@ -79,9 +93,9 @@ func CreateIdentityToken(db *gorm.DB, identityID uid.ID) (token *models.Token, e
 		groups = append(groups, g.Name)
 	}
 
-	expires := time.Now().Add(time.Minute * 5).UTC()
+	expires := time.Now().Add(IdentityTokenLifetime).UTC()
 
-	jwt, err := createJWT(db, identity, groups, expires)
+	jwt, err := createJWT(db, identity, groups, destination, expires)
 	if err != nil {
 		return nil, err
 	}
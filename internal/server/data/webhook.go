@@ -0,0 +1,90 @@
+package data
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func CreateWebhook(db *gorm.DB, webhook *models.Webhook) error {
+	return add(db, webhook)
+}
+
+func GetWebhook(db *gorm.DB, selectors ...SelectorFunc) (*models.Webhook, error) {
+	return get[models.Webhook](db, selectors...)
+}
+
+func ListWebhooks(db *gorm.DB, selectors ...SelectorFunc) ([]models.Webhook, error) {
+	db = orderBy(db, "created_at", false)
+	return list[models.Webhook](db, selectors...)
+}
+
+func DeleteWebhooks(db *gorm.DB, selectors ...SelectorFunc) error {
+	toDelete, err := ListWebhooks(db, selectors...)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uid.ID, 0)
+	for _, w := range toDelete {
+		ids = append(ids, w.ID)
+
+		if err := deleteAll[models.WebhookDelivery](db, ByWebhookID(w.ID)); err != nil {
+			return err
+		}
+	}
+
+	return deleteAll[models.Webhook](db, ByIDs(ids))
+}
+
+func CreateWebhookDelivery(db *gorm.DB, delivery *models.WebhookDelivery) error {
+	return add(db, delivery)
+}
+
+func SaveWebhookDelivery(db *gorm.DB, delivery *models.WebhookDelivery) error {
+	return save(db, delivery)
+}
+
+// ClaimWebhookDelivery marks a pending delivery as no longer pending. It
+// returns false if the delivery was already claimed, so that each attempt is
+// only made once when there is more than one server.
+func ClaimWebhookDelivery(db *gorm.DB, delivery *models.WebhookDelivery) (bool, error) {
+	result := db.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND pending = ?", delivery.ID, true).
+		Update("pending", false)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	delivery.Pending = false
+	return result.RowsAffected == 1, nil
+}
+
+func ListWebhookDeliveries(db *gorm.DB, selectors ...SelectorFunc) ([]models.WebhookDelivery, error) {
+	db = orderBy(db, "created_at", true)
+	return list[models.WebhookDelivery](db, selectors...)
+}
+
+func ByWebhookID(id uid.ID) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("webhook_id = ?", id)
+	}
+}
+
+// ByPendingWebhookDelivery selects the deliveries that are due to be attempted
+// at now.
+func ByPendingWebhookDelivery(now time.Time) SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("pending = ? AND attempt_at <= ?", true, now)
+	}
+}
+
+// ByAttemptedWebhookDelivery selects the deliveries that were attempted.
+func ByAttemptedWebhookDelivery() SelectorFunc {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("pending = ?", false)
+	}
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+//go:embed templates/email/*.tmpl
+var emailTemplateFS embed.FS
+
+var emailTemplates = template.Must(template.ParseFS(emailTemplateFS, "templates/email/*.tmpl"))
+
+const (
+	emailInvitation    = "invitation"
+	emailPasswordReset = "password-reset"
+)
+
+// emailData is the data of the invitation and password reset email templates.
+type emailData struct {
+	Name    string
+	Server  string
+	Token   string
+	Link    string
+	Expires string
+}
+
+// mailer sends emails with the SMTP server of EmailOptions.
+type mailer struct {
+	options EmailOptions
+	// password is the SMTP password, resolved from the secret in options.
+	password string
+}
+
+func newMailer(options EmailOptions) *mailer {
+	return &mailer{options: options}
+}
+
+// enabled returns true if an SMTP server is configured.
+func (m *mailer) enabled() bool {
+	return m != nil && m.options.SMTPHost != ""
+}
+
+// sendPasswordResetLink sends the invitation or password reset email named
+// name to the identity, with a link that uses token.
+func (m *mailer) sendPasswordResetLink(name string, identity *models.Identity, token string, expires time.Time) error {
+	baseURL := strings.TrimSuffix(m.options.BaseURL, "/")
+
+	server := baseURL
+	if u, err := url.Parse(baseURL); err == nil && u.Host != "" {
+		server = u.Host
+	}
+
+	return m.send(identity.Name, name, emailData{
+		Name:    identity.Name,
+		Server:  server,
+		Token:   token,
+		Link:    baseURL + "/password-reset?token=" + url.QueryEscape(token),
+		Expires: expires.UTC().Format(time.RFC1123),
+	})
+}
+
+// sendInvitation emails a link to the new user of the infra provider, which
+// they use to set their password. The email is sent after the request is
+// committed, so that the link works when it arrives.
+func (a *API) sendInvitation(c *gin.Context, identity *models.Identity) error {
+	ttl := a.server.options.Email.InvitationExpiry
+	token, err := access.CreatePasswordResetToken(c, identity.ID, ttl)
+	if err != nil {
+		return err
+	}
+
+	expires := time.Now().Add(ttl)
+	afterCommit(c, func() {
+		if err := a.server.mailer.sendPasswordResetLink(emailInvitation, identity, token, expires); err != nil {
+			// the user can still request a password reset
+			logging.S.Errorf("failed to send invitation to %s: %s", identity.Name, err)
+		}
+	})
+
+	return nil
+}
+
+// send renders the subject and body of the email template named name, and
+// sends the email to the address to.
+func (m *mailer) send(to, name string, data interface{}) error {
+	from, err := mail.ParseAddress(m.options.From)
+	if err != nil {
+		return fmt.Errorf("email from address: %w", err)
+	}
+
+	recipient, err := mail.ParseAddress(to)
+	if err != nil {
+		return fmt.Errorf("email to address: %w", err)
+	}
+
+	var subject, body bytes.Buffer
+	if err := emailTemplates.ExecuteTemplate(&subject, name+".subject", data); err != nil {
+		return fmt.Errorf("email subject: %w", err)
+	}
+
+	if err := emailTemplates.ExecuteTemplate(&body, name+".body", data); err != nil {
+		return fmt.Errorf("email body: %w", err)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from.String())
+	fmt.Fprintf(&msg, "To: %s\r\n", recipient.String())
+	fmt.Fprintf(&msg, "Subject: %s\r\n", strings.TrimSpace(subject.String()))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(strings.TrimLeft(body.String(), "\n"), "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if m.options.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", m.options.SMTPUsername, m.password, m.options.SMTPHost)
+	}
+
+	addr := net.JoinHostPort(m.options.SMTPHost, strconv.Itoa(m.options.SMTPPort))
+	if err := smtp.SendMail(addr, auth, from.Address, []string{recipient.Address}, msg.Bytes()); err != nil {
+		return fmt.Errorf("send %s email: %w", name, err)
+	}
+
+	logging.S.Debugf("sent %s email to %s", name, recipient.Address)
+	return nil
+}
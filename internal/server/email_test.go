@@ -0,0 +1,305 @@
+package server
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/crypto/bcrypt"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+// startSMTPSink starts an SMTP server that accepts every email, and sends the
+// data of each email to the returned channel.
+func startSMTPSink(t *testing.T) (string, int, <-chan string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	t.Cleanup(func() {
+		_ = l.Close()
+	})
+
+	messages := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTPSink(conn, messages)
+		}
+	}()
+
+	addr, ok := l.Addr().(*net.TCPAddr)
+	assert.Assert(t, ok)
+	return addr.IP.String(), addr.Port, messages
+}
+
+func serveSMTPSink(conn net.Conn, messages chan<- string) {
+	defer conn.Close()
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 localhost smtp sink")
+
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return
+		}
+
+		command, _, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(command) {
+		case "EHLO", "HELO":
+			_ = tp.PrintfLine("250 localhost")
+		case "MAIL", "RCPT", "RSET", "NOOP":
+			_ = tp.PrintfLine("250 OK")
+		case "DATA":
+			_ = tp.PrintfLine("354 send the message")
+			msg, err := tp.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			messages <- string(msg)
+			_ = tp.PrintfLine("250 OK")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return
+		default:
+			_ = tp.PrintfLine("502 not implemented")
+		}
+	}
+}
+
+func receiveEmail(t *testing.T, messages <-chan string) string {
+	t.Helper()
+	select {
+	case msg := <-messages:
+		return msg
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for email")
+		return ""
+	}
+}
+
+var emailTokenPattern = regexp.MustCompile(`password-reset\?token=([A-Za-z0-9]+)`)
+
+func TestMailer_Send(t *testing.T) {
+	host, port, messages := startSMTPSink(t)
+
+	m := newMailer(EmailOptions{
+		SMTPHost: host,
+		SMTPPort: port,
+		From:     "Infra <infra@example.com>",
+		BaseURL:  "https://infra.example.com/",
+	})
+
+	expires := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	identity := &models.Identity{Name: "alice@example.com"}
+	err := m.sendPasswordResetLink(emailInvitation, identity, "the-token", expires)
+	assert.NilError(t, err)
+
+	msg := receiveEmail(t, messages)
+	assert.Assert(t, strings.Contains(msg, "From: \"Infra\" <infra@example.com>\n"), msg)
+	assert.Assert(t, strings.Contains(msg, "To: <alice@example.com>\n"), msg)
+	assert.Assert(t, strings.Contains(msg, "Subject: You have been invited to Infra\n"), msg)
+	assert.Assert(t, strings.Contains(msg, "https://infra.example.com/password-reset?token=the-token"), msg)
+	assert.Assert(t, strings.Contains(msg, "infra login infra.example.com --password-reset-token the-token"), msg)
+	assert.Assert(t, strings.Contains(msg, "expires Wed, 01 Jun 2022 12:00:00 UTC"), msg)
+}
+
+func TestAPI_PasswordReset(t *testing.T) {
+	host, port, messages := startSMTPSink(t)
+
+	srv := setupServer(t, withAdminUser, func(t *testing.T, options *Options) {
+		options.Email = EmailOptions{
+			SMTPHost:            host,
+			SMTPPort:            port,
+			From:                "infra@example.com",
+			BaseURL:             "https://infra.example.com",
+			InvitationExpiry:    time.Hour,
+			PasswordResetExpiry: time.Hour,
+		}
+	})
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	// setPassword logs in with the token from the email, and uses the access
+	// key to set a new password.
+	setPassword := func(t *testing.T, msg, password string) {
+		t.Helper()
+		match := emailTokenPattern.FindStringSubmatch(msg)
+		assert.Assert(t, len(match) == 2, msg)
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/login", "", api.LoginRequest{PasswordReset: &api.LoginRequestPasswordReset{Token: match[1]}})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var loginResp api.LoginResponse
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&loginResp))
+		assert.Equal(t, loginResp.PasswordUpdateRequired, true)
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/users/"+loginResp.UserID.String(), loginResp.AccessKey, nil)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPut, "/api/users/"+loginResp.UserID.String(), loginResp.AccessKey, api.UpdateUserRequest{Password: password})
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/users/"+loginResp.UserID.String(), loginResp.AccessKey, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		// the token can only be used once
+		resp = callAPI(t, routes, http.MethodPost, "/api/login", "", api.LoginRequest{PasswordReset: &api.LoginRequestPasswordReset{Token: match[1]}})
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	}
+
+	login := func(t *testing.T, name, password string) *httptest.ResponseRecorder {
+		t.Helper()
+		return callAPI(t, routes, http.MethodPost, "/api/login", "", api.LoginRequest{
+			PasswordCredentials: &api.LoginRequestPasswordCredentials{Name: name, Password: password},
+		})
+	}
+
+	t.Run("invitation", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/users", adminAccessKey(srv), api.CreateUserRequest{Name: "alice@example.com"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var created api.CreateUserResponse
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&created))
+		assert.Equal(t, created.InvitationSent, true)
+		assert.Equal(t, created.OneTimePassword, "")
+
+		msg := receiveEmail(t, messages)
+		assert.Assert(t, strings.Contains(msg, "To: <alice@example.com>"), msg)
+		assert.Assert(t, strings.Contains(msg, "Subject: You have been invited to Infra"), msg)
+
+		setPassword(t, msg, "first-password")
+
+		resp = login(t, "alice@example.com", "first-password")
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+	})
+
+	t.Run("password reset", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/password-reset", "", api.PasswordResetRequest{Email: "alice@example.com"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		msg := receiveEmail(t, messages)
+		assert.Assert(t, strings.Contains(msg, "Subject: Reset your Infra password"), msg)
+
+		setPassword(t, msg, "second-password")
+
+		resp = login(t, "alice@example.com", "first-password")
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+		resp = login(t, "alice@example.com", "second-password")
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+	})
+
+	// expireCooldown makes the password reset tokens old enough that another
+	// reset can be requested.
+	expireCooldown := func(t *testing.T) {
+		t.Helper()
+		before := time.Now().UTC().Add(-access.PasswordResetCooldown)
+		err := srv.db.Exec("UPDATE password_reset_tokens SET created_at = ? WHERE created_at > ?", before, before).Error
+		assert.NilError(t, err)
+	}
+
+	t.Run("a new reset replaces the previous one", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/password-reset", "", api.PasswordResetRequest{Email: "alice@example.com"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		first := receiveEmail(t, messages)
+
+		expireCooldown(t)
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/password-reset", "", api.PasswordResetRequest{Email: "alice@example.com"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		receiveEmail(t, messages)
+
+		match := emailTokenPattern.FindStringSubmatch(first)
+		assert.Assert(t, len(match) == 2, first)
+		resp = callAPI(t, routes, http.MethodPost, "/api/login", "", api.LoginRequest{PasswordReset: &api.LoginRequestPasswordReset{Token: match[1]}})
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("resets are limited by a cooldown", func(t *testing.T) {
+		expireCooldown(t)
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/password-reset", "", api.PasswordResetRequest{Email: "alice@example.com"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		first := receiveEmail(t, messages)
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/password-reset", "", api.PasswordResetRequest{Email: "alice@example.com"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		select {
+		case msg := <-messages:
+			t.Fatalf("unexpected email: %s", msg)
+		case <-time.After(100 * time.Millisecond):
+		}
+
+		// the first token still works
+		match := emailTokenPattern.FindStringSubmatch(first)
+		assert.Assert(t, len(match) == 2, first)
+		resp = callAPI(t, routes, http.MethodPost, "/api/login", "", api.LoginRequest{PasswordReset: &api.LoginRequestPasswordReset{Token: match[1]}})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+	})
+
+	t.Run("unknown users do not get an email", func(t *testing.T) {
+		bob := &models.Identity{Name: "bob@example.com"}
+		assert.NilError(t, data.CreateIdentity(srv.db, bob))
+
+		for _, name := range []string{"unknown@example.com", bob.Name} {
+			resp := callAPI(t, routes, http.MethodPost, "/api/password-reset", "", api.PasswordResetRequest{Email: name})
+			assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		}
+
+		select {
+		case msg := <-messages:
+			t.Fatalf("unexpected email: %s", msg)
+		case <-time.After(100 * time.Millisecond):
+		}
+	})
+
+	t.Run("mfa is still required", func(t *testing.T) {
+		carol := &models.Identity{Name: "carol@example.com"}
+		assert.NilError(t, data.CreateIdentity(srv.db, carol))
+
+		hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+		assert.NilError(t, err)
+		assert.NilError(t, data.CreateCredential(srv.db, &models.Credential{
+			IdentityID:    carol.ID,
+			PasswordHash:  hash,
+			TOTPSecret:    "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+			TOTPConfirmed: true,
+		}))
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/password-reset", "", api.PasswordResetRequest{Email: carol.Name})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		msg := receiveEmail(t, messages)
+
+		match := emailTokenPattern.FindStringSubmatch(msg)
+		assert.Assert(t, len(match) == 2, msg)
+		resp = callAPI(t, routes, http.MethodPost, "/api/login", "", api.LoginRequest{PasswordReset: &api.LoginRequestPasswordReset{Token: match[1]}})
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+		assert.Assert(t, strings.Contains(resp.Body.String(), "mfaCode"), resp.Body.String())
+	})
+}
+
+func TestAPI_PasswordReset_EmailNotConfigured(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	req := httptest.NewRequest(http.MethodPost, "/api/password-reset", jsonBody(t, api.PasswordResetRequest{Email: "admin@example.com"}))
+	req.Header.Set("Infra-Version", "0.13.0")
+	resp := httptest.NewRecorder()
+	routes.ServeHTTP(resp, req)
+	assert.Equal(t, resp.Code, http.StatusNotImplemented, resp.Body.String())
+}
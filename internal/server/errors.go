@@ -15,6 +15,7 @@ import (
 	"github.com/infrahq/infra/internal"
 	"github.com/infrahq/infra/internal/access"
 	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/internal/server/authn"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/providers"
 )
@@ -23,6 +24,14 @@ import (
 // response body using api.Error, then sends both as a response to the active
 // request.
 func sendAPIError(c *gin.Context, err error) {
+	resp := newAPIError(err)
+
+	c.JSON(int(resp.Code), resp)
+	c.Abort()
+}
+
+// newAPIError returns the response for err, and logs the error.
+func newAPIError(err error) *api.Error {
 	resp := &api.Error{
 		Code:    http.StatusInternalServerError,
 		Message: "internal server error", // don't leak any info by default
@@ -31,10 +40,16 @@ func sendAPIError(c *gin.Context, err error) {
 	validationErrors := &validator.ValidationErrors{}
 	var uniqueConstraintError data.UniqueConstraintError
 	var authzError access.AuthorizationError
+	var passwordPolicyError authn.PasswordPolicyError
 
-	log := logging.L.WithOptions(zap.AddCallerSkip(1)).Debug
+	log := logging.L.WithOptions(zap.AddCallerSkip(2)).Debug
 
 	switch {
+	case errors.Is(err, authn.ErrMFARequired):
+		resp.Code = http.StatusUnauthorized
+		resp.Message = err.Error()
+		resp.FieldErrors = []api.FieldError{{FieldName: "mfaCode", Errors: []string{"is required"}}}
+
 	case errors.Is(err, internal.ErrUnauthorized):
 		resp.Code = http.StatusUnauthorized
 		// hide the error text, it may contain sensitive information
@@ -57,6 +72,11 @@ func sendAPIError(c *gin.Context, err error) {
 		resp.Message = err.Error()
 		parseFieldErrors(resp, validationErrors)
 
+	case errors.As(err, &passwordPolicyError):
+		resp.Code = http.StatusBadRequest
+		resp.Message = "password: " + strings.Join(passwordPolicyError.Failures, ", ")
+		resp.FieldErrors = []api.FieldError{{FieldName: "password", Errors: passwordPolicyError.Failures}}
+
 	case errors.Is(err, internal.ErrBadRequest), errors.Is(err, providers.ErrValidation):
 		resp.Code = http.StatusBadRequest
 		resp.Message = err.Error()
@@ -65,6 +85,10 @@ func sendAPIError(c *gin.Context, err error) {
 		resp.Code = http.StatusNotImplemented
 		resp.Message = internal.ErrNotImplemented.Error()
 
+	case errors.Is(err, internal.ErrTooManyRequests):
+		resp.Code = http.StatusTooManyRequests
+		resp.Message = err.Error()
+
 	case errors.Is(err, internal.ErrBadGateway):
 		resp.Code = http.StatusBadGateway
 		resp.Message = err.Error()
@@ -78,13 +102,12 @@ func sendAPIError(c *gin.Context, err error) {
 		resp.Message = "request timed out"
 
 	default:
-		log = logging.L.WithOptions(zap.AddCallerSkip(1)).Error
+		log = logging.L.WithOptions(zap.AddCallerSkip(2)).Error
 	}
 
 	log("api request error", zap.Error(err), zap.Int32("statusCode", resp.Code))
 
-	c.JSON(int(resp.Code), resp)
-	c.Abort()
+	return resp
 }
 
 func parseFieldErrors(resp *api.Error, validationErrors *validator.ValidationErrors) {
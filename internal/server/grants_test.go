@@ -253,7 +253,10 @@ func TestAPI_ListGrants(t *testing.T) {
 					},
 				}
 				assert.DeepEqual(t, grants.Items, expected, cmpAPIGrantShallow)
-				assert.Assert(t, grants.PaginationInfo == api.PaginationResponse{Limit: 2, Page: 2})
+				assert.Equal(t, grants.PaginationInfo.Page, 2)
+				assert.Equal(t, grants.PaginationInfo.Limit, 2)
+				assert.Equal(t, grants.PaginationInfo.TotalCount, 4)
+				assert.Equal(t, grants.PaginationInfo.NextCursor, api.Cursor(0))
 			},
 		},
 		"filter by resource": {
@@ -311,7 +314,7 @@ func TestAPI_ListGrants(t *testing.T) {
 
 				expected := jsonUnmarshal(t, fmt.Sprintf(`
 {
-	"pagination_info":{},
+	"pagination_info":{"totalCount": 1},
 	"count": 1,
 	"items": [{
 		"id": "<any-valid-uid>",
@@ -320,7 +323,8 @@ func TestAPI_ListGrants(t *testing.T) {
 		"resource": "res1",
 		"user": "%[2]v",
 		"created": "%[3]v",
-		"updated": "%[3]v"
+		"updated": "%[3]v",
+		"expires": null
 	}]
 }`,
 					admin.ID,
@@ -339,6 +343,86 @@ func TestAPI_ListGrants(t *testing.T) {
 	}
 }
 
+func TestAPI_ListEffectiveGrants(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	alice := &models.Identity{Name: "alice@example.com"}
+	bob := &models.Identity{Name: "bob@example.com"}
+	createIdentities(t, srv.db, alice, bob)
+
+	developers := &models.Group{Name: "developers"}
+	operators := &models.Group{Name: "operators"}
+	createGroups(t, srv.db, developers, operators)
+	assert.NilError(t, data.AddUsersToGroup(srv.db, developers.ID, []uid.ID{alice.ID}))
+	assert.NilError(t, data.AddUsersToGroup(srv.db, operators.ID, []uid.ID{alice.ID}))
+
+	soon := time.Now().Add(time.Hour)
+	later := time.Now().Add(2 * time.Hour)
+	past := time.Now().Add(-time.Hour)
+
+	grants := []*models.Grant{
+		{Subject: alice.PolyID(), Privilege: "view", Resource: "production"},
+		{Subject: developers.PolyID(), Privilege: "view", Resource: "production"},
+		{Subject: developers.PolyID(), Privilege: "edit", Resource: "staging", ExpiresAt: &soon},
+		{Subject: operators.PolyID(), Privilege: "edit", Resource: "staging", ExpiresAt: &later},
+		{Subject: operators.PolyID(), Privilege: "admin", Resource: "development", ExpiresAt: &past},
+		{Subject: bob.PolyID(), Privilege: "admin", Resource: "production"},
+	}
+	for _, grant := range grants {
+		assert.NilError(t, data.CreateGrant(srv.db, grant))
+	}
+
+	aliceKey, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+		IssuedFor:  alice.ID,
+		ProviderID: data.InfraProvider(srv.db).ID,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	})
+	assert.NilError(t, err)
+
+	list := func(t *testing.T, key string, user uid.ID) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/grants/effective?user="+user.String(), nil)
+		req.Header.Set("Authorization", "Bearer "+key)
+		req.Header.Set("Infra-Version", "0.13.0")
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	t.Run("direct and group grants", func(t *testing.T) {
+		resp := list(t, aliceKey, alice.ID)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var result api.ListResponse[api.EffectiveGrant]
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&result))
+		assert.Equal(t, result.Count, 2)
+
+		production := result.Items[0]
+		assert.Equal(t, production.Resource, "production")
+		assert.Equal(t, production.Privilege, "view")
+		assert.Assert(t, production.Expires.Time().IsZero())
+		assert.Equal(t, len(production.Grants), 2)
+		assert.Equal(t, production.Grants[0].User, alice.ID)
+		assert.Equal(t, production.Grants[1].Group, developers.ID)
+
+		staging := result.Items[1]
+		assert.Equal(t, staging.Resource, "staging")
+		assert.Equal(t, staging.Privilege, "edit")
+		assert.Equal(t, staging.Expires.Time().Unix(), later.Unix())
+		assert.Equal(t, len(staging.Grants), 2)
+	})
+
+	t.Run("other users require grants:read", func(t *testing.T) {
+		resp := list(t, aliceKey, bob.ID)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = list(t, adminAccessKey(srv), bob.ID)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+	})
+}
+
 var cmpAPIGrantShallow = gocmp.Comparer(func(x, y api.Grant) bool {
 	return x.User == y.User && x.Privilege == y.Privilege && x.Resource == y.Resource
 })
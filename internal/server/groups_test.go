@@ -15,6 +15,7 @@ import (
 	"github.com/infrahq/infra/api"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
 )
 
 func createIdentities(t *testing.T, db *gorm.DB, identities ...*models.Identity) {
@@ -137,7 +138,7 @@ func TestAPI_ListGroups(t *testing.T) {
 				err := json.NewDecoder(resp.Body).Decode(&actual)
 				assert.NilError(t, err)
 				assert.Equal(t, len(actual.Items), 1)
-				assert.Equal(t, api.PaginationResponse{Page: 2, Limit: 2}, actual.PaginationInfo)
+				assert.Equal(t, api.PaginationResponse{Page: 2, Limit: 2, TotalCount: 3}, actual.PaginationInfo)
 			},
 		},
 		"authorized by group membership": {
@@ -186,7 +187,7 @@ func TestAPI_ListGroups(t *testing.T) {
 
 				expected := jsonUnmarshal(t, fmt.Sprintf(`
 {
-	"pagination_info": {},
+	"pagination_info": {"totalCount": 2},
 	"count": 2,
 	"items": [{
 		"id": "%[1]v",
@@ -219,7 +220,7 @@ func TestAPI_ListGroups(t *testing.T) {
 
 				expected := jsonUnmarshal(t, fmt.Sprintf(`
 {
-	"pagination_info":{},
+	"pagination_info":{"totalCount": 1},
 	"count": 1,
 	"items": [{
 		"id": "%[1]v",
@@ -320,3 +321,145 @@ func TestAPI_DeleteGroup(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_UpdateUsersInGroup(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	var humans = models.Group{Name: "humans"}
+	createGroups(t, srv.db, &humans)
+
+	var (
+		first  = models.Identity{Name: "first@example.com"}
+		second = models.Identity{Name: "second@example.com"}
+	)
+	createIdentities(t, srv.db, &first, &second)
+
+	type testCase struct {
+		method   string
+		urlPath  string
+		body     api.UpdateUsersInGroupRequest
+		setup    func(t *testing.T, req *http.Request)
+		expected func(t *testing.T, resp *httptest.ResponseRecorder)
+	}
+
+	run := func(t *testing.T, tc testCase) {
+		req := httptest.NewRequest(tc.method, tc.urlPath, jsonBody(t, tc.body))
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Set("Infra-Version", "0.13.0")
+
+		if tc.setup != nil {
+			tc.setup(t, req)
+		}
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+
+		tc.expected(t, resp)
+	}
+
+	groupMembers := func(t *testing.T) []string {
+		t.Helper()
+		users, err := data.ListIdentities(srv.db, data.ByOptionalIdentityGroupID(humans.ID))
+		assert.NilError(t, err)
+		var names []string
+		for _, user := range users {
+			names = append(names, user.Name)
+		}
+		return names
+	}
+
+	testCases := []struct {
+		name string
+		testCase
+	}{
+		{
+			name: "not authorized",
+			testCase: testCase{
+				method:  http.MethodPut,
+				urlPath: "/api/groups/" + humans.ID.String() + "/users",
+				body:    api.UpdateUsersInGroupRequest{UserIDs: []uid.ID{first.ID}},
+				setup: func(t *testing.T, req *http.Request) {
+					key, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+						IssuedFor:  first.ID,
+						ProviderID: data.InfraProvider(srv.db).ID,
+						ExpiresAt:  time.Now().Add(10 * time.Second),
+					})
+					assert.NilError(t, err)
+					req.Header.Set("Authorization", "Bearer "+key)
+				},
+				expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+					assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+					assert.Equal(t, len(groupMembers(t)), 0)
+				},
+			},
+		},
+		{
+			name: "unknown group",
+			testCase: testCase{
+				method:  http.MethodPut,
+				urlPath: "/api/groups/" + uid.New().String() + "/users",
+				body:    api.UpdateUsersInGroupRequest{UserIDs: []uid.ID{first.ID}},
+				expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+					assert.Equal(t, resp.Code, http.StatusNotFound, resp.Body.String())
+				},
+			},
+		},
+		{
+			name: "unknown user",
+			testCase: testCase{
+				method:  http.MethodPut,
+				urlPath: "/api/groups/" + humans.ID.String() + "/users",
+				body:    api.UpdateUsersInGroupRequest{UserIDs: []uid.ID{first.ID, uid.New()}},
+				expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+					assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+					assert.Equal(t, len(groupMembers(t)), 0)
+				},
+			},
+		},
+		{
+			name: "add users",
+			testCase: testCase{
+				method:  http.MethodPut,
+				urlPath: "/api/groups/" + humans.ID.String() + "/users",
+				body:    api.UpdateUsersInGroupRequest{UserIDs: []uid.ID{first.ID, second.ID}},
+				expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+					assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+					assert.DeepEqual(t, groupMembers(t), []string{"first@example.com", "second@example.com"})
+				},
+			},
+		},
+		{
+			name: "add existing member",
+			testCase: testCase{
+				method:  http.MethodPut,
+				urlPath: "/api/groups/" + humans.ID.String() + "/users",
+				body:    api.UpdateUsersInGroupRequest{UserIDs: []uid.ID{first.ID}},
+				expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+					assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+					assert.DeepEqual(t, groupMembers(t), []string{"first@example.com", "second@example.com"})
+				},
+			},
+		},
+		{
+			name: "remove users",
+			testCase: testCase{
+				method:  http.MethodDelete,
+				urlPath: "/api/groups/" + humans.ID.String() + "/users",
+				body:    api.UpdateUsersInGroupRequest{UserIDs: []uid.ID{first.ID}},
+				expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+					assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+					assert.DeepEqual(t, groupMembers(t), []string{"second@example.com"})
+				},
+			},
+		},
+	}
+
+	// test cases are run in order because each one builds on the membership
+	// from the previous case
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			run(t, tc.testCase)
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -30,7 +31,7 @@ type API struct {
 
 func (a *API) ListUsers(c *gin.Context, r *api.ListUsersRequest) (*api.ListResponse[api.User], error) {
 	pg := models.RequestToPagination(r.PaginationRequest)
-	users, err := access.ListIdentities(c, r.Name, r.Group, r.IDs, pg)
+	users, err := access.ListIdentities(c, r.Name, r.Group, r.IDs, r.ShowServiceAccounts, &pg)
 	if err != nil {
 		return nil, err
 	}
@@ -64,7 +65,7 @@ func (a *API) CreateUser(c *gin.Context, r *api.CreateUserRequest) (*api.CreateU
 	infraProvider := access.InfraProvider(c)
 
 	// infra identity creation should be attempted even if an identity is already known
-	identities, err := access.ListIdentities(c, user.Name, 0, nil, models.Pagination{Limit: 2})
+	identities, err := access.ListIdentities(c, user.Name, 0, nil, false, &models.Pagination{Limit: 2})
 	if err != nil {
 		return nil, fmt.Errorf("list identities: %w", err)
 	}
@@ -97,19 +98,48 @@ func (a *API) CreateUser(c *gin.Context, r *api.CreateUserRequest) (*api.CreateU
 		return nil, fmt.Errorf("create credential: %w", err)
 	}
 
-	resp.OneTimePassword = tmpPassword
+	if a.server.mailer.enabled() {
+		if err := a.sendInvitation(c, user); err != nil {
+			// the admin can still share the one time password with the user
+			logging.S.Errorf("failed to create invitation for %s: %s", user.Name, err)
+		} else {
+			resp.InvitationSent = true
+		}
+	}
+
+	if !resp.InvitationSent {
+		resp.OneTimePassword = tmpPassword
+	}
+
+	if len(identities) == 0 {
+		a.sendWebhookEvent(c, api.WebhookEventUserCreated, user.ToAPI())
+	}
 
 	return resp, nil
 }
 
 func (a *API) UpdateUser(c *gin.Context, r *api.UpdateUserRequest) (*api.User, error) {
-	// right now this endpoint can only update a user's credentials, so get the user identity
+	// right now this endpoint can only update a user's credentials and lock state, so get the user identity
 	identity, err := access.GetIdentity(c, r.ID)
 	if err != nil {
 		return nil, err
 	}
 
-	err = access.UpdateCredential(c, identity, r.Password)
+	if r.Unlock {
+		if err := access.UnlockIdentity(c, identity); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.Password == "" {
+		return identity.ToAPI(), nil
+	}
+
+	if identity.IsServiceAccount() {
+		return nil, fmt.Errorf("%w: service accounts can only authenticate with access keys, they can not have a password", internal.ErrBadRequest)
+	}
+
+	err = access.UpdateCredential(c, identity, r.Password, a.server.passwords)
 	if err != nil {
 		return nil, err
 	}
@@ -124,6 +154,84 @@ func (a *API) DeleteUser(c *gin.Context, r *api.Resource) (*api.EmptyResponse, e
 	return nil, access.DeleteIdentity(c, r.ID)
 }
 
+func (a *API) ListServiceAccounts(c *gin.Context, r *api.ListServiceAccountsRequest) (*api.ListResponse[api.User], error) {
+	pg := models.RequestToPagination(r.PaginationRequest)
+	serviceAccounts, err := access.ListServiceAccounts(c, r.Name, &pg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := api.NewListResponse(serviceAccounts, models.PaginationToResponse(pg), func(identity models.Identity) api.User {
+		return *identity.ToAPI()
+	})
+
+	return result, nil
+}
+
+func (a *API) CreateServiceAccount(c *gin.Context, r *api.CreateServiceAccountRequest) (*api.User, error) {
+	serviceAccount := &models.Identity{Name: r.Name}
+	if err := access.CreateServiceAccount(c, serviceAccount); err != nil {
+		return nil, err
+	}
+
+	return serviceAccount.ToAPI(), nil
+}
+
+func (a *API) DeleteServiceAccount(c *gin.Context, r *api.Resource) (*api.EmptyResponse, error) {
+	return nil, access.DeleteServiceAccount(c, r.ID)
+}
+
+func (a *API) EnrollUserMFA(c *gin.Context, r *api.Resource) (*api.EnrollUserMFAResponse, error) {
+	identity, err := access.GetIdentity(c, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := access.EnrollMFA(c, identity.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.EnrollUserMFAResponse{Secret: secret, URI: authn.TOTPURI(identity.Name, secret)}, nil
+}
+
+func (a *API) ConfirmUserMFA(c *gin.Context, r *api.ConfirmUserMFARequest) (*api.ConfirmUserMFAResponse, error) {
+	codes, err := access.ConfirmMFA(c, r.ID, r.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.ConfirmUserMFAResponse{RecoveryCodes: codes}, nil
+}
+
+func (a *API) DisableUserMFA(c *gin.Context, r *api.Resource) (*api.EmptyResponse, error) {
+	return nil, access.DisableMFA(c, r.ID)
+}
+
+func (a *API) ListUserSessions(c *gin.Context, r *api.ListUserSessionsRequest) (*api.ListResponse[api.Session], error) {
+	pg := models.RequestToPagination(r.PaginationRequest)
+	sessions, err := access.ListSessions(c, r.ID, &pg)
+	if err != nil {
+		return nil, err
+	}
+
+	current := access.AuthenticatedAccessKey(c)
+
+	result := api.NewListResponse(sessions, models.PaginationToResponse(pg), func(session models.AccessKey) api.Session {
+		return *session.ToAPISession(current != nil && current.ID == session.ID)
+	})
+
+	return result, nil
+}
+
+func (a *API) RevokeUserSession(c *gin.Context, r *api.RevokeUserSessionRequest) (*api.EmptyResponse, error) {
+	return nil, access.RevokeSession(c, r.ID, r.SessionID)
+}
+
+func (a *API) RevokeUserSessions(c *gin.Context, r *api.RevokeUserSessionsRequest) (*api.EmptyResponse, error) {
+	return nil, access.RevokeSessions(c, r.ID, r.ExcludeCurrent)
+}
+
 // TODO: remove after deprecation period
 func (a *API) deprecatedListUserGroups(c *gin.Context, r *api.Resource) (*api.ListResponse[api.Group], error) {
 	return a.ListGroups(c, &api.ListGroupsRequest{UserID: r.ID})
@@ -131,7 +239,7 @@ func (a *API) deprecatedListUserGroups(c *gin.Context, r *api.Resource) (*api.Li
 
 func (a *API) ListGroups(c *gin.Context, r *api.ListGroupsRequest) (*api.ListResponse[api.Group], error) {
 	pg := models.RequestToPagination(r.PaginationRequest)
-	groups, err := access.ListGroups(c, r.Name, r.UserID, pg)
+	groups, err := access.ListGroups(c, r.Name, r.UserID, &pg)
 	if err != nil {
 		return nil, err
 	}
@@ -174,11 +282,19 @@ func (a *API) DeleteGroup(c *gin.Context, r *api.Resource) (*api.EmptyResponse,
 	return nil, access.DeleteGroup(c, r.ID)
 }
 
+func (a *API) AddUsersToGroup(c *gin.Context, r *api.UpdateUsersInGroupRequest) (*api.EmptyResponse, error) {
+	return nil, access.AddUsersToGroup(c, r.GroupID, r.UserIDs)
+}
+
+func (a *API) RemoveUsersFromGroup(c *gin.Context, r *api.UpdateUsersInGroupRequest) (*api.EmptyResponse, error) {
+	return nil, access.RemoveUsersFromGroup(c, r.GroupID, r.UserIDs)
+}
+
 // caution: this endpoint is unauthenticated, do not return sensitive info
 func (a *API) ListProviders(c *gin.Context, r *api.ListProvidersRequest) (*api.ListResponse[api.Provider], error) {
 	exclude := []string{models.InternalInfraProviderName}
 	pg := models.RequestToPagination(r.PaginationRequest)
-	providers, err := access.ListProviders(c, r.Name, exclude, pg)
+	providers, err := access.ListProviders(c, r.Name, exclude, &pg)
 	if err != nil {
 		return nil, err
 	}
@@ -272,7 +388,7 @@ func (a *API) DeleteProvider(c *gin.Context, r *api.Resource) (*api.EmptyRespons
 
 func (a *API) ListDestinations(c *gin.Context, r *api.ListDestinationsRequest) (*api.ListResponse[api.Destination], error) {
 	pg := models.RequestToPagination(r.PaginationRequest)
-	destinations, err := access.ListDestinations(c, r.UniqueID, r.Name, pg)
+	destinations, err := access.ListDestinations(c, r.UniqueID, r.Name, &pg)
 	if err != nil {
 		return nil, err
 	}
@@ -335,15 +451,33 @@ func (a *API) DeleteDestination(c *gin.Context, r *api.Resource) (*api.EmptyResp
 	return nil, access.DeleteDestination(c, r.ID)
 }
 
-func (a *API) CreateToken(c *gin.Context, r *api.EmptyRequest) (*api.CreateTokenResponse, error) {
+func (a *API) ListAuditEvents(c *gin.Context, r *api.ListAuditEventsRequest) (*api.ListResponse[api.AuditEvent], error) {
+	pg := models.RequestToPagination(r.PaginationRequest)
+	events, err := access.ListAuditEvents(c, r.Actor, r.Resource, r.ResourceID, r.After.Time(), r.Before.Time(), &pg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := api.NewListResponse(events, models.PaginationToResponse(pg), func(event models.AuditEvent) api.AuditEvent {
+		return *event.ToAPI()
+	})
+
+	return result, nil
+}
+
+func (a *API) CreateToken(c *gin.Context, r *api.CreateTokenRequest) (*api.CreateTokenResponse, error) {
 	if access.AuthenticatedIdentity(c) != nil {
-		err := a.UpdateIdentityInfoFromProvider(c)
-		if err != nil {
-			// TODO: why would this fail? seems like this should be a 5xx error
-			return nil, fmt.Errorf("update ident info from provider: %w", err)
+		// requests authenticated with a client certificate have no session
+		// with an identity provider to update from
+		if access.AuthenticatedAccessKey(c) != nil {
+			err := a.UpdateIdentityInfoFromProvider(c)
+			if err != nil {
+				// TODO: why would this fail? seems like this should be a 5xx error
+				return nil, fmt.Errorf("update ident info from provider: %w", err)
+			}
 		}
 
-		token, err := access.CreateToken(c)
+		token, err := access.CreateToken(c, r.Destination)
 		if err != nil {
 			return nil, err
 		}
@@ -356,7 +490,7 @@ func (a *API) CreateToken(c *gin.Context, r *api.EmptyRequest) (*api.CreateToken
 
 func (a *API) ListAccessKeys(c *gin.Context, r *api.ListAccessKeysRequest) (*api.ListResponse[api.AccessKey], error) {
 	pg := models.RequestToPagination(r.PaginationRequest)
-	accessKeys, err := access.ListAccessKeys(c, r.UserID, r.Name, r.ShowExpired, pg)
+	accessKeys, err := access.ListAccessKeys(c, r.UserID, r.Name, r.ShowExpired, &pg)
 	if err != nil {
 		return nil, err
 	}
@@ -380,6 +514,7 @@ func (a *API) CreateAccessKey(c *gin.Context, r *api.CreateAccessKeyRequest) (*a
 		ExpiresAt:         time.Now().UTC().Add(time.Duration(r.TTL)),
 		Extension:         time.Duration(r.ExtensionDeadline),
 		ExtensionDeadline: time.Now().UTC().Add(time.Duration(r.ExtensionDeadline)),
+		Scopes:            r.Scopes,
 	}
 
 	raw, err := access.CreateAccessKey(c, accessKey)
@@ -392,6 +527,54 @@ func (a *API) CreateAccessKey(c *gin.Context, r *api.CreateAccessKeyRequest) (*a
 		Created:           api.Time(accessKey.CreatedAt),
 		Name:              accessKey.Name,
 		IssuedFor:         accessKey.IssuedFor,
+		Scopes:            accessKey.Scopes,
+		Expires:           api.Time(accessKey.ExpiresAt),
+		ExtensionDeadline: api.Time(accessKey.ExtensionDeadline),
+		AccessKey:         raw,
+	}, nil
+}
+
+func (a *API) RotateAccessKey(c *gin.Context, r *api.RotateAccessKeyRequest) (*api.CreateAccessKeyResponse, error) {
+	accessKey, raw, err := access.RotateAccessKey(c, r.ID, time.Duration(r.GracePeriod))
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CreateAccessKeyResponse{
+		ID:                accessKey.ID,
+		Created:           api.Time(accessKey.CreatedAt),
+		Name:              accessKey.Name,
+		IssuedFor:         accessKey.IssuedFor,
+		ProviderID:        accessKey.ProviderID,
+		Scopes:            accessKey.Scopes,
+		Expires:           api.Time(accessKey.ExpiresAt),
+		ExtensionDeadline: api.Time(accessKey.ExtensionDeadline),
+		AccessKey:         raw,
+	}, nil
+}
+
+func (a *API) CreateSCIMAccessKey(c *gin.Context, r *api.CreateSCIMAccessKeyRequest) (*api.CreateAccessKeyResponse, error) {
+	accessKey := &models.AccessKey{
+		Name:       r.Name,
+		ProviderID: r.ProviderID,
+		ExpiresAt:  time.Now().UTC().Add(time.Duration(r.TTL)),
+		// the key is used by the provider whenever a user or group changes,
+		// which may be infrequent, so it does not need to be extended
+		Extension:         time.Duration(r.TTL),
+		ExtensionDeadline: time.Now().UTC().Add(time.Duration(r.TTL)),
+	}
+
+	raw, err := access.CreateSCIMAccessKey(c, accessKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.CreateAccessKeyResponse{
+		ID:                accessKey.ID,
+		Created:           api.Time(accessKey.CreatedAt),
+		Name:              accessKey.Name,
+		IssuedFor:         accessKey.IssuedFor,
+		ProviderID:        accessKey.ProviderID,
 		Expires:           api.Time(accessKey.ExpiresAt),
 		ExtensionDeadline: api.Time(accessKey.ExtensionDeadline),
 		AccessKey:         raw,
@@ -408,7 +591,7 @@ func (a *API) ListGrants(c *gin.Context, r *api.ListGrantsRequest) (*api.ListRes
 		subject = uid.NewGroupPolymorphicID(r.Group)
 	}
 
-	grants, err := access.ListGrants(c, subject, r.Resource, r.Privilege, pg)
+	grants, err := access.ListGrants(c, subject, r.Resource, r.Privilege, &pg)
 	if err != nil {
 		return nil, err
 	}
@@ -455,14 +638,96 @@ func (a *API) CreateGrant(c *gin.Context, r *api.CreateGrantRequest) (*api.Grant
 		Privilege: r.Privilege,
 	}
 
+	if expires := r.Expires.Time(); !expires.IsZero() {
+		if !expires.After(time.Now()) {
+			return nil, fmt.Errorf("%w: expires must be in the future", internal.ErrBadRequest)
+		}
+		grant.ExpiresAt = &expires
+	}
+
 	err := access.CreateGrant(c, grant)
 	if err != nil {
 		return nil, err
 	}
 
+	a.sendWebhookEvent(c, api.WebhookEventGrantCreated, grant.ToAPI())
+
 	return grant.ToAPI(), nil
 }
 
+func (a *API) ListEffectiveGrants(c *gin.Context, r *api.ListEffectiveGrantsRequest) (*api.ListResponse[api.EffectiveGrant], error) {
+	grants, err := access.ListEffectiveGrants(c, r.User)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		privilege string
+		resource  string
+	}
+
+	var effective []*api.EffectiveGrant
+	byKey := make(map[key]*api.EffectiveGrant)
+
+	for _, grant := range grants {
+		g := grant.ToAPI()
+		k := key{privilege: g.Privilege, resource: g.Resource}
+
+		// the privilege expires with the last of its grants to expire
+		e, ok := byKey[k]
+		switch {
+		case !ok:
+			e = &api.EffectiveGrant{Privilege: g.Privilege, Resource: g.Resource, Expires: g.Expires}
+			byKey[k] = e
+			effective = append(effective, e)
+		case e.Expires.Time().IsZero() || g.Expires.Time().IsZero():
+			e.Expires = api.Time{}
+		case g.Expires.Time().After(e.Expires.Time()):
+			e.Expires = g.Expires
+		}
+
+		e.Grants = append(e.Grants, *g)
+	}
+
+	sort.Slice(effective, func(i, j int) bool {
+		if effective[i].Resource != effective[j].Resource {
+			return effective[i].Resource < effective[j].Resource
+		}
+		return effective[i].Privilege < effective[j].Privilege
+	})
+
+	return api.NewListResponse(effective, api.PaginationResponse{}, func(e *api.EffectiveGrant) api.EffectiveGrant {
+		return *e
+	}), nil
+}
+
+func (a *API) CheckAccess(c *gin.Context, r *api.CheckAccessRequest) (*api.CheckAccessResponse, error) {
+	check, err := access.CheckAccess(c, r.User, r.Privilege, r.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	groupNames := make(map[uid.PolymorphicID]string, len(check.Groups))
+	for _, group := range check.Groups {
+		groupNames[group.PolyID()] = group.Name
+	}
+
+	resp := &api.CheckAccessResponse{
+		Allowed: check.Allowed,
+		Grants:  make([]api.CheckAccessGrant, 0, len(check.Grants)),
+		Missing: check.Missing,
+	}
+
+	for _, grant := range check.Grants {
+		resp.Grants = append(resp.Grants, api.CheckAccessGrant{
+			Grant:     *grant.ToAPI(),
+			GroupName: groupNames[grant.Subject],
+		})
+	}
+
+	return resp, nil
+}
+
 func (a *API) DeleteGrant(c *gin.Context, r *api.Resource) (*api.EmptyResponse, error) {
 	grant, err := access.GetGrant(c, r.ID)
 	if err != nil {
@@ -470,7 +735,7 @@ func (a *API) DeleteGrant(c *gin.Context, r *api.Resource) (*api.EmptyResponse,
 	}
 
 	if grant.Resource == access.ResourceInfraAPI && grant.Privilege == models.InfraAdminRole {
-		infraAdminGrants, err := access.ListGrants(c, "", grant.Resource, grant.Privilege, models.Pagination{})
+		infraAdminGrants, err := access.ListGrants(c, "", grant.Resource, grant.Privilege, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -480,7 +745,217 @@ func (a *API) DeleteGrant(c *gin.Context, r *api.Resource) (*api.EmptyResponse,
 		}
 	}
 
-	return nil, access.DeleteGrant(c, r.ID)
+	if err := access.DeleteGrant(c, r.ID); err != nil {
+		return nil, err
+	}
+
+	a.sendWebhookEvent(c, api.WebhookEventGrantDeleted, grant.ToAPI())
+
+	return nil, nil
+}
+
+func (a *API) ListAccessRequests(c *gin.Context, r *api.ListAccessRequestsRequest) (*api.ListResponse[api.AccessRequest], error) {
+	pg := models.RequestToPagination(r.PaginationRequest)
+	requests, err := access.ListAccessRequests(c, r.RequestedBy, r.Resource, r.Status, &pg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := api.NewListResponse(requests, models.PaginationToResponse(pg), func(request models.AccessRequest) api.AccessRequest {
+		return *request.ToAPI()
+	})
+
+	return result, nil
+}
+
+func (a *API) GetAccessRequest(c *gin.Context, r *api.Resource) (*api.AccessRequest, error) {
+	request, err := access.GetAccessRequest(c, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return request.ToAPI(), nil
+}
+
+func (a *API) CreateAccessRequest(c *gin.Context, r *api.CreateAccessRequestRequest) (*api.AccessRequest, error) {
+	if r.Duration < 0 {
+		return nil, fmt.Errorf("%w: duration must be positive", internal.ErrBadRequest)
+	}
+
+	request := &models.AccessRequest{
+		Privilege: r.Privilege,
+		Resource:  r.Resource,
+		Reason:    r.Reason,
+		Duration:  time.Duration(r.Duration),
+	}
+
+	if err := access.CreateAccessRequest(c, request); err != nil {
+		return nil, err
+	}
+
+	return request.ToAPI(), nil
+}
+
+func (a *API) ApproveAccessRequest(c *gin.Context, r *api.Resource) (*api.AccessRequest, error) {
+	request, err := access.ApproveAccessRequest(c, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return request.ToAPI(), nil
+}
+
+func (a *API) DenyAccessRequest(c *gin.Context, r *api.Resource) (*api.AccessRequest, error) {
+	request, err := access.DenyAccessRequest(c, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return request.ToAPI(), nil
+}
+
+func (a *API) ListAccessApprovers(c *gin.Context, r *api.ListAccessApproversRequest) (*api.ListResponse[api.AccessApprover], error) {
+	pg := models.RequestToPagination(r.PaginationRequest)
+	approvers, err := access.ListAccessApprovers(c, r.Resource, &pg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := api.NewListResponse(approvers, models.PaginationToResponse(pg), func(approver models.AccessApprover) api.AccessApprover {
+		return *approver.ToAPI()
+	})
+
+	return result, nil
+}
+
+func (a *API) CreateAccessApprover(c *gin.Context, r *api.CreateAccessApproverRequest) (*api.AccessApprover, error) {
+	var subject uid.PolymorphicID
+
+	switch {
+	case r.User != 0:
+		subject = uid.NewIdentityPolymorphicID(r.User)
+	case r.Group != 0:
+		subject = uid.NewGroupPolymorphicID(r.Group)
+	}
+
+	approver := &models.AccessApprover{
+		Subject:  subject,
+		Resource: r.Resource,
+	}
+
+	if err := access.CreateAccessApprover(c, approver); err != nil {
+		return nil, err
+	}
+
+	return approver.ToAPI(), nil
+}
+
+func (a *API) DeleteAccessApprover(c *gin.Context, r *api.Resource) (*api.EmptyResponse, error) {
+	return nil, access.DeleteAccessApprover(c, r.ID)
+}
+
+func (a *API) ListWebhooks(c *gin.Context, r *api.ListWebhooksRequest) (*api.ListResponse[api.Webhook], error) {
+	pg := models.RequestToPagination(r.PaginationRequest)
+	webhooks, err := access.ListWebhooks(c, &pg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := api.NewListResponse(webhooks, models.PaginationToResponse(pg), func(webhook models.Webhook) api.Webhook {
+		return *webhook.ToAPI()
+	})
+
+	return result, nil
+}
+
+func (a *API) GetWebhook(c *gin.Context, r *api.Resource) (*api.Webhook, error) {
+	webhook, err := access.GetWebhook(c, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return webhook.ToAPI(), nil
+}
+
+func (a *API) CreateWebhook(c *gin.Context, r *api.CreateWebhookRequest) (*api.Webhook, error) {
+	webhook := &models.Webhook{
+		URL:    r.URL,
+		Events: r.Events,
+		Secret: models.EncryptedAtRest(r.Secret),
+	}
+
+	if err := access.CreateWebhook(c, webhook); err != nil {
+		return nil, err
+	}
+
+	return webhook.ToAPI(), nil
+}
+
+func (a *API) DeleteWebhook(c *gin.Context, r *api.Resource) (*api.EmptyResponse, error) {
+	return nil, access.DeleteWebhook(c, r.ID)
+}
+
+func (a *API) ListWebhookDeliveries(c *gin.Context, r *api.ListWebhookDeliveriesRequest) (*api.ListResponse[api.WebhookDelivery], error) {
+	pg := models.RequestToPagination(r.PaginationRequest)
+	deliveries, err := access.ListWebhookDeliveries(c, r.ID, &pg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := api.NewListResponse(deliveries, models.PaginationToResponse(pg), func(delivery models.WebhookDelivery) api.WebhookDelivery {
+		return *delivery.ToAPI()
+	})
+
+	return result, nil
+}
+
+func (a *API) ListRoles(c *gin.Context, r *api.ListRolesRequest) (*api.ListResponse[api.Role], error) {
+	pg := models.RequestToPagination(r.PaginationRequest)
+	roles, err := access.ListRoles(c, r.Name, &pg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := api.NewListResponse(roles, models.PaginationToResponse(pg), func(role models.Role) api.Role {
+		return *role.ToAPI()
+	})
+
+	return result, nil
+}
+
+func (a *API) GetRole(c *gin.Context, r *api.Resource) (*api.Role, error) {
+	role, err := access.GetRole(c, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return role.ToAPI(), nil
+}
+
+func (a *API) CreateRole(c *gin.Context, r *api.CreateRoleRequest) (*api.Role, error) {
+	role := &models.Role{
+		Name:        r.Name,
+		Permissions: r.Permissions,
+	}
+
+	if err := access.CreateRole(c, role); err != nil {
+		return nil, err
+	}
+
+	return role.ToAPI(), nil
+}
+
+func (a *API) UpdateRole(c *gin.Context, r *api.UpdateRoleRequest) (*api.Role, error) {
+	role, err := access.UpdateRole(c, r.ID, r.Permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	return role.ToAPI(), nil
+}
+
+func (a *API) DeleteRole(c *gin.Context, r *api.Resource) (*api.EmptyResponse, error) {
+	return nil, access.DeleteRole(c, r.ID)
 }
 
 func (a *API) SignupEnabled(c *gin.Context, _ *api.EmptyRequest) (*api.SignupEnabledResponse, error) {
@@ -515,7 +990,7 @@ func (a *API) Signup(c *gin.Context, r *api.SignupRequest) (*api.User, error) {
 		r.Name = r.Email
 	}
 
-	identity, err := access.Signup(c, r.Name, r.Password)
+	identity, err := access.Signup(c, r.Name, r.Password, a.server.passwords)
 	if err != nil {
 		return nil, err
 	}
@@ -539,7 +1014,18 @@ func (a *API) Login(c *gin.Context, r *api.LoginRequest) (*api.LoginResponse, er
 			// #1825: remove, this is for migration
 			r.PasswordCredentials.Name = r.PasswordCredentials.Email
 		}
-		loginMethod = authn.NewPasswordCredentialAuthentication(r.PasswordCredentials.Name, r.PasswordCredentials.Password)
+		if err := a.server.logins.Allow(c.ClientIP()); err != nil {
+			return nil, err
+		}
+		loginMethod = authn.NewPasswordCredentialAuthentication(r.PasswordCredentials.Name, r.PasswordCredentials.Password, r.PasswordCredentials.MFACode, authn.PasswordLoginOptions{
+			Lockout:         a.server.options.LoginLockout.userPolicy(),
+			RequireAdminMFA: a.server.options.RequireAdminMFA,
+		})
+	case r.PasswordReset != nil:
+		if err := a.server.logins.Allow(c.ClientIP()); err != nil {
+			return nil, err
+		}
+		loginMethod = authn.NewPasswordResetAuthentication(r.PasswordReset.Token, r.PasswordReset.MFACode)
 	case r.OIDC != nil:
 		provider, err := access.GetProvider(c, r.OIDC.ProviderID)
 		if err != nil {
@@ -565,7 +1051,23 @@ func (a *API) Login(c *gin.Context, r *api.LoginRequest) (*api.LoginResponse, er
 			// this means an external request failed, probably to an IDP
 			return nil, err
 		}
+
+		if errors.Is(err, authn.ErrMFARequired) {
+			// the password was valid, the user should be asked for a code
+			return nil, err
+		}
 		logging.S.Debug(err)
+
+		failed := api.WebhookLogin{Method: loginMethod.Name()}
+		if r.PasswordCredentials != nil {
+			failed.Name = r.PasswordCredentials.Name
+			a.server.logins.Failed(c.ClientIP())
+		}
+		if r.PasswordReset != nil {
+			a.server.logins.Failed(c.ClientIP())
+		}
+		a.sendWebhookEvent(c, api.WebhookEventLoginFailed, failed)
+
 		// all other failures from login should result in an unauthorized response
 		return nil, internal.ErrUnauthorized
 	}
@@ -573,8 +1075,47 @@ func (a *API) Login(c *gin.Context, r *api.LoginRequest) (*api.LoginResponse, er
 	setAuthCookie(c, bearer, expires)
 
 	a.t.Event("login", key.IssuedFor.String(), Properties{"method": loginMethod.Name()})
+	a.sendWebhookEvent(c, api.WebhookEventLogin, api.WebhookLogin{
+		UserID: key.IssuedFor,
+		Name:   key.IssuedForIdentity.Name,
+		Method: loginMethod.Name(),
+	})
 
-	return &api.LoginResponse{UserID: key.IssuedFor, Name: key.IssuedForIdentity.Name, AccessKey: bearer, Expires: api.Time(expires), PasswordUpdateRequired: requiresUpdate}, nil
+	return &api.LoginResponse{
+		UserID:                 key.IssuedFor,
+		Name:                   key.IssuedForIdentity.Name,
+		AccessKey:              bearer,
+		Expires:                api.Time(expires),
+		PasswordUpdateRequired: requiresUpdate,
+		MFAEnrollmentRequired:  key.Scopes.Includes(models.ScopeMFAEnrollment),
+	}, nil
+}
+
+func (a *API) RequestPasswordReset(c *gin.Context, r *api.PasswordResetRequest) (*api.EmptyResponse, error) {
+	if !a.server.mailer.enabled() {
+		return nil, fmt.Errorf("%w: email is not configured", internal.ErrNotImplemented)
+	}
+
+	ttl := a.server.options.Email.PasswordResetExpiry
+	identity, token, err := access.RequestPasswordReset(c, r.Email, ttl)
+	if err != nil {
+		if errors.Is(err, internal.ErrNotFound) || errors.Is(err, internal.ErrTooManyRequests) {
+			// do not reveal which users exist
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	// send the email in the background, so that the response does not take
+	// longer for the users that exist
+	expires := time.Now().Add(ttl)
+	afterCommit(c, func() {
+		if err := a.server.mailer.sendPasswordResetLink(emailPasswordReset, identity, token, expires); err != nil {
+			logging.S.Errorf("failed to send password reset email: %s", err)
+		}
+	})
+
+	return nil, nil
 }
 
 func (a *API) Logout(c *gin.Context, r *api.EmptyRequest) (*api.EmptyResponse, error) {
@@ -588,6 +1129,22 @@ func (a *API) Logout(c *gin.Context, r *api.EmptyRequest) (*api.EmptyResponse, e
 	return nil, nil
 }
 
+func (a *API) SignCertificate(c *gin.Context, r *api.SignCertificateRequest) (*api.SignCertificateResponse, error) {
+	if a.server.certificates == nil {
+		return nil, fmt.Errorf("%w: client certificates are not enabled", internal.ErrNotImplemented)
+	}
+
+	cert, pemBytes, err := access.SignCertificate(c, a.server.certificates, []byte(r.CertificateSigningRequest))
+	if err != nil {
+		return nil, err
+	}
+
+	return &api.SignCertificateResponse{
+		Certificate: api.PEM(pemBytes),
+		Expires:     api.Time(cert.NotAfter),
+	}, nil
+}
+
 func (a *API) Version(c *gin.Context, r *api.EmptyRequest) (*api.Version, error) {
 	return &api.Version{Version: internal.FullVersion()}, nil
 }
@@ -19,6 +19,7 @@ import (
 
 	"github.com/infrahq/infra/api"
 	"github.com/infrahq/infra/internal/generate"
+	"github.com/infrahq/infra/internal/server/authn"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/uid"
@@ -34,8 +35,6 @@ func adminAccessKey(s *Server) string {
 	return ""
 }
 
-var defaultPagination api.PaginationResponse
-
 func TestAPI_ListUsers(t *testing.T) {
 	srv := setupServer(t, withAdminUser)
 	routes := srv.GenerateRoutes(prometheus.NewRegistry())
@@ -75,6 +74,9 @@ func TestAPI_ListUsers(t *testing.T) {
 	id3 := createID(t, "HAL@example.com")
 	_ = createID(t, "other-HAL@example.com")
 
+	connector, err := data.GetIdentity(srv.db, data.ByName("connector"))
+	assert.NilError(t, err)
+
 	type testCase struct {
 		urlPath  string
 		setup    func(t *testing.T, req *http.Request)
@@ -102,7 +104,7 @@ func TestAPI_ListUsers(t *testing.T) {
 			urlPath: "/api/users?name=doesnotmatch",
 			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
 				assert.Equal(t, resp.Code, http.StatusOK)
-				assert.Equal(t, resp.Body.String(), `{"pagination_info":{},"items":[],"count":0}`)
+				assert.Equal(t, resp.Body.String(), `{"pagination_info":{"totalCount":0},"items":[],"count":0}`)
 			},
 		},
 		"name match": {
@@ -118,7 +120,7 @@ func TestAPI_ListUsers(t *testing.T) {
 					Items: []api.User{
 						{Name: "me@example.com"},
 					},
-					PaginationInfo: defaultPagination,
+					PaginationInfo: api.PaginationResponse{TotalCount: 1},
 				}
 				assert.DeepEqual(t, actual, expected, cmpAPIUserShallow)
 			},
@@ -138,7 +140,7 @@ func TestAPI_ListUsers(t *testing.T) {
 						{Name: "me@example.com"},
 						{Name: "other@example.com"},
 					},
-					PaginationInfo: defaultPagination,
+					PaginationInfo: api.PaginationResponse{TotalCount: 3},
 				}
 				assert.DeepEqual(t, actual, expected, cmpAPIUserShallow)
 			},
@@ -162,7 +164,7 @@ func TestAPI_ListUsers(t *testing.T) {
 						{Name: "other-HAL@example.com"},
 						{Name: "other@example.com"},
 					},
-					PaginationInfo: defaultPagination,
+					PaginationInfo: api.PaginationResponse{TotalCount: 7},
 				}
 				assert.DeepEqual(t, actual, expected, cmpAPIUserShallow)
 			},
@@ -191,11 +193,14 @@ func TestAPI_ListUsers(t *testing.T) {
 						{Name: "connector"},
 					},
 					PaginationInfo: api.PaginationResponse{
-						Page:  2,
-						Limit: 2,
+						Page:       2,
+						Limit:      2,
+						TotalCount: 7,
+						NextCursor: actual.PaginationInfo.NextCursor,
 					},
 				}
 				assert.DeepEqual(t, actual, expected, cmpAPIUserShallow)
+				assert.Assert(t, actual.PaginationInfo.NextCursor != 0)
 			},
 		},
 		"user in group": {
@@ -212,7 +217,7 @@ func TestAPI_ListUsers(t *testing.T) {
 					Items: []api.User{
 						{Name: anotherID.Name},
 					},
-					PaginationInfo: defaultPagination,
+					PaginationInfo: api.PaginationResponse{TotalCount: 1},
 				}
 				assert.DeepEqual(t, actual, expected, cmpAPIUserShallow)
 			},
@@ -223,6 +228,35 @@ func TestAPI_ListUsers(t *testing.T) {
 				assert.Equal(t, resp.Code, http.StatusBadRequest)
 			},
 		},
+		"invalid cursor": {
+			urlPath: "/api/users?cursor=not-a-cursor",
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusBadRequest)
+			},
+		},
+		"next page by cursor": {
+			urlPath: "/api/users?limit=2&cursor=" + api.Cursor(connector.ID).String(),
+			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
+				assert.Equal(t, resp.Code, http.StatusOK)
+
+				var actual api.ListResponse[api.User]
+				err := json.NewDecoder(resp.Body).Decode(&actual)
+				assert.NilError(t, err)
+				expected := api.ListResponse[api.User]{
+					Count: 2,
+					Items: []api.User{
+						{Name: "me@example.com"},
+						{Name: "other-HAL@example.com"},
+					},
+					PaginationInfo: api.PaginationResponse{
+						Limit:      2,
+						TotalCount: 7,
+						NextCursor: actual.PaginationInfo.NextCursor,
+					},
+				}
+				assert.DeepEqual(t, actual, expected, cmpAPIUserShallow)
+			},
+		},
 		"invalid page": {
 			urlPath: "/api/users?page=-1",
 			expected: func(t *testing.T, resp *httptest.ResponseRecorder) {
@@ -706,6 +740,25 @@ func jsonBody(t *testing.T, body interface{}) *bytes.Buffer {
 	return buf
 }
 
+// callAPI sends a request to routes, authenticated with key when it is not
+// empty, and returns the recorded response. Each of opts may modify the
+// request before it is sent.
+func callAPI(t *testing.T, routes http.Handler, method, path, key string, body interface{}, opts ...func(*http.Request)) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(method, path, jsonBody(t, body))
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	req.Header.Set("Infra-Version", "0.13.0")
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	resp := httptest.NewRecorder()
+	routes.ServeHTTP(resp, req)
+	return resp
+}
+
 func TestDeleteUser(t *testing.T) {
 	s := setupServer(t, withAdminUser)
 
@@ -811,7 +864,8 @@ func TestAPI_CreateGrant_Success(t *testing.T) {
 		  "resource": "some-cluster",
 		  "user": "TJ",
 		  "created": "%[2]v",
-		  "updated": "%[2]v"
+		  "updated": "%[2]v",
+		  "expires": null
 		}`,
 			accessKey.IssuedFor,
 			time.Now().UTC().Format(time.RFC3339),
@@ -841,6 +895,77 @@ func TestAPI_CreateGrant_Success(t *testing.T) {
 	})
 }
 
+func TestAPI_CreateGrant_WithExpiry(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	user := &models.Identity{Name: "temporary@example.com"}
+	createIdentities(t, srv.db, user)
+
+	createGrant := func(t *testing.T, expires time.Time) *httptest.ResponseRecorder {
+		t.Helper()
+		body := jsonBody(t, api.CreateGrantRequest{
+			User:      user.ID,
+			Privilege: "view",
+			Resource:  "some-cluster",
+			Expires:   api.Time(expires),
+		})
+		req := httptest.NewRequest(http.MethodPost, "/api/grants", body)
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Set("Infra-Version", "0.13.0")
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	listGrants := func(t *testing.T) []api.Grant {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodGet, "/api/grants?user="+user.ID.String(), nil)
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Set("Infra-Version", "0.13.0")
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var grants api.ListResponse[api.Grant]
+		err := json.NewDecoder(resp.Body).Decode(&grants)
+		assert.NilError(t, err)
+		return grants.Items
+	}
+
+	t.Run("expiry in the past", func(t *testing.T) {
+		resp := createGrant(t, time.Now().Add(-time.Minute))
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("expiry in the future", func(t *testing.T) {
+		expires := time.Now().Add(time.Hour).Truncate(time.Second)
+		resp := createGrant(t, expires)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var grant api.Grant
+		err := json.NewDecoder(resp.Body).Decode(&grant)
+		assert.NilError(t, err)
+		assert.Assert(t, grant.Expires.Time().Equal(expires))
+
+		grants := listGrants(t)
+		assert.Equal(t, len(grants), 1)
+		assert.Assert(t, grants[0].Expires.Time().Equal(expires))
+	})
+
+	t.Run("expired grants are not listed", func(t *testing.T) {
+		past := time.Now().Add(-time.Minute)
+		err := srv.db.Model(&models.Grant{}).
+			Where("subject = ?", user.PolyID()).
+			Update("expires_at", past).Error
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(listGrants(t)), 0)
+	})
+}
+
 func TestAPI_CreateGrantV0_12_2_Success(t *testing.T) {
 	srv := setupServer(t, withAdminUser)
 	routes := srv.GenerateRoutes(prometheus.NewRegistry())
@@ -1146,10 +1271,12 @@ func TestAPI_GetUser(t *testing.T) {
 					{
 						"id": "%[1]v",
 						"name": "me@example.com",
+						"kind": "user",
 						"lastSeenAt": "%[2]v",
 						"created": "%[2]v",
 						"providerNames": ["infra"],
-						"updated": "%[2]v"
+						"updated": "%[2]v",
+						"lockedUntil": null
 					}`,
 					idMe.String(),
 					time.Now().UTC().Format(time.RFC3339),
@@ -1458,3 +1585,137 @@ func TestAPI_LoginResponse(t *testing.T) {
 	assert.Equal(t, loginResp.Name, "steve")
 	assert.Equal(t, loginResp.PasswordUpdateRequired, false)
 }
+
+func TestAPI_UpdateUser_PasswordPolicy(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	srv.passwords = authn.PasswordPolicy{MinLength: 12, RequireSymbol: true}
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	user := &models.Identity{Name: "steve@example.com"}
+	createIdentities(t, srv.db, user)
+
+	update := func(t *testing.T, password string) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPut, "/api/users/"+user.ID.String(), jsonBody(t, api.UpdateUserRequest{Password: password}))
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Set("Infra-Version", "0.13.3")
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	resp := update(t, "password1")
+	assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+	var apiErr api.Error
+	assert.NilError(t, json.NewDecoder(resp.Body).Decode(&apiErr))
+	assert.DeepEqual(t, apiErr.FieldErrors, []api.FieldError{{
+		FieldName: "password",
+		Errors:    []string{"must be at least 12 characters", "must contain a symbol"},
+	}})
+	assert.Equal(t, apiErr.Message, "password: must be at least 12 characters, must contain a symbol")
+
+	resp = update(t, "much-longer-password")
+	assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+}
+
+func TestAPI_LoginLockout(t *testing.T) {
+	srv := setupServer(t, withAdminUser, func(t *testing.T, options *Options) {
+		options.LoginLockout = LoginLockoutOptions{
+			UserAttempts: 2,
+			IPAttempts:   4,
+			Duration:     time.Hour,
+		}
+		options.TrustedProxies = []string{"10.0.0.100"}
+	})
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	createUser := func(t *testing.T, name string) *models.Identity {
+		t.Helper()
+		user := &models.Identity{Name: name}
+		assert.NilError(t, data.CreateIdentity(srv.db, user))
+
+		hash, err := bcrypt.GenerateFromPassword([]byte("hunter2"), bcrypt.MinCost)
+		assert.NilError(t, err)
+		assert.NilError(t, data.CreateCredential(srv.db, &models.Credential{IdentityID: user.ID, PasswordHash: hash}))
+		return user
+	}
+
+	login := func(t *testing.T, name, password, ip string, forwardedFor ...string) *httptest.ResponseRecorder {
+		t.Helper()
+		body := jsonBody(t, api.LoginRequest{PasswordCredentials: &api.LoginRequestPasswordCredentials{Name: name, Password: password}})
+		req := httptest.NewRequest(http.MethodPost, "/api/login", body)
+		req.Header.Add("Infra-Version", "0.13.3")
+		req.RemoteAddr = ip + ":4321"
+		for _, addr := range forwardedFor {
+			req.Header.Add("X-Forwarded-For", addr)
+		}
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	steve := createUser(t, "steve@example.com")
+
+	t.Run("user is locked after failed logins", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			resp := login(t, steve.Name, "wrong", "10.0.0.1")
+			assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+		}
+
+		resp := login(t, steve.Name, "hunter2", "10.0.0.2")
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+		req := httptest.NewRequest(http.MethodGet, "/api/users/"+steve.ID.String(), nil)
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Set("Infra-Version", "0.13.3")
+		resp = httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var user api.User
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&user))
+		assert.Equal(t, user.FailedLoginAttempts, 2)
+		assert.Assert(t, time.Time(user.LockedUntil).After(time.Now()))
+	})
+
+	t.Run("admin unlocks the user", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/users/"+steve.ID.String(), jsonBody(t, api.UpdateUserRequest{Unlock: true}))
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Set("Infra-Version", "0.13.3")
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var user api.User
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&user))
+		assert.Equal(t, user.FailedLoginAttempts, 0)
+
+		resp = login(t, steve.Name, "hunter2", "10.0.0.2")
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+	})
+
+	t.Run("source IP is blocked after failed logins", func(t *testing.T) {
+		for _, name := range []string{"a@example.com", "b@example.com"} {
+			createUser(t, name)
+			resp := login(t, name, "wrong", "10.0.0.1")
+			assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+		}
+
+		resp := login(t, steve.Name, "hunter2", "10.0.0.1")
+		assert.Equal(t, resp.Code, http.StatusTooManyRequests, resp.Body.String())
+	})
+
+	t.Run("X-Forwarded-For is only used from a trusted proxy", func(t *testing.T) {
+		resp := login(t, steve.Name, "hunter2", "10.0.0.1", "10.0.0.5")
+		assert.Equal(t, resp.Code, http.StatusTooManyRequests, resp.Body.String())
+
+		resp = login(t, steve.Name, "hunter2", "10.0.0.100", "10.0.0.1")
+		assert.Equal(t, resp.Code, http.StatusTooManyRequests, resp.Body.String())
+
+		resp = login(t, steve.Name, "hunter2", "10.0.0.100", "10.0.0.5")
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+	})
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/crypto/bcrypt"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/authn"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestAPI_MFA(t *testing.T) {
+	srv := setupServer(t, withAdminUser, func(t *testing.T, options *Options) {
+		options.RequireAdminMFA = true
+	})
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	createUser := func(t *testing.T, name string) *models.Identity {
+		t.Helper()
+		user := &models.Identity{Name: name}
+		assert.NilError(t, data.CreateIdentity(srv.db, user))
+
+		hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+		assert.NilError(t, err)
+		assert.NilError(t, data.CreateCredential(srv.db, &models.Credential{IdentityID: user.ID, PasswordHash: hash}))
+		return user
+	}
+
+	login := func(t *testing.T, name, code string) (*httptest.ResponseRecorder, api.LoginResponse) {
+		t.Helper()
+		resp := callAPI(t, routes, http.MethodPost, "/api/login", "", api.LoginRequest{
+			PasswordCredentials: &api.LoginRequestPasswordCredentials{Name: name, Password: "password123", MFACode: code},
+		})
+
+		var loginResp api.LoginResponse
+		if resp.Code == http.StatusCreated {
+			assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &loginResp))
+		}
+		return resp, loginResp
+	}
+
+	enroll := func(t *testing.T, user *models.Identity, key string) (string, []string) {
+		t.Helper()
+		path := "/api/users/" + user.ID.String() + "/mfa"
+
+		resp := callAPI(t, routes, http.MethodPost, path, key, nil)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var enrollment api.EnrollUserMFAResponse
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&enrollment))
+		assert.Equal(t, enrollment.URI, authn.TOTPURI(user.Name, enrollment.Secret))
+
+		resp = callAPI(t, routes, http.MethodPut, path, key, api.ConfirmUserMFARequest{Code: "000000"})
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		code, err := authn.TOTPCode(enrollment.Secret, time.Now())
+		assert.NilError(t, err)
+
+		resp = callAPI(t, routes, http.MethodPut, path, key, api.ConfirmUserMFARequest{Code: code})
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var confirmed api.ConfirmUserMFAResponse
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&confirmed))
+		assert.Equal(t, len(confirmed.RecoveryCodes), 10)
+
+		return enrollment.Secret, confirmed.RecoveryCodes
+	}
+
+	alice := createUser(t, "alice@example.com")
+	var aliceSecret string
+	var aliceRecoveryCodes []string
+
+	t.Run("enable mfa", func(t *testing.T) {
+		resp, loginResp := login(t, alice.Name, "")
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		assert.Equal(t, loginResp.MFAEnrollmentRequired, false)
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/users/"+alice.ID.String()+"/mfa", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		aliceSecret, aliceRecoveryCodes = enroll(t, alice, loginResp.AccessKey)
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/users/"+alice.ID.String()+"/mfa", loginResp.AccessKey, nil)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("login requires a code", func(t *testing.T) {
+		resp, _ := login(t, alice.Name, "")
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+		var apiErr api.Error
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&apiErr))
+		assert.DeepEqual(t, apiErr.FieldErrors, []api.FieldError{{FieldName: "mfaCode", Errors: []string{"is required"}}})
+
+		resp, _ = login(t, alice.Name, "000000")
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+		apiErr = api.Error{}
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&apiErr))
+		assert.Equal(t, len(apiErr.FieldErrors), 0)
+
+		// the code used to confirm can not be used again, use the next one
+		code, err := authn.TOTPCode(aliceSecret, time.Now().Add(30*time.Second))
+		assert.NilError(t, err)
+
+		resp, _ = login(t, alice.Name, code)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+	})
+
+	t.Run("login with a recovery code", func(t *testing.T) {
+		resp, _ := login(t, alice.Name, aliceRecoveryCodes[0])
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		resp, _ = login(t, alice.Name, aliceRecoveryCodes[0])
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("admins must enable mfa", func(t *testing.T) {
+		bob := createUser(t, "bob@example.com")
+		assert.NilError(t, data.CreateGrant(srv.db, &models.Grant{Subject: bob.PolyID(), Privilege: models.InfraAdminRole, Resource: "infra"}))
+
+		resp, loginResp := login(t, bob.Name, "")
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		assert.Equal(t, loginResp.MFAEnrollmentRequired, true)
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/users", loginResp.AccessKey, nil)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+		enroll(t, bob, loginResp.AccessKey)
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/users", loginResp.AccessKey, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+	})
+
+	t.Run("disable mfa", func(t *testing.T) {
+		carol := createUser(t, "carol@example.com")
+		_, loginResp := login(t, carol.Name, "")
+
+		resp := callAPI(t, routes, http.MethodDelete, "/api/users/"+alice.ID.String()+"/mfa", loginResp.AccessKey, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodDelete, "/api/users/"+alice.ID.String()+"/mfa", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		resp, _ = login(t, alice.Name, "")
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+	})
+}
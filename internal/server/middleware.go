@@ -52,14 +52,48 @@ func DatabaseMiddleware(db *gorm.DB) gin.HandlerFunc {
 		})
 		if err != nil {
 			logging.S.Debugf(err.Error())
+			return
+		}
+
+		if fns, ok := c.Get(afterCommitKey); ok {
+			for _, fn := range fns.([]func()) {
+				go fn()
+			}
 		}
 	}
 }
 
-// AuthenticationMiddleware validates the incoming token
+const afterCommitKey = "afterCommit"
+
+// afterCommit runs fn in the background once the transaction of the request
+// has been committed. It is used to send emails, which should not hold the
+// transaction open, or be sent for changes that are rolled back.
+func afterCommit(c *gin.Context, fn func()) {
+	var fns []func()
+	if v, ok := c.Get(afterCommitKey); ok {
+		fns = v.([]func())
+	}
+
+	c.Set(afterCommitKey, append(fns, fn))
+}
+
+// StreamDatabaseMiddleware sets the database of the request without starting a
+// transaction, for routes that stream a response for as long as the request is
+// open. DatabaseMiddleware would hold a transaction open for the life of the
+// stream.
+func StreamDatabaseMiddleware(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("db", db.WithContext(c.Request.Context()))
+		c.Next()
+	}
+}
+
+// AuthenticationMiddleware validates the incoming token, or the client
+// certificate of requests without an Authorization header when client
+// certificates are enabled.
 func AuthenticationMiddleware(a *API) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if err := RequireAccessKey(c); err != nil {
+		if err := a.authenticate(c); err != nil {
 			sendAPIError(c, err)
 			return
 		}
@@ -68,6 +102,34 @@ func AuthenticationMiddleware(a *API) gin.HandlerFunc {
 	}
 }
 
+// authenticate validates the access key, or the client certificate, of the
+// request.
+func (a *API) authenticate(c *gin.Context) error {
+	if cp := a.server.certificates; cp != nil && hasClientCertificate(c) && c.Request.Header.Get("Authorization") == "" {
+		return RequireClientCertificate(c, cp)
+	}
+
+	return RequireAccessKey(c)
+}
+
+// allowedByRequestScopes checks the request is allowed by the read-only, tokens,
+// and destination scopes of an access key. A destination scope allows the same
+// requests as the tokens scope. A key with both read-only and tokens scopes can
+// make the requests allowed by either of them.
+func allowedByRequestScopes(req *http.Request, key *models.AccessKey) bool {
+	readOnly := key.Scopes.Includes(models.ScopeReadOnly)
+	tokens := key.Scopes.Includes(models.ScopeTokens) || len(key.DestinationScopes()) > 0
+	if !readOnly && !tokens {
+		return true
+	}
+
+	if readOnly && (req.Method == http.MethodGet || req.Method == http.MethodHead) {
+		return true
+	}
+
+	return tokens && req.Method == http.MethodPost && req.URL.Path == "/api/tokens"
+}
+
 // RequireAccessKey checks the bearer token is present and valid
 func RequireAccessKey(c *gin.Context) error {
 	db, ok := c.MustGet("db").(*gorm.DB)
@@ -102,11 +164,25 @@ func RequireAccessKey(c *gin.Context) error {
 		return fmt.Errorf("%w: invalid token: %s", internal.ErrUnauthorized, err)
 	}
 
-	if accessKey.Scopes.Includes(models.ScopePasswordReset) {
+	switch {
+	case accessKey.Scopes.Includes(models.ScopePasswordReset):
 		// PUT /api/users/:id only
 		if c.Request.URL.Path != "/api/users/"+accessKey.IssuedFor.String() || c.Request.Method != http.MethodPut {
 			return fmt.Errorf("%w: temporary passwords can only be used to set new passwords", internal.ErrUnauthorized)
 		}
+	case accessKey.Scopes.Includes(models.ScopeMFAEnrollment):
+		// /api/users/:id/mfa only
+		if c.Request.URL.Path != "/api/users/"+accessKey.IssuedFor.String()+"/mfa" {
+			return fmt.Errorf("%w: multi-factor authentication must be enabled before using this access key", internal.ErrUnauthorized)
+		}
+	}
+
+	if accessKey.Scopes.Includes(models.ScopeSCIM) && !strings.HasPrefix(c.Request.URL.Path, "/scim/v2/") {
+		return fmt.Errorf("%w: SCIM access keys can only be used for SCIM provisioning", internal.ErrUnauthorized)
+	}
+
+	if !allowedByRequestScopes(c.Request, accessKey) {
+		return fmt.Errorf("%w: the scopes of this access key do not allow %s %s", internal.ErrUnauthorized, c.Request.Method, c.Request.URL.Path)
 	}
 
 	c.Set("key", accessKey)
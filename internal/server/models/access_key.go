@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 
 	"github.com/infrahq/infra/api"
@@ -12,17 +13,42 @@ var (
 	AccessKeySecretLength = 24 // the length of the secret used to validate an access key
 )
 
-const ScopePasswordReset = "password-reset"
+const (
+	ScopePasswordReset = "password-reset"
+	// ScopeSCIM limits an access key to provisioning the users and groups of
+	// its provider with SCIM.
+	ScopeSCIM = "scim"
+	// ScopeMFAEnrollment limits an access key to enabling multi-factor
+	// authentication for the user it was issued for.
+	ScopeMFAEnrollment = "mfa-enrollment"
+
+	// ScopeReadOnly limits an access key to requests that do not change
+	// anything.
+	ScopeReadOnly = "read-only"
+	// ScopeTokens limits an access key to creating tokens for destinations.
+	ScopeTokens = "tokens"
+	// ScopeDestinationPrefix followed by the name of a destination limits an
+	// access key to creating tokens for that destination.
+	ScopeDestinationPrefix = "destination:"
+)
 
 // AccessKey is a session token presented to the Infra server as proof of authentication
 type AccessKey struct {
 	Model
-	Name              string                `gorm:"uniqueIndex:idx_access_keys_name,where:deleted_at is NULL" validate:"excludes= "`
-	IssuedFor         uid.ID                `validate:"required"` // the ID of the identity that this access key was created for
-	IssuedForIdentity *Identity             `gorm:"foreignKey:IssuedFor"`
-	ProviderID        uid.ID                `validate:"required"`
+	Name              string    `gorm:"uniqueIndex:idx_access_keys_name,where:deleted_at is NULL" validate:"excludes= "`
+	IssuedFor         uid.ID    `validate:"required"` // the ID of the identity that this access key was created for
+	IssuedForIdentity *Identity `gorm:"foreignKey:IssuedFor"`
+	ProviderID        uid.ID    `validate:"required"`
+	Provider          *Provider `gorm:"foreignKey:ProviderID;constraint:-"`
+	CreatedBy         uid.ID
 	Scopes            CommaSeparatedStrings // if set, scopes limit what the key can be used for
 
+	// Session is true when the key was created by a login, instead of being
+	// created explicitly for a user or service account.
+	Session    bool
+	ClientName string    // the client the user logged in with
+	LastUsedAt time.Time // updated when the key is used to authenticate
+
 	ExpiresAt         time.Time     `validate:"required"`
 	Extension         time.Duration // how long to increase the lifetime extension deadline by
 	ExtensionDeadline time.Time
@@ -30,6 +56,67 @@ type AccessKey struct {
 	KeyID          string `gorm:"<-;uniqueIndex:idx_access_keys_key_id,where:deleted_at is NULL"`
 	Secret         string `gorm:"-"`
 	SecretChecksum []byte
+
+	// PreviousSecretChecksum is the checksum of the secret that was replaced
+	// when the key was rotated. It is valid until PreviousSecretExpiresAt.
+	PreviousSecretChecksum  []byte
+	PreviousSecretExpiresAt time.Time
+	// UsedPreviousSecret is set by data.ValidateAccessKey when the key was
+	// validated with its previous secret.
+	UsedPreviousSecret bool `gorm:"-"`
+}
+
+// PermissionScopes returns the scopes of the access key that are permissions of
+// the infra API. When a key has permission scopes it can only be used for those
+// permissions, even if the identity it was issued for has others.
+func (ak *AccessKey) PermissionScopes() []string {
+	var permissions []string
+	for _, scope := range ak.Scopes {
+		if IsPermission(scope) {
+			permissions = append(permissions, scope)
+		}
+	}
+
+	return permissions
+}
+
+// DestinationScopes returns the names of the destinations the access key is
+// limited to creating tokens for.
+func (ak *AccessKey) DestinationScopes() []string {
+	var destinations []string
+	for _, scope := range ak.Scopes {
+		if strings.HasPrefix(scope, ScopeDestinationPrefix) {
+			destinations = append(destinations, strings.TrimPrefix(scope, ScopeDestinationPrefix))
+		}
+	}
+
+	return destinations
+}
+
+// IsLimited returns true if the access key is limited to permissions or
+// destinations, so that it can not act as its identity for everything else.
+func (ak *AccessKey) IsLimited() bool {
+	return len(ak.PermissionScopes()) > 0 || len(ak.DestinationScopes()) > 0
+}
+
+// ToAPISession returns the session of a key that was created by a login.
+// current is true when the key was used to authenticate the request.
+func (ak *AccessKey) ToAPISession(current bool) *api.Session {
+	providerName := ""
+	if ak.Provider != nil {
+		providerName = ak.Provider.Name
+	}
+
+	return &api.Session{
+		ID:           ak.ID,
+		Created:      api.Time(ak.CreatedAt),
+		ProviderID:   ak.ProviderID,
+		ProviderName: providerName,
+		ClientName:   ak.ClientName,
+		LastUsed:     api.Time(ak.LastUsedAt),
+		Expires:      api.Time(ak.ExpiresAt),
+		Current:      current,
+	}
 }
 
 func (ak *AccessKey) ToAPI() *api.AccessKey {
@@ -45,6 +132,7 @@ func (ak *AccessKey) ToAPI() *api.AccessKey {
 		IssuedFor:         ak.IssuedFor,
 		IssuedForName:     issuedForName,
 		ProviderID:        ak.ProviderID,
+		Scopes:            ak.Scopes,
 		Expires:           api.Time(ak.ExpiresAt),
 		ExtensionDeadline: api.Time(ak.ExtensionDeadline),
 	}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"time"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+const (
+	AccessRequestPending  = "pending"
+	AccessRequestApproved = "approved"
+	AccessRequestDenied   = "denied"
+)
+
+// AccessRequest is a request by an identity to be granted a privilege on a
+// resource. When the request is approved a Grant is created for the identity
+// that made the request.
+type AccessRequest struct {
+	Model
+
+	RequestedBy uid.ID `validate:"required"`
+	Privilege   string `validate:"required"`
+	Resource    string `validate:"required"`
+	Reason      string
+	Duration    time.Duration // how long the grant is valid for, zero means no expiry
+
+	Status     string `validate:"required"`
+	ReviewedBy uid.ID // the identity that approved or denied the request
+	GrantID    uid.ID // the grant created when the request was approved
+}
+
+func (r *AccessRequest) ToAPI() *api.AccessRequest {
+	return &api.AccessRequest{
+		ID:          r.ID,
+		Created:     api.Time(r.CreatedAt),
+		Updated:     api.Time(r.UpdatedAt),
+		RequestedBy: r.RequestedBy,
+		Privilege:   r.Privilege,
+		Resource:    r.Resource,
+		Reason:      r.Reason,
+		Duration:    api.Duration(r.Duration),
+		Status:      r.Status,
+		ReviewedBy:  r.ReviewedBy,
+		GrantID:     r.GrantID,
+	}
+}
+
+// AccessApprover allows a user, or the members of a group, to approve access
+// requests for a resource and any of the resources within it.
+type AccessApprover struct {
+	Model
+
+	Subject  uid.PolymorphicID `validate:"required"` // a user or group
+	Resource string            `validate:"required"`
+}
+
+func (r *AccessApprover) ToAPI() *api.AccessApprover {
+	approver := &api.AccessApprover{
+		ID:       r.ID,
+		Created:  api.Time(r.CreatedAt),
+		Resource: r.Resource,
+	}
+
+	switch {
+	case r.Subject.IsIdentity():
+		id, err := r.Subject.ID()
+		if err != nil {
+			return nil
+		}
+
+		approver.User = id
+	case r.Subject.IsGroup():
+		id, err := r.Subject.ID()
+		if err != nil {
+			return nil
+		}
+
+		approver.Group = id
+	}
+
+	return approver
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+// AuditEvent is a record of a single mutating API request. It captures who made
+// the request, what resource it targeted, and the state of that resource before
+// and after the request.
+type AuditEvent struct {
+	Model
+
+	ActorID     uid.ID // the identity that made the request, if it was authenticated
+	ActorName   string
+	ActorKind   IdentityKind // user or service-account
+	AccessKeyID uid.ID       // the access key used to authenticate the request
+
+	Method string
+	Route  string // the route template, ex: /api/grants/:id
+	Path   string // the request path, ex: /api/grants/4yJ3n3D8E2
+
+	TargetKind string // the kind of resource targeted by the request, ex: grants
+	TargetID   uid.ID
+
+	// Before and After are JSON snapshots of the API representation of the
+	// target. They never contain secrets.
+	Before string
+	After  string
+
+	ResultCode int // the HTTP status code of the response
+}
+
+func (e *AuditEvent) ToAPI() *api.AuditEvent {
+	return &api.AuditEvent{
+		ID:          e.ID,
+		Created:     api.Time(e.CreatedAt),
+		Actor:       e.ActorID,
+		ActorName:   e.ActorName,
+		ActorKind:   e.ActorKind.String(),
+		AccessKeyID: e.AccessKeyID,
+		Method:      e.Method,
+		Route:       e.Route,
+		Path:        e.Path,
+		Resource:    e.TargetKind,
+		ResourceID:  e.TargetID,
+		Before:      e.Before,
+		After:       e.After,
+		ResultCode:  e.ResultCode,
+	}
+}
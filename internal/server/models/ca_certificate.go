@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// CACertificate is a root certificate of the certificate authority that signs
+// client certificates. The two newest certificates are kept, so that
+// certificates signed by the previous one can be verified until they expire.
+type CACertificate struct {
+	Model
+
+	KeyAlgorithm     string
+	SigningAlgorithm string
+	PublicKey        []byte
+	PrivateKey       EncryptedAtRestBytes
+	// SignedCertPEM is the PEM encoded certificate of PublicKey.
+	SignedCertPEM []byte
+	ExpiresAt     time.Time
+}
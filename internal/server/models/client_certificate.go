@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/infrahq/infra/uid"
+)
+
+// ClientCertificate is a client certificate that was signed for an identity.
+// A certificate is only accepted while its ClientCertificate exists, so
+// deleting it revokes the certificate before it expires.
+type ClientCertificate struct {
+	Model
+
+	IdentityID   uid.ID    `validate:"required"`
+	SerialNumber string    `gorm:"uniqueIndex:idx_client_certificates_serial_number,where:deleted_at is NULL" validate:"required"`
+	ExpiresAt    time.Time `validate:"required"`
+}
@@ -8,4 +8,44 @@ type Credential struct {
 	IdentityID      uid.ID `gorm:"<-;uniqueIndex:idx_credentials_identity_id,where:deleted_at is NULL"`
 	PasswordHash    []byte `validate:"required"`
 	OneTimePassword bool
+	// PasswordHistory are the hashes of the previous passwords, most recent first.
+	PasswordHistory CommaSeparatedStrings
+
+	// TOTPSecret is the secret of the time-based one-time passwords that are
+	// the second factor of password logins.
+	TOTPSecret EncryptedAtRest
+	// TOTPConfirmed is set once the user has entered a code of TOTPSecret.
+	// Password logins require a second factor only after it is set.
+	TOTPConfirmed bool
+	// TOTPLastStep is the time step of the last TOTP code used, so that a code
+	// can not be used again.
+	TOTPLastStep int64
+	// RecoveryCodes are the SHA-256 hashes of the codes that can each be used
+	// once instead of a TOTP code.
+	RecoveryCodes CommaSeparatedStrings
+}
+
+// PasswordHashes returns the hash of the current password followed by the
+// hashes of the previous passwords.
+func (c *Credential) PasswordHashes() [][]byte {
+	hashes := [][]byte{c.PasswordHash}
+	for _, hash := range c.PasswordHistory {
+		hashes = append(hashes, []byte(hash))
+	}
+	return hashes
+}
+
+// SetPasswordHash replaces the password hash, and keeps the hashes of previous
+// passwords so that PasswordHashes returns at most depth hashes.
+func (c *Credential) SetPasswordHash(hash []byte, depth int) {
+	var history CommaSeparatedStrings
+	if depth > 1 {
+		history = append(CommaSeparatedStrings{string(c.PasswordHash)}, c.PasswordHistory...)
+		if len(history) > depth-1 {
+			history = history[:depth-1]
+		}
+	}
+
+	c.PasswordHash = hash
+	c.PasswordHistory = history
 }
@@ -1,6 +1,8 @@
 package models
 
 import (
+	"time"
+
 	"github.com/infrahq/infra/api"
 	"github.com/infrahq/infra/uid"
 )
@@ -38,6 +40,7 @@ type Grant struct {
 	Privilege string            `validate:"required"` // role or permission
 	Resource  string            `validate:"required"` // Universal Resource Notation
 	CreatedBy uid.ID
+	ExpiresAt *time.Time // optional, the grant is not valid after this time
 }
 
 func (r *Grant) ToAPI() *api.Grant {
@@ -50,6 +53,10 @@ func (r *Grant) ToAPI() *api.Grant {
 		Resource:  r.Resource,
 	}
 
+	if r.ExpiresAt != nil {
+		grant.Expires = api.Time(*r.ExpiresAt)
+	}
+
 	switch {
 	case r.Subject.IsIdentity():
 		identity, err := r.Subject.ID()
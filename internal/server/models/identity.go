@@ -13,13 +13,32 @@ const (
 	InternalInfraConnectorIdentityName = "connector"
 )
 
+type IdentityKind string
+
+const (
+	UserKind IdentityKind = "user"
+	// ServiceAccountKind identities are used by automation. They can only
+	// authenticate with access keys.
+	ServiceAccountKind IdentityKind = "service-account"
+)
+
+func (k IdentityKind) String() string {
+	return string(k)
+}
+
 type Identity struct {
 	Model
 
-	Name       string    `gorm:"uniqueIndex:idx_identities_name,where:deleted_at is NULL"`
+	Name       string `gorm:"uniqueIndex:idx_identities_name,where:deleted_at is NULL"`
+	Kind       IdentityKind
 	LastSeenAt time.Time // updated on when an identity uses a session token
 	CreatedBy  uid.ID
 
+	// FailedLoginAttempts is the count of failed password logins since the
+	// last successful login, password login is not allowed until LockedUntil.
+	FailedLoginAttempts int
+	LockedUntil         time.Time
+
 	// for eager loading, don't use these for saving.
 	Groups    []Group    `gorm:"many2many:identities_groups"`
 	Providers []Provider `gorm:"many2many:provider_users;"`
@@ -27,17 +46,24 @@ type Identity struct {
 
 func (i *Identity) ToAPI() *api.User {
 	return &api.User{
-		ID:         i.ID,
-		Created:    api.Time(i.CreatedAt),
-		Updated:    api.Time(i.UpdatedAt),
-		LastSeenAt: api.Time(i.LastSeenAt),
-		Name:       i.Name,
+		ID:                  i.ID,
+		Created:             api.Time(i.CreatedAt),
+		Updated:             api.Time(i.UpdatedAt),
+		LastSeenAt:          api.Time(i.LastSeenAt),
+		Name:                i.Name,
+		Kind:                i.Kind.String(),
+		FailedLoginAttempts: i.FailedLoginAttempts,
+		LockedUntil:         api.Time(i.LockedUntil),
 		ProviderNames: slice.Map[Provider, string](i.Providers, func(p Provider) string {
 			return p.Name
 		}),
 	}
 }
 
+func (i *Identity) IsServiceAccount() bool {
+	return i.Kind == ServiceAccountKind
+}
+
 // PolyID is a polymorphic name that points to both a model type and an ID
 func (i *Identity) PolyID() uid.PolymorphicID {
 	return uid.NewIdentityPolymorphicID(i.ID)
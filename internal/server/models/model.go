@@ -12,8 +12,12 @@ import (
 // This exists for generics to be able to constrain _any_ down to our set of models.
 type Modelable interface {
 	IsAModel() // there's nothing specific about this function except that all Model structs will have it.
+	Primary() uid.ID
 }
 
+// CreatedBySystem is the creator of records that are managed by the server
+// config. These records are reconciled with the config every time it is loaded,
+// and are removed once they are no longer in the config.
 const CreatedBySystem = 1
 
 type Model struct {
@@ -30,6 +34,11 @@ type Model struct {
 
 func (Model) IsAModel() {}
 
+// Primary returns the primary key of the model.
+func (m Model) Primary() uid.ID {
+	return m.ID
+}
+
 // BeforeCreate sets an ID if one does not already exist. Unfortunately, we can use `gorm:"default"`
 // tags since the ID must be dynamically generated and not all databases support UUID generation.
 func (m *Model) BeforeCreate(_ *gorm.DB) error {
@@ -1,15 +1,28 @@
 package models
 
-import "github.com/infrahq/infra/api"
+import (
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
 
 // Internal Pagination Data
 type Pagination struct {
 	Page  int
 	Limit int
+	// Cursor is the ID of the last record of the previous page. When set the
+	// page starts after that record, and Page is ignored.
+	Cursor uid.ID
+	// Offset is the number of records before the page, for APIs that select
+	// a page by the index of its first record. When set Page is ignored.
+	Offset int
+
+	// TotalCount and NextCursor are set when the list is queried.
+	TotalCount int
+	NextCursor uid.ID
 }
 
 func RequestToPagination(pr api.PaginationRequest) Pagination {
-	if pr.Limit == 0 && pr.Page == 0 {
+	if pr.Limit == 0 && pr.Page == 0 && pr.Cursor == 0 {
 		return Pagination{} // temporary so pagination is disabled by default
 	}
 	page, limit := 1, 100
@@ -22,15 +35,22 @@ func RequestToPagination(pr api.PaginationRequest) Pagination {
 		page = pr.Page
 	}
 
+	if pr.Cursor != 0 {
+		page = 0
+	}
+
 	return Pagination{
-		Page:  page,
-		Limit: limit,
+		Page:   page,
+		Limit:  limit,
+		Cursor: uid.ID(pr.Cursor),
 	}
 }
 
 func PaginationToResponse(pr Pagination) api.PaginationResponse {
 	return api.PaginationResponse{
-		Page:  pr.Page,
-		Limit: pr.Limit,
+		Page:       pr.Page,
+		Limit:      pr.Limit,
+		TotalCount: pr.TotalCount,
+		NextCursor: api.Cursor(pr.NextCursor),
 	}
 }
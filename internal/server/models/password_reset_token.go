@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+
+	"github.com/infrahq/infra/uid"
+)
+
+// PasswordResetToken is a single use token, sent to a user in an invitation or
+// password reset email. It is exchanged at login for an access key that can
+// only be used to set a new password.
+type PasswordResetToken struct {
+	Model
+
+	IdentityID uid.ID `validate:"required"`
+	// TokenChecksum is the SHA-256 checksum of the token. The token itself is
+	// only sent to the user.
+	TokenChecksum []byte    `gorm:"uniqueIndex:idx_password_reset_tokens_token_checksum,where:deleted_at is NULL"`
+	ExpiresAt     time.Time `validate:"required"`
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+// Permissions of the infra API. The built-in roles are each a set of these
+// permissions, and a Role is a custom set of them.
+const (
+	PermissionUsersRead            = "users:read"
+	PermissionUsersWrite           = "users:write"
+	PermissionGroupsRead           = "groups:read"
+	PermissionGroupsWrite          = "groups:write"
+	PermissionGrantsRead           = "grants:read"
+	PermissionGrantsWrite          = "grants:write"
+	PermissionProvidersWrite       = "providers:write"
+	PermissionDestinationsRegister = "destinations:register" // create and update, used by connectors
+	PermissionDestinationsWrite    = "destinations:write"
+	PermissionAccessKeysRead       = "access-keys:read"
+	PermissionAccessKeysWrite      = "access-keys:write"
+	PermissionAccessRequestsRead   = "access-requests:read"
+	PermissionAccessRequestsWrite  = "access-requests:write"
+	PermissionAccessApproversRead  = "access-approvers:read"
+	PermissionAccessApproversWrite = "access-approvers:write"
+	PermissionAuditEventsRead      = "audit-events:read"
+	PermissionWebhooksRead         = "webhooks:read"
+	PermissionWebhooksWrite        = "webhooks:write"
+	PermissionRolesRead            = "roles:read"
+	PermissionRolesWrite           = "roles:write"
+)
+
+// Permissions is every permission of the infra API.
+var Permissions = []string{
+	PermissionUsersRead,
+	PermissionUsersWrite,
+	PermissionGroupsRead,
+	PermissionGroupsWrite,
+	PermissionGrantsRead,
+	PermissionGrantsWrite,
+	PermissionProvidersWrite,
+	PermissionDestinationsRegister,
+	PermissionDestinationsWrite,
+	PermissionAccessKeysRead,
+	PermissionAccessKeysWrite,
+	PermissionAccessRequestsRead,
+	PermissionAccessRequestsWrite,
+	PermissionAccessApproversRead,
+	PermissionAccessApproversWrite,
+	PermissionAuditEventsRead,
+	PermissionWebhooksRead,
+	PermissionWebhooksWrite,
+	PermissionRolesRead,
+	PermissionRolesWrite,
+}
+
+// InfraRolePermissions are the permissions of the built-in infra roles.
+var InfraRolePermissions = map[string][]string{
+	InfraAdminRole: Permissions,
+	InfraViewRole: {
+		PermissionUsersRead,
+		PermissionGroupsRead,
+		PermissionGrantsRead,
+		PermissionAccessKeysRead,
+		PermissionAccessRequestsRead,
+		PermissionAccessApproversRead,
+		PermissionRolesRead,
+	},
+	InfraConnectorRole: {
+		PermissionUsersRead,
+		PermissionGroupsRead,
+		PermissionGrantsRead,
+		PermissionDestinationsRegister,
+	},
+}
+
+// IsPermission returns true if name is a permission of the infra API.
+func IsPermission(name string) bool {
+	for _, permission := range Permissions {
+		if permission == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Role is a custom infra role. It is granted the same way as the built-in
+// roles, with a grant on the infra resource that uses the name of the role as
+// the privilege.
+type Role struct {
+	Model
+
+	Name        string                `gorm:"uniqueIndex:idx_roles_name,where:deleted_at is NULL" validate:"required"`
+	Permissions CommaSeparatedStrings `validate:"required"`
+	CreatedBy   uid.ID
+}
+
+func (r *Role) ToAPI() *api.Role {
+	return &api.Role{
+		ID:          r.ID,
+		Created:     api.Time(r.CreatedAt),
+		Updated:     api.Time(r.UpdatedAt),
+		Name:        r.Name,
+		Permissions: r.Permissions,
+	}
+}
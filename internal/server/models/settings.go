@@ -3,6 +3,9 @@ package models
 type Settings struct {
 	Model
 
+	// PrivateJWK and PublicJWK are the signing key of servers created before
+	// signing keys could be rotated. The key is imported as the first
+	// SigningKey by data.InitializeSettings, which then clears these fields.
 	PrivateJWK EncryptedAtRestBytes
 	PublicJWK  []byte
 }
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// SigningKey is a key used to sign the tokens that users present to
+// destinations. The public key is published in the JWKS before ActiveAt, so
+// that destinations can fetch it before any token is signed with it.
+type SigningKey struct {
+	Model
+
+	KeyID      string `gorm:"uniqueIndex:idx_signing_keys_key_id,where:deleted_at is NULL"`
+	PrivateJWK EncryptedAtRestBytes
+	PublicJWK  []byte
+	// ActiveAt is the time the key starts to be used for signing. It is used
+	// until the ActiveAt of the next key.
+	ActiveAt time.Time
+}
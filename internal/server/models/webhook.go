@@ -0,0 +1,69 @@
+package models
+
+import (
+	"time"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/uid"
+)
+
+// Webhook is a subscription to events. Each event the webhook subscribes to is
+// sent to the URL, signed with the secret.
+type Webhook struct {
+	Model
+
+	URL    string                `validate:"required"`
+	Events CommaSeparatedStrings `validate:"required"`
+	Secret EncryptedAtRest       `validate:"required"`
+}
+
+func (w *Webhook) ToAPI() *api.Webhook {
+	return &api.Webhook{
+		ID:      w.ID,
+		Created: api.Time(w.CreatedAt),
+		Updated: api.Time(w.UpdatedAt),
+		URL:     w.URL,
+		Events:  w.Events,
+	}
+}
+
+// Subscribes returns true if the webhook should be sent the event.
+func (w *Webhook) Subscribes(event string) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WebhookDelivery records an attempt to deliver an event to a webhook. The
+// attempt is queued with Pending set, and is made after AttemptAt.
+type WebhookDelivery struct {
+	Model
+
+	WebhookID  uid.ID `validate:"required"`
+	EventID    uid.ID `validate:"required"` // the same for every attempt to deliver the event
+	Event      string `validate:"required"`
+	Attempt    int
+	StatusCode int
+	Error      string
+
+	Payload   []byte // the request body sent to the webhook
+	Pending   bool
+	AttemptAt time.Time
+}
+
+func (d *WebhookDelivery) ToAPI() *api.WebhookDelivery {
+	return &api.WebhookDelivery{
+		ID:         d.ID,
+		Created:    api.Time(d.CreatedAt),
+		WebhookID:  d.WebhookID,
+		EventID:    d.EventID,
+		Event:      d.Event,
+		Attempt:    d.Attempt,
+		StatusCode: d.StatusCode,
+		Error:      d.Error,
+	}
+}
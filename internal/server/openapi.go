@@ -22,15 +22,24 @@ import (
 var (
 	pathIDReplacer            = regexp.MustCompile(`:\w+`)
 	funcPartialNameToTagNames = map[string]string{
-		"Grant":       "Grants",
-		"User":        "Users",
-		"Group":       "Groups",
-		"AccessKey":   "Authentication",
-		"Provider":    "Providers",
-		"Destination": "Destinations",
-		"Token":       "Destinations",
-		"Login":       "Authentication",
-		"Logout":      "Authentication",
+		"Grant":          "Grants",
+		"User":           "Users",
+		"Group":          "Groups",
+		"AccessKey":      "Authentication",
+		"Provider":       "Providers",
+		"Destination":    "Destinations",
+		"Token":          "Destinations",
+		"Login":          "Authentication",
+		"Logout":         "Authentication",
+		"PasswordReset":  "Authentication",
+		"Certificate":    "Authentication",
+		"AuditEvent":     "Audit",
+		"AccessRequest":  "Access Requests",
+		"AccessApprover": "Access Requests",
+		"Webhook":        "Webhooks",
+		"Role":           "Roles",
+		"CheckAccess":    "Grants",
+		"ServiceAccount": "Users",
 	}
 )
 
@@ -309,6 +318,14 @@ func setTypeInfo(t reflect.Type, schema *openapi3.Schema) {
 		schema.Example = "4yJ3n3D8E2"
 		return
 
+	case "api.Cursor":
+		schema.Type = "string"
+		schema.Example = "NHlKM24zRDhFMg"
+		if len(schema.Description) == 0 {
+			schema.Description = "an opaque position in a list of results"
+		}
+		return
+
 	case "api.IDOrSelf":
 		schema.Type = "string"
 		schema.Format = "uid|self"
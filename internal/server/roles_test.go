@@ -0,0 +1,220 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAPI_Roles(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	alice := &models.Identity{Name: "alice@example.com"}
+	createIdentities(t, srv.db, alice)
+
+	aliceKey, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+		IssuedFor:  alice.ID,
+		ProviderID: data.InfraProvider(srv.db).ID,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	})
+	assert.NilError(t, err)
+
+	var role api.Role
+	t.Run("create role", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/roles", adminAccessKey(srv), api.CreateRoleRequest{
+			Name:        "user-manager",
+			Permissions: []string{models.PermissionUsersRead, models.PermissionUsersWrite},
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&role))
+		assert.Equal(t, role.Name, "user-manager")
+	})
+
+	t.Run("invalid roles", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/roles", adminAccessKey(srv), api.CreateRoleRequest{
+			Name:        "admin",
+			Permissions: []string{models.PermissionUsersRead},
+		})
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/roles", adminAccessKey(srv), api.CreateRoleRequest{
+			Name:        "unknown",
+			Permissions: []string{"users:destroy"},
+		})
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/roles", adminAccessKey(srv), api.CreateRoleRequest{
+			Name:        "user-manager",
+			Permissions: []string{models.PermissionUsersRead},
+		})
+		assert.Equal(t, resp.Code, http.StatusConflict, resp.Body.String())
+	})
+
+	t.Run("grant a custom role", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/users", aliceKey, api.CreateUserRequest{Name: "bob@example.com"})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/grants", adminAccessKey(srv), api.CreateGrantRequest{
+			User:      alice.ID,
+			Privilege: role.Name,
+			Resource:  "infra",
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/users", aliceKey, api.CreateUserRequest{Name: "bob@example.com"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/grants", aliceKey, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/roles", aliceKey, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("roles can not grant more access than the creator has", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPut, "/api/roles/"+role.ID.String(), adminAccessKey(srv), api.UpdateRoleRequest{
+			Permissions: []string{models.PermissionUsersRead, models.PermissionUsersWrite, models.PermissionGrantsWrite, models.PermissionRolesWrite},
+		})
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/grants", aliceKey, api.CreateGrantRequest{
+			User:      alice.ID,
+			Privilege: models.InfraAdminRole,
+			Resource:  "infra",
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPut, "/api/roles/"+role.ID.String(), aliceKey, api.UpdateRoleRequest{
+			Permissions: []string{models.PermissionUsersRead, models.PermissionUsersWrite, models.PermissionGrantsWrite, models.PermissionRolesWrite, models.PermissionProvidersWrite},
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/grants", aliceKey, api.CreateGrantRequest{
+			User:      alice.ID,
+			Privilege: models.PermissionUsersRead,
+			Resource:  "infra",
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+	})
+
+	t.Run("delete role", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodDelete, "/api/roles/"+role.ID.String(), adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		grants, err := data.ListGrants(srv.db, data.BySubject(alice.PolyID()), data.ByPrivilege(role.Name))
+		assert.NilError(t, err)
+		assert.Equal(t, len(grants), 0)
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/users", aliceKey, api.CreateUserRequest{Name: "carol@example.com"})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+}
+
+func TestAPI_DelegatedPermissions(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	alice := &models.Identity{Name: "alice@example.com"}
+	bob := &models.Identity{Name: "bob@example.com"}
+	carol := &models.Identity{Name: "carol@example.com"}
+	createIdentities(t, srv.db, alice, bob, carol)
+
+	admins := &models.Group{Name: "admins"}
+	everyone := &models.Group{Name: "everyone"}
+	createGroups(t, srv.db, admins, everyone)
+
+	grants := []*models.Grant{
+		{Subject: carol.PolyID(), Privilege: models.InfraAdminRole, Resource: "infra"},
+		{Subject: admins.PolyID(), Privilege: models.InfraAdminRole, Resource: "infra"},
+		{Subject: everyone.PolyID(), Privilege: models.PermissionUsersRead, Resource: "infra"},
+	}
+	for _, perm := range []string{
+		models.PermissionUsersRead,
+		models.PermissionUsersWrite,
+		models.PermissionAccessKeysWrite,
+		models.PermissionGroupsRead,
+		models.PermissionGroupsWrite,
+	} {
+		grants = append(grants, &models.Grant{Subject: alice.PolyID(), Privilege: perm, Resource: "infra"})
+	}
+	for _, grant := range grants {
+		assert.NilError(t, data.CreateGrant(srv.db, grant))
+	}
+
+	aliceKey, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+		IssuedFor:  alice.ID,
+		ProviderID: data.InfraProvider(srv.db).ID,
+		ExpiresAt:  time.Now().Add(time.Hour),
+	})
+	assert.NilError(t, err)
+
+	t.Run("update the password of a user", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPut, "/api/users/"+carol.ID.String(), aliceKey, api.UpdateUserRequest{Password: "password123"})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPut, "/api/users/"+bob.ID.String(), aliceKey, api.UpdateUserRequest{Password: "password123"})
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+	})
+
+	t.Run("create an access key for a user", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/access-keys", aliceKey, api.CreateAccessKeyRequest{
+			UserID:            carol.ID,
+			TTL:               api.Duration(time.Hour),
+			ExtensionDeadline: api.Duration(time.Hour),
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/access-keys", aliceKey, api.CreateAccessKeyRequest{
+			UserID:            bob.ID,
+			TTL:               api.Duration(time.Hour),
+			ExtensionDeadline: api.Duration(time.Hour),
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+	})
+
+	t.Run("add users to a group", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPut, "/api/groups/"+admins.ID.String()+"/users", aliceKey, api.UpdateUsersInGroupRequest{
+			UserIDs: []uid.ID{alice.ID},
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPut, "/api/groups/"+everyone.ID.String()+"/users", aliceKey, api.UpdateUsersInGroupRequest{
+			UserIDs: []uid.ID{alice.ID, bob.ID},
+		})
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+	})
+
+	t.Run("grant with a scoped access key", func(t *testing.T) {
+		grantsKey, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+			IssuedFor:  carol.ID,
+			ProviderID: data.InfraProvider(srv.db).ID,
+			ExpiresAt:  time.Now().Add(time.Hour),
+			Scopes:     models.CommaSeparatedStrings{models.PermissionGrantsWrite},
+		})
+		assert.NilError(t, err)
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/grants", grantsKey, api.CreateGrantRequest{
+			User:      bob.ID,
+			Privilege: models.InfraAdminRole,
+			Resource:  "infra",
+		})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/grants", grantsKey, api.CreateGrantRequest{
+			User:      bob.ID,
+			Privilege: models.PermissionGrantsWrite,
+			Resource:  "infra",
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+	})
+}
@@ -41,6 +41,11 @@ func (s *Server) GenerateRoutes(promRegistry prometheus.Registerer) Routes {
 	router := gin.New()
 	router.NoRoute(a.notFoundHandler)
 
+	// the options are validated by New, an invalid proxy trusts none
+	if err := router.SetTrustedProxies(s.options.TrustedProxies); err != nil {
+		logging.S.Errorf("trusted proxies: %s", err)
+	}
+
 	router.Use(gin.Recovery())
 	router.GET("/healthz", healthHandler)
 
@@ -53,13 +58,22 @@ func (s *Server) GenerateRoutes(promRegistry prometheus.Registerer) Routes {
 	a.addRewrites()
 	a.addRedirects()
 
+	metricsMiddleware := metrics.Middleware(promRegistry)
+
 	// This group of middleware only applies to non-ui routes
 	apiGroup := router.Group("/",
-		metrics.Middleware(promRegistry),
+		metricsMiddleware,
 		DatabaseMiddleware(a.server.db), // must be after TimeoutMiddleware to time out db queries.
 	)
 	apiGroup.GET("/.well-known/jwks.json", a.wellKnownJWKsHandler)
 
+	streamGroup := router.Group("/",
+		metricsMiddleware,
+		StreamDatabaseMiddleware(a.server.db),
+		AuthenticationMiddleware(a),
+	)
+	streamGroup.GET("/api/grants/watch", a.WatchGrants)
+
 	authn := apiGroup.Group("/", AuthenticationMiddleware(a))
 
 	get(a, authn, "/api/users", a.ListUsers)
@@ -67,22 +81,49 @@ func (s *Server) GenerateRoutes(promRegistry prometheus.Registerer) Routes {
 	get(a, authn, "/api/users/:id", a.GetUser)
 	put(a, authn, "/api/users/:id", a.UpdateUser)
 	delete(a, authn, "/api/users/:id", a.DeleteUser)
+	post(a, authn, "/api/users/:id/mfa", a.EnrollUserMFA)
+	put(a, authn, "/api/users/:id/mfa", a.ConfirmUserMFA)
+	delete(a, authn, "/api/users/:id/mfa", a.DisableUserMFA)
+	get(a, authn, "/api/users/:id/sessions", a.ListUserSessions)
+	delete(a, authn, "/api/users/:id/sessions", a.RevokeUserSessions)
+	delete(a, authn, "/api/users/:id/sessions/:sessionID", a.RevokeUserSession)
+
+	get(a, authn, "/api/service-accounts", a.ListServiceAccounts)
+	post(a, authn, "/api/service-accounts", a.CreateServiceAccount)
+	delete(a, authn, "/api/service-accounts/:id", a.DeleteServiceAccount)
 
 	get(a, authn, "/api/access-keys", a.ListAccessKeys)
 	post(a, authn, "/api/access-keys", a.CreateAccessKey)
 	delete(a, authn, "/api/access-keys/:id", a.DeleteAccessKey)
+	post(a, authn, "/api/access-keys/:id/rotate", a.RotateAccessKey)
 
 	get(a, authn, "/api/groups", a.ListGroups)
 	post(a, authn, "/api/groups", a.CreateGroup)
 	get(a, authn, "/api/groups/:id", a.GetGroup)
 	delete(a, authn, "/api/groups/:id", a.DeleteGroup)
+	put(a, authn, "/api/groups/:id/users", a.AddUsersToGroup)
+	delete(a, authn, "/api/groups/:id/users", a.RemoveUsersFromGroup)
 
 	get(a, authn, "/api/grants", a.ListGrants)
+	get(a, authn, "/api/grants/effective", a.ListEffectiveGrants)
 	get(a, authn, "/api/grants/:id", a.GetGrant)
 	post(a, authn, "/api/grants", a.CreateGrant)
 	delete(a, authn, "/api/grants/:id", a.DeleteGrant)
 
+	get(a, authn, "/api/authz/check", a.CheckAccess)
+
+	get(a, authn, "/api/access-requests", a.ListAccessRequests)
+	get(a, authn, "/api/access-requests/:id", a.GetAccessRequest)
+	post(a, authn, "/api/access-requests", a.CreateAccessRequest)
+	post(a, authn, "/api/access-requests/:id/approve", a.ApproveAccessRequest)
+	post(a, authn, "/api/access-requests/:id/deny", a.DenyAccessRequest)
+
+	get(a, authn, "/api/access-approvers", a.ListAccessApprovers)
+	post(a, authn, "/api/access-approvers", a.CreateAccessApprover)
+	delete(a, authn, "/api/access-approvers/:id", a.DeleteAccessApprover)
+
 	post(a, authn, "/api/providers", a.CreateProvider)
+	post(a, authn, "/api/providers/:id/scim-access-key", a.CreateSCIMAccessKey)
 	put(a, authn, "/api/providers/:id", a.UpdateProvider)
 	delete(a, authn, "/api/providers/:id", a.DeleteProvider)
 
@@ -92,17 +133,48 @@ func (s *Server) GenerateRoutes(promRegistry prometheus.Registerer) Routes {
 	put(a, authn, "/api/destinations/:id", a.UpdateDestination)
 	delete(a, authn, "/api/destinations/:id", a.DeleteDestination)
 
+	get(a, authn, "/api/audit-events", a.ListAuditEvents)
+
+	get(a, authn, "/api/webhooks", a.ListWebhooks)
+	get(a, authn, "/api/webhooks/:id", a.GetWebhook)
+	post(a, authn, "/api/webhooks", a.CreateWebhook)
+	delete(a, authn, "/api/webhooks/:id", a.DeleteWebhook)
+	get(a, authn, "/api/webhooks/:id/deliveries", a.ListWebhookDeliveries)
+
+	get(a, authn, "/api/roles", a.ListRoles)
+	get(a, authn, "/api/roles/:id", a.GetRole)
+	post(a, authn, "/api/roles", a.CreateRole)
+	put(a, authn, "/api/roles/:id", a.UpdateRole)
+	delete(a, authn, "/api/roles/:id", a.DeleteRole)
+
 	post(a, authn, "/api/tokens", a.CreateToken)
 	post(a, authn, "/api/logout", a.Logout)
+	post(a, authn, "/api/certificates", a.SignCertificate)
 
 	authn.GET("/api/debug/pprof/*profile", pprofHandler)
 
+	scim := apiGroup.Group("/scim/v2", scimAuthentication)
+	scimRoute(scim, http.MethodGet, "/ServiceProviderConfig", a.GetSCIMServiceProviderConfig)
+	scimRoute(scim, http.MethodGet, "/Users", a.ListSCIMUsers)
+	scimRoute(scim, http.MethodPost, "/Users", a.CreateSCIMUser)
+	scimRoute(scim, http.MethodGet, "/Users/:id", a.GetSCIMUser)
+	scimRoute(scim, http.MethodPut, "/Users/:id", a.UpdateSCIMUser)
+	scimRoute(scim, http.MethodPatch, "/Users/:id", a.PatchSCIMUser)
+	scimRoute(scim, http.MethodDelete, "/Users/:id", a.DeleteSCIMUser)
+	scimRoute(scim, http.MethodGet, "/Groups", a.ListSCIMGroups)
+	scimRoute(scim, http.MethodPost, "/Groups", a.CreateSCIMGroup)
+	scimRoute(scim, http.MethodGet, "/Groups/:id", a.GetSCIMGroup)
+	scimRoute(scim, http.MethodPut, "/Groups/:id", a.UpdateSCIMGroup)
+	scimRoute(scim, http.MethodPatch, "/Groups/:id", a.PatchSCIMGroup)
+	scimRoute(scim, http.MethodDelete, "/Groups/:id", a.DeleteSCIMGroup)
+
 	// these endpoints do not require authentication
 	noAuthn := apiGroup.Group("/")
 	get(a, noAuthn, "/api/signup", a.SignupEnabled)
 	post(a, noAuthn, "/api/signup", a.Signup)
 
 	post(a, noAuthn, "/api/login", a.Login)
+	post(a, noAuthn, "/api/password-reset", a.RequestPasswordReset)
 
 	get(a, noAuthn, "/api/providers", a.ListProviders)
 	get(a, noAuthn, "/api/providers/:id", a.GetProvider)
@@ -166,6 +238,12 @@ func add[Req, Res any](a *API, r *gin.RouterGroup, route route[Req, Res]) {
 	}
 
 	wrappedHandler := func(c *gin.Context) {
+		var audit *auditRecord
+		if route.method != http.MethodGet {
+			audit = startAudit(c, route.method, route.path)
+			defer audit.finish(c)
+		}
+
 		req := new(Req)
 		if err := bind(c, req); err != nil {
 			sendAPIError(c, err)
@@ -180,6 +258,10 @@ func add[Req, Res any](a *API, r *gin.RouterGroup, route route[Req, Res]) {
 			return
 		}
 
+		if audit != nil {
+			audit.setTarget(resp)
+		}
+
 		if !route.omitFromTelemetry {
 			a.t.RouteEvent(c, route.path, Properties{"method": strings.ToLower(route.method)})
 		}
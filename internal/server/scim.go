@@ -0,0 +1,718 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// SCIM 2.0 provisioning, see https://datatracker.ietf.org/doc/html/rfc7644
+//
+// An identity provider provisions the users and groups of one provider, using
+// an access key created for that provider. SCIM requests and errors use their
+// own format, so these routes do not use the API route helpers.
+
+const scimContentType = "application/scim+json"
+
+var (
+	errSCIMInvalidFilter = fmt.Errorf("%w: invalid filter", internal.ErrBadRequest)
+	errSCIMInvalidPath   = fmt.Errorf("%w: invalid path", internal.ErrBadRequest)
+)
+
+type scimHandlerFunc func(c *gin.Context) (status int, resp interface{}, err error)
+
+// scimRoute adds a SCIM route to r. Changes made by the route are written to
+// the audit log.
+func scimRoute(r *gin.RouterGroup, method, routePath string, handler scimHandlerFunc) {
+	fullPath := path.Join(r.BasePath(), routePath)
+
+	r.Handle(method, routePath, func(c *gin.Context) {
+		var audit *auditRecord
+		if method != http.MethodGet {
+			audit = startAudit(c, method, fullPath)
+			defer audit.finish(c)
+		}
+
+		status, resp, err := handler(c)
+		if err != nil {
+			sendSCIMError(c, err)
+			return
+		}
+
+		if audit != nil {
+			audit.setTarget(resp)
+		}
+
+		if resp == nil {
+			c.Status(status)
+			c.Writer.WriteHeaderNow()
+			return
+		}
+
+		c.Header("Content-Type", scimContentType)
+		c.JSON(status, resp)
+	})
+}
+
+// scimAuthentication validates the access key used for a SCIM request.
+func scimAuthentication(c *gin.Context) {
+	if err := RequireAccessKey(c); err != nil {
+		sendSCIMError(c, err)
+		return
+	}
+
+	c.Next()
+}
+
+func sendSCIMError(c *gin.Context, err error) {
+	apiErr := newAPIError(err)
+
+	resp := api.SCIMError{
+		Schemas: []string{api.SCIMSchemaError},
+		Status:  strconv.Itoa(int(apiErr.Code)),
+		Detail:  apiErr.Message,
+	}
+
+	var uniqueConstraintError data.UniqueConstraintError
+	switch {
+	case errors.As(err, &uniqueConstraintError):
+		resp.SCIMType = "uniqueness"
+	case errors.Is(err, errSCIMInvalidFilter):
+		resp.SCIMType = "invalidFilter"
+	case errors.Is(err, errSCIMInvalidPath):
+		resp.SCIMType = "invalidPath"
+	case apiErr.Code == http.StatusBadRequest:
+		resp.SCIMType = "invalidValue"
+	}
+
+	c.Header("Content-Type", scimContentType)
+	c.JSON(int(apiErr.Code), resp)
+	c.Abort()
+}
+
+func bindSCIM(c *gin.Context, req interface{}) error {
+	if err := c.ShouldBindJSON(req); err != nil {
+		return fmt.Errorf("%w: %s", internal.ErrBadRequest, err)
+	}
+
+	return validate.Struct(req)
+}
+
+func scimID(c *gin.Context) (uid.ID, error) {
+	id, err := uid.Parse([]byte(c.Param("id")))
+	if err != nil {
+		return 0, internal.ErrNotFound
+	}
+
+	return id, nil
+}
+
+var scimFilterPattern = regexp.MustCompile(`^\s*(\w+)\s+(?i:eq)\s+("(?:[^"\\]|\\.)*")\s*$`)
+
+// parseSCIMFilter returns the value of a filter that compares attribute to a
+// value, for example `userName eq "alice@example.com"`. It is the only kind of
+// filter supported.
+func parseSCIMFilter(filter, attribute string) (string, error) {
+	if filter == "" {
+		return "", nil
+	}
+
+	match := scimFilterPattern.FindStringSubmatch(filter)
+	if match == nil || !strings.EqualFold(match[1], attribute) {
+		return "", fmt.Errorf("%w: only filters of the form '%s eq \"value\"' are supported", errSCIMInvalidFilter, attribute)
+	}
+
+	value, err := strconv.Unquote(match[2])
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", errSCIMInvalidFilter, err)
+	}
+
+	return value, nil
+}
+
+// maxSCIMCount is the most resources returned in a page of a SCIM list.
+const maxSCIMCount = 1000
+
+// scimPage is the page of a SCIM list selected by the startIndex and count
+// query parameters.
+type scimPage struct {
+	startIndex int
+	count      int
+	pagination models.Pagination
+}
+
+func parseSCIMPage(c *gin.Context) (*scimPage, error) {
+	page := &scimPage{startIndex: 1, count: maxSCIMCount}
+
+	if raw := c.Query("startIndex"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid startIndex: %s", internal.ErrBadRequest, err)
+		}
+		if value > 1 {
+			page.startIndex = value
+		}
+	}
+
+	if raw := c.Query("count"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid count: %s", internal.ErrBadRequest, err)
+		}
+		switch {
+		case value < 0:
+			page.count = 0
+		case value < maxSCIMCount:
+			page.count = value
+		}
+	}
+
+	page.pagination = models.Pagination{Offset: page.startIndex - 1, Limit: page.count}
+	if page.count == 0 {
+		// only the total is requested, which is counted for a page of any size
+		page.pagination.Limit = 1
+	}
+
+	return page, nil
+}
+
+// newSCIMListResponse returns the SCIM list response for the items that were
+// listed with the pagination of page.
+func newSCIMListResponse[T any](page *scimPage, items []T) *api.SCIMListResponse[T] {
+	if len(items) > page.count {
+		items = items[:page.count]
+	}
+
+	return &api.SCIMListResponse[T]{
+		Schemas:      []string{api.SCIMSchemaListResponse},
+		TotalResults: page.pagination.TotalCount,
+		StartIndex:   page.startIndex,
+		ItemsPerPage: len(items),
+		Resources:    items,
+	}
+}
+
+func scimMeta(resourceType string, model models.Model) *api.SCIMMeta {
+	return &api.SCIMMeta{
+		ResourceType: resourceType,
+		Created:      api.Time(model.CreatedAt),
+		LastModified: api.Time(model.UpdatedAt),
+		Location:     fmt.Sprintf("/scim/v2/%ss/%s", resourceType, model.ID),
+	}
+}
+
+func scimUser(identity *models.Identity, active bool) api.SCIMUser {
+	user := api.SCIMUser{
+		Schemas:  []string{api.SCIMSchemaUser},
+		ID:       identity.ID.String(),
+		UserName: identity.Name,
+		Active:   &active,
+		Emails:   []api.SCIMEmail{{Value: identity.Name, Primary: true}},
+		Meta:     scimMeta("User", identity.Model),
+	}
+
+	for _, group := range identity.Groups {
+		user.Groups = append(user.Groups, api.SCIMReference{Value: group.ID.String(), Display: group.Name})
+	}
+
+	return user
+}
+
+func scimGroup(group *models.Group) api.SCIMGroup {
+	result := api.SCIMGroup{
+		Schemas:     []string{api.SCIMSchemaGroup},
+		ID:          group.ID.String(),
+		DisplayName: group.Name,
+		Members:     []api.SCIMReference{},
+		Meta:        scimMeta("Group", group.Model),
+	}
+
+	for _, identity := range group.Identities {
+		result.Members = append(result.Members, api.SCIMReference{Value: identity.ID.String(), Display: identity.Name})
+	}
+
+	return result
+}
+
+func (a *API) GetSCIMServiceProviderConfig(c *gin.Context) (int, interface{}, error) {
+	return http.StatusOK, api.SCIMServiceProviderConfig{
+		Schemas: []string{api.SCIMSchemaServiceProviderConfig},
+		Patch:   api.SCIMSupported{Supported: true},
+		Filter:  api.SCIMFilterSupported{Supported: true, MaxResults: 1},
+		AuthenticationSchemes: []api.SCIMAuthentication{{
+			Type:        "oauthbearertoken",
+			Name:        "Infra access key",
+			Description: "An access key created for the provider with POST /api/providers/{id}/scim-access-key",
+		}},
+	}, nil
+}
+
+func (a *API) ListSCIMUsers(c *gin.Context) (int, interface{}, error) {
+	name, err := parseSCIMFilter(c.Query("filter"), "userName")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	page, err := parseSCIMPage(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	identities, err := access.ListSCIMUsers(c, name, &page.pagination)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	users := make([]api.SCIMUser, 0, len(identities))
+	for i := range identities {
+		users = append(users, scimUser(&identities[i], true))
+	}
+
+	return http.StatusOK, newSCIMListResponse(page, users), nil
+}
+
+func (a *API) GetSCIMUser(c *gin.Context) (int, interface{}, error) {
+	id, err := scimID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	identity, err := access.GetSCIMUser(c, id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, scimUser(identity, true), nil
+}
+
+func (a *API) CreateSCIMUser(c *gin.Context) (int, interface{}, error) {
+	var req api.SCIMUser
+	if err := bindSCIM(c, &req); err != nil {
+		return 0, nil, err
+	}
+
+	identity := &models.Identity{Name: req.UserName}
+	if err := access.CreateSCIMUser(c, identity); err != nil {
+		return 0, nil, err
+	}
+
+	identity, err := access.GetSCIMUser(c, identity.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusCreated, scimUser(identity, true), nil
+}
+
+func (a *API) UpdateSCIMUser(c *gin.Context) (int, interface{}, error) {
+	id, err := scimID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req api.SCIMUser
+	if err := bindSCIM(c, &req); err != nil {
+		return 0, nil, err
+	}
+
+	active := req.Active == nil || *req.Active
+	return a.updateSCIMUser(c, id, req.UserName, active)
+}
+
+func (a *API) PatchSCIMUser(c *gin.Context) (int, interface{}, error) {
+	id, err := scimID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req api.SCIMPatchRequest
+	if err := bindSCIM(c, &req); err != nil {
+		return 0, nil, err
+	}
+
+	var name string
+	active := true
+
+	// apply sets the attributes of the user that are stored, other attributes
+	// are ignored
+	apply := func(attribute string, value json.RawMessage) error {
+		switch strings.ToLower(attribute) {
+		case "active":
+			parsed, err := parseSCIMBool(value)
+			if err != nil {
+				return err
+			}
+			active = parsed
+		case "username":
+			if err := json.Unmarshal(value, &name); err != nil {
+				return fmt.Errorf("%w: userName: %s", internal.ErrBadRequest, err)
+			}
+		}
+		return nil
+	}
+
+	for _, op := range req.Operations {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+		case "remove":
+			// none of the stored attributes can be removed
+			continue
+		default:
+			return 0, nil, fmt.Errorf("%w: unsupported operation %q", internal.ErrBadRequest, op.Op)
+		}
+
+		if op.Path != "" {
+			if err := apply(op.Path, op.Value); err != nil {
+				return 0, nil, err
+			}
+			continue
+		}
+
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attributes); err != nil {
+			return 0, nil, fmt.Errorf("%w: value: %s", internal.ErrBadRequest, err)
+		}
+
+		for attribute, value := range attributes {
+			if err := apply(attribute, value); err != nil {
+				return 0, nil, err
+			}
+		}
+	}
+
+	return a.updateSCIMUser(c, id, name, active)
+}
+
+// updateSCIMUser renames the user when name is set, and deprovisions the user
+// when they are no longer active.
+func (a *API) updateSCIMUser(c *gin.Context, id uid.ID, name string, active bool) (int, interface{}, error) {
+	identity, err := access.GetSCIMUser(c, id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if name != "" && name != identity.Name {
+		identity.Name = name
+		if err := access.UpdateSCIMUser(c, identity); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	if !active {
+		if err := access.DeleteSCIMUser(c, id); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	return http.StatusOK, scimUser(identity, active), nil
+}
+
+func (a *API) DeleteSCIMUser(c *gin.Context) (int, interface{}, error) {
+	id, err := scimID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, access.DeleteSCIMUser(c, id)
+}
+
+func (a *API) ListSCIMGroups(c *gin.Context) (int, interface{}, error) {
+	name, err := parseSCIMFilter(c.Query("filter"), "displayName")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	page, err := parseSCIMPage(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	groups, err := access.ListSCIMGroups(c, name, &page.pagination)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	result := make([]api.SCIMGroup, 0, len(groups))
+	for i := range groups {
+		result = append(result, scimGroup(&groups[i]))
+	}
+
+	return http.StatusOK, newSCIMListResponse(page, result), nil
+}
+
+func (a *API) GetSCIMGroup(c *gin.Context) (int, interface{}, error) {
+	id, err := scimID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	group, err := access.GetSCIMGroup(c, id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, scimGroup(group), nil
+}
+
+func (a *API) CreateSCIMGroup(c *gin.Context) (int, interface{}, error) {
+	var req api.SCIMGroup
+	if err := bindSCIM(c, &req); err != nil {
+		return 0, nil, err
+	}
+
+	members, err := parseSCIMMembers(req.Members)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	group := &models.Group{Name: req.DisplayName}
+	if err := access.CreateSCIMGroup(c, group, members); err != nil {
+		return 0, nil, err
+	}
+
+	group, err = access.GetSCIMGroup(c, group.ID)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusCreated, scimGroup(group), nil
+}
+
+func (a *API) UpdateSCIMGroup(c *gin.Context) (int, interface{}, error) {
+	id, err := scimID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req api.SCIMGroup
+	if err := bindSCIM(c, &req); err != nil {
+		return 0, nil, err
+	}
+
+	members, err := parseSCIMMembers(req.Members)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return a.updateSCIMGroup(c, id, func(group *models.Group, current map[uid.ID]bool) error {
+		group.Name = req.DisplayName
+
+		for member := range current {
+			current[member] = false
+		}
+		for _, member := range members {
+			current[member] = true
+		}
+		return nil
+	})
+}
+
+func (a *API) PatchSCIMGroup(c *gin.Context) (int, interface{}, error) {
+	id, err := scimID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var req api.SCIMPatchRequest
+	if err := bindSCIM(c, &req); err != nil {
+		return 0, nil, err
+	}
+
+	return a.updateSCIMGroup(c, id, func(group *models.Group, members map[uid.ID]bool) error {
+		for _, op := range req.Operations {
+			if err := patchSCIMGroup(group, members, op); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// updateSCIMGroup calls update with the group and the set of its members, and
+// saves the changes update makes to them. A member is removed from the set by
+// setting it to false.
+func (a *API) updateSCIMGroup(c *gin.Context, id uid.ID, update func(group *models.Group, members map[uid.ID]bool) error) (int, interface{}, error) {
+	group, err := access.GetSCIMGroup(c, id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	current := make(map[uid.ID]bool, len(group.Identities))
+	members := make(map[uid.ID]bool, len(group.Identities))
+	for _, identity := range group.Identities {
+		current[identity.ID] = true
+		members[identity.ID] = true
+	}
+
+	if err := update(group, members); err != nil {
+		return 0, nil, err
+	}
+
+	var add, remove []uid.ID
+	for id, member := range members {
+		if member && !current[id] {
+			add = append(add, id)
+		}
+	}
+	for id := range current {
+		if !members[id] {
+			remove = append(remove, id)
+		}
+	}
+
+	if err := access.UpdateSCIMGroup(c, group, add, remove); err != nil {
+		return 0, nil, err
+	}
+
+	group, err = access.GetSCIMGroup(c, id)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusOK, scimGroup(group), nil
+}
+
+func (a *API) DeleteSCIMGroup(c *gin.Context) (int, interface{}, error) {
+	id, err := scimID(c)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return http.StatusNoContent, nil, access.DeleteSCIMGroup(c, id)
+}
+
+var scimMemberPathPattern = regexp.MustCompile(`^(?i:members)\[\s*(?i:value)\s+(?i:eq)\s+("(?:[^"\\]|\\.)*")\s*\]$`)
+
+// patchSCIMGroup applies a patch operation to the name and members of a group.
+func patchSCIMGroup(group *models.Group, members map[uid.ID]bool, op api.SCIMPatchOperation) error {
+	operation := strings.ToLower(op.Op)
+
+	switch {
+	case op.Path == "" && (operation == "add" || operation == "replace"):
+		var attributes map[string]json.RawMessage
+		if err := json.Unmarshal(op.Value, &attributes); err != nil {
+			return fmt.Errorf("%w: value: %s", internal.ErrBadRequest, err)
+		}
+
+		for attribute, value := range attributes {
+			if err := patchSCIMGroup(group, members, api.SCIMPatchOperation{Op: op.Op, Path: attribute, Value: value}); err != nil {
+				return err
+			}
+		}
+
+	case strings.EqualFold(op.Path, "displayName"):
+		if operation != "add" && operation != "replace" {
+			return fmt.Errorf("%w: displayName can not be removed", errSCIMInvalidPath)
+		}
+
+		if err := json.Unmarshal(op.Value, &group.Name); err != nil {
+			return fmt.Errorf("%w: displayName: %s", internal.ErrBadRequest, err)
+		}
+
+	case strings.EqualFold(op.Path, "members"):
+		var refs []api.SCIMReference
+		if len(op.Value) > 0 {
+			if err := json.Unmarshal(op.Value, &refs); err != nil {
+				return fmt.Errorf("%w: members: %s", internal.ErrBadRequest, err)
+			}
+		}
+
+		ids, err := parseSCIMMembers(refs)
+		if err != nil {
+			return err
+		}
+
+		switch operation {
+		case "replace":
+			for id := range members {
+				members[id] = false
+			}
+			fallthrough
+		case "add":
+			for _, id := range ids {
+				members[id] = true
+			}
+		case "remove":
+			if len(refs) == 0 {
+				for id := range members {
+					members[id] = false
+				}
+			}
+			for _, id := range ids {
+				members[id] = false
+			}
+		default:
+			return fmt.Errorf("%w: unsupported operation %q", internal.ErrBadRequest, op.Op)
+		}
+
+	case scimMemberPathPattern.MatchString(op.Path):
+		if operation != "remove" {
+			return fmt.Errorf("%w: %s only supports remove", errSCIMInvalidPath, op.Path)
+		}
+
+		value, err := strconv.Unquote(scimMemberPathPattern.FindStringSubmatch(op.Path)[1])
+		if err != nil {
+			return fmt.Errorf("%w: %s", errSCIMInvalidPath, err)
+		}
+
+		ids, err := parseSCIMMembers([]api.SCIMReference{{Value: value}})
+		if err != nil {
+			return err
+		}
+
+		members[ids[0]] = false
+
+	case op.Path == "":
+		return fmt.Errorf("%w: unsupported operation %q", internal.ErrBadRequest, op.Op)
+
+	default:
+		// other attributes are not stored
+	}
+
+	return nil
+}
+
+func parseSCIMMembers(refs []api.SCIMReference) ([]uid.ID, error) {
+	ids := make([]uid.ID, 0, len(refs))
+	for _, ref := range refs {
+		id, err := uid.Parse([]byte(ref.Value))
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid member %q", internal.ErrBadRequest, ref.Value)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+// parseSCIMBool parses a boolean value. Some identity providers send booleans
+// as strings.
+func parseSCIMBool(value json.RawMessage) (bool, error) {
+	var result bool
+	if err := json.Unmarshal(value, &result); err == nil {
+		return result, nil
+	}
+
+	var raw string
+	if err := json.Unmarshal(value, &raw); err == nil {
+		if result, err := strconv.ParseBool(raw); err == nil {
+			return result, nil
+		}
+	}
+
+	return false, fmt.Errorf("%w: invalid boolean %s", internal.ErrBadRequest, value)
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAPI_SCIM(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	provider := &models.Provider{Name: "okta", Kind: models.OktaKind}
+	assert.NilError(t, data.CreateProvider(srv.db, provider))
+
+	decode := func(t *testing.T, resp *httptest.ResponseRecorder, target interface{}) {
+		t.Helper()
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(target))
+	}
+
+	var key string
+	t.Run("create access key", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/providers/"+provider.ID.String()+"/scim-access-key", adminAccessKey(srv),
+			api.CreateSCIMAccessKeyRequest{Name: "okta-scim", TTL: api.Duration(time.Hour)})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var created api.CreateAccessKeyResponse
+		decode(t, resp, &created)
+		assert.Equal(t, created.ProviderID, provider.ID)
+		key = created.AccessKey
+	})
+
+	t.Run("access key for infra provider", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/providers/"+data.InfraProvider(srv.db).ID.String()+"/scim-access-key", adminAccessKey(srv),
+			api.CreateSCIMAccessKeyRequest{TTL: api.Duration(time.Hour)})
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("requires a SCIM access key", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodGet, "/scim/v2/Users", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+		var scimErr api.SCIMError
+		decode(t, resp, &scimErr)
+		assert.Equal(t, scimErr.Status, "401")
+	})
+
+	t.Run("SCIM access key is rejected by the API", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodGet, "/api/users", key, nil)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	var alice, bob api.SCIMUser
+	t.Run("create users", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/scim/v2/Users", key,
+			api.SCIMUser{Schemas: []string{api.SCIMSchemaUser}, UserName: "alice@example.com"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		assert.Equal(t, resp.Header().Get("Content-Type"), scimContentType)
+		decode(t, resp, &alice)
+		assert.Equal(t, alice.UserName, "alice@example.com")
+		assert.Assert(t, alice.ID != "")
+
+		resp = callAPI(t, routes, http.MethodPost, "/scim/v2/Users", key,
+			api.SCIMUser{Schemas: []string{api.SCIMSchemaUser}, UserName: "bob@example.com"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		decode(t, resp, &bob)
+
+		resp = callAPI(t, routes, http.MethodPost, "/scim/v2/Users", key,
+			api.SCIMUser{Schemas: []string{api.SCIMSchemaUser}, UserName: "alice@example.com"})
+		assert.Equal(t, resp.Code, http.StatusConflict, resp.Body.String())
+
+		var scimErr api.SCIMError
+		decode(t, resp, &scimErr)
+		assert.Equal(t, scimErr.SCIMType, "uniqueness")
+	})
+
+	t.Run("users of other providers are not listed", func(t *testing.T) {
+		createIdentities(t, srv.db, &models.Identity{Name: "carol@example.com"})
+
+		resp := callAPI(t, routes, http.MethodGet, "/scim/v2/Users", key, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var list api.SCIMListResponse[api.SCIMUser]
+		decode(t, resp, &list)
+		assert.Equal(t, list.TotalResults, 2)
+	})
+
+	t.Run("filter users", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodGet, `/scim/v2/Users?filter=userName+eq+"bob@example.com"`, key, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var list api.SCIMListResponse[api.SCIMUser]
+		decode(t, resp, &list)
+		assert.Equal(t, list.TotalResults, 1)
+		assert.Equal(t, list.Resources[0].ID, bob.ID)
+
+		resp = callAPI(t, routes, http.MethodGet, `/scim/v2/Users?filter=displayName+co+"bob"`, key, nil)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		var scimErr api.SCIMError
+		decode(t, resp, &scimErr)
+		assert.Equal(t, scimErr.SCIMType, "invalidFilter")
+	})
+
+	t.Run("page users", func(t *testing.T) {
+		list := func(t *testing.T, query string) api.SCIMListResponse[api.SCIMUser] {
+			t.Helper()
+			resp := callAPI(t, routes, http.MethodGet, "/scim/v2/Users?"+query, key, nil)
+			assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+			var list api.SCIMListResponse[api.SCIMUser]
+			decode(t, resp, &list)
+			return list
+		}
+
+		page := list(t, "startIndex=2&count=1")
+		assert.Equal(t, page.TotalResults, 2)
+		assert.Equal(t, page.StartIndex, 2)
+		assert.Equal(t, page.ItemsPerPage, 1)
+		assert.Equal(t, page.Resources[0].ID, bob.ID)
+
+		page = list(t, "count=0")
+		assert.Equal(t, page.TotalResults, 2)
+		assert.Equal(t, len(page.Resources), 0)
+
+		page = list(t, "startIndex=3")
+		assert.Equal(t, page.TotalResults, 2)
+		assert.Equal(t, len(page.Resources), 0)
+
+		page = list(t, "startIndex=2&count=9223372036854775807")
+		assert.Equal(t, page.ItemsPerPage, 1)
+		assert.Equal(t, page.Resources[0].ID, bob.ID)
+	})
+
+	var group api.SCIMGroup
+	t.Run("create group", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/scim/v2/Groups", key, api.SCIMGroup{
+			Schemas:     []string{api.SCIMSchemaGroup},
+			DisplayName: "engineering",
+			Members:     []api.SCIMReference{{Value: alice.ID}},
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+		decode(t, resp, &group)
+		assert.Equal(t, group.DisplayName, "engineering")
+		assert.DeepEqual(t, group.Members, []api.SCIMReference{{Value: alice.ID, Display: "alice@example.com"}})
+	})
+
+	t.Run("patch group members", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPatch, "/scim/v2/Groups/"+group.ID, key, api.SCIMPatchRequest{
+			Schemas: []string{api.SCIMSchemaPatchOp},
+			Operations: []api.SCIMPatchOperation{
+				{Op: "add", Path: "members", Value: json.RawMessage(`[{"value":"` + bob.ID + `"}]`)},
+				{Op: "remove", Path: `members[value eq "` + alice.ID + `"]`},
+				{Op: "replace", Value: json.RawMessage(`{"displayName":"eng"}`)},
+			},
+		})
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var patched api.SCIMGroup
+		decode(t, resp, &patched)
+		assert.Equal(t, patched.DisplayName, "eng")
+		assert.DeepEqual(t, patched.Members, []api.SCIMReference{{Value: bob.ID, Display: "bob@example.com"}})
+	})
+
+	t.Run("members must be users of the provider", func(t *testing.T) {
+		carol, err := data.GetIdentity(srv.db, data.ByName("carol@example.com"))
+		assert.NilError(t, err)
+
+		resp := callAPI(t, routes, http.MethodPatch, "/scim/v2/Groups/"+group.ID, key, api.SCIMPatchRequest{
+			Operations: []api.SCIMPatchOperation{
+				{Op: "add", Path: "members", Value: json.RawMessage(`[{"value":"` + carol.ID.String() + `"}]`)},
+			},
+		})
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("deactivate user", func(t *testing.T) {
+		id, err := uid.Parse([]byte(bob.ID))
+		assert.NilError(t, err)
+
+		_, err = data.CreateAccessKey(srv.db, &models.AccessKey{
+			IssuedFor:  id,
+			ProviderID: provider.ID,
+			ExpiresAt:  time.Now().Add(time.Hour),
+		})
+		assert.NilError(t, err)
+
+		resp := callAPI(t, routes, http.MethodPatch, "/scim/v2/Users/"+bob.ID, key, api.SCIMPatchRequest{
+			Schemas:    []string{api.SCIMSchemaPatchOp},
+			Operations: []api.SCIMPatchOperation{{Op: "replace", Path: "active", Value: json.RawMessage(`"False"`)}},
+		})
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		_, err = data.GetIdentity(srv.db, data.ByID(id))
+		assert.ErrorIs(t, err, internal.ErrNotFound)
+
+		keys, err := data.ListAccessKeys(srv.db, data.ByIssuedFor(id))
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 0)
+
+		resp = callAPI(t, routes, http.MethodGet, "/scim/v2/Groups/"+group.ID, key, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var updated api.SCIMGroup
+		decode(t, resp, &updated)
+		assert.Equal(t, len(updated.Members), 0)
+	})
+
+	t.Run("delete group", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodDelete, "/scim/v2/Groups/"+group.ID, key, nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, "/scim/v2/Groups/"+group.ID, key, nil)
+		assert.Equal(t, resp.Code, http.StatusNotFound, resp.Body.String())
+	})
+
+	t.Run("service accounts are not adopted", func(t *testing.T) {
+		createIdentities(t, srv.db, &models.Identity{Name: "deploy-bot", Kind: models.ServiceAccountKind})
+
+		resp := callAPI(t, routes, http.MethodPost, "/scim/v2/Users", key,
+			api.SCIMUser{Schemas: []string{api.SCIMSchemaUser}, UserName: "deploy-bot"})
+		assert.Equal(t, resp.Code, http.StatusConflict, resp.Body.String())
+
+		_, err := data.GetIdentity(srv.db, data.ByName("deploy-bot"))
+		assert.NilError(t, err)
+	})
+
+	t.Run("users of other providers are not renamed or deleted", func(t *testing.T) {
+		erin := &models.Identity{Name: "erin@example.com"}
+		createIdentities(t, srv.db, erin)
+		_, err := data.CreateProviderUser(srv.db, data.InfraProvider(srv.db), erin)
+		assert.NilError(t, err)
+
+		resp := callAPI(t, routes, http.MethodPost, "/scim/v2/Users", key,
+			api.SCIMUser{Schemas: []string{api.SCIMSchemaUser}, UserName: erin.Name})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var adopted api.SCIMUser
+		decode(t, resp, &adopted)
+		assert.Equal(t, adopted.ID, erin.ID.String())
+
+		resp = callAPI(t, routes, http.MethodPut, "/scim/v2/Users/"+adopted.ID, key,
+			api.SCIMUser{Schemas: []string{api.SCIMSchemaUser}, UserName: "mallory@example.com"})
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodDelete, "/scim/v2/Users/"+adopted.ID, key, nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		_, err = data.GetIdentity(srv.db, data.ByName(erin.Name))
+		assert.NilError(t, err)
+
+		resp = callAPI(t, routes, http.MethodGet, "/scim/v2/Users/"+adopted.ID, key, nil)
+		assert.Equal(t, resp.Code, http.StatusNotFound, resp.Body.String())
+	})
+
+	t.Run("delete user", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodDelete, "/scim/v2/Users/"+alice.ID, key, nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		_, err := data.GetIdentity(srv.db, data.ByName("alice@example.com"))
+		assert.ErrorIs(t, err, internal.ErrNotFound)
+
+		events, err := data.ListAuditEvents(srv.db, data.ByOptionalTargetKind("users"))
+		assert.NilError(t, err)
+		assert.Assert(t, len(events) > 0)
+	})
+}
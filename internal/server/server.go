@@ -28,9 +28,11 @@ import (
 	"github.com/infrahq/infra/internal/ginutil"
 	"github.com/infrahq/infra/internal/logging"
 	"github.com/infrahq/infra/internal/repeat"
+	"github.com/infrahq/infra/internal/server/authn"
 	"github.com/infrahq/infra/internal/server/data"
 	"github.com/infrahq/infra/internal/server/models"
 	"github.com/infrahq/infra/metrics"
+	"github.com/infrahq/infra/pki"
 )
 
 type Options struct {
@@ -40,6 +42,22 @@ type Options struct {
 	EnableSignup             bool
 	SessionDuration          time.Duration
 	SessionExtensionDeadline time.Duration
+	LoginLockout             LoginLockoutOptions
+	PasswordPolicy           PasswordPolicyOptions
+	// TrustedProxies are the IPs or CIDRs of the proxies in front of the
+	// server. The X-Forwarded-For header is only used for the client IP of a
+	// request, which limits failed logins, when it was set by one of them.
+	TrustedProxies []string `validate:"dive,ip|cidr"`
+	// SigningKeyRotation is how often the key used to sign the tokens for
+	// destinations is replaced. Zero disables rotation.
+	SigningKeyRotation time.Duration
+	// EnableClientCertificates allows users to authenticate to the HTTPS
+	// listener with a client certificate signed by the server.
+	EnableClientCertificates bool
+	// RequireAdminMFA requires users with the admin role to enable
+	// multi-factor authentication before they can use password logins.
+	RequireAdminMFA bool
+	Email           EmailOptions
 
 	DBFile                  string
 	DBEncryptionKey         string
@@ -55,6 +73,9 @@ type Options struct {
 	Secrets []SecretProvider
 
 	Config
+	// ConfigFile is the file that Config was loaded from. When it is set the
+	// Config is reloaded when the file changes, or on SIGHUP.
+	ConfigFile string `config:"-"`
 
 	Addr ListenerOptions
 	UI   UIOptions
@@ -66,6 +87,68 @@ type ListenerOptions struct {
 	Metrics string
 }
 
+// LoginLockoutOptions limit failed password logins. After each failed login
+// the next login is delayed, starting at Delay and doubling with each failure.
+// After UserAttempts failed logins for a user, or IPAttempts failed logins from
+// a source IP, logins are blocked for Duration.
+type LoginLockoutOptions struct {
+	UserAttempts int
+	IPAttempts   int
+	Delay        time.Duration
+	Duration     time.Duration
+}
+
+func (o LoginLockoutOptions) userPolicy() authn.LockoutPolicy {
+	return authn.LockoutPolicy{Attempts: o.UserAttempts, Delay: o.Delay, Duration: o.Duration}
+}
+
+func (o LoginLockoutOptions) ipPolicy() authn.LockoutPolicy {
+	return authn.LockoutPolicy{Attempts: o.IPAttempts, Delay: o.Delay, Duration: o.Duration}
+}
+
+// PasswordPolicyOptions are the rules that passwords of users in the infra
+// provider must follow.
+type PasswordPolicyOptions struct {
+	MinLength        int
+	RequireLowercase bool
+	RequireUppercase bool
+	RequireNumber    bool
+	RequireSymbol    bool
+	// HistoryDepth is the number of previous passwords that can not be reused.
+	HistoryDepth int
+	// DenyListFile is a file of passwords that can not be used, one on each line.
+	DenyListFile string
+}
+
+func (o PasswordPolicyOptions) policy() authn.PasswordPolicy {
+	return authn.PasswordPolicy{
+		MinLength:        o.MinLength,
+		RequireLowercase: o.RequireLowercase,
+		RequireUppercase: o.RequireUppercase,
+		RequireNumber:    o.RequireNumber,
+		RequireSymbol:    o.RequireSymbol,
+		HistoryDepth:     o.HistoryDepth,
+	}
+}
+
+// EmailOptions configure the SMTP server that sends invitation and password
+// reset emails. Emails are not sent when SMTPHost is empty.
+type EmailOptions struct {
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	// SMTPPassword is a secret, for example env:SMTP_PASSWORD.
+	SMTPPassword string
+	From         string `validate:"required_with=SMTPHost"`
+	// BaseURL is the URL of the Infra UI, which the links in emails open.
+	BaseURL string `validate:"required_with=SMTPHost"`
+	// InvitationExpiry is how long the link in an invitation can be used.
+	InvitationExpiry time.Duration
+	// PasswordResetExpiry is how long the link in a password reset email can
+	// be used.
+	PasswordResetExpiry time.Duration
+}
+
 type UIOptions struct {
 	Enabled  bool
 	ProxyURL types.URL
@@ -74,13 +157,22 @@ type UIOptions struct {
 }
 
 type Server struct {
-	options  Options
-	db       *gorm.DB
-	tel      *Telemetry
-	secrets  map[string]secrets.SecretStorage
-	keys     map[string]secrets.SymmetricKeyProvider
-	Addrs    Addrs
-	routines []routine
+	options   Options
+	db        *gorm.DB
+	tel       *Telemetry
+	secrets   map[string]secrets.SecretStorage
+	keys      map[string]secrets.SymmetricKeyProvider
+	webhooks  *webhookSender
+	grants    *grantWatcher
+	logins    *authn.LoginLimiter
+	passwords authn.PasswordPolicy
+	mailer    *mailer
+	Addrs     Addrs
+	routines  []routine
+
+	// certificates signs and verifies client certificates. It is nil unless
+	// EnableClientCertificates is set.
+	certificates pki.CertificateProvider
 }
 
 type Addrs struct {
@@ -93,9 +185,14 @@ type Addrs struct {
 func newServer(options Options) *Server {
 	options.UI.FS = uiFS
 	return &Server{
-		options: options,
-		secrets: map[string]secrets.SecretStorage{},
-		keys:    map[string]secrets.SymmetricKeyProvider{},
+		options:   options,
+		secrets:   map[string]secrets.SecretStorage{},
+		keys:      map[string]secrets.SymmetricKeyProvider{},
+		webhooks:  newWebhookSender(),
+		grants:    newGrantWatcher(),
+		logins:    authn.NewLoginLimiter(options.LoginLockout.ipPolicy()),
+		passwords: options.PasswordPolicy.policy(),
+		mailer:    newMailer(options.Email),
 	}
 }
 
@@ -107,6 +204,14 @@ func New(options Options) (*Server, error) {
 		return nil, fmt.Errorf("invalid options: %w", err)
 	}
 
+	if options.PasswordPolicy.DenyListFile != "" {
+		denied, err := authn.LoadDenyList(options.PasswordPolicy.DenyListFile)
+		if err != nil {
+			return nil, err
+		}
+		server.passwords.Denied = denied
+	}
+
 	if err := importSecrets(options.Secrets, server.secrets); err != nil {
 		return nil, fmt.Errorf("secrets config: %w", err)
 	}
@@ -115,6 +220,14 @@ func New(options Options) (*Server, error) {
 		return nil, fmt.Errorf("key config: %w", err)
 	}
 
+	if options.Email.SMTPPassword != "" {
+		password, err := secrets.GetSecret(options.Email.SMTPPassword, server.secrets)
+		if err != nil {
+			return nil, fmt.Errorf("smtp password: %w", err)
+		}
+		server.mailer.password = password
+	}
+
 	driver, err := server.getDatabaseDriver()
 	if err != nil {
 		return nil, fmt.Errorf("driver: %w", err)
@@ -130,6 +243,13 @@ func New(options Options) (*Server, error) {
 		return nil, fmt.Errorf("settings: %w", err)
 	}
 
+	if options.EnableClientCertificates {
+		server.certificates, err = newCertificateProvider(server.db)
+		if err != nil {
+			return nil, fmt.Errorf("certificates: %w", err)
+		}
+	}
+
 	if options.EnableTelemetry {
 		if err := configureTelemetry(server); err != nil {
 			return nil, fmt.Errorf("configuring telemetry: %w", err)
@@ -156,6 +276,34 @@ func (s *Server) Run(ctx context.Context) error {
 		})
 	}
 
+	repeat.Start(ctx, 1*time.Minute, func(context.Context) {
+		if err := data.DeleteExpiredGrants(s.db); err != nil {
+			logging.S.Errorf("failed to delete expired grants: %s", err)
+		}
+	})
+
+	repeat.Start(ctx, s.grants.interval, func(ctx context.Context) {
+		s.grants.poll(ctx, s.db)
+	})
+
+	repeat.Start(ctx, s.webhooks.interval, func(ctx context.Context) {
+		s.webhooks.deliverPending(ctx, s.db)
+	})
+
+	repeat.Start(ctx, 1*time.Minute, func(context.Context) {
+		if err := data.RotateSigningKeys(s.db, s.options.SigningKeyRotation, time.Now().UTC()); err != nil {
+			logging.S.Errorf("failed to rotate signing keys: %s", err)
+		}
+	})
+
+	if s.options.ConfigFile != "" {
+		checksum, err := configChecksum(s.options.ConfigFile)
+		if err != nil {
+			logging.S.Warnf("config reload: %s", err)
+		}
+		go s.watchConfig(ctx, configReloadInterval, checksum)
+	}
+
 	group, _ := errgroup.WithContext(ctx)
 	for i := range s.routines {
 		group.Go(s.routines[i].run)
@@ -243,6 +391,12 @@ func (s *Server) listen() error {
 		return fmt.Errorf("tls config: %w", err)
 	}
 
+	if s.certificates != nil {
+		// client certificates are verified by the authentication middleware,
+		// so that requests without one can still use an access key
+		tlsConfig.ClientAuth = tls.RequestClientCert
+	}
+
 	tlsServer := &http.Server{
 		Addr:      s.options.Addr.HTTPS,
 		TLSConfig: tlsConfig,
@@ -0,0 +1,130 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/crypto/bcrypt"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+func TestAPI_ServiceAccounts(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	listNames := func(t *testing.T, path string) []string {
+		t.Helper()
+		resp := callAPI(t, routes, http.MethodGet, path, adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var list api.ListResponse[api.User]
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &list))
+
+		var names []string
+		for _, item := range list.Items {
+			names = append(names, item.Name)
+		}
+		return names
+	}
+
+	resp := callAPI(t, routes, http.MethodPost, "/api/service-accounts", adminAccessKey(srv), api.CreateServiceAccountRequest{Name: "deploy-bot"})
+	assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+	var serviceAccount api.User
+	assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &serviceAccount))
+	assert.Equal(t, serviceAccount.Name, "deploy-bot")
+	assert.Equal(t, serviceAccount.Kind, "service-account")
+
+	t.Run("listed separately from users", func(t *testing.T) {
+		assert.DeepEqual(t, listNames(t, "/api/service-accounts"), []string{"deploy-bot"})
+		assert.DeepEqual(t, listNames(t, "/api/users"), []string{"admin@example.com", "connector"})
+		assert.DeepEqual(t, listNames(t, "/api/users?showServiceAccounts=true"), []string{"admin@example.com", "connector", "deploy-bot"})
+	})
+
+	t.Run("name is unique across users", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/users", adminAccessKey(srv), api.CreateUserRequest{Name: "admin@example.com"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/service-accounts", adminAccessKey(srv), api.CreateServiceAccountRequest{Name: "admin@example.com"})
+		assert.Equal(t, resp.Code, http.StatusConflict, resp.Body.String())
+	})
+
+	t.Run("can not have a password", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPut, "/api/users/"+serviceAccount.ID.String(), adminAccessKey(srv), api.UpdateUserRequest{Password: "password123"})
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		_, err := data.GetCredential(srv.db, data.ByIdentityID(serviceAccount.ID))
+		assert.ErrorContains(t, err, "record not found")
+	})
+
+	t.Run("can not login with a password", func(t *testing.T) {
+		hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+		assert.NilError(t, err)
+		// a credential created before the identity was a service account
+		credential := &models.Credential{IdentityID: serviceAccount.ID, PasswordHash: hash}
+		assert.NilError(t, data.CreateCredential(srv.db, credential))
+		t.Cleanup(func() {
+			assert.NilError(t, data.DeleteCredential(srv.db, credential.ID))
+		})
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/login", "", api.LoginRequest{
+			PasswordCredentials: &api.LoginRequestPasswordCredentials{Name: "deploy-bot", Password: "password123"},
+		})
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("authenticates with access keys", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/access-keys", adminAccessKey(srv), api.CreateAccessKeyRequest{
+			UserID:            serviceAccount.ID,
+			TTL:               api.Duration(time.Hour),
+			ExtensionDeadline: api.Duration(time.Hour),
+		})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var key api.CreateAccessKeyResponse
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &key))
+
+		err := data.CreateGrant(srv.db, &models.Grant{
+			Subject:   uid.NewIdentityPolymorphicID(serviceAccount.ID),
+			Privilege: models.InfraAdminRole,
+			Resource:  "infra",
+		})
+		assert.NilError(t, err)
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/users/self", key.AccessKey, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodPost, "/api/groups", key.AccessKey, api.CreateGroupRequest{Name: "deployers"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/audit-events?actor="+serviceAccount.ID.String(), adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var events api.ListResponse[api.AuditEvent]
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &events))
+		assert.Equal(t, len(events.Items), 1)
+		assert.Equal(t, events.Items[0].ActorName, "deploy-bot")
+		assert.Equal(t, events.Items[0].ActorKind, "service-account")
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		admin, err := data.GetIdentity(srv.db, data.ByName("admin@example.com"))
+		assert.NilError(t, err)
+
+		resp := callAPI(t, routes, http.MethodDelete, "/api/service-accounts/"+admin.ID.String(), adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodDelete, "/api/service-accounts/"+serviceAccount.ID.String(), adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		assert.Equal(t, len(listNames(t, "/api/service-accounts")), 0)
+	})
+}
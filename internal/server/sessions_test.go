@@ -0,0 +1,191 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/crypto/bcrypt"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestAPI_UserSessions(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	createUser := func(t *testing.T, name string) *models.Identity {
+		t.Helper()
+		user := &models.Identity{Name: name}
+		assert.NilError(t, data.CreateIdentity(srv.db, user))
+
+		hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+		assert.NilError(t, err)
+		assert.NilError(t, data.CreateCredential(srv.db, &models.Credential{IdentityID: user.ID, PasswordHash: hash}))
+		return user
+	}
+
+	login := func(t *testing.T, name, userAgent string) string {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/login", jsonBody(t, api.LoginRequest{
+			PasswordCredentials: &api.LoginRequestPasswordCredentials{Name: name, Password: "password123"},
+		}))
+		req.Header.Set("Infra-Version", "0.13.0")
+		req.Header.Set("User-Agent", userAgent)
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var loginResp api.LoginResponse
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &loginResp))
+		return loginResp.AccessKey
+	}
+
+	listSessions := func(t *testing.T, user *models.Identity, key string) []api.Session {
+		t.Helper()
+		resp := callAPI(t, routes, http.MethodGet, "/api/users/"+user.ID.String()+"/sessions", key, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var sessions api.ListResponse[api.Session]
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &sessions))
+		return sessions.Items
+	}
+
+	userPath := func(user *models.Identity) string {
+		return "/api/users/" + user.ID.String()
+	}
+
+	t.Run("list sessions", func(t *testing.T) {
+		user := createUser(t, "alice@example.com")
+		cliKey := login(t, user.Name, "Infra/0.13.0 (cli 0.13.4; linux/amd64)")
+		browserKey := login(t, user.Name, "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)")
+
+		// access keys that were not created by a login are not sessions
+		_, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+			IssuedFor:  user.ID,
+			ProviderID: data.InfraProvider(srv.db).ID,
+			ExpiresAt:  time.Now().Add(time.Hour),
+		})
+		assert.NilError(t, err)
+
+		sessions := listSessions(t, user, cliKey)
+		assert.Equal(t, len(sessions), 2)
+
+		clientNames := map[string]bool{}
+		for _, session := range sessions {
+			clientNames[session.ClientName] = session.Current
+			assert.Equal(t, session.ProviderName, models.InternalInfraProviderName)
+			assert.Assert(t, !session.LastUsed.Time().IsZero())
+		}
+
+		expected := map[string]bool{
+			"cli 0.13.4 (linux/amd64)":                        true,
+			"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7)": false,
+		}
+		assert.DeepEqual(t, clientNames, expected)
+
+		// admins can list the sessions of other users
+		sessions = listSessions(t, user, adminAccessKey(srv))
+		assert.Equal(t, len(sessions), 2)
+
+		other := createUser(t, "mallory@example.com")
+		resp := callAPI(t, routes, http.MethodGet, userPath(user)+"/sessions", login(t, other.Name, ""), nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, userPath(user), browserKey, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+	})
+
+	t.Run("revoke a session", func(t *testing.T) {
+		user := createUser(t, "bob@example.com")
+		key := login(t, user.Name, "")
+		otherKey := login(t, user.Name, "")
+
+		var revoke api.Session
+		for _, session := range listSessions(t, user, key) {
+			if !session.Current {
+				revoke = session
+			}
+		}
+
+		resp := callAPI(t, routes, http.MethodDelete, userPath(user)+"/sessions/"+revoke.ID.String(), key, nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, userPath(user), otherKey, nil)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, userPath(user), key, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		// the session of another user is not found
+		other := createUser(t, "eve@example.com")
+		login(t, other.Name, "")
+		otherSessions := listSessions(t, other, adminAccessKey(srv))
+		assert.Equal(t, len(otherSessions), 1)
+
+		resp = callAPI(t, routes, http.MethodDelete, userPath(user)+"/sessions/"+otherSessions[0].ID.String(), key, nil)
+		assert.Equal(t, resp.Code, http.StatusNotFound, resp.Body.String())
+	})
+
+	t.Run("revoke all other sessions", func(t *testing.T) {
+		user := createUser(t, "carol@example.com")
+		key := login(t, user.Name, "")
+		otherKey := login(t, user.Name, "")
+
+		resp := callAPI(t, routes, http.MethodDelete, userPath(user)+"/sessions?excludeCurrent=true", key, nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, userPath(user), key, nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, userPath(user), otherKey, nil)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+	})
+
+	t.Run("admin revokes all sessions", func(t *testing.T) {
+		user := createUser(t, "dave@example.com")
+		key := login(t, user.Name, "")
+		otherKey := login(t, user.Name, "")
+
+		_, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+			IssuedFor:  user.ID,
+			ProviderID: data.InfraProvider(srv.db).ID,
+			ExpiresAt:  time.Now().Add(time.Hour),
+		})
+		assert.NilError(t, err)
+
+		resp := callAPI(t, routes, http.MethodDelete, userPath(user)+"/sessions", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		for _, k := range []string{key, otherKey} {
+			resp = callAPI(t, routes, http.MethodGet, userPath(user), k, nil)
+			assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+		}
+
+		keys, err := data.ListAccessKeys(srv.db, data.ByIssuedFor(user.ID))
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 1, "access keys that are not sessions are not revoked")
+	})
+
+	t.Run("deleting a user revokes its sessions", func(t *testing.T) {
+		user := createUser(t, "frank@example.com")
+		key := login(t, user.Name, "")
+
+		resp := callAPI(t, routes, http.MethodDelete, userPath(user), adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, userPath(user), key, nil)
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+		keys, err := data.ListAccessKeys(srv.db, data.ByIssuedFor(user.ID))
+		assert.NilError(t, err)
+		assert.Equal(t, len(keys), 0)
+	})
+}
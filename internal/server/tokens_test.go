@@ -0,0 +1,75 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gopkg.in/square/go-jose.v2/jwt"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/claims"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestAPI_CreateToken(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	// the signing key of destination tokens
+	_, err := data.InitializeSettings(srv.db)
+	assert.NilError(t, err)
+
+	assert.NilError(t, data.CreateDestination(srv.db, &models.Destination{Name: "production", UniqueID: "production"}))
+
+	createToken := func(t *testing.T, body interface{}) *httptest.ResponseRecorder {
+		t.Helper()
+		req := httptest.NewRequest(http.MethodPost, "/api/tokens", jsonBody(t, body))
+		req.Header.Set("Authorization", "Bearer "+adminAccessKey(srv))
+		req.Header.Set("Infra-Version", "0.13.0")
+
+		resp := httptest.NewRecorder()
+		routes.ServeHTTP(resp, req)
+		return resp
+	}
+
+	tokenClaims := func(t *testing.T, resp *httptest.ResponseRecorder) jwt.Claims {
+		t.Helper()
+		var created api.CreateTokenResponse
+		assert.NilError(t, json.Unmarshal(resp.Body.Bytes(), &created))
+
+		tok, err := jwt.ParseSigned(created.Token)
+		assert.NilError(t, err)
+
+		var cl jwt.Claims
+		assert.NilError(t, tok.UnsafeClaimsWithoutVerification(&cl))
+		return cl
+	}
+
+	t.Run("for a destination", func(t *testing.T) {
+		resp := createToken(t, api.CreateTokenRequest{Destination: "production"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		cl := tokenClaims(t, resp)
+		assert.Equal(t, cl.Issuer, claims.Issuer)
+		assert.DeepEqual(t, cl.Audience, jwt.Audience{"production"})
+	})
+
+	t.Run("for an unknown destination", func(t *testing.T) {
+		resp := createToken(t, api.CreateTokenRequest{Destination: "staging"})
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+	})
+
+	t.Run("without a destination", func(t *testing.T) {
+		resp := createToken(t, nil)
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		cl := tokenClaims(t, resp)
+		assert.Equal(t, cl.Issuer, claims.Issuer)
+		assert.Equal(t, len(cl.Audience), 0)
+	})
+}
@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/access"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+// grantWatcher checks the grants table for changes, and notifies the watches
+// that subscribed to it. A single check is shared by every watch, so the number
+// of open watches does not change how often the database is queried.
+type grantWatcher struct {
+	interval      time.Duration // how often the grants table is checked for changes
+	checkInterval time.Duration // how often a watch checks its credentials, and sends a keepalive when idle
+
+	mu          sync.Mutex
+	version     string
+	subscribers []chan struct{}
+}
+
+func newGrantWatcher() *grantWatcher {
+	return &grantWatcher{
+		interval:      time.Second,
+		checkInterval: 15 * time.Second,
+	}
+}
+
+// subscribe returns a channel that receives a value when the grants change.
+// Changes that happen before the last one was received are merged.
+func (w *grantWatcher) subscribe() chan struct{} {
+	ch := make(chan struct{}, 1)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.subscribers = append(w.subscribers, ch)
+	return ch
+}
+
+func (w *grantWatcher) unsubscribe(ch chan struct{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, sub := range w.subscribers {
+		if sub == ch {
+			w.subscribers = append(w.subscribers[:i], w.subscribers[i+1:]...)
+			return
+		}
+	}
+}
+
+// poll checks the grants table for changes, and notifies the subscribers when
+// it changed. It is called periodically by the server.
+func (w *grantWatcher) poll(ctx context.Context, db *gorm.DB) {
+	version, err := data.GrantsVersion(db.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() == nil {
+			logging.S.Errorf("grant watch: %s", err)
+		}
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if version == w.version {
+		return
+	}
+
+	w.version = version
+	for _, ch := range w.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// WatchGrants streams the grants for a resource, and for the resources within
+// it, as server-sent events. An event with every matching grant is sent when
+// the watch starts, and again whenever those grants change. The id of each
+// event is its revision. A client resumes a watch by sending the revision of
+// the last event it received, either as the revision query parameter or in the
+// Last-Event-ID header, and only receives an event once the grants differ from
+// that revision.
+//
+// The credentials of the request are checked again periodically, and the
+// stream ends when they are no longer valid, or when the request times out.
+// Clients are expected to reconnect.
+func (a *API) WatchGrants(c *gin.Context) {
+	var req api.WatchGrantsRequest
+	if err := bind(c, &req); err != nil {
+		sendAPIError(c, err)
+		return
+	}
+
+	if req.Revision == "" {
+		req.Revision = c.GetHeader("Last-Event-ID")
+	}
+
+	watcher := a.server.grants
+	changes := watcher.subscribe()
+	defer watcher.unsubscribe(changes)
+
+	grants, err := access.ListGrantsForResource(c, req.Resource)
+	if err != nil {
+		sendAPIError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no") // disable response buffering in nginx
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	ticker := time.NewTicker(watcher.checkInterval)
+	defer ticker.Stop()
+
+	revision := req.Revision
+	idle := false
+
+	for {
+		if current := grantsRevision(grants); current != revision {
+			if err := writeGrantsEvent(c, current, grants); err != nil {
+				logging.S.Debugf("grant watch: %s", err)
+				return
+			}
+			revision = current
+			idle = false
+		}
+
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-ticker.C:
+			if err := a.authenticate(c); err != nil {
+				logging.S.Debugf("grant watch: %s", err)
+				return
+			}
+
+			if idle {
+				if _, err := fmt.Fprint(c.Writer, ": keepalive\n\n"); err != nil {
+					logging.S.Debugf("grant watch: %s", err)
+					return
+				}
+				c.Writer.Flush()
+			}
+			idle = true
+			continue
+		case <-changes:
+		}
+
+		grants, err = access.ListGrantsForResource(c, req.Resource)
+		if err != nil {
+			if c.Request.Context().Err() == nil {
+				logging.S.Errorf("grant watch: %s", err)
+			}
+			return
+		}
+	}
+}
+
+func writeGrantsEvent(c *gin.Context, revision string, grants []models.Grant) error {
+	resp := api.WatchGrantsResponse{Revision: revision, Items: make([]api.Grant, 0, len(grants))}
+	for _, grant := range grants {
+		resp.Items = append(resp.Items, *grant.ToAPI())
+	}
+
+	body, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(c.Writer, "id: %s\nevent: grants\ndata: %s\n\n", revision, body); err != nil {
+		return err
+	}
+
+	c.Writer.Flush()
+	return nil
+}
+
+// grantsRevision returns a revision that changes whenever a grant is added to,
+// removed from, or updated in grants. grants is sorted by ID, so that the
+// revision does not depend on the order the grants were listed in.
+func grantsRevision(grants []models.Grant) string {
+	sort.Slice(grants, func(i, j int) bool {
+		return grants[i].ID < grants[j].ID
+	})
+
+	hash := sha256.New()
+	buf := make([]byte, 16)
+	for _, grant := range grants {
+		binary.BigEndian.PutUint64(buf[:8], uint64(grant.ID))
+		binary.BigEndian.PutUint64(buf[8:], uint64(grant.UpdatedAt.UnixNano()))
+		hash.Write(buf)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)[:12])
+}
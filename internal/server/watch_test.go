@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gotest.tools/v3/assert"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/repeat"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+)
+
+func TestAPI_WatchGrants(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	srv.grants.checkInterval = 50 * time.Millisecond
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	repeat.Start(ctx, 10*time.Millisecond, func(ctx context.Context) {
+		srv.grants.poll(ctx, srv.db)
+	})
+
+	httpSrv := httptest.NewServer(routes)
+	t.Cleanup(httpSrv.Close)
+
+	alice := &models.Identity{Name: "alice@example.com"}
+	createIdentities(t, srv.db, alice)
+
+	createGrant := func(t *testing.T, resource string) {
+		t.Helper()
+		err := data.CreateGrant(srv.db, &models.Grant{
+			Subject:   alice.PolyID(),
+			Privilege: "view",
+			Resource:  resource,
+		})
+		assert.NilError(t, err)
+	}
+
+	createGrant(t, "example")
+	createGrant(t, "example.ns1")
+	createGrant(t, "example-2")
+
+	client := api.Client{URL: httpSrv.URL, AccessKey: adminAccessKey(srv)}
+
+	// watch starts a watch, and returns a channel that receives each event
+	watch := func(t *testing.T, ctx context.Context, client api.Client, req api.WatchGrantsRequest) (<-chan api.WatchGrantsResponse, <-chan error) {
+		t.Helper()
+		events := make(chan api.WatchGrantsResponse, 10)
+		errs := make(chan error, 1)
+		go func() {
+			errs <- client.WatchGrants(ctx, req, func(resp api.WatchGrantsResponse) error {
+				events <- resp
+				return nil
+			})
+		}()
+		return events, errs
+	}
+
+	next := func(t *testing.T, events <-chan api.WatchGrantsResponse) api.WatchGrantsResponse {
+		t.Helper()
+		select {
+		case resp := <-events:
+			return resp
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return api.WatchGrantsResponse{}
+		}
+	}
+
+	resources := func(resp api.WatchGrantsResponse) []string {
+		var result []string
+		for _, grant := range resp.Items {
+			result = append(result, grant.Resource)
+		}
+		return result
+	}
+
+	var first, second api.WatchGrantsResponse
+	t.Run("sends the current grants, then changes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events, errs := watch(t, ctx, client, api.WatchGrantsRequest{Resource: "example"})
+
+		first = next(t, events)
+		assert.DeepEqual(t, resources(first), []string{"example", "example.ns1"})
+		assert.Assert(t, first.Revision != "")
+
+		createGrant(t, "example.ns2")
+		createGrant(t, "other")
+
+		second = next(t, events)
+		assert.DeepEqual(t, resources(second), []string{"example", "example.ns1", "example.ns2"})
+		assert.Assert(t, second.Revision != first.Revision)
+
+		cancel()
+		assert.Assert(t, errors.Is(<-errs, context.Canceled))
+	})
+
+	t.Run("resume from the current revision", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		defer cancel()
+
+		events, errs := watch(t, ctx, client, api.WatchGrantsRequest{Resource: "example", Revision: second.Revision})
+
+		err := <-errs
+		assert.Assert(t, errors.Is(err, context.DeadlineExceeded), err)
+		assert.Equal(t, len(events), 0)
+	})
+
+	t.Run("resume from an old revision", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		events, _ := watch(t, ctx, client, api.WatchGrantsRequest{Resource: "example", Revision: first.Revision})
+
+		resp := next(t, events)
+		assert.DeepEqual(t, resp, second)
+	})
+
+	t.Run("includes grants with wildcards", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		createGrant(t, "exam*.ns3")
+		createGrant(t, "*.ns4")
+		createGrant(t, "other-*")
+
+		events, _ := watch(t, ctx, client, api.WatchGrantsRequest{Resource: "example"})
+
+		resp := next(t, events)
+		assert.DeepEqual(t, resources(resp), []string{"example", "example.ns1", "example.ns2", "exam*.ns3", "*.ns4"})
+	})
+
+	t.Run("requires a resource", func(t *testing.T) {
+		err := client.WatchGrants(context.Background(), api.WatchGrantsRequest{}, func(api.WatchGrantsResponse) error {
+			return nil
+		})
+		assert.Equal(t, api.ErrorStatusCode(err), int32(http.StatusBadRequest), err)
+	})
+
+	t.Run("requires an infra role", func(t *testing.T) {
+		key, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+			IssuedFor:  alice.ID,
+			ProviderID: data.InfraProvider(srv.db).ID,
+			ExpiresAt:  time.Now().Add(time.Minute),
+		})
+		assert.NilError(t, err)
+
+		client := api.Client{URL: httpSrv.URL, AccessKey: key}
+		err = client.WatchGrants(context.Background(), api.WatchGrantsRequest{Resource: "example"}, func(api.WatchGrantsResponse) error {
+			return nil
+		})
+		assert.Equal(t, api.ErrorStatusCode(err), int32(http.StatusForbidden), err)
+	})
+
+	t.Run("ends when the access key is revoked", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		admin, err := data.GetIdentity(srv.db, data.ByName("admin@example.com"))
+		assert.NilError(t, err)
+
+		key := &models.AccessKey{
+			IssuedFor:  admin.ID,
+			ProviderID: data.InfraProvider(srv.db).ID,
+			ExpiresAt:  time.Now().Add(time.Minute),
+		}
+		secret, err := data.CreateAccessKey(srv.db, key)
+		assert.NilError(t, err)
+
+		client := api.Client{URL: httpSrv.URL, AccessKey: secret}
+		events, errs := watch(t, ctx, client, api.WatchGrantsRequest{Resource: "example"})
+		next(t, events)
+
+		assert.NilError(t, data.DeleteAccessKey(srv.db, key.ID))
+
+		select {
+		case err := <-errs:
+			assert.NilError(t, err)
+		case <-ctx.Done():
+			t.Fatal("watch did not end after the access key was revoked")
+		}
+	})
+
+	t.Run("requires authentication", func(t *testing.T) {
+		client := api.Client{URL: httpSrv.URL}
+		err := client.WatchGrants(context.Background(), api.WatchGrantsRequest{Resource: "example"}, func(api.WatchGrantsResponse) error {
+			return nil
+		})
+		assert.Equal(t, api.ErrorStatusCode(err), int32(http.StatusUnauthorized), err)
+	})
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal"
+	"github.com/infrahq/infra/internal/logging"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+// webhookSender delivers events to webhooks. Events are queued as pending
+// WebhookDelivery in the transaction of the request, and are delivered by
+// deliverPending after the transaction is committed. Failed attempts are
+// retried with an exponential backoff until they succeed or run out of attempts.
+type webhookSender struct {
+	client      *http.Client
+	interval    time.Duration // how often pending deliveries are checked
+	maxAttempts int
+	backoff     time.Duration // the wait before the first retry, doubled for each retry after that
+}
+
+func newWebhookSender() *webhookSender {
+	return &webhookSender{
+		client:      &http.Client{Timeout: 10 * time.Second},
+		interval:    time.Second,
+		maxAttempts: 5,
+		backoff:     2 * time.Second,
+	}
+}
+
+// sendWebhookEvent queues the event for every webhook subscribed to it. The
+// webhooks are sent asynchronously, so a slow or failing webhook does not
+// affect the request.
+func (a *API) sendWebhookEvent(c *gin.Context, event string, value interface{}) {
+	db := auditDB(c)
+	if db == nil {
+		return
+	}
+
+	webhooks, err := data.ListWebhooks(db)
+	if err != nil {
+		logging.S.Errorf("failed to list webhooks for %s event: %s", event, err)
+		return
+	}
+
+	subscribed := make([]models.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		if webhook.Subscribes(event) {
+			subscribed = append(subscribed, webhook)
+		}
+	}
+
+	if len(subscribed) == 0 {
+		return
+	}
+
+	raw, err := json.Marshal(value)
+	if err != nil {
+		logging.S.Errorf("failed to encode %s event: %s", event, err)
+		return
+	}
+
+	payload := api.WebhookPayload{
+		ID:      uid.New(),
+		Event:   event,
+		Created: api.Time(time.Now()),
+		Data:    raw,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		logging.S.Errorf("failed to encode %s event: %s", event, err)
+		return
+	}
+
+	for _, webhook := range subscribed {
+		delivery := &models.WebhookDelivery{
+			WebhookID: webhook.ID,
+			EventID:   payload.ID,
+			Event:     payload.Event,
+			Attempt:   1,
+			Payload:   body,
+			Pending:   true,
+			AttemptAt: time.Now(),
+		}
+		if err := data.CreateWebhookDelivery(db, delivery); err != nil {
+			logging.S.Errorf("failed to queue webhook delivery: %s", err)
+		}
+	}
+}
+
+// deliverPending makes the delivery attempts that are due. It is called
+// periodically by the server, and stops early when ctx is cancelled.
+func (s *webhookSender) deliverPending(ctx context.Context, db *gorm.DB) {
+	deliveries, err := data.ListWebhookDeliveries(db, data.ByPendingWebhookDelivery(time.Now()))
+	if err != nil {
+		logging.S.Errorf("failed to list pending webhook deliveries: %s", err)
+		return
+	}
+
+	for i := range deliveries {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := s.deliver(ctx, db, &deliveries[i]); err != nil {
+			logging.S.Errorf("failed to deliver webhook event %s: %s", deliveries[i].EventID, err)
+		}
+	}
+}
+
+func (s *webhookSender) deliver(ctx context.Context, db *gorm.DB, delivery *models.WebhookDelivery) error {
+	claimed, err := data.ClaimWebhookDelivery(db, delivery)
+	if err != nil || !claimed {
+		return err
+	}
+
+	webhook, err := data.GetWebhook(db, data.ByID(delivery.WebhookID))
+	if err != nil {
+		return err
+	}
+
+	statusCode, postErr := s.post(ctx, *webhook, delivery)
+	delivery.StatusCode = statusCode
+	if postErr != nil {
+		delivery.Error = postErr.Error()
+	}
+
+	if err := data.SaveWebhookDelivery(db, delivery); err != nil {
+		return err
+	}
+
+	if postErr == nil {
+		return nil
+	}
+
+	logging.S.Debugf("webhook %s delivery of %s failed on attempt %d: %s", webhook.ID, delivery.EventID, delivery.Attempt, postErr)
+
+	if delivery.Attempt >= s.maxAttempts {
+		logging.S.Warnf("webhook %s delivery of %s event %s failed after %d attempts", webhook.ID, delivery.Event, delivery.EventID, s.maxAttempts)
+		return nil
+	}
+
+	return data.CreateWebhookDelivery(db, &models.WebhookDelivery{
+		WebhookID: delivery.WebhookID,
+		EventID:   delivery.EventID,
+		Event:     delivery.Event,
+		Attempt:   delivery.Attempt + 1,
+		Payload:   delivery.Payload,
+		Pending:   true,
+		AttemptAt: time.Now().Add(s.backoff << (delivery.Attempt - 1)),
+	})
+}
+
+func (s *webhookSender) post(ctx context.Context, webhook models.Webhook, delivery *models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Infra/"+internal.FullVersion())
+	req.Header.Set("Infra-Event", delivery.Event)
+	req.Header.Set("Infra-Delivery", delivery.EventID.String())
+	req.Header.Set("Infra-Signature", webhookSignature(string(webhook.Secret), delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// read the body so that the connection can be reused
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// webhookSignature returns the value of the Infra-Signature header for body.
+func webhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
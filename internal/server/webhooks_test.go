@@ -0,0 +1,264 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"gotest.tools/v3/assert"
+	"gotest.tools/v3/poll"
+
+	"github.com/infrahq/infra/api"
+	"github.com/infrahq/infra/internal/repeat"
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
+	"github.com/infrahq/infra/uid"
+)
+
+type webhookRequest struct {
+	header  http.Header
+	body    []byte
+	payload api.WebhookPayload
+}
+
+// webhookReceiver is a local HTTP server that records webhook requests. The
+// first failures requests are rejected with a 500.
+type webhookReceiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	failures int
+	requests []webhookRequest
+}
+
+func newWebhookReceiver(t *testing.T, failures int) *webhookReceiver {
+	t.Helper()
+	receiver := &webhookReceiver{failures: failures}
+	receiver.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.Check(t, err)
+
+		req := webhookRequest{header: r.Header.Clone(), body: body}
+		assert.Check(t, json.Unmarshal(body, &req.payload))
+
+		receiver.mu.Lock()
+		defer receiver.mu.Unlock()
+		receiver.requests = append(receiver.requests, req)
+
+		if receiver.failures > 0 {
+			receiver.failures--
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver
+}
+
+func (r *webhookReceiver) received() []webhookRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]webhookRequest{}, r.requests...)
+}
+
+func (r *webhookReceiver) waitFor(t *testing.T, count int) []webhookRequest {
+	t.Helper()
+	poll.WaitOn(t, func(t poll.LogT) poll.Result {
+		if received := len(r.received()); received < count {
+			return poll.Continue("received %d of %d requests", received, count)
+		}
+		return poll.Success()
+	}, poll.WithTimeout(5*time.Second), poll.WithDelay(10*time.Millisecond))
+	return r.received()
+}
+
+func TestAPI_Webhooks(t *testing.T) {
+	srv := setupServer(t, withAdminUser)
+	srv.webhooks.backoff = time.Millisecond
+	srv.webhooks.maxAttempts = 3
+	routes := srv.GenerateRoutes(prometheus.NewRegistry())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	repeat.Start(ctx, 10*time.Millisecond, func(ctx context.Context) {
+		srv.webhooks.deliverPending(ctx, srv.db)
+	})
+
+	const secret = "0123456789abcdef"
+
+	createWebhook := func(t *testing.T, url string, events ...string) api.Webhook {
+		t.Helper()
+		resp := callAPI(t, routes, http.MethodPost, "/api/webhooks", adminAccessKey(srv),
+			api.CreateWebhookRequest{URL: url, Events: events, Secret: secret})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var webhook api.Webhook
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&webhook))
+		return webhook
+	}
+
+	listDeliveries := func(t *testing.T, id uid.ID) []api.WebhookDelivery {
+		t.Helper()
+		resp := callAPI(t, routes, http.MethodGet, "/api/webhooks/"+id.String()+"/deliveries", adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusOK, resp.Body.String())
+
+		var deliveries api.ListResponse[api.WebhookDelivery]
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&deliveries))
+		return deliveries.Items
+	}
+
+	// deliveries are recorded after the receiver responds, so wait for them
+	// before checking the delivery log
+	waitForDeliveries := func(t *testing.T, id uid.ID, count int) {
+		t.Helper()
+		poll.WaitOn(t, func(t poll.LogT) poll.Result {
+			deliveries, err := data.ListWebhookDeliveries(srv.db, data.ByWebhookID(id), data.ByAttemptedWebhookDelivery())
+			if err != nil {
+				return poll.Error(err)
+			}
+			if len(deliveries) < count {
+				return poll.Continue("%d of %d deliveries recorded", len(deliveries), count)
+			}
+			return poll.Success()
+		}, poll.WithTimeout(5*time.Second), poll.WithDelay(10*time.Millisecond))
+	}
+
+	t.Run("requires infra admin", func(t *testing.T) {
+		user := &models.Identity{Name: "webhook-user@example.com"}
+		createIdentities(t, srv.db, user)
+
+		key, err := data.CreateAccessKey(srv.db, &models.AccessKey{
+			IssuedFor:  user.ID,
+			ProviderID: data.InfraProvider(srv.db).ID,
+			ExpiresAt:  time.Now().Add(time.Minute),
+		})
+		assert.NilError(t, err)
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/webhooks", key,
+			api.CreateWebhookRequest{URL: "https://example.com", Events: []string{api.WebhookEventUserCreated}, Secret: secret})
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+
+		resp = callAPI(t, routes, http.MethodGet, "/api/webhooks", key, nil)
+		assert.Equal(t, resp.Code, http.StatusForbidden, resp.Body.String())
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		resp := callAPI(t, routes, http.MethodPost, "/api/webhooks", adminAccessKey(srv),
+			api.CreateWebhookRequest{URL: "https://example.com", Events: []string{"grant.updated"}, Secret: "short"})
+		assert.Equal(t, resp.Code, http.StatusBadRequest, resp.Body.String())
+
+		var apiErr api.Error
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&apiErr))
+		assert.Equal(t, len(apiErr.FieldErrors), 2, resp.Body.String())
+	})
+
+	t.Run("grant events are signed and delivered", func(t *testing.T) {
+		receiver := newWebhookReceiver(t, 0)
+		webhook := createWebhook(t, receiver.URL, api.WebhookEventGrantCreated, api.WebhookEventGrantDeleted)
+
+		user := &models.Identity{Name: "grantee@example.com"}
+		createIdentities(t, srv.db, user)
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/grants", adminAccessKey(srv),
+			api.CreateGrantRequest{User: user.ID, Privilege: "view", Resource: "example-cluster"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		var grant api.Grant
+		assert.NilError(t, json.NewDecoder(resp.Body).Decode(&grant))
+
+		resp = callAPI(t, routes, http.MethodDelete, "/api/grants/"+grant.ID.String(), adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+
+		requests := receiver.waitFor(t, 2)
+		assert.Equal(t, len(requests), 2)
+
+		events := map[string]webhookRequest{}
+		for _, req := range requests {
+			assert.Equal(t, req.header.Get("Infra-Signature"), webhookSignature(secret, req.body))
+			assert.Equal(t, req.header.Get("Infra-Event"), req.payload.Event)
+			assert.Equal(t, req.header.Get("Infra-Delivery"), req.payload.ID.String())
+			events[req.payload.Event] = req
+		}
+
+		for _, event := range []string{api.WebhookEventGrantCreated, api.WebhookEventGrantDeleted} {
+			req, ok := events[event]
+			assert.Assert(t, ok, "missing %s event", event)
+
+			var actual api.Grant
+			assert.NilError(t, json.Unmarshal(req.payload.Data, &actual))
+			assert.Equal(t, actual.ID, grant.ID)
+			assert.Equal(t, actual.User, user.ID)
+		}
+
+		waitForDeliveries(t, webhook.ID, 2)
+		for _, delivery := range listDeliveries(t, webhook.ID) {
+			assert.Equal(t, delivery.Attempt, 1)
+			assert.Equal(t, delivery.StatusCode, http.StatusNoContent)
+			assert.Equal(t, delivery.Error, "")
+		}
+
+		resp = callAPI(t, routes, http.MethodDelete, "/api/webhooks/"+webhook.ID.String(), adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+	})
+
+	t.Run("failed deliveries are retried", func(t *testing.T) {
+		receiver := newWebhookReceiver(t, 2)
+		webhook := createWebhook(t, receiver.URL, api.WebhookEventUserCreated)
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/users", adminAccessKey(srv),
+			api.CreateUserRequest{Name: "new-user@example.com"})
+		assert.Equal(t, resp.Code, http.StatusCreated, resp.Body.String())
+
+		requests := receiver.waitFor(t, 3)
+		assert.Equal(t, len(requests), 3)
+		for _, req := range requests {
+			assert.Equal(t, req.payload.ID, requests[0].payload.ID)
+			assert.Equal(t, req.payload.Event, api.WebhookEventUserCreated)
+		}
+
+		var user api.User
+		assert.NilError(t, json.Unmarshal(requests[0].payload.Data, &user))
+		assert.Equal(t, user.Name, "new-user@example.com")
+
+		waitForDeliveries(t, webhook.ID, 3)
+
+		attempts := map[int]api.WebhookDelivery{}
+		for _, delivery := range listDeliveries(t, webhook.ID) {
+			attempts[delivery.Attempt] = delivery
+		}
+		assert.Equal(t, attempts[1].StatusCode, http.StatusInternalServerError)
+		assert.Equal(t, attempts[1].Error, "unexpected response status 500")
+		assert.Equal(t, attempts[2].StatusCode, http.StatusInternalServerError)
+		assert.Equal(t, attempts[3].StatusCode, http.StatusNoContent)
+		assert.Equal(t, attempts[3].Error, "")
+
+		resp = callAPI(t, routes, http.MethodDelete, "/api/webhooks/"+webhook.ID.String(), adminAccessKey(srv), nil)
+		assert.Equal(t, resp.Code, http.StatusNoContent, resp.Body.String())
+	})
+
+	t.Run("failed login", func(t *testing.T) {
+		receiver := newWebhookReceiver(t, 0)
+		webhook := createWebhook(t, receiver.URL, api.WebhookEventLoginFailed)
+
+		resp := callAPI(t, routes, http.MethodPost, "/api/login", "", api.LoginRequest{
+			PasswordCredentials: &api.LoginRequestPasswordCredentials{Name: "nobody@example.com", Password: "password"},
+		})
+		assert.Equal(t, resp.Code, http.StatusUnauthorized, resp.Body.String())
+
+		requests := receiver.waitFor(t, 1)
+		assert.Equal(t, requests[0].payload.Event, api.WebhookEventLoginFailed)
+
+		var login api.WebhookLogin
+		assert.NilError(t, json.Unmarshal(requests[0].payload.Data, &login))
+		assert.DeepEqual(t, login, api.WebhookLogin{Name: "nobody@example.com", Method: "credentials"})
+
+		waitForDeliveries(t, webhook.ID, 1)
+	})
+}
@@ -22,7 +22,6 @@ import (
 )
 
 func TestCertificateSigningWorks(t *testing.T) {
-	t.Skip("persistence not implemented")
 	db := setupDB(t)
 
 	cp, err := pki.NewNativeCertificateProvider(db, pki.NativeCertificateProviderConfig{
@@ -55,7 +54,6 @@ func TestCertificateSigningWorks(t *testing.T) {
 	requireMutualTLSWorks(t, keyPair, cp)
 }
 
-// nolint:unused
 func requireMutualTLSWorks(t *testing.T, clientKeypair *pki.KeyPair, cp pki.CertificateProvider) {
 	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		fmt.Fprintf(w, "success!")
@@ -110,7 +108,6 @@ func requireMutualTLSWorks(t *testing.T, clientKeypair *pki.KeyPair, cp pki.Cert
 	assert.Equal(t, "success!", body)
 }
 
-// nolint:unused
 func setupDB(t *testing.T) *gorm.DB {
 	driver, err := data.NewSQLiteDriver("file::memory:")
 	assert.NilError(t, err)
@@ -19,7 +19,6 @@ import (
 	"gotest.tools/v3/assert/opt"
 )
 
-// nolint:unused
 func eachProvider(t *testing.T, eachFunc func(t *testing.T, p CertificateProvider)) {
 	providers := map[string]CertificateProvider{}
 
@@ -43,7 +42,6 @@ func eachProvider(t *testing.T, eachFunc func(t *testing.T, p CertificateProvide
 }
 
 func TestCertificatesImplementations(t *testing.T) {
-	t.Skip("persistence not implemented")
 	eachProvider(t, func(t *testing.T, p CertificateProvider) {
 		err := p.CreateCA()
 		assert.NilError(t, err)
@@ -15,11 +15,18 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/infrahq/infra/internal/server/data"
+	"github.com/infrahq/infra/internal/server/models"
 )
 
 const (
 	day        = 24 * time.Hour
 	rootCAName = "Root Infra CA"
+
+	// ClockSkew is how far the NotBefore of signed certificates is set before
+	// the time they are signed, to allow for clocks that are behind.
+	ClockSkew = 5 * time.Minute
 )
 
 var (
@@ -249,16 +256,21 @@ func (n *NativeCertificateProvider) SignCertificate(csr x509.CertificateRequest)
 		return nil, fmt.Errorf("%q is not an acceptable public key algorithm, expecting one of: %v", csr.PublicKeyAlgorithm, allowedPublicKeyAlgorithms)
 	}
 
+	serial, err := rand.Int(randReader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("creating random serial: %w", err)
+	}
+
 	certTemplate := &x509.Certificate{
 		Signature:          csr.Signature,
 		SignatureAlgorithm: csr.SignatureAlgorithm,
 		PublicKeyAlgorithm: csr.PublicKeyAlgorithm,
 		PublicKey:          csr.PublicKey,
-		SerialNumber:       big.NewInt(2),
+		SerialNumber:       serial,
 		Issuer:             n.activeKeypair.SignedCert.Subject,
 		Subject:            csr.Subject,
 		EmailAddresses:     csr.EmailAddresses,
-		NotBefore:          time.Now().Add(-5 * time.Minute).UTC(),
+		NotBefore:          time.Now().Add(-ClockSkew).UTC(),
 		NotAfter:           time.Now().Add(24 * time.Hour).UTC(),
 		KeyUsage:           x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
 		ExtKeyUsage:        []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
@@ -334,7 +346,7 @@ func createCertSignedBy(signer, signee KeyPair, lifetime time.Duration) (*x509.C
 		SerialNumber:       serial,
 		Issuer:             pkix.Name{CommonName: rootCAName},
 		Subject:            pkix.Name{CommonName: rootCAName},
-		NotBefore:          time.Now().Add(-5 * time.Minute).UTC(),
+		NotBefore:          time.Now().Add(-ClockSkew).UTC(),
 		NotAfter:           time.Now().Add(lifetime).UTC(),
 		KeyUsage: x509.KeyUsageCertSign |
 			x509.KeyUsageDigitalSignature |
@@ -380,15 +392,76 @@ func createCertSignedBy(signer, signee KeyPair, lifetime time.Duration) (*x509.C
 	return cert, rawCert, nil
 }
 
+// loadFromDB loads the newest two certificates from the database. The newest
+// certificate is the active one.
 func (n *NativeCertificateProvider) loadFromDB() error {
-	// TODO:
-	return fmt.Errorf("persistence not implemented")
+	certs, err := data.ListCACertificates(n.db)
+	if err != nil {
+		return fmt.Errorf("loading certificates: %w", err)
+	}
+
+	keyPairs := []*KeyPair{&n.activeKeypair, &n.previousKeypair}
+	for i := range keyPairs {
+		if i >= len(certs) {
+			break
+		}
+
+		keyPair, err := keyPairFromModel(certs[len(certs)-1-i])
+		if err != nil {
+			return err
+		}
+
+		*keyPairs[i] = *keyPair
+	}
+
+	return nil
 }
 
 // saveToDB stores new certs to the database. Used when rotating keys.
 func (n *NativeCertificateProvider) saveToDB() error {
-	// TODO:
-	return fmt.Errorf("persistence not implemented")
+	certs := []models.CACertificate{}
+
+	for _, keyPair := range []KeyPair{n.previousKeypair, n.activeKeypair} {
+		if keyPair.SignedCert == nil {
+			continue
+		}
+
+		certs = append(certs, models.CACertificate{
+			KeyAlgorithm:     keyPair.KeyAlgorithm,
+			SigningAlgorithm: keyPair.SigningAlgorithm,
+			PublicKey:        keyPair.PublicKey,
+			PrivateKey:       models.EncryptedAtRestBytes(keyPair.PrivateKey),
+			SignedCertPEM:    keyPair.SignedCertPEM,
+			ExpiresAt:        keyPair.SignedCert.NotAfter,
+		})
+	}
+
+	if err := data.SaveCACertificates(n.db, certs); err != nil {
+		return fmt.Errorf("saving certificates: %w", err)
+	}
+
+	return nil
+}
+
+func keyPairFromModel(cert models.CACertificate) (*KeyPair, error) {
+	p, _ := pem.Decode(cert.SignedCertPEM)
+	if p == nil {
+		return nil, fmt.Errorf("decoding certificate %s: no pem data", cert.ID)
+	}
+
+	signedCert, err := x509.ParseCertificate(p.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parsing certificate %s: %w", cert.ID, err)
+	}
+
+	return &KeyPair{
+		KeyAlgorithm:     cert.KeyAlgorithm,
+		SigningAlgorithm: cert.SigningAlgorithm,
+		PublicKey:        cert.PublicKey,
+		PrivateKey:       ed25519.PrivateKey(cert.PrivateKey),
+		SignedCertPEM:    cert.SignedCertPEM,
+		SignedCert:       signedCert,
+	}, nil
 }
 
 func (n *NativeCertificateProvider) TLSCertificates() ([]tls.Certificate, error) {